@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	apihandlers "github.com/zjpiazza/plantastic/cmd/api/internal/handlers"
+	"github.com/zjpiazza/plantastic/internal/auth"
+)
+
+// LocalAuthHandler mints tokens for the local auth provider's static users.
+// It is only mounted when AUTH_PROVIDER=local, so it never exists alongside Clerk.
+type LocalAuthHandler struct {
+	authenticator *auth.LocalAuthenticator
+}
+
+// NewLocalAuthHandler creates a new local auth handler
+func NewLocalAuthHandler(authenticator *auth.LocalAuthenticator) *LocalAuthHandler {
+	return &LocalAuthHandler{authenticator: authenticator}
+}
+
+type IssueTokenRequest struct {
+	UserID string `json:"user_id" binding:"required"`
+}
+
+// IssueToken signs a token for one of the configured local users
+func (h *LocalAuthHandler) IssueToken(c *gin.Context) {
+	var req IssueTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apihandlers.WriteProblem(c, http.StatusBadRequest, apihandlers.CodeInvalidRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	token, expiresAt, err := h.authenticator.Issue(req.UserID)
+	if err != nil {
+		if err == auth.ErrUnknownUser {
+			apihandlers.WriteProblem(c, http.StatusNotFound, apihandlers.CodeNotFound, "Unknown local user")
+			return
+		}
+		apihandlers.WriteProblem(c, http.StatusInternalServerError, apihandlers.CodeInternal, "Failed to issue token: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":      token,
+		"token_type": "Bearer",
+		"expires_at": expiresAt,
+	})
+}
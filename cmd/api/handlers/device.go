@@ -2,145 +2,459 @@ package handlers
 
 import (
 	// "encoding/json" // No longer needed for AuthenticateDevice if using gin.BindJSON
+	"context"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
-	// Keep for Clerk initialization if still needed here, though it's often global
-	// "github.com/clerk/clerk-sdk-go/v2" // Clerk client instance not directly used in this version
-	"github.com/clerk/clerk-sdk-go/v2/jwt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	apihandlers "github.com/zjpiazza/plantastic/cmd/api/internal/handlers"
+	"github.com/zjpiazza/plantastic/internal/auth"
 	"github.com/zjpiazza/plantastic/internal/device"
+	"github.com/zjpiazza/plantastic/internal/models"
+	"github.com/zjpiazza/plantastic/internal/ratelimit"
+)
 
-	// "github.com/zjpiazza/plantastic/internal/models" // Not directly used in handlers
-	"log"
+// DeviceCodeGrantType is the grant_type for polling the token endpoint (RFC 8628 section 3.4).
+const DeviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+// OAuth error codes returned by the device endpoints (RFC 8628 section 3.5, RFC 6749 sections 4.1.2.1 and 5.2).
+const (
+	oauthAuthorizationPending   = "authorization_pending"
+	oauthSlowDown               = "slow_down"
+	oauthExpiredToken           = "expired_token"
+	oauthAccessDenied           = "access_denied"
+	oauthInvalidGrant           = "invalid_grant"
+	oauthInvalidRequest         = "invalid_request"
+	oauthUnsupportedGrantType   = "unsupported_grant_type"
+	oauthServerError            = "server_error"
+	oauthTemporarilyUnavailable = "temporarily_unavailable"
 )
 
+// DeviceRateLimits throttles the public device flow endpoints. Code issuance
+// is limited per client IP and per device_id; token polling per client IP and
+// per device_code. A nil limiter disables that check.
+type DeviceRateLimits struct {
+	CodePerIP     *ratelimit.Limiter
+	CodePerDevice *ratelimit.Limiter
+	PollPerIP     *ratelimit.Limiter
+	PollPerDevice *ratelimit.Limiter
+}
+
+// DefaultDeviceRateLimits returns limits generous enough for well-behaved
+// clients polling at the RFC 8628 interval.
+func DefaultDeviceRateLimits() DeviceRateLimits {
+	return DeviceRateLimits{
+		CodePerIP:     ratelimit.New(10, time.Minute),
+		CodePerDevice: ratelimit.New(3, time.Minute),
+		PollPerIP:     ratelimit.New(60, time.Minute),
+		PollPerDevice: ratelimit.New(30, time.Minute),
+	}
+}
+
+// Sweep drops idle keys from every limiter. Its signature matches
+// scheduler.JobFunc.
+func (l DeviceRateLimits) Sweep(ctx context.Context) (map[string]int64, error) {
+	stats := map[string]int64{}
+	for name, limiter := range map[string]*ratelimit.Limiter{
+		"code_per_ip":     l.CodePerIP,
+		"code_per_device": l.CodePerDevice,
+		"poll_per_ip":     l.PollPerIP,
+		"poll_per_device": l.PollPerDevice,
+	} {
+		if limiter == nil {
+			continue
+		}
+		s, err := limiter.Sweep(ctx)
+		if err != nil {
+			return stats, err
+		}
+		stats[name+"_keys_removed"] = s["keys_removed"]
+	}
+	return stats, nil
+}
+
 // DeviceHandler handles device code authentication
 type DeviceHandler struct {
 	deviceManager *device.Manager
-	// clerk         *clerk.Client // Clerk client not directly used now in handlers for these flows
+	authenticator auth.Authenticator // Verifies session tokens from the configured provider
+	webURL        string             // Base URL of the web app that serves /link
+	limits        DeviceRateLimits
 }
 
-// NewDeviceHandler creates a new device handler
-func NewDeviceHandler(deviceManager *device.Manager) (*DeviceHandler, error) {
-	// Clerk key is set globally in main.go, so explicit client init might not be needed here
-	// unless specific client features are used. For jwt.Verify, global SetKey is sufficient.
-	// clerkClient, err := clerk.NewClient(os.Getenv("CLERK_SECRET_KEY"))
-	// if err != nil {
-	// 	return nil, fmt.Errorf("failed to create clerk client: %w", err)
-	// }
+// NewDeviceHandler creates a new device handler. webURL is the public base URL
+// of the web app, used to build verification_uri.
+func NewDeviceHandler(deviceManager *device.Manager, authenticator auth.Authenticator, webURL string, limits DeviceRateLimits) (*DeviceHandler, error) {
+	if authenticator == nil {
+		return nil, fmt.Errorf("authenticator cannot be nil")
+	}
+	if webURL == "" {
+		return nil, fmt.Errorf("web URL cannot be empty")
+	}
 	return &DeviceHandler{
 		deviceManager: deviceManager,
-		// clerk: clerkClient,
+		authenticator: authenticator,
+		webURL:        strings.TrimRight(webURL, "/"),
+		limits:        limits,
 	}, nil
 }
 
-type GenerateCodeRequest struct {
-	DeviceID string `json:"device_id" binding:"required"`
+// allow checks key against limiter and, when the caller is over its limit,
+// writes a 429 slow_down response with Retry-After and returns false.
+func allow(c *gin.Context, limiter *ratelimit.Limiter, key string) bool {
+	if limiter == nil || key == "" {
+		return true
+	}
+	ok, retryAfter := limiter.Allow(key)
+	if ok {
+		return true
+	}
+	seconds := int(retryAfter.Round(time.Second).Seconds())
+	if seconds < 1 {
+		seconds = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	oauthError(c, http.StatusTooManyRequests, oauthSlowDown, "rate limit exceeded")
+	return false
 }
 
-// GenerateCode generates a new user code for a given DeviceID
-func (h *DeviceHandler) GenerateCode(c *gin.Context) {
-	var req GenerateCodeRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+type DeviceAuthorizationRequest struct {
+	ClientID   string `form:"client_id" json:"client_id"`
+	DeviceID   string `form:"device_id" json:"device_id"`     // Optional; generated if the client doesn't track one
+	DeviceName string `form:"device_name" json:"device_name"` // Optional; shown on the devices page
+}
+
+// DeviceAuthorization starts the device grant (RFC 8628 section 3.1). It accepts
+// form-encoded or JSON bodies and returns the device_code/user_code pair.
+func (h *DeviceHandler) DeviceAuthorization(c *gin.Context) {
+	var req DeviceAuthorizationRequest
+	if err := c.ShouldBind(&req); err != nil {
+		oauthError(c, http.StatusBadRequest, oauthInvalidRequest, err.Error())
+		return
+	}
+	if !allow(c, h.limits.CodePerIP, c.ClientIP()) || !allow(c, h.limits.CodePerDevice, req.DeviceID) {
 		return
 	}
+	if req.DeviceID == "" {
+		req.DeviceID = uuid.New().String()
+	}
 
-	userCode, err := h.deviceManager.NewCode(req.DeviceID)
+	name := req.DeviceName
+	if name == "" {
+		name = req.ClientID
+	}
+	if name == "" {
+		name = "Unnamed device"
+	}
+	authorization, err := h.deviceManager.NewCode(c.Request.Context(), req.DeviceID, device.ClientInfo{
+		Name:      name,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate code: " + err.Error()})
+		switch {
+		case errors.Is(err, device.ErrDeviceIDInUse):
+			oauthError(c, http.StatusConflict, oauthInvalidRequest, err.Error())
+		case errors.Is(err, device.ErrUserCodeExhausted):
+			oauthError(c, http.StatusServiceUnavailable, oauthTemporarilyUnavailable, err.Error())
+		default:
+			log.Printf("[DeviceAuthorization] Error generating code for device %s: %v", req.DeviceID, err)
+			oauthError(c, http.StatusInternalServerError, oauthServerError, "failed to generate code")
+		}
 		return
 	}
-	// Consider also returning the verification URI base path if it's fixed or configurable
+
+	verificationURI := h.webURL + "/link"
+	c.Header("Cache-Control", "no-store")
 	c.JSON(http.StatusOK, gin.H{
-		"user_code":        userCode,
-		"verification_uri": "/verify-device", // Example, TUI would append this to base URL
-		"expires_in":       int(device.UserCodeDuration.Seconds()),
-		"interval":         5, // Suggested polling interval in seconds
+		"device_code":               authorization.DeviceCode,
+		"user_code":                 authorization.UserCode,
+		"verification_uri":          verificationURI,
+		"verification_uri_complete": verificationURI + "/" + url.PathEscape(authorization.UserCode),
+		"expires_in":                int(time.Until(authorization.ExpiresAt).Seconds()),
+		"interval":                  int(authorization.Interval.Seconds()),
 	})
 }
 
-// CheckStatus checks the status of a device code (user_code)
-func (h *DeviceHandler) CheckStatus(c *gin.Context) {
-	userCode := c.Query("code") // Changed to query parameter for GET
-	if userCode == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "user_code query parameter is required"})
+type TokenRequest struct {
+	GrantType    string `form:"grant_type" json:"grant_type" binding:"required"`
+	DeviceCode   string `form:"device_code" json:"device_code"`
+	RefreshToken string `form:"refresh_token" json:"refresh_token"`
+	ClientID     string `form:"client_id" json:"client_id"`
+}
+
+// Token is the OAuth token endpoint. It exchanges a device_code for tokens once
+// the user has approved the request (RFC 8628 section 3.4), and also accepts the
+// standard refresh_token grant.
+func (h *DeviceHandler) Token(c *gin.Context) {
+	var req TokenRequest
+	if err := c.ShouldBind(&req); err != nil {
+		oauthError(c, http.StatusBadRequest, oauthInvalidRequest, err.Error())
 		return
 	}
 
-	log.Printf("[CheckStatus] Called for user_code: %s", userCode) // Log entry
-
-	token, err := h.deviceManager.GetTokenForUserCode(userCode)
-	if err != nil {
-		log.Printf("[CheckStatus] Error from GetTokenForUserCode for %s: %v", userCode, err) // Log error
-		if err.Error() == "token not yet available for this user code" {
-			c.JSON(http.StatusOK, gin.H{"status": "pending_activation"})
+	var (
+		pair *device.TokenPair
+		err  error
+	)
+	switch req.GrantType {
+	case DeviceCodeGrantType:
+		if req.DeviceCode == "" {
+			oauthError(c, http.StatusBadRequest, oauthInvalidRequest, "device_code is required")
+			return
+		}
+		if !allow(c, h.limits.PollPerIP, c.ClientIP()) || !allow(c, h.limits.PollPerDevice, device.HashToken(req.DeviceCode)) {
 			return
 		}
-		if err.Error() == "invalid user code" || err.Error() == "user code expired and was not activated" {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error(), "status": "failed"})
+		pair, err = h.deviceManager.PollDeviceCode(c.Request.Context(), req.DeviceCode)
+	case "refresh_token":
+		if req.RefreshToken == "" {
+			oauthError(c, http.StatusBadRequest, oauthInvalidRequest, "refresh_token is required")
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error checking status: " + err.Error(), "status": "failed"})
+		pair, err = h.deviceManager.RefreshTokens(c.Request.Context(), req.RefreshToken)
+	default:
+		oauthError(c, http.StatusBadRequest, oauthUnsupportedGrantType, "unsupported grant_type "+req.GrantType)
 		return
 	}
 
-	log.Printf("[CheckStatus] Token retrieved for %s: %s", userCode, token) // Log retrieved token
-
-	// Token found, now verify it with Clerk (as before)
-	claims, err := jwt.Verify(c.Request.Context(), &jwt.VerifyParams{Token: token})
 	if err != nil {
-		log.Printf("[CheckStatus] jwt.Verify failed for token of user_code %s. Token: %s, Error: %v", userCode, token, err) // Log verification error
-		// This case implies the token we stored from Clerk is somehow invalid, which is concerning.
-		// Or the Clerk keys changed, or token is malformed.
-		h.deviceManager.SetTokenForUserCode(userCode, "") // Clear the invalid token? Or handle differently.
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Stored token is invalid: " + err.Error(), "status": "failed"})
+		switch {
+		case errors.Is(err, device.ErrAuthorizationPending):
+			oauthError(c, http.StatusBadRequest, oauthAuthorizationPending, err.Error())
+		case errors.Is(err, device.ErrSlowDown):
+			oauthError(c, http.StatusBadRequest, oauthSlowDown, err.Error())
+		case errors.Is(err, device.ErrExpiredNotActivated):
+			oauthError(c, http.StatusBadRequest, oauthExpiredToken, err.Error())
+		case errors.Is(err, device.ErrAccessDenied):
+			oauthError(c, http.StatusBadRequest, oauthAccessDenied, err.Error())
+		case errors.Is(err, device.ErrInvalidDeviceCode), errors.Is(err, device.ErrTokensAlreadyIssued), errors.Is(err, device.ErrInvalidToken):
+			// Tokens are only handed out once; a second poll must not receive them again.
+			oauthError(c, http.StatusBadRequest, oauthInvalidGrant, err.Error())
+		default:
+			log.Printf("[Token] Error handling %s grant: %v", req.GrantType, err)
+			oauthError(c, http.StatusInternalServerError, oauthServerError, err.Error())
+		}
 		return
 	}
 
-	log.Printf("[CheckStatus] jwt.Verify successful for user_code %s. Claims Subject: %s", userCode, claims.Subject) // Log success
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, tokenPairResponse(pair))
+}
 
-	c.JSON(http.StatusOK, gin.H{
-		"status":     "activated",
-		"token":      token,
-		"token_type": "Bearer", // Standard OAuth2 practice
-	})
+// oauthError writes an OAuth 2.0 error response (RFC 6749 section 5.2)
+func oauthError(c *gin.Context, status int, code, description string) {
+	c.Header("Cache-Control", "no-store")
+	c.JSON(status, gin.H{"error": code, "error_description": description})
 }
 
 type AuthenticateDeviceRequest struct {
 	UserCode string `json:"user_code" binding:"required"`
-	Token    string `json:"clerk_session_token" binding:"required"` // This is the JWT from Clerk after web auth
+	Token    string `json:"clerk_session_token" binding:"required"` // Session token from the auth provider after web auth (name kept for compatibility)
 }
 
-// AuthenticateDevice is called by the web flow after user authenticates with Clerk.
-// It links the Clerk session token with the UserCode.
+// AuthenticateDevice is called by the web flow after user authenticates with the auth provider.
+// It links the session token with the UserCode.
 func (h *DeviceHandler) AuthenticateDevice(c *gin.Context) {
 	var req AuthenticateDeviceRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		apihandlers.WriteProblem(c, http.StatusBadRequest, apihandlers.CodeInvalidRequest, "Invalid request body: "+err.Error())
 		return
 	}
 
-	// First, verify the provided Clerk session token to ensure it's valid
-	// This step is crucial: the web frontend is claiming "this user is authenticated by Clerk,
+	// First, verify the provided session token to ensure it's valid
+	// This step is crucial: the web frontend is claiming "this user is authenticated,
 	// and here's their token, and they want to activate this user_code".
 	// We must verify the token before trusting it.
-	_, err := jwt.Verify(c.Request.Context(), &jwt.VerifyParams{Token: req.Token})
+	principal, err := h.authenticator.Authenticate(c.Request.Context(), req.Token)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid Clerk session token: " + err.Error()})
+		apihandlers.WriteProblem(c, http.StatusUnauthorized, apihandlers.CodeUnauthorized, "Invalid session token: "+err.Error())
 		return
 	}
 
-	// Now, try to associate this verified Clerk token with the user_code
-	err = h.deviceManager.SetTokenForUserCode(req.UserCode, req.Token)
+	// Now, bind the user_code to the verified user; the device mints its own tokens on its next poll
+	err = h.deviceManager.LinkUserCode(c.Request.Context(), req.UserCode, principal.UserID)
 	if err != nil {
-		// Handle specific errors from SetTokenForUserCode
-		if err.Error() == "invalid user code" || err.Error() == "user code expired" || err.Error() == "user code already activated" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot link device: " + err.Error()})
+		// Handle specific errors from LinkUserCode
+		if errors.Is(err, device.ErrInvalidUserCode) || errors.Is(err, device.ErrUserCodeExpired) || errors.Is(err, device.ErrAlreadyActivated) {
+			apihandlers.WriteProblem(c, http.StatusBadRequest, apihandlers.CodeInvalidRequest, err.Error())
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to link device: " + err.Error()})
+		apihandlers.WriteProblem(c, http.StatusInternalServerError, apihandlers.CodeInternal, "Failed to link device: "+err.Error())
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Device linking initiated successfully. TUI can now poll for the token."})
 }
+
+type DenyDeviceRequest struct {
+	UserCode string `json:"user_code" binding:"required"`
+}
+
+// DenyDevice is called by the web flow when the user rejects a link request.
+// The device's next poll fails with access_denied.
+func (h *DeviceHandler) DenyDevice(c *gin.Context) {
+	var req DenyDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apihandlers.WriteProblem(c, http.StatusBadRequest, apihandlers.CodeInvalidRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	if err := h.deviceManager.DenyUserCode(c.Request.Context(), req.UserCode); err != nil {
+		if errors.Is(err, device.ErrInvalidUserCode) || errors.Is(err, device.ErrUserCodeExpired) || errors.Is(err, device.ErrAlreadyActivated) {
+			apihandlers.WriteProblem(c, http.StatusBadRequest, apihandlers.CodeInvalidRequest, err.Error())
+			return
+		}
+		apihandlers.WriteProblem(c, http.StatusInternalServerError, apihandlers.CodeInternal, "Failed to deny device: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Device request denied."})
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// RefreshToken exchanges a device refresh token for a new access/refresh token pair
+func (h *DeviceHandler) RefreshToken(c *gin.Context) {
+	var req RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apihandlers.WriteProblem(c, http.StatusBadRequest, apihandlers.CodeInvalidRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	pair, err := h.deviceManager.RefreshTokens(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, device.ErrInvalidToken) {
+			apihandlers.WriteProblem(c, http.StatusUnauthorized, apihandlers.CodeUnauthorized, "Invalid or expired refresh token")
+			return
+		}
+		apihandlers.WriteProblem(c, http.StatusInternalServerError, apihandlers.CodeInternal, "Failed to refresh token: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, tokenPairResponse(pair))
+}
+
+// tokenPairResponse renders a token pair in OAuth2 token-response form
+func tokenPairResponse(pair *device.TokenPair) gin.H {
+	return gin.H{
+		"access_token":       pair.AccessToken,
+		"refresh_token":      pair.RefreshToken,
+		"token_type":         "Bearer", // Standard OAuth2 practice
+		"expires_in":         int(time.Until(pair.AccessTokenExpiresAt).Seconds()),
+		"refresh_expires_in": int(time.Until(pair.RefreshTokenExpiresAt).Seconds()),
+	}
+}
+
+// LinkedDevice is the view of a linked device returned by the device management endpoints
+type LinkedDevice struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	DeviceID   string     `json:"device_id"`
+	LinkedAt   *time.Time `json:"linked_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	IPAddress  string     `json:"ip_address"`
+	UserAgent  string     `json:"user_agent"`
+	Current    bool       `json:"current"` // True if this is the device making the request
+}
+
+func newLinkedDevice(d models.Device, principal *auth.Principal) LinkedDevice {
+	return LinkedDevice{
+		ID:         d.ID,
+		Name:       d.Name,
+		DeviceID:   d.DeviceID,
+		LinkedAt:   d.ActivatedAt,
+		LastUsedAt: d.LastUsedAt,
+		IPAddress:  d.IPAddress,
+		UserAgent:  d.UserAgent,
+		Current:    principal.Provider == device.ProviderName && principal.SessionID == d.ID,
+	}
+}
+
+// requirePrincipal returns the authenticated caller or writes a 401 response
+func requirePrincipal(c *gin.Context) (*auth.Principal, bool) {
+	principal, ok := auth.PrincipalFromContext(c.Request.Context())
+	if !ok || principal.UserID == "" {
+		apihandlers.WriteProblem(c, http.StatusUnauthorized, apihandlers.CodeUnauthorized, "Unauthorized")
+		return nil, false
+	}
+	return principal, true
+}
+
+// ListDevices lists the caller's linked devices
+func (h *DeviceHandler) ListDevices(c *gin.Context) {
+	principal, ok := requirePrincipal(c)
+	if !ok {
+		return
+	}
+
+	devices, err := h.deviceManager.ListDevices(c.Request.Context(), principal.UserID)
+	if err != nil {
+		apihandlers.WriteProblem(c, http.StatusInternalServerError, apihandlers.CodeInternal, "Failed to fetch devices")
+		return
+	}
+
+	response := make([]LinkedDevice, 0, len(devices))
+	for _, d := range devices {
+		response = append(response, newLinkedDevice(d, principal))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+type RenameDeviceRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// RenameDevice changes the display name of one of the caller's devices
+func (h *DeviceHandler) RenameDevice(c *gin.Context) {
+	principal, ok := requirePrincipal(c)
+	if !ok {
+		return
+	}
+
+	var req RenameDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apihandlers.WriteProblem(c, http.StatusBadRequest, apihandlers.CodeInvalidRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	d, err := h.deviceManager.RenameDevice(c.Request.Context(), principal.UserID, c.Param("device_id"), req.Name)
+	if err != nil {
+		switch {
+		case errors.Is(err, device.ErrDeviceNotFound):
+			apihandlers.WriteProblem(c, http.StatusNotFound, apihandlers.CodeNotFound, "Device not found")
+		case errors.Is(err, device.ErrInvalidDeviceName):
+			apihandlers.WriteProblem(c, http.StatusBadRequest, apihandlers.CodeValidationFailed, err.Error())
+		default:
+			apihandlers.WriteProblem(c, http.StatusInternalServerError, apihandlers.CodeInternal, "Failed to rename device")
+		}
+		return
+	}
+	c.JSON(http.StatusOK, newLinkedDevice(*d, principal))
+}
+
+// RevokeDevice unlinks one of the caller's devices; its tokens stop working immediately
+func (h *DeviceHandler) RevokeDevice(c *gin.Context) {
+	principal, ok := requirePrincipal(c)
+	if !ok {
+		return
+	}
+
+	if err := h.deviceManager.RevokeDevice(c.Request.Context(), principal.UserID, c.Param("device_id")); err != nil {
+		if errors.Is(err, device.ErrDeviceNotFound) {
+			apihandlers.WriteProblem(c, http.StatusNotFound, apihandlers.CodeNotFound, "Device not found")
+			return
+		}
+		apihandlers.WriteProblem(c, http.StatusInternalServerError, apihandlers.CodeInternal, "Failed to revoke device")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Device revoked successfully"})
+}
@@ -0,0 +1,217 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zjpiazza/plantastic/cmd/api/handlers"
+	"github.com/zjpiazza/plantastic/internal/auth"
+	"github.com/zjpiazza/plantastic/internal/database"
+	"github.com/zjpiazza/plantastic/internal/device"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// stubAuthenticator rejects every token; the device routes under test only
+// use it to satisfy NewDeviceHandler.
+type stubAuthenticator struct{}
+
+func (stubAuthenticator) Name() string { return "stub" }
+
+func (stubAuthenticator) Authenticate(ctx context.Context, token string) (*auth.Principal, error) {
+	return nil, auth.ErrInvalidToken
+}
+
+// deviceTestEnv is an API wired to a SQLite-backed device manager. Device
+// management routes authenticate with device access tokens, as in production.
+type deviceTestEnv struct {
+	manager *device.Manager
+	router  *gin.Engine
+}
+
+func newDeviceTestEnv(t *testing.T) *deviceTestEnv {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	db, err := database.Open(database.Config{Driver: database.DriverSQLite, DSN: database.Memory}, &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+	_, err = database.MigrateUp(db)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	manager := device.NewManager(db)
+	h, err := handlers.NewDeviceHandler(manager, stubAuthenticator{}, "http://web.test", handlers.DeviceRateLimits{})
+	require.NoError(t, err)
+
+	authenticator := device.NewAuthenticator(manager)
+	router := gin.New()
+	router.POST("/device/token", h.Token)
+	protected := router.Group("/", func(c *gin.Context) {
+		principal, err := authenticator.Authenticate(c.Request.Context(), auth.BearerToken(c.Request))
+		if err != nil {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principal))
+	})
+	protected.GET("/devices", h.ListDevices)
+	protected.PATCH("/devices/:device_id", h.RenameDevice)
+	protected.DELETE("/devices/:device_id", h.RevokeDevice)
+
+	return &deviceTestEnv{manager: manager, router: router}
+}
+
+// linkedDevice is a device that completed the device flow.
+type linkedDevice struct {
+	id           string // Record ID used in /devices/:device_id
+	accessToken  string
+	refreshToken string
+}
+
+// link runs the device flow for deviceID on behalf of userID.
+func (e *deviceTestEnv) link(t *testing.T, deviceID, userID string) linkedDevice {
+	t.Helper()
+	ctx := context.Background()
+	authorization, err := e.manager.NewCode(ctx, deviceID, device.ClientInfo{Name: deviceID})
+	require.NoError(t, err)
+	require.NoError(t, e.manager.LinkUserCode(ctx, authorization.UserCode, userID))
+	pair, err := e.manager.PollDeviceCode(ctx, authorization.DeviceCode)
+	require.NoError(t, err)
+	d, err := e.manager.AuthenticateAccessToken(ctx, pair.AccessToken)
+	require.NoError(t, err)
+	return linkedDevice{id: d.ID, accessToken: pair.AccessToken, refreshToken: pair.RefreshToken}
+}
+
+func (e *deviceTestEnv) do(method, path, token string, body interface{}) *httptest.ResponseRecorder {
+	var b []byte
+	if body != nil {
+		b, _ = json.Marshal(body)
+	}
+	req, _ := http.NewRequest(method, path, bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	e.router.ServeHTTP(w, req)
+	return w
+}
+
+func (e *deviceTestEnv) refresh(refreshToken string) *httptest.ResponseRecorder {
+	form := url.Values{"grant_type": {"refresh_token"}, "refresh_token": {refreshToken}}
+	req, _ := http.NewRequest(http.MethodPost, "/device/token", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	e.router.ServeHTTP(w, req)
+	return w
+}
+
+func TestListDevices_OnlyCallersDevices(t *testing.T) {
+	env := newDeviceTestEnv(t)
+	laptop := env.link(t, "laptop", "user-1")
+	env.link(t, "phone", "user-1")
+	env.link(t, "other", "user-2")
+
+	w := env.do(http.MethodGet, "/devices", laptop.accessToken, nil)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var devices []handlers.LinkedDevice
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &devices))
+	names := map[string]bool{}
+	for _, d := range devices {
+		names[d.Name] = true
+		assert.Equal(t, d.ID == laptop.id, d.Current, "only the calling device is current")
+	}
+	assert.Equal(t, map[string]bool{"laptop": true, "phone": true}, names)
+}
+
+func TestRenameDevice(t *testing.T) {
+	env := newDeviceTestEnv(t)
+	laptop := env.link(t, "laptop", "user-1")
+
+	w := env.do(http.MethodPatch, "/devices/"+laptop.id, laptop.accessToken, gin.H{"name": "  Work laptop  "})
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var renamed handlers.LinkedDevice
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &renamed))
+	assert.Equal(t, "Work laptop", renamed.Name)
+}
+
+func TestRenameDevice_InvalidName(t *testing.T) {
+	env := newDeviceTestEnv(t)
+	laptop := env.link(t, "laptop", "user-1")
+
+	tests := []struct {
+		name string
+		body interface{}
+	}{
+		{"missing", gin.H{}},
+		{"blank", gin.H{"name": "   "}},
+		{"too long", gin.H{"name": strings.Repeat("x", device.MaxDeviceNameLength+1)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := env.do(http.MethodPatch, "/devices/"+laptop.id, laptop.accessToken, tt.body)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+
+	d, err := env.manager.AuthenticateAccessToken(context.Background(), laptop.accessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "laptop", d.Name, "a rejected rename must not change the name")
+}
+
+func TestDeviceManagement_OtherUsersDeviceNotFound(t *testing.T) {
+	env := newDeviceTestEnv(t)
+	mine := env.link(t, "laptop", "user-1")
+	theirs := env.link(t, "other", "user-2")
+
+	w := env.do(http.MethodPatch, "/devices/"+theirs.id, mine.accessToken, gin.H{"name": "Mine now"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = env.do(http.MethodDelete, "/devices/"+theirs.id, mine.accessToken, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// The other user's device is untouched and still works.
+	w = env.do(http.MethodGet, "/devices", theirs.accessToken, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var devices []handlers.LinkedDevice
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &devices))
+	require.Len(t, devices, 1)
+	assert.Equal(t, "other", devices[0].Name)
+}
+
+func TestRevokeDevice_InvalidatesTokens(t *testing.T) {
+	env := newDeviceTestEnv(t)
+	laptop := env.link(t, "laptop", "user-1")
+	phone := env.link(t, "phone", "user-1")
+
+	w := env.do(http.MethodDelete, "/devices/"+phone.id, laptop.accessToken, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = env.do(http.MethodGet, "/devices", phone.accessToken, nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "the revoked access token must stop working")
+
+	w = env.refresh(phone.refreshToken)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "the revoked refresh token must stop working")
+	assert.Contains(t, w.Body.String(), `"invalid_grant"`)
+
+	w = env.do(http.MethodDelete, "/devices/"+phone.id, laptop.accessToken, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code, "a revoked device cannot be revoked again")
+
+	w = env.refresh(laptop.refreshToken)
+	assert.Equal(t, http.StatusOK, w.Code, "other devices keep working")
+}
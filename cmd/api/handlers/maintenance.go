@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	apihandlers "github.com/zjpiazza/plantastic/cmd/api/internal/handlers"
+	"github.com/zjpiazza/plantastic/internal/scheduler"
+)
+
+// MaintenanceHandler exposes the background job scheduler's state to administrators
+type MaintenanceHandler struct {
+	scheduler *scheduler.Scheduler
+	admins    map[string]bool // User IDs allowed to see maintenance state
+}
+
+// NewMaintenanceHandler creates a new maintenance handler. Only the users in
+// adminUserIDs may use it; with none, every request is forbidden.
+func NewMaintenanceHandler(s *scheduler.Scheduler, adminUserIDs []string) *MaintenanceHandler {
+	admins := map[string]bool{}
+	for _, id := range adminUserIDs {
+		if id = strings.TrimSpace(id); id != "" {
+			admins[id] = true
+		}
+	}
+	return &MaintenanceHandler{scheduler: s, admins: admins}
+}
+
+// Jobs lists every registered background job with its last-run stats
+func (h *MaintenanceHandler) Jobs(c *gin.Context) {
+	principal, ok := requirePrincipal(c)
+	if !ok {
+		return
+	}
+	if !h.admins[principal.UserID] {
+		apihandlers.WriteProblem(c, http.StatusForbidden, apihandlers.CodeForbidden, "Only administrators can view background jobs")
+		return
+	}
+	c.JSON(http.StatusOK, h.scheduler.Status())
+}
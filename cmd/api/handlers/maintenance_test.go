@@ -0,0 +1,43 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/zjpiazza/plantastic/cmd/api/handlers"
+	"github.com/zjpiazza/plantastic/internal/auth"
+	"github.com/zjpiazza/plantastic/internal/scheduler"
+)
+
+func TestMaintenanceJobs_AdminsOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := handlers.NewMaintenanceHandler(scheduler.New(), []string{" admin-1 ", ""})
+
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		want      int
+	}{
+		{"anonymous", nil, http.StatusUnauthorized},
+		{"user", &auth.Principal{UserID: "user-1"}, http.StatusForbidden},
+		{"admin", &auth.Principal{UserID: "admin-1"}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/maintenance/jobs", func(c *gin.Context) {
+				if tt.principal != nil {
+					c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), tt.principal))
+				}
+				h.Jobs(c)
+			})
+			req, _ := http.NewRequest(http.MethodGet, "/maintenance/jobs", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
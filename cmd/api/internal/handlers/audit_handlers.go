@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/zjpiazza/plantastic/cmd/api/internal/storage"
+)
+
+// ListAuditHandler lists the audit log of changes to the caller's gardens,
+// beds, tasks, plants and plantings, newest first. It filters by entity,
+// entity_id, actor (an actor ID), actor_type, action and created_from/to.
+func ListAuditHandler(storer storage.AuditStorer, c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	q, ok := parseListQuery(c)
+	if !ok {
+		return
+	}
+	page, err := storer.ListAudit(requestContext(c), userID, q)
+	if err != nil {
+		respondListError(c, err, "the audit log")
+		return
+	}
+	respondWithPage(c, page)
+}
@@ -0,0 +1,65 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/zjpiazza/plantastic/cmd/api/internal/handlers"
+	"github.com/zjpiazza/plantastic/cmd/api/internal/storage"
+	"github.com/zjpiazza/plantastic/internal/models"
+)
+
+// MockAuditStore is a mock implementation of storage.AuditStorer
+type MockAuditStore struct {
+	mock.Mock
+}
+
+func (m *MockAuditStore) ListAudit(ctx context.Context, userID string, q storage.ListQuery) (storage.Page[models.AuditEntry], error) {
+	args := m.Called(userID, q)
+	return args.Get(0).(storage.Page[models.AuditEntry]), args.Error(1)
+}
+
+func TestListAuditHandler_FiltersByEntity(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockAuditStore)
+	entries := []models.AuditEntry{{
+		ID: "a1", UserID: testUserID, ActorType: models.ActorDevice, ActorID: "dev1",
+		Entity: "task", EntityID: "t1", Action: models.AuditUpdate,
+		Before: models.AuditValues{"status": "Pending"}, After: models.AuditValues{"status": "Completed"},
+	}}
+	q := storage.ListQuery{Filters: map[string]string{"entity": "task", "entity_id": "t1"}}
+	mockStore.On("ListAudit", testUserID, q).Return(storage.Page[models.AuditEntry]{Items: entries, NextCursor: "c1"}, nil)
+
+	w := httptest.NewRecorder()
+	c := newAuthedTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/audit?entity=task&entity_id=t1", nil)
+	handlers.ListAuditHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "c1", w.Header().Get(handlers.NextCursorHeader))
+	var actual []models.AuditEntry
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &actual))
+	assert.Equal(t, entries, actual)
+	mockStore.AssertExpectations(t)
+}
+
+func TestListAuditHandler_UnknownFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockAuditStore)
+	mockStore.On("ListAudit", testUserID, mock.Anything).Return(storage.Page[models.AuditEntry]{}, storage.ErrInvalidQuery)
+
+	w := httptest.NewRecorder()
+	c := newAuthedTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/audit?colour=red", nil)
+	handlers.ListAuditHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockStore.AssertExpectations(t)
+}
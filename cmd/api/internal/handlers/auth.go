@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zjpiazza/plantastic/internal/auth"
+)
+
+// PrincipalKey is the Gin context key under which the auth middleware stores
+// the caller's *auth.Principal.
+const PrincipalKey = "auth_principal"
+
+// currentUserID returns the user ID of the authenticated caller, if any.
+func currentUserID(c *gin.Context) (string, bool) {
+	value, exists := c.Get(PrincipalKey)
+	if !exists {
+		return "", false
+	}
+	principal, ok := value.(*auth.Principal)
+	if !ok || principal == nil || principal.UserID == "" {
+		return "", false
+	}
+	return principal.UserID, true
+}
+
+// requireUserID returns the caller's user ID or writes a 401 response.
+// Handlers should return immediately when ok is false.
+func requireUserID(c *gin.Context) (string, bool) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, "Unauthorized")
+		return "", false
+	}
+	return userID, true
+}
+
+// requestContext returns the context to run the caller's storage operations
+// in: the request's, carrying the caller's principal so that the audit log
+// attributes changes to them.
+func requestContext(c *gin.Context) context.Context {
+	ctx := context.Background()
+	if c.Request != nil {
+		ctx = c.Request.Context()
+	}
+	if _, ok := auth.PrincipalFromContext(ctx); ok {
+		return ctx
+	}
+	if value, exists := c.Get(PrincipalKey); exists {
+		if principal, ok := value.(*auth.Principal); ok && principal != nil {
+			ctx = auth.WithPrincipal(ctx, principal)
+		}
+	}
+	return ctx
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -9,80 +10,203 @@ import (
 )
 
 func ListBedsHandler(storer storage.BedStorer, c *gin.Context) {
-	beds, err := storer.GetAllBeds()
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	q, ok := parseListQuery(c)
+	if !ok {
+		return
+	}
+	page, err := storer.ListBeds(requestContext(c), userID, q)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch beds"})
+		respondListError(c, err, "beds")
 		return
 	}
-	c.JSON(http.StatusOK, beds)
+	respondWithPage(c, page)
 }
 
+// ListGardenBedsHandler lists the beds in the garden named by the garden_id
+// path parameter, with the same filters and paging as ListBedsHandler.
+func ListGardenBedsHandler(storer storage.BedStorer, c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	q, ok := parseListQuery(c)
+	if !ok {
+		return
+	}
+	page, err := storer.GetBedsByGardenID(requestContext(c), userID, c.Param("garden_id"), q)
+	if err != nil {
+		if err == storage.ErrRecordNotFound {
+			respondError(c, http.StatusNotFound, "Garden not found")
+			return
+		}
+		respondListError(c, err, "beds")
+		return
+	}
+	respondWithPage(c, page)
+}
+
+// CreateBedHandler creates a bed. Under /gardens/:garden_id/beds the garden
+// comes from the path, overriding any garden_id in the body.
 func CreateBedHandler(storer storage.BedStorer, c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
 	var bed models.Bed
 	if err := c.ShouldBindJSON(&bed); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		respondError(c, http.StatusBadRequest, "Invalid request body")
 		return
 	}
+	if gardenID := c.Param("garden_id"); gardenID != "" {
+		bed.GardenID = gardenID
+	}
 
-	if err := storer.CreateBed(&bed); err != nil {
-		if err == storage.ErrValidation {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed: " + err.Error()})
+	if err := storer.CreateBed(requestContext(c), userID, &bed); err != nil {
+		if errors.Is(err, storage.ErrValidation) {
+			respondValidationError(c, err)
 			return
 		} else if err == storage.ErrConflict {
-			c.JSON(http.StatusConflict, gin.H{"error": "Conflict: " + err.Error()})
+			respondError(c, http.StatusConflict, "Conflict: "+err.Error())
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create bed"})
+		respondStorageError(c, err, "Failed to create bed")
 		return
 	}
+	c.Header("ETag", etag(bed.Version))
 	c.JSON(http.StatusCreated, bed)
 }
 
 func GetBedHandler(storer storage.BedStorer, c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
 	bedID := c.Param("bed_id")
-	bed, err := storer.GetBedByID(bedID)
+	bed, err := storer.GetBedByID(requestContext(c), userID, bedID)
 	if err != nil {
 		if err == storage.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Bed not found"})
+			respondError(c, http.StatusNotFound, "Bed not found")
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch bed"})
+		respondStorageError(c, err, "Failed to fetch bed")
 		return
 	}
+	c.Header("ETag", etag(bed.Version))
 	c.JSON(http.StatusOK, bed)
 }
 
 func UpdateBedHandler(storer storage.BedStorer, c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
 	bedID := c.Param("bed_id")
 	var bedUpdates models.Bed
 	if err := c.ShouldBindJSON(&bedUpdates); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		respondError(c, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 	bedUpdates.ID = bedID // Ensure ID from path is used
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+	bedUpdates.Version = version // The precondition comes from If-Match, never the body
 
-	if err := storer.UpdateBed(&bedUpdates); err != nil {
+	if err := storer.UpdateBed(requestContext(c), userID, &bedUpdates); err != nil {
 		if err == storage.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Bed not found"})
+			respondError(c, http.StatusNotFound, "Bed not found")
+			return
+		} else if err == storage.ErrVersionConflict {
+			respondVersionConflict(c, "Bed")
 			return
-		} else if err == storage.ErrValidation {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed: " + err.Error()})
+		} else if errors.Is(err, storage.ErrValidation) {
+			respondValidationError(c, err)
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to update bed"})
+		respondStorageError(c, err, "Unable to update bed")
 		return
 	}
+	c.Header("ETag", etag(bedUpdates.Version))
 	c.JSON(http.StatusOK, gin.H{"message": "Bed updated successfully"})
 }
 
+// PatchBedHandler applies a JSON merge patch (RFC 7396) to a bed. Moving a
+// bed is done by patching garden_id; see PatchGardenHandler.
+func PatchBedHandler(storer storage.BedStorer, c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	bedID := c.Param("bed_id")
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+	bed, err := storer.GetBedByID(requestContext(c), userID, bedID)
+	if err != nil {
+		if err == storage.ErrRecordNotFound {
+			respondError(c, http.StatusNotFound, "Bed not found")
+			return
+		}
+		respondStorageError(c, err, "Failed to fetch bed")
+		return
+	}
+	if version != 0 && version != bed.Version {
+		respondVersionConflict(c, "Bed")
+		return
+	}
+
+	patched, ok := bindMergePatch(c, bed)
+	if !ok {
+		return
+	}
+	patched.ID = bed.ID
+	patched.UserID = bed.UserID
+	patched.CreatedAt = bed.CreatedAt
+	patched.Version = bed.Version
+
+	if err := storer.UpdateBed(requestContext(c), userID, &patched); err != nil {
+		if err == storage.ErrRecordNotFound {
+			respondError(c, http.StatusNotFound, "Bed not found")
+			return
+		} else if err == storage.ErrVersionConflict {
+			respondVersionConflict(c, "Bed")
+			return
+		} else if errors.Is(err, storage.ErrValidation) {
+			respondValidationError(c, err)
+			return
+		}
+		respondStorageError(c, err, "Unable to update bed")
+		return
+	}
+	c.Header("ETag", etag(patched.Version))
+	c.JSON(http.StatusOK, patched)
+}
+
 func DeleteBedHandler(storer storage.BedStorer, c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
 	bedID := c.Param("bed_id")
-	if err := storer.DeleteBed(bedID); err != nil {
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+	if err := storer.DeleteBed(requestContext(c), userID, bedID, version); err != nil {
 		if err == storage.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Bed not found"})
+			respondError(c, http.StatusNotFound, "Bed not found")
+			return
+		} else if err == storage.ErrVersionConflict {
+			respondVersionConflict(c, "Bed")
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to delete bed"})
+		respondStorageError(c, err, "Unable to delete bed")
 		return
 	}
 	c.AbortWithStatus(http.StatusNoContent)
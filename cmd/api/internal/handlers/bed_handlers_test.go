@@ -2,6 +2,7 @@ package handlers_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -23,44 +24,46 @@ type MockBedStore struct {
 	mock.Mock
 }
 
-func (m *MockBedStore) GetAllBeds() ([]models.Bed, error) {
-	args := m.Called()
+func (m *MockBedStore) GetAllBeds(ctx context.Context, userID string) ([]models.Bed, error) {
+	args := m.Called(userID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]models.Bed), args.Error(1)
 }
 
-func (m *MockBedStore) GetBedByID(bedID string) (models.Bed, error) {
-	args := m.Called(bedID)
+func (m *MockBedStore) ListBeds(ctx context.Context, userID string, q storage.ListQuery) (storage.Page[models.Bed], error) {
+	args := m.Called(userID, q)
+	return args.Get(0).(storage.Page[models.Bed]), args.Error(1)
+}
+
+func (m *MockBedStore) GetBedByID(ctx context.Context, userID, bedID string) (models.Bed, error) {
+	args := m.Called(userID, bedID)
 	if args.Get(0) == nil {
 		return models.Bed{}, args.Error(1)
 	}
 	return args.Get(0).(models.Bed), args.Error(1)
 }
 
-func (m *MockBedStore) CreateBed(bed *models.Bed) error {
-	args := m.Called(bed)
+func (m *MockBedStore) CreateBed(ctx context.Context, userID string, bed *models.Bed) error {
+	args := m.Called(userID, bed)
 	return args.Error(0)
 }
 
-func (m *MockBedStore) UpdateBed(bed *models.Bed) error {
-	args := m.Called(bed)
+func (m *MockBedStore) UpdateBed(ctx context.Context, userID string, bed *models.Bed) error {
+	args := m.Called(userID, bed)
 	return args.Error(0)
 }
 
-func (m *MockBedStore) DeleteBed(bedID string) error {
-	args := m.Called(bedID)
+func (m *MockBedStore) DeleteBed(ctx context.Context, userID, bedID string, version int) error {
+	args := m.Called(userID, bedID, version)
 	return args.Error(0)
 }
 
 // Add GetBedsByGardenID to satisfy the BedStorer interface
-func (m *MockBedStore) GetBedsByGardenID(gardenID string) ([]models.Bed, error) {
-	args := m.Called(gardenID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]models.Bed), args.Error(1)
+func (m *MockBedStore) GetBedsByGardenID(ctx context.Context, userID, gardenID string, q storage.ListQuery) (storage.Page[models.Bed], error) {
+	args := m.Called(userID, gardenID, q)
+	return args.Get(0).(storage.Page[models.Bed]), args.Error(1)
 }
 
 func TestListBedsHandler_Success(t *testing.T) {
@@ -71,10 +74,11 @@ func TestListBedsHandler_Success(t *testing.T) {
 		{ID: "b1", Name: "Test Bed 1", GardenID: "g1", CreatedAt: now, UpdatedAt: now},
 		{ID: "b2", Name: "Test Bed 2", GardenID: "g1", CreatedAt: now, UpdatedAt: now},
 	}
-	mockStore.On("GetAllBeds").Return(expectedBeds, nil)
+	mockStore.On("ListBeds", testUserID, storage.ListQuery{Filters: map[string]string{}}).Return(storage.Page[models.Bed]{Items: expectedBeds}, nil)
 
 	w := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(w)
+	c := newAuthedTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/beds", nil)
 	handlers.ListBedsHandler(mockStore, c)
 
 	assert.Equal(t, http.StatusOK, w.Code)
@@ -92,10 +96,11 @@ func TestListBedsHandler_Success(t *testing.T) {
 func TestListBedsHandler_Error(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockStore := new(MockBedStore)
-	mockStore.On("GetAllBeds").Return(nil, errors.New("db error"))
+	mockStore.On("ListBeds", testUserID, storage.ListQuery{Filters: map[string]string{}}).Return(storage.Page[models.Bed]{}, errors.New("db error"))
 
 	w := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(w)
+	c := newAuthedTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/beds", nil)
 	handlers.ListBedsHandler(mockStore, c)
 
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
@@ -108,10 +113,10 @@ func TestGetBedHandler_Success(t *testing.T) {
 	bedID := "b1"
 	now := time.Now().Truncate(time.Second)
 	expectedBed := models.Bed{ID: bedID, Name: "Test Bed", GardenID: "g1", CreatedAt: now, UpdatedAt: now}
-	mockStore.On("GetBedByID", bedID).Return(expectedBed, nil)
+	mockStore.On("GetBedByID", testUserID, bedID).Return(expectedBed, nil)
 
 	w := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(w)
+	c := newAuthedTestContext(w)
 	c.Params = gin.Params{gin.Param{Key: "bed_id", Value: bedID}}
 
 	handlers.GetBedHandler(mockStore, c)
@@ -130,10 +135,10 @@ func TestGetBedHandler_NotFound(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockStore := new(MockBedStore)
 	bedID := "b_nonexistent"
-	mockStore.On("GetBedByID", bedID).Return(models.Bed{}, storage.ErrRecordNotFound)
+	mockStore.On("GetBedByID", testUserID, bedID).Return(models.Bed{}, storage.ErrRecordNotFound)
 
 	w := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(w)
+	c := newAuthedTestContext(w)
 	c.Params = gin.Params{gin.Param{Key: "bed_id", Value: bedID}}
 
 	handlers.GetBedHandler(mockStore, c)
@@ -142,6 +147,60 @@ func TestGetBedHandler_NotFound(t *testing.T) {
 	mockStore.AssertExpectations(t)
 }
 
+func TestListGardenBedsHandler_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockBedStore)
+	expectedBeds := []models.Bed{{ID: "b1", GardenID: "g1", Name: "Rose Bed"}}
+	mockStore.On("GetBedsByGardenID", testUserID, "g1", storage.ListQuery{Filters: map[string]string{}}).
+		Return(storage.Page[models.Bed]{Items: expectedBeds}, nil)
+
+	w := httptest.NewRecorder()
+	c := newAuthedTestContext(w)
+	c.Params = gin.Params{gin.Param{Key: "garden_id", Value: "g1"}}
+	c.Request, _ = http.NewRequest(http.MethodGet, "/gardens/g1/beds", nil)
+	handlers.ListGardenBedsHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var actualBeds []models.Bed
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &actualBeds))
+	assert.Equal(t, expectedBeds, actualBeds)
+	mockStore.AssertExpectations(t)
+}
+
+func TestListGardenBedsHandler_GardenNotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockBedStore)
+	mockStore.On("GetBedsByGardenID", testUserID, "g404", storage.ListQuery{Filters: map[string]string{}}).
+		Return(storage.Page[models.Bed]{}, storage.ErrRecordNotFound)
+
+	w := httptest.NewRecorder()
+	c := newAuthedTestContext(w)
+	c.Params = gin.Params{gin.Param{Key: "garden_id", Value: "g404"}}
+	c.Request, _ = http.NewRequest(http.MethodGet, "/gardens/g404/beds", nil)
+	handlers.ListGardenBedsHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockStore.AssertExpectations(t)
+}
+
+func TestCreateBedHandler_GardenFromPath(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockBedStore)
+	mockStore.On("CreateBed", testUserID, &models.Bed{Name: "New Bed", GardenID: "g1"}).Return(nil)
+
+	jsonBody, _ := json.Marshal(models.Bed{Name: "New Bed", GardenID: "ignored"})
+	w := httptest.NewRecorder()
+	c := newAuthedTestContext(w)
+	c.Params = gin.Params{gin.Param{Key: "garden_id", Value: "g1"}}
+	c.Request, _ = http.NewRequest(http.MethodPost, "/gardens/g1/beds", bytes.NewBuffer(jsonBody))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handlers.CreateBedHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	mockStore.AssertExpectations(t)
+}
+
 func TestCreateBedHandler_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockStore := new(MockBedStore)
@@ -152,8 +211,8 @@ func TestCreateBedHandler_Success(t *testing.T) {
 	createdBed.CreatedAt = now
 	createdBed.UpdatedAt = now
 
-	mockStore.On("CreateBed", &newBed).Return(nil).Run(func(args mock.Arguments) {
-		arg := args.Get(0).(*models.Bed)
+	mockStore.On("CreateBed", testUserID, &newBed).Return(nil).Run(func(args mock.Arguments) {
+		arg := args.Get(1).(*models.Bed)
 		arg.ID = createdBed.ID
 		arg.CreatedAt = createdBed.CreatedAt
 		arg.UpdatedAt = createdBed.UpdatedAt
@@ -161,7 +220,7 @@ func TestCreateBedHandler_Success(t *testing.T) {
 
 	jsonBody, _ := json.Marshal(newBed)
 	w := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(w)
+	c := newAuthedTestContext(w)
 	c.Request, _ = http.NewRequest(http.MethodPost, "/beds", bytes.NewBuffer(jsonBody))
 	c.Request.Header.Set("Content-Type", "application/json")
 
@@ -181,11 +240,11 @@ func TestCreateBedHandler_ValidationError(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockStore := new(MockBedStore)
 	invalidBed := models.Bed{Name: ""} // Missing GardenID too
-	mockStore.On("CreateBed", &invalidBed).Return(storage.ErrValidation)
+	mockStore.On("CreateBed", testUserID, &invalidBed).Return(storage.ErrValidation)
 
 	jsonBody, _ := json.Marshal(invalidBed)
 	w := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(w)
+	c := newAuthedTestContext(w)
 	c.Request, _ = http.NewRequest(http.MethodPost, "/beds", bytes.NewBuffer(jsonBody))
 	c.Request.Header.Set("Content-Type", "application/json")
 
@@ -201,13 +260,13 @@ func TestUpdateBedHandler_Success(t *testing.T) {
 	bedID := "b1"
 	updates := models.Bed{Name: "Updated Bed Name", GardenID: "g1"}
 
-	mockStore.On("UpdateBed", mock.MatchedBy(func(b *models.Bed) bool {
+	mockStore.On("UpdateBed", testUserID, mock.MatchedBy(func(b *models.Bed) bool {
 		return b.ID == bedID && b.Name == updates.Name && b.GardenID == updates.GardenID
 	})).Return(nil)
 
 	jsonBody, _ := json.Marshal(updates)
 	w := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(w)
+	c := newAuthedTestContext(w)
 	c.Params = gin.Params{gin.Param{Key: "bed_id", Value: bedID}}
 	c.Request, _ = http.NewRequest(http.MethodPut, "/beds/"+bedID, bytes.NewBuffer(jsonBody))
 	c.Request.Header.Set("Content-Type", "application/json")
@@ -218,15 +277,52 @@ func TestUpdateBedHandler_Success(t *testing.T) {
 	mockStore.AssertExpectations(t)
 }
 
+func TestPatchBedHandler_MovesBed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockBedStore)
+	existing := models.Bed{ID: "b1", GardenID: "g1", Name: "Raised", SoilType: "Loam", Version: 1}
+	mockStore.On("GetBedByID", testUserID, "b1").Return(existing, nil)
+	mockStore.On("UpdateBed", testUserID, mock.MatchedBy(func(b *models.Bed) bool {
+		return b.ID == "b1" && b.GardenID == "g2" && b.Name == "Raised" && b.SoilType == "Loam"
+	})).Return(nil)
+
+	w := httptest.NewRecorder()
+	c := newAuthedTestContext(w)
+	c.Params = gin.Params{gin.Param{Key: "bed_id", Value: "b1"}}
+	c.Request, _ = http.NewRequest(http.MethodPatch, "/beds/b1", bytes.NewBufferString(`{"garden_id": "g2"}`))
+
+	handlers.PatchBedHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockStore.AssertExpectations(t)
+}
+
+func TestPatchBedHandler_NotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockBedStore)
+	mockStore.On("GetBedByID", testUserID, "missing").Return(models.Bed{}, storage.ErrRecordNotFound)
+
+	w := httptest.NewRecorder()
+	c := newAuthedTestContext(w)
+	c.Params = gin.Params{gin.Param{Key: "bed_id", Value: "missing"}}
+	c.Request, _ = http.NewRequest(http.MethodPatch, "/beds/missing", bytes.NewBufferString(`{"name": "x"}`))
+
+	handlers.PatchBedHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockStore.AssertExpectations(t)
+}
+
 func TestDeleteBedHandler_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockStore := new(MockBedStore)
 	bedID := "b1"
-	mockStore.On("DeleteBed", bedID).Return(nil)
+	mockStore.On("DeleteBed", testUserID, bedID, 0).Return(nil)
 
 	w := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(w)
+	c := newAuthedTestContext(w)
 	c.Params = gin.Params{gin.Param{Key: "bed_id", Value: bedID}}
+	c.Request, _ = http.NewRequest(http.MethodDelete, "/beds/"+bedID, nil)
 
 	handlers.DeleteBedHandler(mockStore, c)
 
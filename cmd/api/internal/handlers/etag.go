@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// etag formats a resource version as a strong entity tag.
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// ifMatchVersion reads the If-Match header into the version a write is
+// conditional on. No header, or "*", gives 0: the write is unconditional. A
+// header that cannot match any version, such as a weak or malformed tag or a
+// list of tags, writes a 412 response; handlers should return immediately
+// when ok is false.
+func ifMatchVersion(c *gin.Context) (version int, ok bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return 0, true
+	}
+	if unquoted, err := strconv.Unquote(header); err == nil {
+		if version, err := strconv.Atoi(unquoted); err == nil && version > 0 {
+			return version, true
+		}
+	}
+	respondError(c, http.StatusPreconditionFailed, "If-Match must be a single ETag from a previous response, or *")
+	return 0, false
+}
+
+// respondVersionConflict writes the response for a write whose If-Match no
+// longer matches; what names the resource (e.g. "Garden").
+func respondVersionConflict(c *gin.Context, what string) {
+	respondError(c, http.StatusPreconditionFailed, what+" was changed by someone else; fetch it again and retry")
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -10,88 +11,186 @@ import (
 
 // ListGardensHandler uses GardenStorer to fetch and return gardens.
 func ListGardensHandler(storer storage.GardenStorer, c *gin.Context) {
-	gardens, err := storer.GetAllGardens()
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	q, ok := parseListQuery(c)
+	if !ok {
+		return
+	}
+	page, err := storer.ListGardens(requestContext(c), userID, q)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch gardens"})
+		respondListError(c, err, "gardens")
 		return
 	}
-	c.JSON(http.StatusOK, gardens)
+	respondWithPage(c, page)
 }
 
 // CreateGardenHandler uses GardenStorer to create a new garden.
 func CreateGardenHandler(storer storage.GardenStorer, c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
 	var garden models.Garden
 	if err := c.ShouldBindJSON(&garden); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		respondError(c, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
-	if err := storer.CreateGarden(&garden); err != nil {
+	if err := storer.CreateGarden(requestContext(c), userID, &garden); err != nil {
 		// Check for specific storage errors to return more appropriate HTTP status codes
-		if err == storage.ErrValidation {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed: " + err.Error()})
+		if errors.Is(err, storage.ErrValidation) {
+			respondValidationError(c, err)
 			return
 		} else if err == storage.ErrConflict {
-			c.JSON(http.StatusConflict, gin.H{"error": "Conflict: " + err.Error()})
+			respondError(c, http.StatusConflict, "Conflict: "+err.Error())
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create garden"})
+		respondStorageError(c, err, "Failed to create garden")
 		return
 	}
+	c.Header("ETag", etag(garden.Version))
 	c.JSON(http.StatusCreated, garden) // Return the created garden
 }
 
 // GetGardenHandler uses GardenStorer to fetch a specific garden by ID.
 func GetGardenHandler(storer storage.GardenStorer, c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
 	gardenID := c.Param("garden_id")
-	garden, err := storer.GetGardenByID(gardenID)
+	garden, err := storer.GetGardenByID(requestContext(c), userID, gardenID)
 	if err != nil {
 		if err == storage.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Garden not found"})
+			respondError(c, http.StatusNotFound, "Garden not found")
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch garden"})
+		respondStorageError(c, err, "Failed to fetch garden")
 		return
 	}
+	c.Header("ETag", etag(garden.Version))
 	c.JSON(http.StatusOK, garden)
 }
 
 // UpdateGardenHandler uses GardenStorer to update an existing garden.
 func UpdateGardenHandler(storer storage.GardenStorer, c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
 	gardenID := c.Param("garden_id")
 	var gardenUpdates models.Garden
 
 	if err := c.ShouldBindJSON(&gardenUpdates); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		respondError(c, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
 	// It's important that the ID from the path is used, not from the body, to prevent misuse.
 	gardenUpdates.ID = gardenID
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+	gardenUpdates.Version = version // The precondition comes from If-Match, never the body
 
-	if err := storer.UpdateGarden(&gardenUpdates); err != nil {
+	if err := storer.UpdateGarden(requestContext(c), userID, &gardenUpdates); err != nil {
 		if err == storage.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Garden not found"})
+			respondError(c, http.StatusNotFound, "Garden not found")
+			return
+		} else if err == storage.ErrVersionConflict {
+			respondVersionConflict(c, "Garden")
 			return
-		} else if err == storage.ErrValidation {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed: " + err.Error()})
+		} else if errors.Is(err, storage.ErrValidation) {
+			respondValidationError(c, err)
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to update garden"})
+		respondStorageError(c, err, "Unable to update garden")
 		return
 	}
+	c.Header("ETag", etag(gardenUpdates.Version))
 	c.JSON(http.StatusOK, gin.H{"message": "Garden updated successfully"}) // Or return the updated garden
 }
 
+// PatchGardenHandler applies a JSON merge patch (RFC 7396) to a garden,
+// leaving fields the patch does not mention unchanged. The update is
+// conditional on the version the patch was applied to, so a concurrent write
+// is reported as a 412 rather than silently undone.
+func PatchGardenHandler(storer storage.GardenStorer, c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	gardenID := c.Param("garden_id")
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+	garden, err := storer.GetGardenByID(requestContext(c), userID, gardenID)
+	if err != nil {
+		if err == storage.ErrRecordNotFound {
+			respondError(c, http.StatusNotFound, "Garden not found")
+			return
+		}
+		respondStorageError(c, err, "Failed to fetch garden")
+		return
+	}
+	if version != 0 && version != garden.Version {
+		respondVersionConflict(c, "Garden")
+		return
+	}
+
+	patched, ok := bindMergePatch(c, garden)
+	if !ok {
+		return
+	}
+	// Identity and bookkeeping fields are not patchable
+	patched.ID = garden.ID
+	patched.UserID = garden.UserID
+	patched.CreatedAt = garden.CreatedAt
+	patched.Version = garden.Version
+
+	if err := storer.UpdateGarden(requestContext(c), userID, &patched); err != nil {
+		if err == storage.ErrRecordNotFound {
+			respondError(c, http.StatusNotFound, "Garden not found")
+			return
+		} else if err == storage.ErrVersionConflict {
+			respondVersionConflict(c, "Garden")
+			return
+		} else if errors.Is(err, storage.ErrValidation) {
+			respondValidationError(c, err)
+			return
+		}
+		respondStorageError(c, err, "Unable to update garden")
+		return
+	}
+	c.Header("ETag", etag(patched.Version))
+	c.JSON(http.StatusOK, patched)
+}
+
 // DeleteGardenHandler uses GardenStorer to delete a garden by ID.
 func DeleteGardenHandler(storer storage.GardenStorer, c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
 	gardenID := c.Param("garden_id")
-	if err := storer.DeleteGarden(gardenID); err != nil {
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+	if err := storer.DeleteGarden(requestContext(c), userID, gardenID, version); err != nil {
 		if err == storage.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Garden not found"})
+			respondError(c, http.StatusNotFound, "Garden not found")
+			return
+		} else if err == storage.ErrVersionConflict {
+			respondVersionConflict(c, "Garden")
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to delete garden"})
+		respondStorageError(c, err, "Unable to delete garden")
 		return
 	}
 	c.AbortWithStatus(http.StatusNoContent)
@@ -2,6 +2,7 @@ package handlers_test
 
 import (
 	"bytes"
+	"context"
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
@@ -16,6 +17,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/zjpiazza/plantastic/cmd/api/internal/handlers"
 	"github.com/zjpiazza/plantastic/cmd/api/internal/storage"
+	"github.com/zjpiazza/plantastic/internal/auth"
 	"github.com/zjpiazza/plantastic/internal/models"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -26,8 +28,8 @@ type MockGardenStore struct {
 	mock.Mock
 }
 
-func (m *MockGardenStore) GetAllGardens() ([]models.Garden, error) {
-	args := m.Called()
+func (m *MockGardenStore) GetAllGardens(ctx context.Context, userID string) ([]models.Garden, error) {
+	args := m.Called(userID)
 	// Need to type assert carefully, as Called() returns []interface{}
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -35,13 +37,18 @@ func (m *MockGardenStore) GetAllGardens() ([]models.Garden, error) {
 	return args.Get(0).([]models.Garden), args.Error(1)
 }
 
-func (m *MockGardenStore) CreateGarden(garden *models.Garden) error {
-	args := m.Called(garden)
+func (m *MockGardenStore) ListGardens(ctx context.Context, userID string, q storage.ListQuery) (storage.Page[models.Garden], error) {
+	args := m.Called(userID, q)
+	return args.Get(0).(storage.Page[models.Garden]), args.Error(1)
+}
+
+func (m *MockGardenStore) CreateGarden(ctx context.Context, userID string, garden *models.Garden) error {
+	args := m.Called(userID, garden)
 	return args.Error(0)
 }
 
-func (m *MockGardenStore) GetGardenByID(gardenID string) (models.Garden, error) {
-	args := m.Called(gardenID)
+func (m *MockGardenStore) GetGardenByID(ctx context.Context, userID, gardenID string) (models.Garden, error) {
+	args := m.Called(userID, gardenID)
 	if args.Get(0) == nil {
 		// Return zero models.Garden and the error if the first arg is nil (indicating error path)
 		return models.Garden{}, args.Error(1)
@@ -49,31 +56,23 @@ func (m *MockGardenStore) GetGardenByID(gardenID string) (models.Garden, error)
 	return args.Get(0).(models.Garden), args.Error(1)
 }
 
-func (m *MockGardenStore) UpdateGarden(garden *models.Garden) error {
-	args := m.Called(garden)
+func (m *MockGardenStore) UpdateGarden(ctx context.Context, userID string, garden *models.Garden) error {
+	args := m.Called(userID, garden)
 	return args.Error(0)
 }
 
-func (m *MockGardenStore) DeleteGarden(gardenID string) error {
-	args := m.Called(gardenID)
+func (m *MockGardenStore) DeleteGarden(ctx context.Context, userID, gardenID string, version int) error {
+	args := m.Called(userID, gardenID, version)
 	return args.Error(0)
 }
 
-func (m *MockGardenStore) CreateGardenWithTransaction(garden *models.Garden, beds []models.Bed) error {
-	args := m.Called(garden, beds)
+func (m *MockGardenStore) CreateGardenWithTransaction(ctx context.Context, userID string, garden *models.Garden, beds []models.Bed) error {
+	args := m.Called(userID, garden, beds)
 	return args.Error(0)
 }
 
-func (m *MockGardenStore) GetAllGardensWithTimeout(timeout time.Duration) ([]models.Garden, error) {
-	args := m.Called(timeout)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]models.Garden), args.Error(1)
-}
-
-func (m *MockGardenStore) GetGardensByQuery(params map[string]string) ([]models.Garden, error) {
-	args := m.Called(params)
+func (m *MockGardenStore) GetGardensByQuery(ctx context.Context, userID string, params map[string]string) ([]models.Garden, error) {
+	args := m.Called(userID, params)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -99,6 +98,29 @@ func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock, error) {
 	return gormDB, mock, nil
 }
 
+// testUserID is the Clerk subject attached to every authenticated test request.
+const testUserID = "user_test"
+
+// newAuthedTestContext creates a Gin test context authenticated as testUserID.
+func newAuthedTestContext(w *httptest.ResponseRecorder) *gin.Context {
+	c, _ := gin.CreateTestContext(w)
+	c.Set(handlers.PrincipalKey, &auth.Principal{UserID: testUserID, Provider: auth.ProviderLocal})
+	return c
+}
+
+func TestListGardensHandler_Unauthorized(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockGardenStore)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w) // No claims in context
+
+	handlers.ListGardensHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	mockStore.AssertNotCalled(t, "ListGardens", mock.Anything)
+}
+
 func TestListGardensHandler_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockStore := new(MockGardenStore)
@@ -109,10 +131,11 @@ func TestListGardensHandler_Success(t *testing.T) {
 		{ID: "g2", Name: "Test Garden 2", Location: "Loc2", Description: "Desc2", CreatedAt: now, UpdatedAt: now},
 	}
 
-	mockStore.On("GetAllGardens").Return(expectedGardens, nil)
+	mockStore.On("ListGardens", testUserID, storage.ListQuery{Filters: map[string]string{}}).Return(storage.Page[models.Garden]{Items: expectedGardens}, nil)
 
 	w := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(w)
+	c := newAuthedTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/gardens", nil)
 
 	handlers.ListGardensHandler(mockStore, c)
 
@@ -133,16 +156,25 @@ func TestListGardensHandler_Error(t *testing.T) {
 	mockStore := new(MockGardenStore)
 
 	dbError := errors.New("simulated database error")
-	mockStore.On("GetAllGardens").Return(nil, dbError)
+	mockStore.On("ListGardens", testUserID, storage.ListQuery{Filters: map[string]string{}}).Return(storage.Page[models.Garden]{}, dbError)
 
 	w := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(w)
+	c := newAuthedTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/gardens", nil)
 
 	handlers.ListGardensHandler(mockStore, c)
 
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
-	expectedResponse := gin.H{"error": "Failed to fetch gardens"}
-	var actualResponse gin.H
+	assert.Equal(t, handlers.ProblemContentType, w.Header().Get("Content-Type"))
+	expectedResponse := handlers.Problem{
+		Type:     "about:blank",
+		Title:    "Internal Server Error",
+		Status:   http.StatusInternalServerError,
+		Detail:   "Failed to fetch gardens",
+		Instance: "/gardens",
+		Code:     handlers.CodeInternal,
+	}
+	var actualResponse handlers.Problem
 	err := json.Unmarshal(w.Body.Bytes(), &actualResponse)
 	assert.NoError(t, err)
 	assert.Equal(t, expectedResponse, actualResponse)
@@ -153,10 +185,11 @@ func TestListGardensHandler_EmptyResult(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockStore := new(MockGardenStore)
 
-	mockStore.On("GetAllGardens").Return([]models.Garden{}, nil)
+	mockStore.On("ListGardens", testUserID, storage.ListQuery{Filters: map[string]string{}}).Return(storage.Page[models.Garden]{Items: []models.Garden{}}, nil)
 
 	w := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(w)
+	c := newAuthedTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/gardens", nil)
 
 	handlers.ListGardensHandler(mockStore, c)
 
@@ -189,10 +222,10 @@ func TestGetGardenHandler_Success(t *testing.T) {
 	now := time.Now().Truncate(time.Second)
 	expectedGarden := models.Garden{ID: gardenID, Name: "Test Garden", CreatedAt: now, UpdatedAt: now}
 
-	mockStore.On("GetGardenByID", gardenID).Return(expectedGarden, nil)
+	mockStore.On("GetGardenByID", testUserID, gardenID).Return(expectedGarden, nil)
 
 	w := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(w)
+	c := newAuthedTestContext(w)
 	c.Params = gin.Params{gin.Param{Key: "garden_id", Value: gardenID}}
 	c.Request, _ = http.NewRequest(http.MethodGet, "/gardens/"+gardenID, nil)
 
@@ -213,10 +246,10 @@ func TestGetGardenHandler_NotFound(t *testing.T) {
 	mockStore := new(MockGardenStore)
 	gardenID := "nonexistent"
 
-	mockStore.On("GetGardenByID", gardenID).Return(models.Garden{}, storage.ErrRecordNotFound)
+	mockStore.On("GetGardenByID", testUserID, gardenID).Return(models.Garden{}, storage.ErrRecordNotFound)
 
 	w := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(w)
+	c := newAuthedTestContext(w)
 	c.Params = gin.Params{gin.Param{Key: "garden_id", Value: gardenID}}
 	c.Request, _ = http.NewRequest(http.MethodGet, "/gardens/"+gardenID, nil)
 
@@ -239,8 +272,8 @@ func TestCreateGardenHandler_Success(t *testing.T) {
 	// Mock CreateGarden to return nil (success)
 	// The handler relies on the input 'garden' struct being populated by CreateGarden if it modifies it.
 	// If CreateGarden doesn't modify the input struct but the handler expects it to, this test might need adjustment.
-	mockStore.On("CreateGarden", &newGarden).Return(nil).Run(func(args mock.Arguments) {
-		arg := args.Get(0).(*models.Garden)
+	mockStore.On("CreateGarden", testUserID, &newGarden).Return(nil).Run(func(args mock.Arguments) {
+		arg := args.Get(1).(*models.Garden)
 		arg.ID = createdGarden.ID
 		arg.CreatedAt = createdGarden.CreatedAt
 		arg.UpdatedAt = createdGarden.UpdatedAt
@@ -248,7 +281,7 @@ func TestCreateGardenHandler_Success(t *testing.T) {
 
 	jsonBody, _ := json.Marshal(newGarden)
 	w := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(w)
+	c := newAuthedTestContext(w)
 	c.Request, _ = http.NewRequest(http.MethodPost, "/gardens", bytes.NewBuffer(jsonBody))
 	c.Request.Header.Set("Content-Type", "application/json")
 
@@ -269,11 +302,11 @@ func TestCreateGardenHandler_ValidationError(t *testing.T) {
 	mockStore := new(MockGardenStore)
 	invalidGarden := models.Garden{Name: ""} // Invalid: empty name
 
-	mockStore.On("CreateGarden", &invalidGarden).Return(storage.ErrValidation)
+	mockStore.On("CreateGarden", testUserID, &invalidGarden).Return(storage.ErrValidation)
 
 	jsonBody, _ := json.Marshal(invalidGarden)
 	w := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(w)
+	c := newAuthedTestContext(w)
 	c.Request, _ = http.NewRequest(http.MethodPost, "/gardens", bytes.NewBuffer(jsonBody))
 	c.Request.Header.Set("Content-Type", "application/json")
 
@@ -290,13 +323,13 @@ func TestUpdateGardenHandler_Success(t *testing.T) {
 	updates := models.Garden{Name: "Updated Name"} // ID will be set by handler from path param
 
 	// We need to match on a pointer to a models.Garden struct where ID is gardenID
-	mockStore.On("UpdateGarden", mock.MatchedBy(func(g *models.Garden) bool {
+	mockStore.On("UpdateGarden", testUserID, mock.MatchedBy(func(g *models.Garden) bool {
 		return g.ID == gardenID && g.Name == updates.Name
 	})).Return(nil)
 
 	jsonBody, _ := json.Marshal(updates)
 	w := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(w)
+	c := newAuthedTestContext(w)
 	c.Params = gin.Params{gin.Param{Key: "garden_id", Value: gardenID}}
 	c.Request, _ = http.NewRequest(http.MethodPut, "/gardens/"+gardenID, bytes.NewBuffer(jsonBody))
 	c.Request.Header.Set("Content-Type", "application/json")
@@ -307,15 +340,156 @@ func TestUpdateGardenHandler_Success(t *testing.T) {
 	mockStore.AssertExpectations(t)
 }
 
+func TestGetGardenHandler_SetsETag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockGardenStore)
+	mockStore.On("GetGardenByID", testUserID, "g1").Return(models.Garden{ID: "g1", Name: "Backyard", Version: 7}, nil)
+
+	w := httptest.NewRecorder()
+	c := newAuthedTestContext(w)
+	c.Params = gin.Params{gin.Param{Key: "garden_id", Value: "g1"}}
+
+	handlers.GetGardenHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"7"`, w.Header().Get("ETag"))
+}
+
+func TestUpdateGardenHandler_IfMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockGardenStore)
+	mockStore.On("UpdateGarden", testUserID, mock.MatchedBy(func(g *models.Garden) bool {
+		return g.ID == "g1" && g.Version == 7
+	})).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.Garden).Version = 8
+	})
+
+	// A version in the body is ignored in favour of If-Match
+	jsonBody, _ := json.Marshal(models.Garden{Name: "Updated Name", Version: 99})
+	w := httptest.NewRecorder()
+	c := newAuthedTestContext(w)
+	c.Params = gin.Params{gin.Param{Key: "garden_id", Value: "g1"}}
+	c.Request, _ = http.NewRequest(http.MethodPut, "/gardens/g1", bytes.NewBuffer(jsonBody))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.Header.Set("If-Match", `"7"`)
+
+	handlers.UpdateGardenHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"8"`, w.Header().Get("ETag"))
+	mockStore.AssertExpectations(t)
+}
+
+func TestUpdateGardenHandler_VersionConflict(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockGardenStore)
+	mockStore.On("UpdateGarden", testUserID, mock.Anything).Return(storage.ErrVersionConflict)
+
+	jsonBody, _ := json.Marshal(models.Garden{Name: "Updated Name"})
+	w := httptest.NewRecorder()
+	c := newAuthedTestContext(w)
+	c.Params = gin.Params{gin.Param{Key: "garden_id", Value: "g1"}}
+	c.Request, _ = http.NewRequest(http.MethodPut, "/gardens/g1", bytes.NewBuffer(jsonBody))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.Header.Set("If-Match", `"6"`)
+
+	handlers.UpdateGardenHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	mockStore.AssertExpectations(t)
+}
+
+func TestUpdateGardenHandler_UnusableIfMatch(t *testing.T) {
+	for _, header := range []string{`W/"7"`, `7`, `"7", "8"`, `"0"`} {
+		t.Run(header, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			mockStore := new(MockGardenStore)
+
+			jsonBody, _ := json.Marshal(models.Garden{Name: "Updated Name"})
+			w := httptest.NewRecorder()
+			c := newAuthedTestContext(w)
+			c.Params = gin.Params{gin.Param{Key: "garden_id", Value: "g1"}}
+			c.Request, _ = http.NewRequest(http.MethodPut, "/gardens/g1", bytes.NewBuffer(jsonBody))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Request.Header.Set("If-Match", header)
+
+			handlers.UpdateGardenHandler(mockStore, c)
+
+			assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+			mockStore.AssertNotCalled(t, "UpdateGarden", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestPatchGardenHandler_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockGardenStore)
+	existing := models.Garden{ID: "g1", UserID: testUserID, Name: "Backyard", Location: "North", Description: "Veg", Version: 2}
+	mockStore.On("GetGardenByID", testUserID, "g1").Return(existing, nil)
+	mockStore.On("UpdateGarden", testUserID, mock.MatchedBy(func(g *models.Garden) bool {
+		return g.ID == "g1" && g.Name == "Backyard" && g.Location == "South" && g.Description == "" && g.Version == 2
+	})).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.Garden).Version = 3
+	})
+
+	w := httptest.NewRecorder()
+	c := newAuthedTestContext(w)
+	c.Params = gin.Params{gin.Param{Key: "garden_id", Value: "g1"}}
+	c.Request, _ = http.NewRequest(http.MethodPatch, "/gardens/g1", bytes.NewBufferString(`{"location": "South", "description": null}`))
+	c.Request.Header.Set("Content-Type", "application/merge-patch+json")
+	c.Request.Header.Set("If-Match", `"2"`)
+
+	handlers.PatchGardenHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+	mockStore.AssertExpectations(t)
+}
+
+func TestPatchGardenHandler_StaleIfMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockGardenStore)
+	mockStore.On("GetGardenByID", testUserID, "g1").Return(models.Garden{ID: "g1", Name: "Backyard", Version: 5}, nil)
+
+	w := httptest.NewRecorder()
+	c := newAuthedTestContext(w)
+	c.Params = gin.Params{gin.Param{Key: "garden_id", Value: "g1"}}
+	c.Request, _ = http.NewRequest(http.MethodPatch, "/gardens/g1", bytes.NewBufferString(`{"name": "Front"}`))
+	c.Request.Header.Set("Content-Type", "application/merge-patch+json")
+	c.Request.Header.Set("If-Match", `"4"`)
+
+	handlers.PatchGardenHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	mockStore.AssertNotCalled(t, "UpdateGarden", mock.Anything, mock.Anything)
+}
+
+func TestDeleteGardenHandler_VersionConflict(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockGardenStore)
+	mockStore.On("DeleteGarden", testUserID, "g1", 3).Return(storage.ErrVersionConflict)
+
+	w := httptest.NewRecorder()
+	c := newAuthedTestContext(w)
+	c.Params = gin.Params{gin.Param{Key: "garden_id", Value: "g1"}}
+	c.Request, _ = http.NewRequest(http.MethodDelete, "/gardens/g1", nil)
+	c.Request.Header.Set("If-Match", `"3"`)
+
+	handlers.DeleteGardenHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	mockStore.AssertExpectations(t)
+}
+
 func TestDeleteGardenHandler_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockStore := new(MockGardenStore)
 	gardenID := "g1"
 
-	mockStore.On("DeleteGarden", gardenID).Return(nil)
+	mockStore.On("DeleteGarden", testUserID, gardenID, 0).Return(nil)
 
 	w := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(w)
+	c := newAuthedTestContext(w)
 	c.Params = gin.Params{gin.Param{Key: "garden_id", Value: gardenID}}
 	c.Request, _ = http.NewRequest(http.MethodDelete, "/gardens/"+gardenID, nil)
 
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zjpiazza/plantastic/cmd/api/internal/storage"
+	"github.com/zjpiazza/plantastic/internal/models"
+)
+
+const (
+	// IdempotencyKeyHeader names the request header clients use to make a
+	// mutating request safe to retry.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set on responses replayed from an earlier
+	// request with the same key.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	// DefaultIdempotencyTTL is how long responses are kept for replay unless
+	// configured otherwise.
+	DefaultIdempotencyTTL = 24 * time.Hour
+	// MaxIdempotentBodySize caps the body of a request sent with an
+	// Idempotency-Key, which is read into memory to be hashed.
+	MaxIdempotentBodySize = 1 << 20
+
+	maxIdempotencyKeyLength = 255
+)
+
+// Idempotency returns middleware honouring the Idempotency-Key header on POST,
+// PUT, PATCH and DELETE requests. The first request with a key is handled
+// normally and its response stored for ttl; a retry with the same key and the
+// same method, URL and body gets the stored response instead of being applied
+// again. Reusing a key for a different request is rejected with 422, and a
+// retry that arrives while the first request is still running with 409, and
+// a body over MaxIdempotentBodySize with 413. Server errors are not stored, so a request that failed that way can be
+// retried for real. It must run after authentication: keys are per user.
+func Idempotency(store storage.IdempotencyStorer, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || !isMutatingMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			respondError(c, http.StatusBadRequest, "Idempotency-Key must be at most 255 characters")
+			return
+		}
+		userID, ok := currentUserID(c)
+		if !ok {
+			c.Next() // Nothing to scope the key to; let the handler reject the request
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, MaxIdempotentBodySize))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				respondError(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("A request with an Idempotency-Key must have a body of at most %d bytes", tooLarge.Limit))
+				return
+			}
+			respondError(c, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		now := time.Now()
+		rec := &models.IdempotencyRecord{
+			UserID:      userID,
+			Key:         key,
+			RequestHash: requestHash(c.Request, body),
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(ttl),
+		}
+		ctx := c.Request.Context()
+		existing, err := store.Reserve(ctx, rec)
+		if err != nil {
+			log.Printf("Idempotency: failed to reserve key for user %s: %v\n", userID, err)
+			respondStorageError(c, err, "Unable to process Idempotency-Key")
+			return
+		}
+		if existing != nil {
+			replayIdempotent(c, rec, existing)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		stored := false
+		// Settle the key even if the client has gone, so a retry isn't
+		// turned away as in progress until the record expires
+		ctx = context.WithoutCancel(ctx)
+		defer func() {
+			// Also runs if the handler panics, so the key is not left held
+			if stored {
+				return
+			}
+			if err := store.Release(ctx, userID, key); err != nil {
+				log.Printf("Idempotency: failed to release key for user %s: %v\n", userID, err)
+			}
+		}()
+		c.Next()
+
+		if recorder.Status() >= http.StatusInternalServerError {
+			return
+		}
+		rec.StatusCode = recorder.Status()
+		rec.ContentType = recorder.Header().Get("Content-Type")
+		rec.ETag = recorder.Header().Get("ETag")
+		rec.Body = recorder.body.Bytes()
+		if err := store.Complete(ctx, rec); err != nil {
+			log.Printf("Idempotency: failed to store response for user %s: %v\n", userID, err)
+			return
+		}
+		stored = true
+	}
+}
+
+// replayIdempotent answers a request whose key is already held by existing.
+func replayIdempotent(c *gin.Context, rec, existing *models.IdempotencyRecord) {
+	switch {
+	case existing.RequestHash != rec.RequestHash:
+		WriteProblem(c, http.StatusUnprocessableEntity, CodeIdempotencyKeyReused, "Idempotency-Key was already used for a different request")
+	case !existing.Completed():
+		WriteProblem(c, http.StatusConflict, CodeRequestInProgress, "A request with this Idempotency-Key is still being processed; retry later")
+	default:
+		if existing.ETag != "" {
+			c.Header("ETag", existing.ETag)
+		}
+		c.Header(IdempotentReplayedHeader, "true")
+		if len(existing.Body) == 0 {
+			c.AbortWithStatus(existing.StatusCode)
+			return
+		}
+		c.Data(existing.StatusCode, existing.ContentType, existing.Body)
+		c.Abort()
+	}
+}
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// requestHash identifies a request by its method, URL and body, so a key
+// reused for anything else can be told apart from a retry.
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method+" "+r.URL.RequestURI()+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder keeps a copy of the response body as it is written.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,162 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/zjpiazza/plantastic/cmd/api/internal/handlers"
+	"github.com/zjpiazza/plantastic/internal/auth"
+	"github.com/zjpiazza/plantastic/internal/models"
+)
+
+// memoryIdempotencyStore is an in-memory storage.IdempotencyStorer.
+type memoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]models.IdempotencyRecord
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{records: map[string]models.IdempotencyRecord{}}
+}
+
+func (s *memoryIdempotencyStore) Reserve(_ context.Context, rec *models.IdempotencyRecord) (*models.IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.records[rec.UserID+"/"+rec.Key]; ok {
+		return &existing, nil
+	}
+	s.records[rec.UserID+"/"+rec.Key] = *rec
+	return nil, nil
+}
+
+func (s *memoryIdempotencyStore) Complete(_ context.Context, rec *models.IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[rec.UserID+"/"+rec.Key] = *rec
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Release(_ context.Context, userID, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, userID+"/"+key)
+	return nil
+}
+
+// newIdempotentRouter serves POST /gardens with a handler that counts its
+// calls and answers with status.
+func newIdempotentRouter(store *memoryIdempotencyStore, status int, calls *int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(handlers.PrincipalKey, &auth.Principal{UserID: testUserID, Provider: auth.ProviderLocal})
+	})
+	r.Use(handlers.Idempotency(store, time.Hour))
+	handler := func(c *gin.Context) {
+		*calls++
+		c.Header("ETag", `"1"`)
+		c.JSON(status, gin.H{"id": "g1", "call": *calls})
+	}
+	r.POST("/gardens", handler)
+	r.GET("/gardens", handler)
+	return r
+}
+
+func sendIdempotent(r *gin.Engine, method, key, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, "/gardens", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(handlers.IdempotencyKeyHeader, key)
+	}
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotency_ReplaysRetry(t *testing.T) {
+	calls := 0
+	r := newIdempotentRouter(newMemoryIdempotencyStore(), http.StatusCreated, &calls)
+
+	first := sendIdempotent(r, http.MethodPost, "k1", `{"name": "Backyard"}`)
+	retry := sendIdempotent(r, http.MethodPost, "k1", `{"name": "Backyard"}`)
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+	assert.Equal(t, `"1"`, retry.Header().Get("ETag"))
+	assert.Equal(t, "true", retry.Header().Get(handlers.IdempotentReplayedHeader))
+	assert.Empty(t, first.Header().Get(handlers.IdempotentReplayedHeader))
+}
+
+func TestIdempotency_KeyReusedForDifferentRequest(t *testing.T) {
+	calls := 0
+	r := newIdempotentRouter(newMemoryIdempotencyStore(), http.StatusCreated, &calls)
+
+	sendIdempotent(r, http.MethodPost, "k1", `{"name": "Backyard"}`)
+	w := sendIdempotent(r, http.MethodPost, "k1", `{"name": "Front"}`)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, 1, calls)
+}
+
+func TestIdempotency_StillInProgress(t *testing.T) {
+	calls := 0
+	store := newMemoryIdempotencyStore()
+	r := newIdempotentRouter(store, http.StatusCreated, &calls)
+
+	// A reservation with no response yet, as left by a request still running
+	sendIdempotent(r, http.MethodPost, "k1", `{}`)
+	rec := store.records[testUserID+"/k1"]
+	rec.StatusCode = 0
+	store.records[testUserID+"/k1"] = rec
+
+	w := sendIdempotent(r, http.MethodPost, "k1", `{}`)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, 1, calls)
+}
+
+func TestIdempotency_ServerErrorsAreNotStored(t *testing.T) {
+	calls := 0
+	r := newIdempotentRouter(newMemoryIdempotencyStore(), http.StatusInternalServerError, &calls)
+
+	sendIdempotent(r, http.MethodPost, "k1", `{}`)
+	w := sendIdempotent(r, http.MethodPost, "k1", `{}`)
+
+	assert.Equal(t, 2, calls)
+	assert.Empty(t, w.Header().Get(handlers.IdempotentReplayedHeader))
+}
+
+func TestIdempotency_Ignored(t *testing.T) {
+	calls := 0
+	r := newIdempotentRouter(newMemoryIdempotencyStore(), http.StatusOK, &calls)
+
+	sendIdempotent(r, http.MethodGet, "k1", "")
+	sendIdempotent(r, http.MethodGet, "k1", "")
+	sendIdempotent(r, http.MethodPost, "", `{}`)
+	sendIdempotent(r, http.MethodPost, "", `{}`)
+	assert.Equal(t, 4, calls)
+
+	w := sendIdempotent(r, http.MethodPost, strings.Repeat("k", 256), `{}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestIdempotency_BodyTooLarge(t *testing.T) {
+	calls := 0
+	store := newMemoryIdempotencyStore()
+	r := newIdempotentRouter(store, http.StatusCreated, &calls)
+
+	w := sendIdempotent(r, http.MethodPost, "k1", `{"notes": "`+strings.Repeat("x", handlers.MaxIdempotentBodySize)+`"}`)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), handlers.CodeRequestTooLarge)
+	assert.Equal(t, 0, calls)
+	assert.Empty(t, store.records, "the key must not be held")
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zjpiazza/plantastic/cmd/api/internal/storage"
+)
+
+// NextCursorHeader carries the cursor for the next page of a list response;
+// the same cursor is in the Link header's rel="next" URL.
+const NextCursorHeader = "X-Next-Cursor"
+
+// Query parameters reserved by list endpoints; every other parameter is a filter.
+const (
+	queryLimit  = "limit"
+	queryCursor = "cursor"
+	querySort   = "sort"
+)
+
+// parseListQuery reads limit, cursor, sort and filters from the query string,
+// writing a 400 response if limit is malformed. Handlers should return
+// immediately when ok is false.
+func parseListQuery(c *gin.Context) (storage.ListQuery, bool) {
+	q := storage.ListQuery{Filters: map[string]string{}}
+	for key, values := range c.Request.URL.Query() {
+		switch key {
+		case queryLimit:
+			limit, err := strconv.Atoi(values[0])
+			if err != nil || limit < 1 || limit > storage.MaxPageSize {
+				respondError(c, http.StatusBadRequest, fmt.Sprintf("Invalid limit: must be between 1 and %d", storage.MaxPageSize))
+				return q, false
+			}
+			q.Limit = limit
+		case queryCursor:
+			q.Cursor = values[0]
+		case querySort:
+			q.Sort = values[0]
+		default:
+			q.Filters[key] = values[0]
+		}
+	}
+	return q, true
+}
+
+// respondWithPage writes a page of results as a JSON array, advertising the
+// next page, if any, in the Link and X-Next-Cursor headers.
+func respondWithPage[T any](c *gin.Context, page storage.Page[T]) {
+	if page.NextCursor != "" {
+		next := *c.Request.URL
+		query := next.Query()
+		query.Set(queryCursor, page.NextCursor)
+		next.RawQuery = query.Encode()
+		// Added rather than set, so other links (such as a deprecated route's
+		// successor) are kept
+		c.Writer.Header().Add("Link", fmt.Sprintf(`<%s>; rel="next"`, (&url.URL{Path: next.Path, RawQuery: next.RawQuery}).String()))
+		c.Header(NextCursorHeader, page.NextCursor)
+	}
+	items := page.Items
+	if items == nil {
+		items = []T{}
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// respondListError maps a list storer error to a response; what names the
+// resource for the generic failure message (e.g. "gardens").
+func respondListError(c *gin.Context, err error, what string) {
+	switch {
+	case errors.Is(err, storage.ErrInvalidQuery):
+		respondError(c, http.StatusBadRequest, "Invalid query: unknown filter or sort field, or a cursor from a different query")
+	case errors.Is(err, storage.ErrValidation):
+		WriteProblem(c, http.StatusBadRequest, CodeValidationFailed, "Invalid filter value: "+err.Error())
+	default:
+		respondStorageError(c, err, "Failed to fetch "+what)
+	}
+}
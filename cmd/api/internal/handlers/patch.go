@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mergePatchContentType is the media type of an RFC 7396 JSON merge patch.
+// PATCH endpoints also accept plain application/json bodies as merge patches.
+const mergePatchContentType = "application/merge-patch+json"
+
+// mergePatch applies patch to target following RFC 7396: members of a patch
+// object replace those of the target, recursing into objects, and a null
+// member removes the target's member. A patch that is not an object replaces
+// the target outright.
+func mergePatch(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+			continue
+		}
+		targetObject[name] = mergePatch(targetObject[name], value)
+	}
+	return targetObject
+}
+
+// bindMergePatch applies the request body, a JSON merge patch, to the JSON
+// form of current and decodes the result into a new T. Members the patch
+// removes come back as zero values, so null clears an optional field. On an
+// unsupported content type or a malformed patch it writes the error response;
+// handlers should return immediately when ok is false.
+func bindMergePatch[T any](c *gin.Context, current T) (patched T, ok bool) {
+	if contentType := c.GetHeader("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || (mediaType != mergePatchContentType && mediaType != gin.MIMEJSON) {
+			respondError(c, http.StatusUnsupportedMediaType, "PATCH requires a "+mergePatchContentType+" body")
+			return patched, false
+		}
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body")
+		return patched, false
+	}
+	var patch interface{}
+	if err := decodeJSON(body, &patch); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body")
+		return patched, false
+	}
+	if _, isObject := patch.(map[string]interface{}); !isObject {
+		respondError(c, http.StatusBadRequest, "Merge patch must be a JSON object")
+		return patched, false
+	}
+
+	currentJSON, err := json.Marshal(current)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Unable to apply patch")
+		return patched, false
+	}
+	var document interface{}
+	if err := decodeJSON(currentJSON, &document); err != nil {
+		respondError(c, http.StatusInternalServerError, "Unable to apply patch")
+		return patched, false
+	}
+
+	merged, err := json.Marshal(mergePatch(document, patch))
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Unable to apply patch")
+		return patched, false
+	}
+	if err := json.Unmarshal(merged, &patched); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid patch: "+err.Error())
+		return patched, false
+	}
+	return patched, true
+}
+
+// decodeJSON decodes data keeping numbers as json.Number, so values pass
+// through a merge without losing precision.
+func decodeJSON(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return errors.New("unexpected data after JSON value")
+	}
+	return nil
+}
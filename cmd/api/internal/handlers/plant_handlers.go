@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zjpiazza/plantastic/cmd/api/internal/storage"
+	"github.com/zjpiazza/plantastic/internal/models"
+)
+
+func ListPlantsHandler(storer storage.PlantStorer, c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	q, ok := parseListQuery(c)
+	if !ok {
+		return
+	}
+	page, err := storer.ListPlants(requestContext(c), userID, q)
+	if err != nil {
+		respondListError(c, err, "plants")
+		return
+	}
+	respondWithPage(c, page)
+}
+
+func CreatePlantHandler(storer storage.PlantStorer, c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	var plant models.Plant
+	if err := c.ShouldBindJSON(&plant); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := storer.CreatePlant(requestContext(c), userID, &plant); err != nil {
+		if errors.Is(err, storage.ErrValidation) {
+			respondValidationError(c, err)
+			return
+		} else if err == storage.ErrConflict {
+			respondError(c, http.StatusConflict, "Conflict: "+err.Error())
+			return
+		}
+		respondStorageError(c, err, "Failed to create plant")
+		return
+	}
+	c.JSON(http.StatusCreated, plant)
+}
+
+func GetPlantHandler(storer storage.PlantStorer, c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	plantID := c.Param("plant_id")
+	plant, err := storer.GetPlantByID(requestContext(c), userID, plantID)
+	if err != nil {
+		if err == storage.ErrRecordNotFound {
+			respondError(c, http.StatusNotFound, "Plant not found")
+			return
+		}
+		respondStorageError(c, err, "Failed to fetch plant")
+		return
+	}
+	c.JSON(http.StatusOK, plant)
+}
+
+func UpdatePlantHandler(storer storage.PlantStorer, c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	plantID := c.Param("plant_id")
+	var plantUpdates models.Plant
+	if err := c.ShouldBindJSON(&plantUpdates); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	plantUpdates.ID = plantID // Ensure ID from path is used
+
+	if err := storer.UpdatePlant(requestContext(c), userID, &plantUpdates); err != nil {
+		if err == storage.ErrRecordNotFound {
+			respondError(c, http.StatusNotFound, "Plant not found")
+			return
+		} else if errors.Is(err, storage.ErrValidation) {
+			respondValidationError(c, err)
+			return
+		}
+		respondStorageError(c, err, "Unable to update plant")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Plant updated successfully"})
+}
+
+func DeletePlantHandler(storer storage.PlantStorer, c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	plantID := c.Param("plant_id")
+	if err := storer.DeletePlant(requestContext(c), userID, plantID); err != nil {
+		if err == storage.ErrRecordNotFound {
+			respondError(c, http.StatusNotFound, "Plant not found")
+			return
+		} else if err == storage.ErrConflict {
+			respondError(c, http.StatusConflict, "Plant is still planted in a bed; remove its plantings first")
+			return
+		}
+		respondStorageError(c, err, "Unable to delete plant")
+		return
+	}
+	c.AbortWithStatus(http.StatusNoContent)
+}
@@ -0,0 +1,158 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/zjpiazza/plantastic/cmd/api/internal/handlers"
+	"github.com/zjpiazza/plantastic/cmd/api/internal/storage"
+	"github.com/zjpiazza/plantastic/internal/models"
+)
+
+// MockPlantStore is a mock implementation of storage.PlantStorer
+type MockPlantStore struct {
+	mock.Mock
+}
+
+func (m *MockPlantStore) GetAllPlants(ctx context.Context, userID string) ([]models.Plant, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Plant), args.Error(1)
+}
+
+func (m *MockPlantStore) ListPlants(ctx context.Context, userID string, q storage.ListQuery) (storage.Page[models.Plant], error) {
+	args := m.Called(userID, q)
+	return args.Get(0).(storage.Page[models.Plant]), args.Error(1)
+}
+
+func (m *MockPlantStore) GetPlantByID(ctx context.Context, userID, plantID string) (models.Plant, error) {
+	args := m.Called(userID, plantID)
+	if args.Get(0) == nil {
+		return models.Plant{}, args.Error(1)
+	}
+	return args.Get(0).(models.Plant), args.Error(1)
+}
+
+func (m *MockPlantStore) CreatePlant(ctx context.Context, userID string, plant *models.Plant) error {
+	args := m.Called(userID, plant)
+	return args.Error(0)
+}
+
+func (m *MockPlantStore) UpdatePlant(ctx context.Context, userID string, plant *models.Plant) error {
+	args := m.Called(userID, plant)
+	return args.Error(0)
+}
+
+func (m *MockPlantStore) DeletePlant(ctx context.Context, userID, plantID string) error {
+	args := m.Called(userID, plantID)
+	return args.Error(0)
+}
+
+func TestListPlantsHandler_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockPlantStore)
+	expectedPlants := []models.Plant{
+		{ID: "p1", Name: "Tomato", Variety: "Cherokee Purple"},
+		{ID: "p2", Name: "Basil"},
+	}
+	mockStore.On("ListPlants", testUserID, storage.ListQuery{Filters: map[string]string{}}).Return(storage.Page[models.Plant]{Items: expectedPlants}, nil)
+
+	w := httptest.NewRecorder()
+	c := newAuthedTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/plants", nil)
+	handlers.ListPlantsHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var actualPlants []models.Plant
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &actualPlants))
+	assert.ElementsMatch(t, expectedPlants, actualPlants)
+	mockStore.AssertExpectations(t)
+}
+
+func TestGetPlantHandler_NotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockPlantStore)
+	mockStore.On("GetPlantByID", testUserID, "p404").Return(nil, storage.ErrRecordNotFound)
+
+	w := httptest.NewRecorder()
+	c := newAuthedTestContext(w)
+	c.Params = gin.Params{gin.Param{Key: "plant_id", Value: "p404"}}
+
+	handlers.GetPlantHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockStore.AssertExpectations(t)
+}
+
+func TestCreatePlantHandler_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockPlantStore)
+	newPlant := models.Plant{Name: "Pepper", Variety: "Jalapeño", DaysToMaturity: 70}
+
+	mockStore.On("CreatePlant", testUserID, &newPlant).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.Plant).ID = "p3"
+	})
+
+	jsonBody, _ := json.Marshal(newPlant)
+	w := httptest.NewRecorder()
+	c := newAuthedTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/plants", bytes.NewBuffer(jsonBody))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handlers.CreatePlantHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var actual models.Plant
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &actual))
+	assert.Equal(t, "p3", actual.ID)
+	assert.Equal(t, newPlant.Name, actual.Name)
+	mockStore.AssertExpectations(t)
+}
+
+func TestUpdatePlantHandler_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockPlantStore)
+	plantID := "p1"
+	updates := models.Plant{Name: "Tomato", Variety: "Sungold"}
+
+	mockStore.On("UpdatePlant", testUserID, mock.MatchedBy(func(p *models.Plant) bool {
+		return p.ID == plantID && p.Variety == updates.Variety
+	})).Return(nil)
+
+	jsonBody, _ := json.Marshal(updates)
+	w := httptest.NewRecorder()
+	c := newAuthedTestContext(w)
+	c.Params = gin.Params{gin.Param{Key: "plant_id", Value: plantID}}
+	c.Request, _ = http.NewRequest(http.MethodPut, "/plants/"+plantID, bytes.NewBuffer(jsonBody))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handlers.UpdatePlantHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockStore.AssertExpectations(t)
+}
+
+func TestDeletePlantHandler_StillPlanted(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockPlantStore)
+	mockStore.On("DeletePlant", testUserID, "p1").Return(storage.ErrConflict)
+
+	w := httptest.NewRecorder()
+	c := newAuthedTestContext(w)
+	c.Params = gin.Params{gin.Param{Key: "plant_id", Value: "p1"}}
+
+	handlers.DeletePlantHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockStore.AssertExpectations(t)
+}
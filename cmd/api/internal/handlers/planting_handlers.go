@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zjpiazza/plantastic/cmd/api/internal/storage"
+	"github.com/zjpiazza/plantastic/internal/models"
+)
+
+// ListPlantingsHandler lists the caller's plantings, optionally filtered by
+// garden_id, bed_id, plant_id or status.
+func ListPlantingsHandler(storer storage.PlantingStorer, c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	q, ok := parseListQuery(c)
+	if !ok {
+		return
+	}
+	page, err := storer.ListPlantings(requestContext(c), userID, q)
+	if err != nil {
+		respondListError(c, err, "plantings")
+		return
+	}
+	respondWithPage(c, page)
+}
+
+func CreatePlantingHandler(storer storage.PlantingStorer, c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	var planting models.Planting
+	if err := c.ShouldBindJSON(&planting); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := storer.CreatePlanting(requestContext(c), userID, &planting); err != nil {
+		if errors.Is(err, storage.ErrValidation) {
+			respondValidationError(c, err)
+			return
+		} else if err == storage.ErrConflict {
+			respondError(c, http.StatusConflict, "Conflict: "+err.Error())
+			return
+		}
+		respondStorageError(c, err, "Failed to create planting")
+		return
+	}
+	c.JSON(http.StatusCreated, planting)
+}
+
+func GetPlantingHandler(storer storage.PlantingStorer, c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	plantingID := c.Param("planting_id")
+	planting, err := storer.GetPlantingByID(requestContext(c), userID, plantingID)
+	if err != nil {
+		if err == storage.ErrRecordNotFound {
+			respondError(c, http.StatusNotFound, "Planting not found")
+			return
+		}
+		respondStorageError(c, err, "Failed to fetch planting")
+		return
+	}
+	c.JSON(http.StatusOK, planting)
+}
+
+func UpdatePlantingHandler(storer storage.PlantingStorer, c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	plantingID := c.Param("planting_id")
+	var plantingUpdates models.Planting
+	if err := c.ShouldBindJSON(&plantingUpdates); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	plantingUpdates.ID = plantingID // Ensure ID from path is used
+
+	if err := storer.UpdatePlanting(requestContext(c), userID, &plantingUpdates); err != nil {
+		if err == storage.ErrRecordNotFound {
+			respondError(c, http.StatusNotFound, "Planting not found")
+			return
+		} else if errors.Is(err, storage.ErrValidation) {
+			respondValidationError(c, err)
+			return
+		}
+		respondStorageError(c, err, "Unable to update planting")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Planting updated successfully"})
+}
+
+func DeletePlantingHandler(storer storage.PlantingStorer, c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	plantingID := c.Param("planting_id")
+	if err := storer.DeletePlanting(requestContext(c), userID, plantingID); err != nil {
+		if err == storage.ErrRecordNotFound {
+			respondError(c, http.StatusNotFound, "Planting not found")
+			return
+		}
+		respondStorageError(c, err, "Unable to delete planting")
+		return
+	}
+	c.AbortWithStatus(http.StatusNoContent)
+}
@@ -0,0 +1,157 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/zjpiazza/plantastic/cmd/api/internal/handlers"
+	"github.com/zjpiazza/plantastic/cmd/api/internal/storage"
+	"github.com/zjpiazza/plantastic/internal/models"
+)
+
+// MockPlantingStore is a mock implementation of storage.PlantingStorer
+type MockPlantingStore struct {
+	mock.Mock
+}
+
+func (m *MockPlantingStore) GetAllPlantings(ctx context.Context, userID string) ([]models.Planting, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Planting), args.Error(1)
+}
+
+func (m *MockPlantingStore) ListPlantings(ctx context.Context, userID string, q storage.ListQuery) (storage.Page[models.Planting], error) {
+	args := m.Called(userID, q)
+	return args.Get(0).(storage.Page[models.Planting]), args.Error(1)
+}
+
+func (m *MockPlantingStore) GetPlantingByID(ctx context.Context, userID, plantingID string) (models.Planting, error) {
+	args := m.Called(userID, plantingID)
+	if args.Get(0) == nil {
+		return models.Planting{}, args.Error(1)
+	}
+	return args.Get(0).(models.Planting), args.Error(1)
+}
+
+func (m *MockPlantingStore) CreatePlanting(ctx context.Context, userID string, planting *models.Planting) error {
+	args := m.Called(userID, planting)
+	return args.Error(0)
+}
+
+func (m *MockPlantingStore) UpdatePlanting(ctx context.Context, userID string, planting *models.Planting) error {
+	args := m.Called(userID, planting)
+	return args.Error(0)
+}
+
+func (m *MockPlantingStore) DeletePlanting(ctx context.Context, userID, plantingID string) error {
+	args := m.Called(userID, plantingID)
+	return args.Error(0)
+}
+
+func (m *MockPlantingStore) GetPlantingsByBedID(ctx context.Context, userID, bedID string) ([]models.Planting, error) {
+	args := m.Called(userID, bedID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Planting), args.Error(1)
+}
+
+func TestListPlantingsHandler_FiltersByBed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockPlantingStore)
+	expected := []models.Planting{{ID: "pl1", BedID: "b1", PlantID: "p1", Quantity: 6, Status: models.PlantingStatusGrowing}}
+	mockStore.On("ListPlantings", testUserID, storage.ListQuery{Filters: map[string]string{"bed_id": "b1"}}).Return(storage.Page[models.Planting]{Items: expected}, nil)
+
+	w := httptest.NewRecorder()
+	c := newAuthedTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/plantings?bed_id=b1", nil)
+
+	handlers.ListPlantingsHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var actual []models.Planting
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &actual))
+	assert.Equal(t, expected, actual)
+	mockStore.AssertExpectations(t)
+}
+
+func TestListPlantingsHandler_All(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockPlantingStore)
+	mockStore.On("ListPlantings", testUserID, storage.ListQuery{Filters: map[string]string{}}).Return(storage.Page[models.Planting]{Items: []models.Planting{}}, nil)
+
+	w := httptest.NewRecorder()
+	c := newAuthedTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/plantings", nil)
+
+	handlers.ListPlantingsHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockStore.AssertExpectations(t)
+}
+
+func TestCreatePlantingHandler_ValidationError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockPlantingStore)
+	invalid := models.Planting{PlantID: "p1"} // Missing BedID
+	mockStore.On("CreatePlanting", testUserID, &invalid).Return(storage.ErrValidation)
+
+	jsonBody, _ := json.Marshal(invalid)
+	w := httptest.NewRecorder()
+	c := newAuthedTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/plantings", bytes.NewBuffer(jsonBody))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handlers.CreatePlantingHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockStore.AssertExpectations(t)
+}
+
+func TestUpdatePlantingHandler_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockPlantingStore)
+	plantingID := "pl1"
+	updates := models.Planting{BedID: "b1", PlantID: "p1", Quantity: 4, Status: models.PlantingStatusTransplanted}
+
+	mockStore.On("UpdatePlanting", testUserID, mock.MatchedBy(func(p *models.Planting) bool {
+		return p.ID == plantingID && p.Quantity == 4 && p.Status == models.PlantingStatusTransplanted
+	})).Return(nil)
+
+	jsonBody, _ := json.Marshal(updates)
+	w := httptest.NewRecorder()
+	c := newAuthedTestContext(w)
+	c.Params = gin.Params{gin.Param{Key: "planting_id", Value: plantingID}}
+	c.Request, _ = http.NewRequest(http.MethodPut, "/plantings/"+plantingID, bytes.NewBuffer(jsonBody))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handlers.UpdatePlantingHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockStore.AssertExpectations(t)
+}
+
+func TestDeletePlantingHandler_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockPlantingStore)
+	mockStore.On("DeletePlanting", testUserID, "pl1").Return(nil)
+
+	w := httptest.NewRecorder()
+	c := newAuthedTestContext(w)
+	c.Params = gin.Params{gin.Param{Key: "planting_id", Value: "pl1"}}
+
+	handlers.DeletePlantingHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	mockStore.AssertExpectations(t)
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zjpiazza/plantastic/cmd/api/internal/storage"
+)
+
+// ProblemContentType is the media type of error responses (RFC 7807).
+const ProblemContentType = "application/problem+json"
+
+// Values of Problem.Code. These are stable: clients should branch on them
+// rather than on Title or Detail, whose wording may change.
+const (
+	CodeInvalidRequest       = "invalid_request"
+	CodeValidationFailed     = "validation_failed"
+	CodeUnauthorized         = "unauthorized"
+	CodeForbidden            = "forbidden"
+	CodeNotFound             = "not_found"
+	CodeConflict             = "conflict"
+	CodeVersionConflict      = "version_conflict"
+	CodeUnsupportedMediaType = "unsupported_media_type"
+	CodeRequestTooLarge      = "request_too_large"
+	CodeIdempotencyKeyReused = "idempotency_key_reused"
+	CodeRequestInProgress    = "request_in_progress"
+	CodeTimeout              = "timeout"
+	CodeInternal             = "internal_error"
+)
+
+// Problem is the body of every error response from the resource API: an
+// RFC 7807 problem details object, extended with a stable error code, the ID
+// of the request and, for validation failures, the fields at fault.
+type Problem struct {
+	Type      string               `json:"type"`
+	Title     string               `json:"title"`
+	Status    int                  `json:"status"`
+	Detail    string               `json:"detail,omitempty"`
+	Instance  string               `json:"instance,omitempty"` // Path of the request
+	Code      string               `json:"code"`
+	RequestID string               `json:"request_id,omitempty"`
+	Errors    []storage.FieldError `json:"errors,omitempty"`
+}
+
+// WriteProblem aborts the request with a problem+json response. detail is a
+// human-readable explanation of this occurrence of the problem.
+func WriteProblem(c *gin.Context, status int, code, detail string) {
+	writeProblem(c, Problem{Status: status, Code: code, Detail: detail})
+}
+
+func writeProblem(c *gin.Context, p Problem) {
+	p.Type = "about:blank" // Code identifies the problem; there are no problem type documents
+	p.Title = http.StatusText(p.Status)
+	if c.Request != nil {
+		p.Instance = c.Request.URL.Path
+	}
+	p.RequestID = RequestIDFrom(c)
+	c.Header("Content-Type", ProblemContentType) // c.JSON keeps a Content-Type that is already set
+	c.AbortWithStatusJSON(p.Status, p)
+}
+
+// respondError writes a problem response with the code usual for status.
+func respondError(c *gin.Context, status int, detail string) {
+	WriteProblem(c, status, codeForStatus(status), detail)
+}
+
+// respondValidationError writes a 400 response for a storage.ErrValidation,
+// listing the fields at fault if err names them.
+func respondValidationError(c *gin.Context, err error) {
+	writeProblem(c, Problem{
+		Status: http.StatusBadRequest,
+		Code:   CodeValidationFailed,
+		Detail: "The request has invalid fields",
+		Errors: storage.FieldErrors(err),
+	})
+}
+
+// statusClientClosedRequest is nginx's status for a request the client gave
+// up on before it was answered. It is only ever logged: no one reads it.
+const statusClientClosedRequest = 499
+
+// respondStorageError writes the response for a storage error that has no
+// more specific meaning to the handler: 504 if the request's queries ran out
+// of time, or 500 with detail.
+func respondStorageError(c *gin.Context, err error, detail string) {
+	switch {
+	case errors.Is(err, storage.ErrTimeout):
+		respondError(c, http.StatusGatewayTimeout, "The database took too long to answer; try again later")
+	case errors.Is(err, storage.ErrCanceled):
+		c.AbortWithStatus(statusClientClosedRequest)
+	default:
+		respondError(c, http.StatusInternalServerError, detail)
+	}
+}
+
+func codeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeInvalidRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusPreconditionFailed:
+		return CodeVersionConflict
+	case http.StatusUnsupportedMediaType:
+		return CodeUnsupportedMediaType
+	case http.StatusRequestEntityTooLarge:
+		return CodeRequestTooLarge
+	case http.StatusGatewayTimeout:
+		return CodeTimeout
+	}
+	if status < http.StatusInternalServerError {
+		return CodeInvalidRequest
+	}
+	return CodeInternal
+}
@@ -0,0 +1,83 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/zjpiazza/plantastic/cmd/api/internal/handlers"
+	"github.com/zjpiazza/plantastic/cmd/api/internal/storage"
+	"github.com/zjpiazza/plantastic/internal/auth"
+)
+
+// newProblemRouter serves POST /tasks with CreateTaskHandler behind the
+// RequestID middleware.
+func newProblemRouter(mockStore *MockTaskStore) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(handlers.RequestID())
+	r.Use(func(c *gin.Context) {
+		c.Set(handlers.PrincipalKey, &auth.Principal{UserID: testUserID, Provider: auth.ProviderLocal})
+	})
+	r.POST("/tasks", func(c *gin.Context) { handlers.CreateTaskHandler(mockStore, c) })
+	return r
+}
+
+func TestCreateTaskHandler_FieldErrors(t *testing.T) {
+	mockStore := new(MockTaskStore)
+	mockStore.On("CreateTask", testUserID, mock.Anything).Return(&storage.ValidationError{Fields: []storage.FieldError{
+		{Field: "garden_id", Message: "garden does not exist"},
+	}})
+	r := newProblemRouter(mockStore)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/tasks", strings.NewReader(`{"description": "Weed", "garden_id": "nope"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(handlers.RequestIDHeader, "req-123")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, handlers.ProblemContentType, w.Header().Get("Content-Type"))
+	var problem handlers.Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, handlers.CodeValidationFailed, problem.Code)
+	assert.Equal(t, http.StatusBadRequest, problem.Status)
+	assert.Equal(t, "/tasks", problem.Instance)
+	assert.Equal(t, "req-123", problem.RequestID)
+	assert.Equal(t, []storage.FieldError{{Field: "garden_id", Message: "garden does not exist"}}, problem.Errors)
+	mockStore.AssertExpectations(t)
+}
+
+func TestRequestID(t *testing.T) {
+	r := newProblemRouter(new(MockTaskStore))
+	send := func(id string) (header string, problem handlers.Problem) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/tasks", strings.NewReader(`not json`))
+		if id != "" {
+			req.Header.Set(handlers.RequestIDHeader, id)
+		}
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+		return w.Header().Get(handlers.RequestIDHeader), problem
+	}
+
+	header, problem := send("client-chosen-id")
+	assert.Equal(t, "client-chosen-id", header)
+	assert.Equal(t, "client-chosen-id", problem.RequestID)
+	assert.Equal(t, handlers.CodeInvalidRequest, problem.Code)
+
+	// Missing or unusable IDs are replaced with a generated one
+	for _, id := range []string{"", "has spaces", strings.Repeat("x", 129)} {
+		header, problem := send(id)
+		assert.NotEmpty(t, header)
+		assert.NotEqual(t, id, header)
+		assert.Equal(t, header, problem.RequestID)
+	}
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries the ID of a request. Clients may send one to tie
+// the request to their own logs; otherwise the API assigns one. Either way it
+// is echoed on the response and included in error bodies.
+const RequestIDHeader = "X-Request-ID"
+
+const (
+	requestIDKey       = "request_id"
+	maxRequestIDLength = 128
+)
+
+// RequestID returns middleware that assigns each request its ID.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+		c.Set(requestIDKey, id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// RequestIDFrom returns the ID RequestID assigned to the request, or "" if
+// the middleware did not run.
+func RequestIDFrom(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+// validRequestID accepts short IDs of printable ASCII, so a client-supplied ID
+// is safe to echo in headers and logs.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < '!' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
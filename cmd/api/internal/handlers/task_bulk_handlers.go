@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zjpiazza/plantastic/cmd/api/internal/storage"
+	"github.com/zjpiazza/plantastic/internal/models"
+)
+
+// Values of the mode field accepted by BulkTasksHandler.
+const (
+	BulkModeAtomic  = "atomic"
+	BulkModePartial = "partial"
+)
+
+// BulkTasksRequest is the body of POST /tasks/bulk. Mode defaults to atomic.
+type BulkTasksRequest struct {
+	Mode       string               `json:"mode"`
+	Operations []storage.BulkTaskOp `json:"operations"`
+}
+
+// BulkTasksResponse lists the outcome of each operation, in request order.
+type BulkTasksResponse struct {
+	Mode    string           `json:"mode"`
+	Results []BulkTaskResult `json:"results"`
+}
+
+// BulkTaskResult is the outcome of one operation. Status is the HTTP status
+// the operation would have had as a single request; Errors lists the fields
+// at fault when it failed validation.
+type BulkTaskResult struct {
+	Index  int                  `json:"index"`
+	Op     string               `json:"op"`
+	ID     string               `json:"id,omitempty"`
+	Status int                  `json:"status"`
+	Error  string               `json:"error,omitempty"`
+	Errors []storage.FieldError `json:"errors,omitempty"`
+	Task   *models.Task         `json:"task,omitempty"`
+}
+
+// BulkTasksHandler applies a batch of task operations. In atomic mode the
+// batch succeeds or fails as a whole, and a failure is reported with the
+// failing operation's status. In partial mode each operation stands alone and
+// a batch with any failures is answered with 207 Multi-Status.
+func BulkTasksHandler(storer storage.TaskStorer, c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	var request BulkTasksRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	switch request.Mode {
+	case "":
+		request.Mode = BulkModeAtomic
+	case BulkModeAtomic, BulkModePartial:
+	default:
+		respondError(c, http.StatusBadRequest, "Invalid mode: must be 'atomic' or 'partial'")
+		return
+	}
+
+	results, err := storer.BulkTasks(requestContext(c), userID, request.Operations, request.Mode == BulkModeAtomic)
+	if err != nil {
+		if errors.Is(err, storage.ErrValidation) {
+			respondValidationError(c, err)
+			return
+		}
+		respondStorageError(c, err, "Unable to apply bulk operations")
+		return
+	}
+
+	response := BulkTasksResponse{Mode: request.Mode, Results: make([]BulkTaskResult, len(results))}
+	status := http.StatusOK
+	for i, result := range results {
+		itemStatus, message := bulkResultStatus(result)
+		response.Results[i] = BulkTaskResult{
+			Index:  i,
+			Op:     result.Op,
+			ID:     result.ID,
+			Status: itemStatus,
+			Error:  message,
+			Errors: storage.FieldErrors(result.Err),
+			Task:   result.Task,
+		}
+		if result.Err == nil || result.Err == storage.ErrBulkAborted {
+			continue
+		}
+		if request.Mode == BulkModeAtomic {
+			status = itemStatus
+		} else {
+			status = http.StatusMultiStatus
+		}
+	}
+	c.JSON(status, response)
+}
+
+// bulkResultStatus maps an operation's outcome to a status and error message.
+func bulkResultStatus(result storage.BulkTaskResult) (int, string) {
+	switch err := result.Err; {
+	case err == nil:
+		switch result.Op {
+		case storage.BulkOpCreate:
+			return http.StatusCreated, ""
+		case storage.BulkOpDelete:
+			return http.StatusNoContent, ""
+		}
+		return http.StatusOK, ""
+	case errors.Is(err, storage.ErrRecordNotFound):
+		return http.StatusNotFound, "Task not found"
+	case errors.Is(err, storage.ErrVersionConflict):
+		return http.StatusPreconditionFailed, "Task was changed by someone else; fetch it again and retry"
+	case errors.Is(err, storage.ErrValidation):
+		return http.StatusBadRequest, "The operation has invalid fields"
+	case errors.Is(err, storage.ErrConflict):
+		return http.StatusConflict, "Conflict: " + err.Error()
+	case errors.Is(err, storage.ErrBulkAborted):
+		return http.StatusFailedDependency, "Not applied: another operation in the batch failed"
+	case errors.Is(err, storage.ErrTimeout):
+		return http.StatusGatewayTimeout, "The database took too long to answer"
+	}
+	return http.StatusInternalServerError, "Unable to apply operation"
+}
@@ -0,0 +1,124 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/zjpiazza/plantastic/cmd/api/internal/handlers"
+	"github.com/zjpiazza/plantastic/cmd/api/internal/storage"
+	"github.com/zjpiazza/plantastic/internal/models"
+)
+
+func newBulkTestContext(w *httptest.ResponseRecorder, body string) *gin.Context {
+	c := newAuthedTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/tasks/bulk", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	return c
+}
+
+func TestBulkTasksHandler_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockTaskStore)
+	created := models.Task{ID: "t9", Description: "Mulch", GardenID: "g1"}
+	mockStore.On("BulkTasks", testUserID, mock.MatchedBy(func(ops []storage.BulkTaskOp) bool {
+		return len(ops) == 2 && ops[0].Op == storage.BulkOpCreate && ops[1].Op == storage.BulkOpReschedule && ops[1].ShiftDays == 2
+	}), true).Return([]storage.BulkTaskResult{
+		{Op: storage.BulkOpCreate, ID: "t9", Task: &created},
+		{Op: storage.BulkOpReschedule, ID: "t1", Task: &models.Task{ID: "t1"}},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	c := newBulkTestContext(w, `{"operations": [
+		{"op": "create", "task": {"description": "Mulch", "garden_id": "g1"}},
+		{"op": "reschedule", "id": "t1", "shift_days": 2}
+	]}`)
+
+	handlers.BulkTasksHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response handlers.BulkTasksResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, handlers.BulkModeAtomic, response.Mode)
+	require.Len(t, response.Results, 2)
+	assert.Equal(t, http.StatusCreated, response.Results[0].Status)
+	assert.Equal(t, "t9", response.Results[0].Task.ID)
+	assert.Equal(t, http.StatusOK, response.Results[1].Status)
+	mockStore.AssertExpectations(t)
+}
+
+func TestBulkTasksHandler_AtomicFailure(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockTaskStore)
+	mockStore.On("BulkTasks", testUserID, mock.Anything, true).Return([]storage.BulkTaskResult{
+		{Op: storage.BulkOpComplete, ID: "t1", Err: storage.ErrBulkAborted},
+		{Op: storage.BulkOpComplete, ID: "t2", Err: storage.ErrVersionConflict},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	c := newBulkTestContext(w, `{"mode": "atomic", "operations": [
+		{"op": "complete", "id": "t1"},
+		{"op": "complete", "id": "t2", "version": 3}
+	]}`)
+
+	handlers.BulkTasksHandler(mockStore, c)
+
+	// The batch fails with the status of the operation that failed
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	var response handlers.BulkTasksResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, http.StatusFailedDependency, response.Results[0].Status)
+	assert.Equal(t, http.StatusPreconditionFailed, response.Results[1].Status)
+	assert.NotEmpty(t, response.Results[1].Error)
+}
+
+func TestBulkTasksHandler_PartialFailure(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockTaskStore)
+	mockStore.On("BulkTasks", testUserID, mock.Anything, false).Return([]storage.BulkTaskResult{
+		{Op: storage.BulkOpDelete, ID: "t1"},
+		{Op: storage.BulkOpDelete, ID: "t2", Err: storage.ErrRecordNotFound},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	c := newBulkTestContext(w, `{"mode": "partial", "operations": [{"op": "delete", "id": "t1"}, {"op": "delete", "id": "t2"}]}`)
+
+	handlers.BulkTasksHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusMultiStatus, w.Code)
+	var response handlers.BulkTasksResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, http.StatusNoContent, response.Results[0].Status)
+	assert.Equal(t, http.StatusNotFound, response.Results[1].Status)
+}
+
+func TestBulkTasksHandler_BadRequests(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"invalid mode", `{"mode": "eventually", "operations": [{"op": "delete", "id": "t1"}]}`},
+		{"empty batch", `{"operations": []}`},
+		{"malformed", `{"operations": `},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			mockStore := new(MockTaskStore)
+			mockStore.On("BulkTasks", testUserID, mock.Anything, true).Return(nil, storage.ErrValidation)
+
+			w := httptest.NewRecorder()
+			c := newBulkTestContext(w, tt.body)
+
+			handlers.BulkTasksHandler(mockStore, c)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -8,81 +10,285 @@ import (
 	"github.com/zjpiazza/plantastic/internal/models"
 )
 
+// Values of the scope query parameter accepted by UpdateTaskHandler.
+const (
+	TaskScopeOccurrence = "occurrence"
+	TaskScopeSeries     = "series"
+)
+
 func ListTasksHandler(storer storage.TaskStorer, c *gin.Context) {
-	tasks, err := storer.GetAllTasks()
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	q, ok := parseListQuery(c)
+	if !ok {
+		return
+	}
+	page, err := storer.ListTasks(requestContext(c), userID, q)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tasks"})
+		respondListError(c, err, "tasks")
 		return
 	}
-	c.JSON(http.StatusOK, tasks)
+	respondWithPage(c, page)
 }
 
+// ListGardenTasksHandler lists the tasks in the garden named by the
+// garden_id path parameter, with the same filters and paging as ListTasksHandler.
+func ListGardenTasksHandler(storer storage.TaskStorer, c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	q, ok := parseListQuery(c)
+	if !ok {
+		return
+	}
+	page, err := storer.GetTasksByGardenID(requestContext(c), userID, c.Param("garden_id"), q)
+	if err != nil {
+		if err == storage.ErrRecordNotFound {
+			respondError(c, http.StatusNotFound, "Garden not found")
+			return
+		}
+		respondListError(c, err, "tasks")
+		return
+	}
+	respondWithPage(c, page)
+}
+
+// ListBedTasksHandler lists the tasks for the bed named by the bed_id path
+// parameter, which must be in the garden named by garden_id.
+func ListBedTasksHandler(storer storage.TaskStorer, c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	q, ok := parseListQuery(c)
+	if !ok {
+		return
+	}
+	page, err := storer.GetTasksByBedID(requestContext(c), userID, c.Param("garden_id"), c.Param("bed_id"), q)
+	if err != nil {
+		if err == storage.ErrRecordNotFound {
+			respondError(c, http.StatusNotFound, "Garden or bed not found")
+			return
+		}
+		respondListError(c, err, "tasks")
+		return
+	}
+	respondWithPage(c, page)
+}
+
+// CreateTaskHandler creates a task. Under /gardens/:garden_id/tasks and
+// /gardens/:garden_id/beds/:bed_id/tasks the garden and bed come from the
+// path, overriding any in the body.
 func CreateTaskHandler(storer storage.TaskStorer, c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
 	var task models.Task
 	if err := c.ShouldBindJSON(&task); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		respondError(c, http.StatusBadRequest, "Invalid request body")
 		return
 	}
+	if gardenID := c.Param("garden_id"); gardenID != "" {
+		task.GardenID = gardenID
+	}
+	if bedID := c.Param("bed_id"); bedID != "" {
+		task.BedID = &bedID
+	}
 
-	if err := storer.CreateTask(&task); err != nil {
-		if err == storage.ErrValidation {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed: " + err.Error()})
+	if err := storer.CreateTask(requestContext(c), userID, &task); err != nil {
+		if errors.Is(err, storage.ErrValidation) {
+			respondValidationError(c, err)
 			return
 		} else if err == storage.ErrConflict {
-			c.JSON(http.StatusConflict, gin.H{"error": "Conflict: " + err.Error()})
+			respondError(c, http.StatusConflict, "Conflict: "+err.Error())
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create task"})
+		respondStorageError(c, err, "Failed to create task")
 		return
 	}
+	c.Header("ETag", etag(task.Version))
 	c.JSON(http.StatusCreated, task)
 }
 
 func GetTaskHandler(storer storage.TaskStorer, c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
 	taskID := c.Param("task_id")
-	task, err := storer.GetTaskByID(taskID)
+	task, err := storer.GetTaskByID(requestContext(c), userID, taskID)
 	if err != nil {
 		if err == storage.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+			respondError(c, http.StatusNotFound, "Task not found")
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch task"})
+		respondStorageError(c, err, "Failed to fetch task")
 		return
 	}
+	c.Header("ETag", etag(task.Version))
 	c.JSON(http.StatusOK, task)
 }
 
+// GetTaskHistoryHandler lists a task's status transitions, oldest first
+func GetTaskHistoryHandler(storer storage.TaskStorer, c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	taskID := c.Param("task_id")
+	events, err := storer.GetTaskHistory(requestContext(c), userID, taskID)
+	if err != nil {
+		if err == storage.ErrRecordNotFound {
+			respondError(c, http.StatusNotFound, "Task not found")
+			return
+		}
+		respondStorageError(c, err, "Failed to fetch task history")
+		return
+	}
+	c.JSON(http.StatusOK, events)
+}
+
 func UpdateTaskHandler(storer storage.TaskStorer, c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
 	taskID := c.Param("task_id")
+	update, ok := taskUpdater(storer, c)
+	if !ok {
+		return
+	}
 	var taskUpdates models.Task
 	if err := c.ShouldBindJSON(&taskUpdates); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		respondError(c, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 	taskUpdates.ID = taskID // Ensure ID from path is used
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+	taskUpdates.Version = version // The precondition comes from If-Match, never the body
 
-	if err := storer.UpdateTask(&taskUpdates); err != nil {
+	if err := update(requestContext(c), userID, &taskUpdates); err != nil {
 		if err == storage.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+			respondError(c, http.StatusNotFound, "Task not found")
+			return
+		} else if err == storage.ErrVersionConflict {
+			respondVersionConflict(c, "Task")
 			return
-		} else if err == storage.ErrValidation {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed: " + err.Error()})
+		} else if errors.Is(err, storage.ErrValidation) {
+			respondValidationError(c, err)
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to update task"})
+		respondStorageError(c, err, "Unable to update task")
 		return
 	}
+	c.Header("ETag", etag(taskUpdates.Version))
 	c.JSON(http.StatusOK, gin.H{"message": "Task updated successfully"})
 }
 
+// PatchTaskHandler applies a JSON merge patch (RFC 7396) to a task, e.g.
+// {"garden_bed_id": null} to take it out of its bed. It accepts the same
+// scope parameter as UpdateTaskHandler; see PatchGardenHandler for how
+// concurrent writes are handled.
+func PatchTaskHandler(storer storage.TaskStorer, c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	taskID := c.Param("task_id")
+	update, ok := taskUpdater(storer, c)
+	if !ok {
+		return
+	}
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+	task, err := storer.GetTaskByID(requestContext(c), userID, taskID)
+	if err != nil {
+		if err == storage.ErrRecordNotFound {
+			respondError(c, http.StatusNotFound, "Task not found")
+			return
+		}
+		respondStorageError(c, err, "Failed to fetch task")
+		return
+	}
+	if version != 0 && version != task.Version {
+		respondVersionConflict(c, "Task")
+		return
+	}
+
+	patched, ok := bindMergePatch(c, task)
+	if !ok {
+		return
+	}
+	// Identity, bookkeeping and series membership are not patchable
+	patched.ID = task.ID
+	patched.UserID = task.UserID
+	patched.CreatedAt = task.CreatedAt
+	patched.Version = task.Version
+	patched.SeriesID = task.SeriesID
+	patched.Occurrence = task.Occurrence
+	patched.RecurrenceID = task.RecurrenceID
+
+	if err := update(requestContext(c), userID, &patched); err != nil {
+		if err == storage.ErrRecordNotFound {
+			respondError(c, http.StatusNotFound, "Task not found")
+			return
+		} else if err == storage.ErrVersionConflict {
+			respondVersionConflict(c, "Task")
+			return
+		} else if errors.Is(err, storage.ErrValidation) {
+			respondValidationError(c, err)
+			return
+		}
+		respondStorageError(c, err, "Unable to update task")
+		return
+	}
+	c.Header("ETag", etag(patched.Version))
+	c.JSON(http.StatusOK, patched)
+}
+
+// taskUpdater picks the storer method for the request's scope: scope=series
+// edits every open occurrence of a recurring task, the default just this one.
+// It writes a 400 for an unknown scope.
+func taskUpdater(storer storage.TaskStorer, c *gin.Context) (func(context.Context, string, *models.Task) error, bool) {
+	switch scope := c.Query("scope"); scope {
+	case "", TaskScopeOccurrence:
+		return storer.UpdateTask, true
+	case TaskScopeSeries:
+		return storer.UpdateTaskSeries, true
+	default:
+		respondError(c, http.StatusBadRequest, "Invalid scope: must be 'occurrence' or 'series'")
+		return nil, false
+	}
+}
+
 func DeleteTaskHandler(storer storage.TaskStorer, c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
 	taskID := c.Param("task_id")
-	if err := storer.DeleteTask(taskID); err != nil {
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+	if err := storer.DeleteTask(requestContext(c), userID, taskID, version); err != nil {
 		if err == storage.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+			respondError(c, http.StatusNotFound, "Task not found")
+			return
+		} else if err == storage.ErrVersionConflict {
+			respondVersionConflict(c, "Task")
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to delete task"})
+		respondStorageError(c, err, "Unable to delete task")
 		return
 	}
 	c.AbortWithStatus(http.StatusNoContent)
@@ -2,6 +2,7 @@ package handlers_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -23,16 +24,31 @@ type MockTaskStore struct {
 	mock.Mock
 }
 
-func (m *MockTaskStore) GetAllTasks() ([]models.Task, error) {
-	args := m.Called()
+func (m *MockTaskStore) GetAllTasks(ctx context.Context, userID string) ([]models.Task, error) {
+	args := m.Called(userID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]models.Task), args.Error(1)
 }
 
-func (m *MockTaskStore) GetTaskByID(taskID string) (models.Task, error) {
-	args := m.Called(taskID)
+func (m *MockTaskStore) ListTasks(ctx context.Context, userID string, q storage.ListQuery) (storage.Page[models.Task], error) {
+	args := m.Called(userID, q)
+	return args.Get(0).(storage.Page[models.Task]), args.Error(1)
+}
+
+func (m *MockTaskStore) GetTasksByGardenID(ctx context.Context, userID, gardenID string, q storage.ListQuery) (storage.Page[models.Task], error) {
+	args := m.Called(userID, gardenID, q)
+	return args.Get(0).(storage.Page[models.Task]), args.Error(1)
+}
+
+func (m *MockTaskStore) GetTasksByBedID(ctx context.Context, userID, gardenID, bedID string, q storage.ListQuery) (storage.Page[models.Task], error) {
+	args := m.Called(userID, gardenID, bedID, q)
+	return args.Get(0).(storage.Page[models.Task]), args.Error(1)
+}
+
+func (m *MockTaskStore) GetTaskByID(ctx context.Context, userID, taskID string) (models.Task, error) {
+	args := m.Called(userID, taskID)
 	var task models.Task
 	if args.Get(0) != nil {
 		task = args.Get(0).(models.Task)
@@ -40,21 +56,42 @@ func (m *MockTaskStore) GetTaskByID(taskID string) (models.Task, error) {
 	return task, args.Error(1)
 }
 
-func (m *MockTaskStore) CreateTask(task *models.Task) error {
-	args := m.Called(task)
+func (m *MockTaskStore) GetTaskHistory(ctx context.Context, userID, taskID string) ([]models.TaskEvent, error) {
+	args := m.Called(userID, taskID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.TaskEvent), args.Error(1)
+}
+
+func (m *MockTaskStore) CreateTask(ctx context.Context, userID string, task *models.Task) error {
+	args := m.Called(userID, task)
 	return args.Error(0)
 }
 
-func (m *MockTaskStore) UpdateTask(task *models.Task) error {
-	args := m.Called(task)
+func (m *MockTaskStore) UpdateTask(ctx context.Context, userID string, task *models.Task) error {
+	args := m.Called(userID, task)
 	return args.Error(0)
 }
 
-func (m *MockTaskStore) DeleteTask(taskID string) error {
-	args := m.Called(taskID)
+func (m *MockTaskStore) UpdateTaskSeries(ctx context.Context, userID string, task *models.Task) error {
+	args := m.Called(userID, task)
 	return args.Error(0)
 }
 
+func (m *MockTaskStore) DeleteTask(ctx context.Context, userID, taskID string, version int) error {
+	args := m.Called(userID, taskID, version)
+	return args.Error(0)
+}
+
+func (m *MockTaskStore) BulkTasks(ctx context.Context, userID string, ops []storage.BulkTaskOp, atomic bool) ([]storage.BulkTaskResult, error) {
+	args := m.Called(userID, ops, atomic)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]storage.BulkTaskResult), args.Error(1)
+}
+
 func TestListTasksHandler_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockStore := new(MockTaskStore)
@@ -63,10 +100,11 @@ func TestListTasksHandler_Success(t *testing.T) {
 		{ID: "t1", Description: "Test Task 1", GardenID: "g1", DueDate: now, CreatedAt: now, UpdatedAt: now},
 		{ID: "t2", Description: "Test Task 2", GardenID: "g1", DueDate: now, CreatedAt: now, UpdatedAt: now},
 	}
-	mockStore.On("GetAllTasks").Return(expectedTasks, nil)
+	mockStore.On("ListTasks", testUserID, storage.ListQuery{Filters: map[string]string{}}).Return(storage.Page[models.Task]{Items: expectedTasks}, nil)
 
 	w := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(w)
+	c := newAuthedTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/tasks", nil)
 	handlers.ListTasksHandler(mockStore, c)
 
 	assert.Equal(t, http.StatusOK, w.Code)
@@ -85,26 +123,133 @@ func TestListTasksHandler_Success(t *testing.T) {
 func TestListTasksHandler_Error(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockStore := new(MockTaskStore)
-	mockStore.On("GetAllTasks").Return(nil, errors.New("db error"))
+	mockStore.On("ListTasks", testUserID, storage.ListQuery{Filters: map[string]string{}}).Return(storage.Page[models.Task]{}, errors.New("db error"))
 
 	w := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(w)
+	c := newAuthedTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/tasks", nil)
 	handlers.ListTasksHandler(mockStore, c)
 
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 	mockStore.AssertExpectations(t)
 }
 
+func TestListTasksHandler_FiltersAndNextPage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockTaskStore)
+	query := storage.ListQuery{
+		Filters: map[string]string{"garden_id": "g1", "status": "Pending"},
+		Sort:    "-due_date",
+		Limit:   2,
+	}
+	page := storage.Page[models.Task]{Items: []models.Task{{ID: "t1"}, {ID: "t2"}}, NextCursor: "abc"}
+	mockStore.On("ListTasks", testUserID, query).Return(page, nil)
+
+	w := httptest.NewRecorder()
+	c := newAuthedTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/tasks?garden_id=g1&status=Pending&sort=-due_date&limit=2", nil)
+	handlers.ListTasksHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "abc", w.Header().Get(handlers.NextCursorHeader))
+	assert.Equal(t, `</tasks?cursor=abc&garden_id=g1&limit=2&sort=-due_date&status=Pending>; rel="next"`, w.Header().Get("Link"))
+	var actualTasks []models.Task
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &actualTasks))
+	assert.Len(t, actualTasks, 2)
+	mockStore.AssertExpectations(t)
+}
+
+func TestListTasksHandler_InvalidLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockTaskStore)
+
+	w := httptest.NewRecorder()
+	c := newAuthedTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/tasks?limit=0", nil)
+	handlers.ListTasksHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockStore.AssertNotCalled(t, "ListTasks", mock.Anything, mock.Anything)
+}
+
+func TestListTasksHandler_InvalidQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockTaskStore)
+	query := storage.ListQuery{Filters: map[string]string{"colour": "red"}}
+	mockStore.On("ListTasks", testUserID, query).Return(storage.Page[models.Task]{}, storage.ErrInvalidQuery)
+
+	w := httptest.NewRecorder()
+	c := newAuthedTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/tasks?colour=red", nil)
+	handlers.ListTasksHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockStore.AssertExpectations(t)
+}
+
+func TestListGardenTasksHandler_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockTaskStore)
+	expectedTasks := []models.Task{{ID: "t1", GardenID: "g1", Description: "Water"}}
+	mockStore.On("GetTasksByGardenID", testUserID, "g1", storage.ListQuery{Filters: map[string]string{}}).
+		Return(storage.Page[models.Task]{Items: expectedTasks}, nil)
+
+	w := httptest.NewRecorder()
+	c := newAuthedTestContext(w)
+	c.Params = gin.Params{gin.Param{Key: "garden_id", Value: "g1"}}
+	c.Request, _ = http.NewRequest(http.MethodGet, "/gardens/g1/tasks", nil)
+	handlers.ListGardenTasksHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockStore.AssertExpectations(t)
+}
+
+func TestListBedTasksHandler_NotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockTaskStore)
+	mockStore.On("GetTasksByBedID", testUserID, "g1", "b9", storage.ListQuery{Filters: map[string]string{}}).
+		Return(storage.Page[models.Task]{}, storage.ErrRecordNotFound)
+
+	w := httptest.NewRecorder()
+	c := newAuthedTestContext(w)
+	c.Params = gin.Params{{Key: "garden_id", Value: "g1"}, {Key: "bed_id", Value: "b9"}}
+	c.Request, _ = http.NewRequest(http.MethodGet, "/gardens/g1/beds/b9/tasks", nil)
+	handlers.ListBedTasksHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockStore.AssertExpectations(t)
+}
+
+func TestCreateTaskHandler_ParentsFromPath(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockTaskStore)
+	mockStore.On("CreateTask", testUserID, mock.MatchedBy(func(task *models.Task) bool {
+		return task.GardenID == "g1" && task.BedID != nil && *task.BedID == "b1"
+	})).Return(nil)
+
+	jsonBody, _ := json.Marshal(models.Task{Description: "Prune", GardenID: "other"})
+	w := httptest.NewRecorder()
+	c := newAuthedTestContext(w)
+	c.Params = gin.Params{{Key: "garden_id", Value: "g1"}, {Key: "bed_id", Value: "b1"}}
+	c.Request, _ = http.NewRequest(http.MethodPost, "/gardens/g1/beds/b1/tasks", bytes.NewBuffer(jsonBody))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handlers.CreateTaskHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	mockStore.AssertExpectations(t)
+}
+
 func TestGetTaskHandler_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockStore := new(MockTaskStore)
 	taskID := "t1"
 	now := time.Now().Truncate(time.Second)
 	expectedTask := models.Task{ID: taskID, Description: "Test Task", GardenID: "g1", DueDate: now, CreatedAt: now, UpdatedAt: now}
-	mockStore.On("GetTaskByID", taskID).Return(expectedTask, nil)
+	mockStore.On("GetTaskByID", testUserID, taskID).Return(expectedTask, nil)
 
 	w := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(w)
+	c := newAuthedTestContext(w)
 	c.Params = gin.Params{gin.Param{Key: "task_id", Value: taskID}}
 
 	handlers.GetTaskHandler(mockStore, c)
@@ -124,10 +269,10 @@ func TestGetTaskHandler_NotFound(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockStore := new(MockTaskStore)
 	taskID := "t_nonexistent"
-	mockStore.On("GetTaskByID", taskID).Return(models.Task{}, storage.ErrRecordNotFound)
+	mockStore.On("GetTaskByID", testUserID, taskID).Return(models.Task{}, storage.ErrRecordNotFound)
 
 	w := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(w)
+	c := newAuthedTestContext(w)
 	c.Params = gin.Params{gin.Param{Key: "task_id", Value: taskID}}
 
 	handlers.GetTaskHandler(mockStore, c)
@@ -146,8 +291,8 @@ func TestCreateTaskHandler_Success(t *testing.T) {
 	createdTask.CreatedAt = now
 	createdTask.UpdatedAt = now
 
-	mockStore.On("CreateTask", &newTask).Return(nil).Run(func(args mock.Arguments) {
-		arg := args.Get(0).(*models.Task)
+	mockStore.On("CreateTask", testUserID, &newTask).Return(nil).Run(func(args mock.Arguments) {
+		arg := args.Get(1).(*models.Task)
 		arg.ID = createdTask.ID
 		arg.CreatedAt = createdTask.CreatedAt
 		arg.UpdatedAt = createdTask.UpdatedAt
@@ -155,7 +300,7 @@ func TestCreateTaskHandler_Success(t *testing.T) {
 
 	jsonBody, _ := json.Marshal(newTask)
 	w := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(w)
+	c := newAuthedTestContext(w)
 	c.Request, _ = http.NewRequest(http.MethodPost, "/tasks", bytes.NewBuffer(jsonBody))
 	c.Request.Header.Set("Content-Type", "application/json")
 
@@ -176,11 +321,11 @@ func TestCreateTaskHandler_ValidationError(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockStore := new(MockTaskStore)
 	invalidTask := models.Task{Description: ""} // Missing GardenID too
-	mockStore.On("CreateTask", &invalidTask).Return(storage.ErrValidation)
+	mockStore.On("CreateTask", testUserID, &invalidTask).Return(storage.ErrValidation)
 
 	jsonBody, _ := json.Marshal(invalidTask)
 	w := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(w)
+	c := newAuthedTestContext(w)
 	c.Request, _ = http.NewRequest(http.MethodPost, "/tasks", bytes.NewBuffer(jsonBody))
 	c.Request.Header.Set("Content-Type", "application/json")
 
@@ -196,13 +341,13 @@ func TestUpdateTaskHandler_Success(t *testing.T) {
 	taskID := "t1"
 	updates := models.Task{Description: "Updated Task Desc", GardenID: "g1"}
 
-	mockStore.On("UpdateTask", mock.MatchedBy(func(tsk *models.Task) bool {
+	mockStore.On("UpdateTask", testUserID, mock.MatchedBy(func(tsk *models.Task) bool {
 		return tsk.ID == taskID && tsk.Description == updates.Description && tsk.GardenID == updates.GardenID
 	})).Return(nil)
 
 	jsonBody, _ := json.Marshal(updates)
 	w := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(w)
+	c := newAuthedTestContext(w)
 	c.Params = gin.Params{gin.Param{Key: "task_id", Value: taskID}}
 	c.Request, _ = http.NewRequest(http.MethodPut, "/tasks/"+taskID, bytes.NewBuffer(jsonBody))
 	c.Request.Header.Set("Content-Type", "application/json")
@@ -213,15 +358,184 @@ func TestUpdateTaskHandler_Success(t *testing.T) {
 	mockStore.AssertExpectations(t)
 }
 
+func TestPatchTaskHandler_ClearsBedAndKeepsUnsetFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockTaskStore)
+	bedID := "b1"
+	due := time.Date(2030, 5, 1, 9, 0, 0, 0, time.UTC)
+	existing := models.Task{
+		ID: "t1", UserID: testUserID, GardenID: "g1", BedID: &bedID, Description: "Water",
+		DueDate: due, Status: models.TaskStatusInProgress, Priority: models.PriorityHigh, Version: 3,
+	}
+	mockStore.On("GetTaskByID", testUserID, "t1").Return(existing, nil)
+	mockStore.On("UpdateTask", testUserID, mock.MatchedBy(func(tsk *models.Task) bool {
+		return tsk.ID == "t1" && tsk.BedID == nil && tsk.Description == "Water deeply" &&
+			tsk.GardenID == "g1" && tsk.Status == models.TaskStatusInProgress &&
+			tsk.Priority == models.PriorityHigh && tsk.DueDate.Equal(due) && tsk.Version == 3
+	})).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.Task).Version = 4
+	})
+
+	w := httptest.NewRecorder()
+	c := newAuthedTestContext(w)
+	c.Params = gin.Params{gin.Param{Key: "task_id", Value: "t1"}}
+	// The id and version in the patch are ignored
+	c.Request, _ = http.NewRequest(http.MethodPatch, "/tasks/t1",
+		bytes.NewBufferString(`{"garden_bed_id": null, "description": "Water deeply", "id": "t9", "version": 1}`))
+	c.Request.Header.Set("Content-Type", "application/merge-patch+json")
+
+	handlers.PatchTaskHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"4"`, w.Header().Get("ETag"))
+	var patched models.Task
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &patched))
+	assert.Nil(t, patched.BedID)
+	assert.Equal(t, models.PriorityHigh, patched.Priority)
+	mockStore.AssertExpectations(t)
+}
+
+func TestPatchTaskHandler_NullRequiredField(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockTaskStore)
+	mockStore.On("GetTaskByID", testUserID, "t1").Return(models.Task{ID: "t1", GardenID: "g1", Description: "Water", Version: 1}, nil)
+	mockStore.On("UpdateTask", testUserID, mock.MatchedBy(func(tsk *models.Task) bool {
+		return tsk.Description == ""
+	})).Return(storage.ErrValidation)
+
+	w := httptest.NewRecorder()
+	c := newAuthedTestContext(w)
+	c.Params = gin.Params{gin.Param{Key: "task_id", Value: "t1"}}
+	c.Request, _ = http.NewRequest(http.MethodPatch, "/tasks/t1", bytes.NewBufferString(`{"description": null}`))
+	c.Request.Header.Set("Content-Type", "application/merge-patch+json")
+
+	handlers.PatchTaskHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockStore.AssertExpectations(t)
+}
+
+func TestPatchTaskHandler_InvalidPatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		code        int
+	}{
+		{"not an object", "application/merge-patch+json", `["status"]`, http.StatusBadRequest},
+		{"malformed", "application/merge-patch+json", `{"status": `, http.StatusBadRequest},
+		{"trailing data", "application/merge-patch+json", `{} {}`, http.StatusBadRequest},
+		{"wrong type", "application/merge-patch+json", `{"due_date": 5}`, http.StatusBadRequest},
+		{"json patch", "application/json-patch+json", `[{"op": "remove", "path": "/garden_bed_id"}]`, http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			mockStore := new(MockTaskStore)
+			mockStore.On("GetTaskByID", testUserID, "t1").Return(models.Task{ID: "t1", GardenID: "g1", Description: "Water", Version: 1}, nil)
+
+			w := httptest.NewRecorder()
+			c := newAuthedTestContext(w)
+			c.Params = gin.Params{gin.Param{Key: "task_id", Value: "t1"}}
+			c.Request, _ = http.NewRequest(http.MethodPatch, "/tasks/t1", bytes.NewBufferString(tt.body))
+			c.Request.Header.Set("Content-Type", tt.contentType)
+
+			handlers.PatchTaskHandler(mockStore, c)
+
+			assert.Equal(t, tt.code, w.Code)
+			mockStore.AssertNotCalled(t, "UpdateTask", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestUpdateTaskHandler_SeriesScope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockTaskStore)
+	taskID := "t1"
+	updates := models.Task{Description: "Water tomatoes", GardenID: "g1", Recurrence: "every 3 days"}
+
+	mockStore.On("UpdateTaskSeries", testUserID, mock.MatchedBy(func(tsk *models.Task) bool {
+		return tsk.ID == taskID && tsk.Recurrence == updates.Recurrence
+	})).Return(nil)
+
+	jsonBody, _ := json.Marshal(updates)
+	w := httptest.NewRecorder()
+	c := newAuthedTestContext(w)
+	c.Params = gin.Params{gin.Param{Key: "task_id", Value: taskID}}
+	c.Request, _ = http.NewRequest(http.MethodPut, "/tasks/"+taskID+"?scope=series", bytes.NewBuffer(jsonBody))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handlers.UpdateTaskHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockStore.AssertExpectations(t)
+	mockStore.AssertNotCalled(t, "UpdateTask", mock.Anything, mock.Anything)
+}
+
+func TestUpdateTaskHandler_InvalidScope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockTaskStore)
+
+	w := httptest.NewRecorder()
+	c := newAuthedTestContext(w)
+	c.Params = gin.Params{gin.Param{Key: "task_id", Value: "t1"}}
+	c.Request, _ = http.NewRequest(http.MethodPut, "/tasks/t1?scope=everything", bytes.NewBufferString(`{"description":"x","garden_id":"g1"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handlers.UpdateTaskHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockStore.AssertExpectations(t)
+}
+
+func TestGetTaskHistoryHandler_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockTaskStore)
+	now := time.Now().Truncate(time.Second)
+	events := []models.TaskEvent{
+		{ID: "e1", TaskID: "t1", FromStatus: models.TaskStatusPending, ToStatus: models.TaskStatusOverdue, Reason: models.TaskEventReasonDuePassed, CreatedAt: now},
+	}
+	mockStore.On("GetTaskHistory", testUserID, "t1").Return(events, nil)
+
+	w := httptest.NewRecorder()
+	c := newAuthedTestContext(w)
+	c.Params = gin.Params{gin.Param{Key: "task_id", Value: "t1"}}
+
+	handlers.GetTaskHistoryHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var actual []models.TaskEvent
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &actual))
+	require.Len(t, actual, 1)
+	assert.Equal(t, models.TaskStatusOverdue, actual[0].ToStatus)
+	mockStore.AssertExpectations(t)
+}
+
+func TestGetTaskHistoryHandler_NotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockTaskStore)
+	mockStore.On("GetTaskHistory", testUserID, "t_missing").Return(nil, storage.ErrRecordNotFound)
+
+	w := httptest.NewRecorder()
+	c := newAuthedTestContext(w)
+	c.Params = gin.Params{gin.Param{Key: "task_id", Value: "t_missing"}}
+
+	handlers.GetTaskHistoryHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockStore.AssertExpectations(t)
+}
+
 func TestDeleteTaskHandler_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockStore := new(MockTaskStore)
 	taskID := "t1"
-	mockStore.On("DeleteTask", taskID).Return(nil)
+	mockStore.On("DeleteTask", testUserID, taskID, 0).Return(nil)
 
 	w := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(w)
+	c := newAuthedTestContext(w)
 	c.Params = gin.Params{gin.Param{Key: "task_id", Value: taskID}}
+	c.Request, _ = http.NewRequest(http.MethodDelete, "/tasks/"+taskID, nil)
 
 	handlers.DeleteTaskHandler(mockStore, c)
 
@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// QueryTimeout returns middleware that gives the request's context a
+// deadline d from now. Handlers pass that context to the storers, which
+// report a query cut short by it as storage.ErrTimeout, answered with 504
+// Gateway Timeout. The context also ends if the client disconnects.
+func QueryTimeout(d time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -0,0 +1,75 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zjpiazza/plantastic/cmd/api/internal/handlers"
+	"github.com/zjpiazza/plantastic/cmd/api/internal/storage"
+	"github.com/zjpiazza/plantastic/internal/auth"
+	"github.com/zjpiazza/plantastic/internal/models"
+)
+
+// slowGardenStore lists gardens only once the context ends, like a query
+// still running at the deadline or when the client goes away.
+type slowGardenStore struct{ storage.GardenStorer }
+
+func (slowGardenStore) ListGardens(ctx context.Context, _ string, _ storage.ListQuery) (storage.Page[models.Garden], error) {
+	<-ctx.Done()
+	return storage.Page[models.Garden]{}, storage.ParseDatabaseError(ctx.Err())
+}
+
+// newTimeoutRouter serves GET /gardens from slowGardenStore with a query
+// deadline of timeout.
+func newTimeoutRouter(timeout time.Duration) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(handlers.PrincipalKey, &auth.Principal{UserID: testUserID, Provider: auth.ProviderLocal})
+	})
+	r.GET("/gardens", handlers.QueryTimeout(timeout), func(c *gin.Context) {
+		handlers.ListGardensHandler(slowGardenStore{}, c)
+	})
+	return r
+}
+
+func TestQueryTimeout_DeadlineGives504(t *testing.T) {
+	r := newTimeoutRouter(10 * time.Millisecond)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/gardens", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	var problem handlers.Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, handlers.CodeTimeout, problem.Code)
+}
+
+func TestQueryTimeout_ClientDisconnectCancelsQuery(t *testing.T) {
+	r := newTimeoutRouter(time.Minute)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/gardens", nil)
+	done := make(chan struct{})
+	go func() {
+		r.ServeHTTP(w, req)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the query was not canceled when the client went away")
+	}
+	assert.Equal(t, 499, w.Code) // Never seen by the client, only logged
+	assert.Empty(t, w.Body.String())
+}
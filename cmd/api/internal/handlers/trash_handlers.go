@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zjpiazza/plantastic/cmd/api/internal/storage"
+)
+
+// EmptyTrashResponse is the response to DELETE /trash.
+type EmptyTrashResponse struct {
+	Purged int64 `json:"purged"`
+}
+
+// ListTrashHandler lists the caller's deleted gardens, beds and tasks,
+// optionally only those of the type given in the type query parameter.
+func ListTrashHandler(storer storage.TrashStorer, c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	items, err := storer.ListTrash(requestContext(c), userID, c.Query("type"))
+	if err != nil {
+		if errors.Is(err, storage.ErrValidation) {
+			respondValidationError(c, err)
+			return
+		}
+		respondStorageError(c, err, "Failed to fetch the trash")
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// RestoreTrashHandler takes an item out of the trash; a garden comes back
+// with the beds and tasks deleted along with it.
+func RestoreTrashHandler(storer storage.TrashStorer, c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	result, err := storer.Restore(requestContext(c), userID, c.Param("type"), c.Param("id"))
+	if err != nil {
+		respondTrashError(c, err, "Unable to restore item")
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// PurgeTrashHandler deletes an item in the trash for good.
+func PurgeTrashHandler(storer storage.TrashStorer, c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	if err := storer.Purge(requestContext(c), userID, c.Param("type"), c.Param("id")); err != nil {
+		respondTrashError(c, err, "Unable to purge item")
+		return
+	}
+	c.AbortWithStatus(http.StatusNoContent)
+}
+
+// EmptyTrashHandler deletes everything in the caller's trash for good.
+func EmptyTrashHandler(storer storage.TrashStorer, c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	purged, err := storer.EmptyTrash(requestContext(c), userID)
+	if err != nil {
+		respondStorageError(c, err, "Unable to empty the trash")
+		return
+	}
+	c.JSON(http.StatusOK, EmptyTrashResponse{Purged: purged})
+}
+
+// respondTrashError maps an error from restoring or purging one item.
+func respondTrashError(c *gin.Context, err error, failure string) {
+	switch {
+	case errors.Is(err, storage.ErrValidation):
+		respondValidationError(c, err)
+	case errors.Is(err, storage.ErrRecordNotFound):
+		respondError(c, http.StatusNotFound, "No such item in the trash")
+	case errors.Is(err, storage.ErrGardenInTrash):
+		respondError(c, http.StatusConflict, "Its garden is in the trash; restore the garden instead")
+	default:
+		respondStorageError(c, err, failure)
+	}
+}
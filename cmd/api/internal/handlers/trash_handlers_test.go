@@ -0,0 +1,141 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/zjpiazza/plantastic/cmd/api/internal/handlers"
+	"github.com/zjpiazza/plantastic/cmd/api/internal/storage"
+)
+
+// MockTrashStore is a mock implementation of storage.TrashStorer
+type MockTrashStore struct {
+	mock.Mock
+}
+
+func (m *MockTrashStore) ListTrash(ctx context.Context, userID, itemType string) ([]storage.TrashItem, error) {
+	args := m.Called(userID, itemType)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]storage.TrashItem), args.Error(1)
+}
+
+func (m *MockTrashStore) Restore(ctx context.Context, userID, itemType, id string) (storage.RestoreResult, error) {
+	args := m.Called(userID, itemType, id)
+	return args.Get(0).(storage.RestoreResult), args.Error(1)
+}
+
+func (m *MockTrashStore) Purge(ctx context.Context, userID, itemType, id string) error {
+	args := m.Called(userID, itemType, id)
+	return args.Error(0)
+}
+
+func (m *MockTrashStore) EmptyTrash(ctx context.Context, userID string) (int64, error) {
+	args := m.Called(userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func trashParams(itemType, id string) gin.Params {
+	return gin.Params{{Key: "type", Value: itemType}, {Key: "id", Value: id}}
+}
+
+func TestListTrashHandler_FiltersByType(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockTrashStore)
+	deletedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	items := []storage.TrashItem{{Type: storage.TrashBed, ID: "b1", Name: "North", GardenID: "g1", DeletedAt: deletedAt, PurgeAfter: deletedAt.Add(storage.DefaultTrashRetention)}}
+	mockStore.On("ListTrash", testUserID, storage.TrashBed).Return(items, nil)
+
+	w := httptest.NewRecorder()
+	c := newAuthedTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/trash?type=bed", nil)
+	handlers.ListTrashHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var actual []storage.TrashItem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &actual))
+	assert.Equal(t, items, actual)
+	mockStore.AssertExpectations(t)
+}
+
+func TestRestoreTrashHandler_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockTrashStore)
+	result := storage.RestoreResult{Type: storage.TrashGarden, ID: "g1", RestoredBeds: 2, RestoredTasks: 5}
+	mockStore.On("Restore", testUserID, storage.TrashGarden, "g1").Return(result, nil)
+
+	w := httptest.NewRecorder()
+	c := newAuthedTestContext(w)
+	c.Params = trashParams(storage.TrashGarden, "g1")
+	handlers.RestoreTrashHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var actual storage.RestoreResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &actual))
+	assert.Equal(t, result, actual)
+	mockStore.AssertExpectations(t)
+}
+
+func TestRestoreTrashHandler_Errors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"not in the trash", storage.ErrRecordNotFound, http.StatusNotFound},
+		{"garden in the trash", storage.ErrGardenInTrash, http.StatusConflict},
+		{"database", storage.ErrDatabase, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := new(MockTrashStore)
+			mockStore.On("Restore", testUserID, storage.TrashBed, "b1").Return(storage.RestoreResult{}, tt.err)
+
+			w := httptest.NewRecorder()
+			c := newAuthedTestContext(w)
+			c.Params = trashParams(storage.TrashBed, "b1")
+			handlers.RestoreTrashHandler(mockStore, c)
+
+			assert.Equal(t, tt.status, w.Code)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPurgeTrashHandler_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockTrashStore)
+	mockStore.On("Purge", testUserID, storage.TrashTask, "t1").Return(nil)
+
+	w := httptest.NewRecorder()
+	c := newAuthedTestContext(w)
+	c.Params = trashParams(storage.TrashTask, "t1")
+	handlers.PurgeTrashHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	mockStore.AssertExpectations(t)
+}
+
+func TestEmptyTrashHandler_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockStore := new(MockTrashStore)
+	mockStore.On("EmptyTrash", testUserID).Return(int64(3), nil)
+
+	w := httptest.NewRecorder()
+	c := newAuthedTestContext(w)
+	handlers.EmptyTrashHandler(mockStore, c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"purged":3}`, w.Body.String())
+	mockStore.AssertExpectations(t)
+}
@@ -0,0 +1,197 @@
+// Package openapi describes the API as an OpenAPI 3.0 document. The document
+// is generated from the routes registered with Gin: each route is looked up
+// in the operation table in operations.go, and the schemas are derived from
+// the Go types the handlers read and write, so the two cannot drift apart
+// unnoticed.
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Path is where the API serves its OpenAPI document.
+const Path = "/openapi.json"
+
+// Document is an OpenAPI 3.0 document.
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info describes the API as a whole.
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// PathItem holds the operations on one path, keyed by lower-case method.
+type PathItem map[string]*Operation
+
+// Operation describes one method on one path.
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Security    []map[string][]string `json:"security,omitempty"` // Unset for public operations
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+}
+
+// Parameter is a path, query or header parameter.
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody describes the body an operation accepts.
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// MediaType gives the schema of a body in one content type.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Response describes the response for one status code.
+type Response struct {
+	Description string               `json:"description"`
+	Headers     map[string]Header    `json:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// Header describes a response header.
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// Components holds the schemas and security schemes operations refer to.
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+// SecurityScheme describes how callers authenticate.
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	Description  string `json:"description,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// Build describes routes, as returned by gin.Engine.Routes, for the API
+// version served under prefix (such as "/v1"). Unversioned routes that alias
+// a route under prefix are its deprecated copies and left out. It fails if any
+// other route has no entry in the operation table, so a new route cannot ship
+// undocumented.
+func Build(routes gin.RoutesInfo, prefix string) (*Document, error) {
+	s := newSchemas()
+	table := operations(s)
+
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info: Info{
+			Title:       "Plantastic API",
+			Description: "Manage gardens, beds, plants, plantings and garden tasks.",
+			Version:     "1.0",
+		},
+		Paths: map[string]PathItem{},
+		Components: Components{
+			Schemas: s.components,
+			SecuritySchemes: map[string]SecurityScheme{
+				bearerAuth: {
+					Type:        "http",
+					Scheme:      "bearer",
+					Description: "A session token from the auth provider, or an access token from the device flow.",
+				},
+			},
+		},
+	}
+
+	versioned := map[string]bool{}
+	for _, route := range routes {
+		if path, ok := unversioned(route.Path, prefix); ok {
+			versioned[route.Method+" "+path] = true
+		}
+	}
+
+	var missing []string
+	for _, route := range routes {
+		key := route.Method + " " + route.Path
+		if path, ok := unversioned(route.Path, prefix); ok {
+			key = route.Method + " " + path
+		} else if versioned[key] {
+			continue
+		}
+		op, ok := table[key]
+		if !ok {
+			missing = append(missing, key)
+			continue
+		}
+		op.Parameters = append(pathParameters(route.Path), op.Parameters...)
+		path := openAPIPath(route.Path)
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = PathItem{}
+		}
+		doc.Paths[path][strings.ToLower(route.Method)] = op
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("openapi: no operation documented for %s", strings.Join(missing, ", "))
+	}
+	return doc, nil
+}
+
+// Handler serves doc as JSON.
+func Handler(doc *Document) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, doc)
+	}
+}
+
+// unversioned strips prefix from a route path under it, e.g. "/v1/gardens"
+// gives "/gardens".
+func unversioned(path, prefix string) (string, bool) {
+	rest, ok := strings.CutPrefix(path, prefix)
+	if !ok || !strings.HasPrefix(rest, "/") {
+		return "", false
+	}
+	return rest, true
+}
+
+// openAPIPath converts a Gin route path ("/gardens/:garden_id") to an OpenAPI
+// path template ("/gardens/{garden_id}").
+func openAPIPath(ginPath string) string {
+	segments := strings.Split(ginPath, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func pathParameters(ginPath string) []Parameter {
+	var params []Parameter
+	for _, segment := range strings.Split(ginPath, "/") {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			params = append(params, Parameter{Name: segment[1:], In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		}
+	}
+	return params
+}
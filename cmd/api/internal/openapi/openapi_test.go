@@ -0,0 +1,268 @@
+package openapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zjpiazza/plantastic/cmd/api/internal/handlers"
+	"github.com/zjpiazza/plantastic/cmd/api/internal/openapi"
+	"github.com/zjpiazza/plantastic/cmd/api/internal/routes"
+	"github.com/zjpiazza/plantastic/cmd/api/internal/storage"
+	"github.com/zjpiazza/plantastic/internal/auth"
+	"github.com/zjpiazza/plantastic/internal/models"
+)
+
+const testUserID = "user_test"
+
+var created = time.Date(2025, 4, 1, 9, 0, 0, 0, time.UTC)
+
+// The fake stores embed the storer interfaces, so a method the test does not
+// expect to be called panics.
+
+type fakeGardenStore struct{ storage.GardenStorer }
+
+func (fakeGardenStore) ListGardens(context.Context, string, storage.ListQuery) (storage.Page[models.Garden], error) {
+	return storage.Page[models.Garden]{Items: []models.Garden{testGarden()}, NextCursor: "next"}, nil
+}
+
+func (fakeGardenStore) GetGardenByID(_ context.Context, _, gardenID string) (models.Garden, error) {
+	if gardenID != "g1" {
+		return models.Garden{}, storage.ErrRecordNotFound
+	}
+	return testGarden(), nil
+}
+
+func (fakeGardenStore) CreateGarden(_ context.Context, userID string, garden *models.Garden) error {
+	garden.ID, garden.UserID, garden.Version = "g2", userID, 1
+	garden.CreatedAt, garden.UpdatedAt = created, created
+	return nil
+}
+
+func (fakeGardenStore) DeleteGarden(context.Context, string, string, int) error { return nil }
+
+type fakeTaskStore struct{ storage.TaskStorer }
+
+func (fakeTaskStore) GetTaskByID(_ context.Context, _, taskID string) (models.Task, error) {
+	if taskID != "t1" {
+		return models.Task{}, storage.ErrRecordNotFound
+	}
+	return testTask(), nil
+}
+
+func (fakeTaskStore) GetTaskHistory(context.Context, string, string) ([]models.TaskEvent, error) {
+	return []models.TaskEvent{{ID: "e1", UserID: testUserID, TaskID: "t1", FromStatus: models.TaskStatusPending, ToStatus: models.TaskStatusCompleted, CreatedAt: created}}, nil
+}
+
+func (fakeTaskStore) CreateTask(context.Context, string, *models.Task) error {
+	return &storage.ValidationError{Fields: []storage.FieldError{{Field: "garden_id", Message: "garden does not exist"}}}
+}
+
+func (fakeTaskStore) BulkTasks(_ context.Context, _ string, ops []storage.BulkTaskOp, _ bool) ([]storage.BulkTaskResult, error) {
+	task := testTask()
+	return []storage.BulkTaskResult{
+		{Op: ops[0].Op, ID: task.ID, Task: &task},
+		{Op: ops[1].Op, ID: ops[1].ID, Err: storage.ErrRecordNotFound},
+	}, nil
+}
+
+type fakeTrashStore struct{ storage.TrashStorer }
+
+func (fakeTrashStore) ListTrash(context.Context, string, string) ([]storage.TrashItem, error) {
+	return []storage.TrashItem{{Type: storage.TrashGarden, ID: "g1", Name: "Backyard", DeletedAt: created, PurgeAfter: created.Add(storage.DefaultTrashRetention)}}, nil
+}
+
+func (fakeTrashStore) Restore(_ context.Context, _, itemType, id string) (storage.RestoreResult, error) {
+	if itemType == storage.TrashBed {
+		return storage.RestoreResult{}, storage.ErrGardenInTrash
+	}
+	return storage.RestoreResult{Type: itemType, ID: id, RestoredBeds: 2, RestoredTasks: 5}, nil
+}
+
+type fakeAuditStore struct{}
+
+func (fakeAuditStore) ListAudit(context.Context, string, storage.ListQuery) (storage.Page[models.AuditEntry], error) {
+	return storage.Page[models.AuditEntry]{Items: []models.AuditEntry{{
+		ID: "a1", UserID: testUserID, ActorType: models.ActorUser, ActorID: testUserID, Entity: "garden", EntityID: "g1",
+		Action: models.AuditUpdate, Before: models.AuditValues{"name": "Yard"}, After: models.AuditValues{"name": "Backyard"}, CreatedAt: created,
+	}}}, nil
+}
+
+func testGarden() models.Garden {
+	return models.Garden{ID: "g1", UserID: testUserID, Name: "Backyard", Version: 3, CreatedAt: created, UpdatedAt: created}
+}
+
+func testTask() models.Task {
+	bed := "b1"
+	return models.Task{
+		ID: "t1", UserID: testUserID, GardenID: "g1", BedID: &bed, Description: "Water",
+		DueDate: created, Status: models.TaskStatusPending, Priority: models.PriorityLow,
+		Version: 1, CreatedAt: created, UpdatedAt: created,
+	}
+}
+
+// newRouter registers the API's routes the way main does, with fakes behind
+// them.
+func newRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(handlers.RequestID())
+	deps := routes.Deps{
+		Gardens: fakeGardenStore{},
+		Tasks:   fakeTaskStore{},
+		Trash:   fakeTrashStore{},
+		Audit:   fakeAuditStore{},
+		Protected: []gin.HandlerFunc{func(c *gin.Context) {
+			c.Set(handlers.PrincipalKey, &auth.Principal{UserID: testUserID, Provider: auth.ProviderLocal})
+		}},
+		Timeouts: routes.DefaultTimeouts,
+	}
+	routes.SetupV1(r.Group(routes.V1), deps)
+	routes.SetupV1(r.Group("/", routes.Deprecated(routes.LegacyDeprecated, routes.LegacySunset, routes.V1)), deps)
+	return r
+}
+
+func TestBuild_DocumentsEveryRoute(t *testing.T) {
+	r := newRouter()
+	doc, err := openapi.Build(r.Routes(), routes.V1)
+	require.NoError(t, err)
+
+	get := doc.Paths["/v1/gardens/{garden_id}"]["get"]
+	require.NotNil(t, get)
+	assert.Equal(t, "getGarden", get.OperationID)
+	assert.Equal(t, "garden_id", get.Parameters[0].Name)
+	assert.Equal(t, "path", get.Parameters[0].In)
+	assert.Contains(t, get.Responses, "401")
+	assert.Nil(t, doc.Paths["/v1/device/code"]["post"].Security)
+
+	// Writes document the Idempotency-Key header
+	var names []string
+	for _, p := range doc.Paths["/v1/gardens"]["post"].Parameters {
+		names = append(names, p.Name)
+	}
+	assert.Contains(t, names, handlers.IdempotencyKeyHeader)
+
+	// The document survives a round trip through JSON
+	_, err = json.Marshal(doc)
+	require.NoError(t, err)
+}
+
+func TestBuild_LeavesOutLegacyAliases(t *testing.T) {
+	doc, err := openapi.Build(newRouter().Routes(), routes.V1)
+	require.NoError(t, err)
+
+	assert.Contains(t, doc.Paths, "/v1/gardens")
+	assert.NotContains(t, doc.Paths, "/gardens")
+}
+
+func TestLegacyRoutes_AreDeprecated(t *testing.T) {
+	r := newRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/gardens?limit=1", nil)
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, fmt.Sprintf("@%d", routes.LegacyDeprecated.Unix()), w.Header().Get("Deprecation"))
+	assert.Equal(t, routes.LegacySunset.Format(http.TimeFormat), w.Header().Get("Sunset"))
+	assert.ElementsMatch(t, []string{
+		`</v1/gardens>; rel="successor-version"`,
+		`</gardens?cursor=next&limit=1>; rel="next"`,
+	}, w.Header().Values("Link"))
+
+	// The versioned route is not deprecated
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/v1/gardens?limit=1", nil)
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Deprecation"))
+	assert.Equal(t, `</v1/gardens?cursor=next&limit=1>; rel="next"`, w.Header().Get("Link"))
+}
+
+func TestBuild_RejectsUndocumentedRoute(t *testing.T) {
+	r := newRouter()
+	r.GET("/compost", func(*gin.Context) {})
+	_, err := openapi.Build(r.Routes(), routes.V1)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "GET /compost")
+}
+
+// TestResponsesMatchDocument sends real requests through the handlers and
+// checks each response against the document.
+func TestResponsesMatchDocument(t *testing.T) {
+	r := newRouter()
+	doc, err := openapi.Build(r.Routes(), routes.V1)
+	require.NoError(t, err)
+
+	tests := []struct {
+		method, route, target, body string
+		wantStatus                  int
+	}{
+		{"GET", "/v1/gardens", "/v1/gardens?limit=1", "", http.StatusOK},
+		{"GET", "/v1/gardens", "/v1/gardens?limit=0", "", http.StatusBadRequest},
+		{"GET", "/v1/gardens/:garden_id", "/v1/gardens/g1", "", http.StatusOK},
+		{"GET", "/v1/gardens/:garden_id", "/v1/gardens/nope", "", http.StatusNotFound},
+		{"POST", "/v1/gardens", "/v1/gardens", `{"name": "Front yard"}`, http.StatusCreated},
+		{"DELETE", "/v1/gardens/:garden_id", "/v1/gardens/g1", "", http.StatusNoContent},
+		{"DELETE", "/v1/gardens/:garden_id", "/v1/gardens/g1", "", http.StatusPreconditionFailed},
+		{"GET", "/v1/tasks/:task_id", "/v1/tasks/t1", "", http.StatusOK},
+		{"GET", "/v1/tasks/:task_id/history", "/v1/tasks/t1/history", "", http.StatusOK},
+		{"POST", "/v1/tasks", "/v1/tasks", `{"description": "Weed", "garden_id": "nope", "due_date": "2025-04-02T00:00:00Z"}`, http.StatusBadRequest},
+		{"POST", "/v1/tasks/bulk", "/v1/tasks/bulk", `{"mode": "partial", "operations": [{"op": "complete", "id": "t1"}, {"op": "delete", "id": "t9"}]}`, http.StatusMultiStatus},
+		{"POST", "/v1/device/token", "/v1/device/token", "grant_type=password", http.StatusBadRequest},
+		{"GET", "/v1/trash", "/v1/trash", "", http.StatusOK},
+		{"POST", "/v1/trash/:type/:id/restore", "/v1/trash/garden/g1/restore", "", http.StatusOK},
+		{"POST", "/v1/trash/:type/:id/restore", "/v1/trash/bed/b1/restore", "", http.StatusConflict},
+		{"GET", "/v1/audit", "/v1/audit?entity=garden&entity_id=g1", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.target, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			switch {
+			case tt.route == "/v1/device/token":
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			case tt.body != "":
+				req.Header.Set("Content-Type", "application/json")
+			}
+			if tt.wantStatus == http.StatusPreconditionFailed {
+				req.Header.Set("If-Match", "not-a-version")
+			}
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			assert.NoError(t, doc.ValidateResponse(tt.method, tt.route, w.Code, w.Header().Get("Content-Type"), w.Body.Bytes()))
+		})
+	}
+}
+
+func TestValidateResponse_CatchesDrift(t *testing.T) {
+	doc, err := openapi.Build(newRouter().Routes(), routes.V1)
+	require.NoError(t, err)
+	garden := `{"id": "g1", "user_id": "u", "name": "n", "location": "", "description": "", "version": 1, "created_at": "2025-04-01T09:00:00Z", "updated_at": "2025-04-01T09:00:00Z"`
+
+	assert.NoError(t, doc.ValidateResponse("GET", "/v1/gardens/:garden_id", 200, "application/json; charset=utf-8", []byte(garden+"}")))
+	for name, tt := range map[string]struct {
+		status      int
+		contentType string
+		body        string
+	}{
+		"undocumented property": {200, "application/json", garden + `, "soil": "clay"}`},
+		"missing property":      {200, "application/json", `{"id": "g1"}`},
+		"wrong type":            {200, "application/json", strings.Replace(garden, `"version": 1`, `"version": "1"`, 1) + "}"},
+		"bad date-time":         {200, "application/json", strings.Replace(garden, "2025-04-01T09:00:00Z", "yesterday", 1) + "}"},
+		"undocumented status":   {418, "application/json", "{}"},
+		"wrong content type":    {404, "application/json", `{"error": "Not found"}`},
+	} {
+		assert.Error(t, doc.ValidateResponse("GET", "/v1/gardens/:garden_id", tt.status, tt.contentType, []byte(tt.body)), name)
+	}
+}
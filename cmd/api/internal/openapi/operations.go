@@ -0,0 +1,437 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	devicehandlers "github.com/zjpiazza/plantastic/cmd/api/handlers"
+	"github.com/zjpiazza/plantastic/cmd/api/internal/handlers"
+	"github.com/zjpiazza/plantastic/cmd/api/internal/storage"
+	"github.com/zjpiazza/plantastic/internal/models"
+	"github.com/zjpiazza/plantastic/internal/scheduler"
+)
+
+const bearerAuth = "bearerAuth"
+
+// Message is the body of responses that only confirm an action.
+type Message struct {
+	Message string `json:"message"`
+}
+
+// DeviceAuthorization is the response to POST /device/code (RFC 8628
+// section 3.2).
+type DeviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// TokenPair is the OAuth token response from the device token endpoints.
+type TokenPair struct {
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int    `json:"expires_in"`
+	RefreshExpiresIn int    `json:"refresh_expires_in"`
+}
+
+// OAuthError is an error from the OAuth endpoints (RFC 6749 section 5.2),
+// which keep that format rather than problem details.
+type OAuthError struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// LocalToken is the response to POST /auth/local/token.
+type LocalToken struct {
+	Token     string    `json:"token"`
+	TokenType string    `json:"token_type"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Descriptions of the error statuses operations are documented with.
+var errorDescriptions = map[int]string{
+	http.StatusBadRequest:            "The request or one of its fields is invalid",
+	http.StatusUnauthorized:          "Missing or invalid bearer token",
+	http.StatusForbidden:             "The caller is not allowed to do this",
+	http.StatusNotFound:              "The resource does not exist",
+	http.StatusConflict:              "The request conflicts with the current state",
+	http.StatusPreconditionFailed:    "The resource changed since the ETag given in If-Match",
+	http.StatusRequestEntityTooLarge: "The body of a request with an Idempotency-Key is too large",
+	http.StatusUnsupportedMediaType:  "The body is not in a supported content type",
+	http.StatusUnprocessableEntity:   "The Idempotency-Key was already used for a different request",
+	http.StatusTooManyRequests:       "Too many requests; retry after the number of seconds in Retry-After",
+	http.StatusInternalServerError:   "The server failed to handle the request",
+	http.StatusServiceUnavailable:    "The server cannot handle the request right now",
+	http.StatusGatewayTimeout:        "The database did not answer within the route's deadline; retry later",
+}
+
+var responseHeaders = map[string]Header{
+	"ETag": {
+		Description: "Version of the resource; send it back in If-Match to make a change conditional",
+		Schema:      &Schema{Type: "string"},
+	},
+	"Link": {
+		Description: `URL of the next page, as rel="next"`,
+		Schema:      &Schema{Type: "string"},
+	},
+	handlers.NextCursorHeader: {
+		Description: "Cursor for the next page; absent on the last page",
+		Schema:      &Schema{Type: "string"},
+	},
+}
+
+var (
+	ifMatch = Parameter{
+		Name:        "If-Match",
+		In:          "header",
+		Description: "Only apply the change if the resource is still at this ETag",
+		Schema:      &Schema{Type: "string"},
+	}
+	idempotencyKey = Parameter{
+		Name:        handlers.IdempotencyKeyHeader,
+		In:          "header",
+		Description: "Makes the request safe to retry: a retry with the same key gets the first response instead of being applied again",
+		Schema:      &Schema{Type: "string"},
+	}
+	taskScope = Parameter{
+		Name:        "scope",
+		In:          "query",
+		Description: "occurrence (the default) changes this occurrence of a recurring task; series changes every open occurrence",
+		Schema:      &Schema{Type: "string", Enum: []string{handlers.TaskScopeOccurrence, handlers.TaskScopeSeries}},
+	}
+)
+
+func (o *Operation) public() *Operation {
+	o.Security = nil
+	return o
+}
+
+func (o *Operation) describe(description string) *Operation {
+	o.Description = description
+	return o
+}
+
+func (o *Operation) params(params ...Parameter) *Operation {
+	o.Parameters = append(o.Parameters, params...)
+	return o
+}
+
+func (o *Operation) body(contentType string, schema *Schema) *Operation {
+	o.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{contentType: {Schema: schema}}}
+	return o
+}
+
+// respond documents a successful response; schema is nil for one without a
+// body.
+func (o *Operation) respond(status int, description string, schema *Schema, headers ...string) *Operation {
+	response := &Response{Description: description}
+	if schema != nil {
+		response.Content = map[string]MediaType{"application/json": {Schema: schema}}
+	}
+	for _, name := range headers {
+		if response.Headers == nil {
+			response.Headers = map[string]Header{}
+		}
+		response.Headers[name] = responseHeaders[name]
+	}
+	o.Responses[strconv.Itoa(status)] = response
+	return o
+}
+
+// fail documents problem+json error responses.
+func (o *Operation) fail(statuses ...int) *Operation {
+	for _, status := range statuses {
+		o.Responses[strconv.Itoa(status)] = &Response{
+			Description: errorDescriptions[status],
+			Content:     map[string]MediaType{handlers.ProblemContentType: {Schema: ref("Problem")}},
+		}
+	}
+	return o
+}
+
+// failOAuth documents OAuth-style error responses.
+func (o *Operation) failOAuth(statuses ...int) *Operation {
+	for _, status := range statuses {
+		o.Responses[strconv.Itoa(status)] = &Response{
+			Description: errorDescriptions[status],
+			Content:     map[string]MediaType{"application/json": {Schema: ref("OAuthError")}},
+		}
+	}
+	return o
+}
+
+func ref(component string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + component}
+}
+
+func arrayOf(schema *Schema) *Schema {
+	return &Schema{Type: "array", Items: schema}
+}
+
+// listParams documents the paging, sorting and filter parameters of a list.
+func listParams(opts storage.ListOptions) []Parameter {
+	sorts := make([]string, 0, 2*len(opts.Sorts))
+	for _, name := range opts.Sorts {
+		sorts = append(sorts, name, "-"+name)
+	}
+	params := []Parameter{
+		{Name: "limit", In: "query", Description: fmt.Sprintf("Page size, 1 to %d; %d if unset", storage.MaxPageSize, storage.DefaultPageSize), Schema: &Schema{Type: "integer"}},
+		{Name: "cursor", In: "query", Description: "Cursor from the previous page's " + handlers.NextCursorHeader + " header", Schema: &Schema{Type: "string"}},
+		{Name: "sort", In: "query", Description: "Field to sort by, prefixed with - for descending; " + opts.DefaultSort + " if unset", Schema: &Schema{Type: "string", Enum: sorts}},
+	}
+	for _, name := range opts.Filters {
+		description := "Only items whose " + name + " matches"
+		switch {
+		case strings.HasSuffix(name, "_from"):
+			description = "Only items at or after this RFC 3339 time"
+		case strings.HasSuffix(name, "_to"):
+			description = "Only items at or before this RFC 3339 time"
+		}
+		params = append(params, Parameter{Name: name, In: "query", Description: description, Schema: &Schema{Type: "string"}})
+	}
+	return params
+}
+
+// operations returns the operation table, keyed by method and Gin route path.
+func operations(s *schemas) map[string]*Operation {
+	s.of(handlers.Problem{})
+	s.of(OAuthError{})
+	var (
+		garden   = s.of(models.Garden{})
+		bed      = s.of(models.Bed{})
+		task     = s.of(models.Task{})
+		plant    = s.of(models.Plant{})
+		planting = s.of(models.Planting{})
+		message  = s.of(Message{})
+	)
+
+	table := map[string]*Operation{}
+	add := func(method, path, id, summary, tag string) *Operation {
+		op := &Operation{
+			OperationID: id,
+			Summary:     summary,
+			Tags:        []string{tag},
+			Security:    []map[string][]string{{bearerAuth: {}}},
+			Responses:   map[string]*Response{},
+		}
+		table[method+" "+path] = op
+		return op
+	}
+	const mergePatch = "application/merge-patch+json"
+
+	// Gardens
+	add("GET", "/gardens", "listGardens", "List gardens", "Gardens").
+		params(listParams(storage.GardenListOptions())...).
+		respond(200, "A page of gardens", arrayOf(garden), "Link", handlers.NextCursorHeader).fail(400)
+	add("POST", "/gardens", "createGarden", "Create a garden", "Gardens").
+		body("application/json", garden).
+		respond(201, "The created garden", garden, "ETag").fail(400, 409)
+	add("GET", "/gardens/:garden_id", "getGarden", "Get a garden", "Gardens").
+		respond(200, "The garden", garden, "ETag").fail(404)
+	add("PUT", "/gardens/:garden_id", "updateGarden", "Replace a garden", "Gardens").
+		params(ifMatch).body("application/json", garden).
+		respond(200, "The garden was updated", message, "ETag").fail(400, 404, 412)
+	add("PATCH", "/gardens/:garden_id", "patchGarden", "Update some fields of a garden", "Gardens").
+		describe("Applies a JSON merge patch (RFC 7396).").
+		params(ifMatch).body(mergePatch, garden).
+		respond(200, "The updated garden", garden, "ETag").fail(400, 404, 412, 415)
+	add("DELETE", "/gardens/:garden_id", "deleteGarden", "Delete a garden", "Gardens").
+		describe("Moves the garden, its beds and its tasks to the trash, from which they can be restored together.").
+		params(ifMatch).respond(204, "The garden was moved to the trash", nil).fail(404, 412)
+	add("GET", "/gardens/:garden_id/beds", "listGardenBeds", "List the beds in a garden", "Beds").
+		params(listParams(storage.BedListOptions())...).
+		respond(200, "A page of beds", arrayOf(bed), "Link", handlers.NextCursorHeader).fail(400, 404)
+	add("POST", "/gardens/:garden_id/beds", "createGardenBed", "Create a bed in a garden", "Beds").
+		body("application/json", bed).
+		respond(201, "The created bed", bed, "ETag").fail(400, 409)
+	add("GET", "/gardens/:garden_id/tasks", "listGardenTasks", "List the tasks in a garden", "Tasks").
+		params(listParams(storage.TaskListOptions())...).
+		respond(200, "A page of tasks", arrayOf(task), "Link", handlers.NextCursorHeader).fail(400, 404)
+	add("POST", "/gardens/:garden_id/tasks", "createGardenTask", "Create a task in a garden", "Tasks").
+		body("application/json", task).
+		respond(201, "The created task", task, "ETag").fail(400, 409)
+	add("GET", "/gardens/:garden_id/beds/:bed_id/tasks", "listBedTasks", "List the tasks for a bed", "Tasks").
+		params(listParams(storage.TaskListOptions())...).
+		respond(200, "A page of tasks", arrayOf(task), "Link", handlers.NextCursorHeader).fail(400, 404)
+	add("POST", "/gardens/:garden_id/beds/:bed_id/tasks", "createBedTask", "Create a task for a bed", "Tasks").
+		body("application/json", task).
+		respond(201, "The created task", task, "ETag").fail(400, 409)
+
+	// Beds
+	add("GET", "/beds", "listBeds", "List beds", "Beds").
+		params(listParams(storage.BedListOptions())...).
+		respond(200, "A page of beds", arrayOf(bed), "Link", handlers.NextCursorHeader).fail(400)
+	add("POST", "/beds", "createBed", "Create a bed", "Beds").
+		body("application/json", bed).
+		respond(201, "The created bed", bed, "ETag").fail(400, 409)
+	add("GET", "/beds/:bed_id", "getBed", "Get a bed", "Beds").
+		respond(200, "The bed", bed, "ETag").fail(404)
+	add("PUT", "/beds/:bed_id", "updateBed", "Replace a bed", "Beds").
+		params(ifMatch).body("application/json", bed).
+		respond(200, "The bed was updated", message, "ETag").fail(400, 404, 412)
+	add("PATCH", "/beds/:bed_id", "patchBed", "Update some fields of a bed", "Beds").
+		describe("Applies a JSON merge patch (RFC 7396).").
+		params(ifMatch).body(mergePatch, bed).
+		respond(200, "The updated bed", bed, "ETag").fail(400, 404, 412, 415)
+	add("DELETE", "/beds/:bed_id", "deleteBed", "Delete a bed", "Beds").
+		describe("Moves the bed to the trash. Its tasks stay in the garden.").
+		params(ifMatch).respond(204, "The bed was moved to the trash", nil).fail(404, 412)
+
+	// Tasks
+	add("GET", "/tasks", "listTasks", "List tasks", "Tasks").
+		params(listParams(storage.TaskListOptions())...).
+		respond(200, "A page of tasks", arrayOf(task), "Link", handlers.NextCursorHeader).fail(400)
+	add("POST", "/tasks", "createTask", "Create a task", "Tasks").
+		body("application/json", task).
+		respond(201, "The created task", task, "ETag").fail(400, 409)
+	add("POST", "/tasks/bulk", "bulkTasks", "Apply operations to many tasks", "Tasks").
+		describe(fmt.Sprintf("Applies up to %d create, update, delete, complete and reschedule operations. "+
+			"In atomic mode (the default) either all are applied or none, and a failure is answered with the failing operation's status. "+
+			"In partial mode each stands alone, and a batch with failures is answered with 207.", storage.MaxBulkTaskOps)).
+		body("application/json", s.of(handlers.BulkTasksRequest{})).
+		respond(200, "Every operation was applied", s.of(handlers.BulkTasksResponse{})).
+		respond(207, "Some operations failed (partial mode)", s.of(handlers.BulkTasksResponse{})).
+		respond(404, "An operation's task does not exist (atomic mode)", s.of(handlers.BulkTasksResponse{})).
+		respond(409, "An operation conflicted (atomic mode)", s.of(handlers.BulkTasksResponse{})).
+		respond(412, "An operation's version is stale (atomic mode)", s.of(handlers.BulkTasksResponse{})).
+		fail(400)
+	add("GET", "/tasks/:task_id", "getTask", "Get a task", "Tasks").
+		respond(200, "The task", task, "ETag").fail(404)
+	add("GET", "/tasks/:task_id/history", "getTaskHistory", "List a task's status changes", "Tasks").
+		respond(200, "Status changes, oldest first", arrayOf(s.of(models.TaskEvent{}))).fail(404)
+	add("PUT", "/tasks/:task_id", "updateTask", "Replace a task", "Tasks").
+		params(taskScope, ifMatch).body("application/json", task).
+		respond(200, "The task was updated", message, "ETag").fail(400, 404, 412)
+	add("PATCH", "/tasks/:task_id", "patchTask", "Update some fields of a task", "Tasks").
+		describe(`Applies a JSON merge patch (RFC 7396); {"garden_bed_id": null} takes the task out of its bed.`).
+		params(taskScope, ifMatch).body(mergePatch, task).
+		respond(200, "The updated task", task, "ETag").fail(400, 404, 412, 415)
+	add("DELETE", "/tasks/:task_id", "deleteTask", "Delete a task", "Tasks").
+		describe("Moves the task to the trash.").
+		params(ifMatch).respond(204, "The task was moved to the trash", nil).fail(404, 412)
+
+	// Plant catalog
+	add("GET", "/plants", "listPlants", "List the plant catalog", "Plants").
+		params(listParams(storage.PlantListOptions())...).
+		respond(200, "A page of plants", arrayOf(plant), "Link", handlers.NextCursorHeader).fail(400)
+	add("POST", "/plants", "createPlant", "Add a plant to the catalog", "Plants").
+		body("application/json", plant).
+		respond(201, "The created plant", plant).fail(400, 409)
+	add("GET", "/plants/:plant_id", "getPlant", "Get a plant", "Plants").
+		respond(200, "The plant", plant).fail(404)
+	add("PUT", "/plants/:plant_id", "updatePlant", "Replace a plant", "Plants").
+		body("application/json", plant).
+		respond(200, "The plant was updated", message).fail(400, 404)
+	add("DELETE", "/plants/:plant_id", "deletePlant", "Remove a plant from the catalog", "Plants").
+		respond(204, "The plant was deleted", nil).fail(404, 409)
+
+	// Plantings
+	add("GET", "/plantings", "listPlantings", "List plantings", "Plantings").
+		params(listParams(storage.PlantingListOptions())...).
+		respond(200, "A page of plantings", arrayOf(planting), "Link", handlers.NextCursorHeader).fail(400)
+	add("POST", "/plantings", "createPlanting", "Plant something in a bed", "Plantings").
+		body("application/json", planting).
+		respond(201, "The created planting", planting).fail(400, 409)
+	add("GET", "/plantings/:planting_id", "getPlanting", "Get a planting", "Plantings").
+		respond(200, "The planting", planting).fail(404)
+	add("PUT", "/plantings/:planting_id", "updatePlanting", "Replace a planting", "Plantings").
+		body("application/json", planting).
+		respond(200, "The planting was updated", message).fail(400, 404)
+	add("DELETE", "/plantings/:planting_id", "deletePlanting", "Delete a planting", "Plantings").
+		respond(204, "The planting was deleted", nil).fail(404)
+
+	// Trash
+	trashType := Parameter{
+		Name:        "type",
+		In:          "query",
+		Description: "Only items of this type",
+		Schema:      &Schema{Type: "string", Enum: []string{storage.TrashGarden, storage.TrashBed, storage.TrashTask}},
+	}
+	add("GET", "/trash", "listTrash", "List deleted gardens, beds and tasks", "Trash").
+		describe("Most recently deleted first. Beds and tasks deleted with their garden are not listed separately.").
+		params(trashType).
+		respond(200, "Items in the trash", arrayOf(s.of(storage.TrashItem{}))).fail(400)
+	add("DELETE", "/trash", "emptyTrash", "Empty the trash", "Trash").
+		respond(200, "Everything in the trash was deleted for good", s.of(handlers.EmptyTrashResponse{}))
+	add("POST", "/trash/:type/:id/restore", "restoreTrashItem", "Restore a deleted item", "Trash").
+		describe("type is garden, bed or task. A garden comes back with the beds and tasks deleted along with it; "+
+			"a bed or task whose garden is in the trash cannot be restored on its own.").
+		respond(200, "What was restored", s.of(storage.RestoreResult{})).fail(400, 404, 409)
+	add("DELETE", "/trash/:type/:id", "purgeTrashItem", "Delete an item in the trash for good", "Trash").
+		describe("type is garden, bed or task. Everything in the item goes with it.").
+		respond(204, "The item was deleted for good", nil).fail(400, 404)
+
+	// Audit log
+	add("GET", "/audit", "listAudit", "List changes to your data", "Audit").
+		describe("Every create, update and delete of a garden, bed, task, plant or planting, newest first, with who made it: "+
+			"a user (actor_id is their user ID), a linked device (actor_id is the device ID) or the system. "+
+			"before and after hold the fields that changed; the whole entity for a create or delete.").
+		params(listParams(storage.AuditListOptions())...).
+		respond(200, "A page of audit entries", arrayOf(s.of(models.AuditEntry{})), "Link", handlers.NextCursorHeader).fail(400)
+
+	// Device flow (RFC 8628) and linked devices
+	add("POST", "/device/code", "startDeviceAuthorization", "Start linking a device", "Devices").public().
+		body("application/x-www-form-urlencoded", s.of(devicehandlers.DeviceAuthorizationRequest{})).
+		respond(200, "Codes for the device to show the user and to poll with", s.of(DeviceAuthorization{})).
+		failOAuth(400, 409, http.StatusTooManyRequests, 500, 503)
+	add("POST", "/device/token", "pollDeviceToken", "Exchange a device code or refresh token for tokens", "Devices").public().
+		body("application/x-www-form-urlencoded", s.of(devicehandlers.TokenRequest{})).
+		respond(200, "The device's tokens", s.of(TokenPair{})).
+		failOAuth(400, http.StatusTooManyRequests, 500)
+	add("POST", "/device/token/refresh", "refreshDeviceToken", "Refresh a device's tokens", "Devices").public().
+		body("application/json", s.of(devicehandlers.RefreshTokenRequest{})).
+		respond(200, "New tokens", s.of(TokenPair{})).fail(400, 401, 500)
+	add("POST", "/device/link", "linkDevice", "Approve a device's link request", "Devices").
+		body("application/json", s.of(devicehandlers.AuthenticateDeviceRequest{})).
+		respond(200, "The device can now collect its tokens", message).fail(400)
+	add("POST", "/device/deny", "denyDevice", "Reject a device's link request", "Devices").
+		body("application/json", s.of(devicehandlers.DenyDeviceRequest{})).
+		respond(200, "The request was denied", message).fail(400)
+	add("GET", "/devices", "listDevices", "List linked devices", "Devices").
+		respond(200, "The caller's linked devices", arrayOf(s.of(devicehandlers.LinkedDevice{})))
+	add("PATCH", "/devices/:device_id", "renameDevice", "Rename a linked device", "Devices").
+		body("application/json", s.of(devicehandlers.RenameDeviceRequest{})).
+		respond(200, "The renamed device", s.of(devicehandlers.LinkedDevice{})).fail(400, 404)
+	add("DELETE", "/devices/:device_id", "revokeDevice", "Unlink a device", "Devices").
+		respond(200, "The device was unlinked", message).fail(404)
+
+	// Everything else
+	add("POST", "/auth/local/token", "issueLocalToken", "Issue a token for a local user", "Auth").public().
+		describe("Only available when the API runs with AUTH_PROVIDER=local.").
+		body("application/json", s.of(devicehandlers.IssueTokenRequest{})).
+		respond(200, "A bearer token", s.of(LocalToken{})).fail(400, 404, 500)
+	add("GET", "/maintenance/jobs", "listJobs", "List background jobs", "Maintenance").
+		describe("Only available to the users listed in ADMIN_USER_IDS.").
+		respond(200, "Every background job and its last run", arrayOf(s.of(scheduler.JobStatus{}))).fail(403)
+	add("GET", "/", "welcome", "Check that the API is up", "Meta").public().
+		respond(200, "A greeting", message)
+	add("GET", Path, "getOpenAPI", "Get this document", "Meta").public().
+		respond(200, "The OpenAPI document", &Schema{Type: "object"})
+
+	for key, op := range table {
+		if op.Security == nil {
+			continue
+		}
+		op.fail(401, 500)
+		method, path, _ := strings.Cut(key, " ")
+		if !strings.HasPrefix(path, "/device") && path != "/maintenance/jobs" {
+			op.fail(504) // Resource routes run their queries under a deadline
+		}
+		if method != "GET" {
+			// Handled by the Idempotency middleware on every protected write
+			op.params(idempotencyKey)
+			op.fail(413, 422)
+			if _, ok := op.Responses["409"]; !ok {
+				op.fail(409)
+			}
+		}
+	}
+	return table
+}
@@ -0,0 +1,152 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Schema is an OpenAPI 3.0 schema object, limited to what the API's types
+// need.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	ReadOnly             bool               `json:"readOnly,omitempty"`
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// modelsPkgPath is the package of the stored resources. Their bookkeeping
+// fields are set by the server and documented as read-only.
+const modelsPkgPath = "github.com/zjpiazza/plantastic/internal/models"
+
+var serverSetFields = map[string]bool{"id": true, "user_id": true, "version": true, "created_at": true, "updated_at": true}
+
+// schemas turns Go types into schemas the way encoding/json would encode
+// them. Named struct types become components, referenced by name.
+type schemas struct {
+	components map[string]*Schema
+	types      map[string]reflect.Type // Go type of each component, to catch name clashes
+}
+
+func newSchemas() *schemas {
+	return &schemas{components: map[string]*Schema{}, types: map[string]reflect.Type{}}
+}
+
+// of returns the schema for values like v.
+func (s *schemas) of(v interface{}) *Schema {
+	return s.forType(reflect.TypeOf(v))
+}
+
+func (s *schemas) forType(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case rawMessageType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		elem := s.forType(t.Elem())
+		if elem.Ref != "" {
+			return &Schema{AllOf: []*Schema{elem}, Nullable: true} // $ref ignores sibling keywords
+		}
+		elem.Nullable = true
+		return elem
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.forType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.forType(t.Elem())}
+	case reflect.Interface:
+		return &Schema{}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		return s.component(t)
+	}
+	panic(fmt.Sprintf("openapi: no schema for %s", t))
+}
+
+// component registers t as a component schema and returns a reference to it.
+func (s *schemas) component(t reflect.Type) *Schema {
+	name := t.Name()
+	ref := &Schema{Ref: "#/components/schemas/" + name}
+	if existing, ok := s.types[name]; ok {
+		if existing != t {
+			panic(fmt.Sprintf("openapi: %s and %s would both be component %q", existing, t, name))
+		}
+		return ref
+	}
+	s.types[name] = t
+	s.components[name] = nil // Placeholder, so recursive types terminate
+	s.components[name] = s.object(t)
+	return ref
+}
+
+func (s *schemas) object(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	s.addFields(schema, t)
+	return schema
+}
+
+func (s *schemas) addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			s.addFields(schema, field.Type) // Embedded fields are encoded inline
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := s.forType(field.Type)
+		if t.PkgPath() == modelsPkgPath && serverSetFields[name] {
+			if property.Ref != "" {
+				property = &Schema{AllOf: []*Schema{property}}
+			}
+			property.ReadOnly = true
+		}
+		schema.Properties[name] = property
+		if !strings.Contains(options, "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValidateResponse checks a response from the route method ginPath against
+// the document: the status must be documented for the operation, and the body
+// must be in a documented content type and match its schema. Properties the
+// schema does not declare are errors, so a field added to a response without
+// being documented is caught.
+func (d *Document) ValidateResponse(method, ginPath string, status int, contentType string, body []byte) error {
+	op := d.Paths[openAPIPath(ginPath)][strings.ToLower(method)]
+	if op == nil {
+		return fmt.Errorf("%s %s is not documented", method, ginPath)
+	}
+	response := op.Responses[strconv.Itoa(status)]
+	if response == nil {
+		return fmt.Errorf("%s %s: status %d is not documented", method, ginPath, status)
+	}
+	if len(response.Content) == 0 {
+		if len(body) > 0 {
+			return fmt.Errorf("%s %s: status %d is documented without a body, got %q", method, ginPath, status, body)
+		}
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	content, ok := response.Content[mediaType]
+	if !ok {
+		return fmt.Errorf("%s %s: status %d is not documented with content type %q", method, ginPath, status, contentType)
+	}
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return fmt.Errorf("%s %s: status %d: body is not JSON: %w", method, ginPath, status, err)
+	}
+	if err := d.validate(content.Schema, value, "body"); err != nil {
+		return fmt.Errorf("%s %s: status %d: %w", method, ginPath, status, err)
+	}
+	return nil
+}
+
+// validate checks value, as decoded by encoding/json, against the subset of
+// JSON Schema the document uses.
+func (d *Document) validate(schema *Schema, value interface{}, at string) error {
+	if schema.Ref != "" {
+		name := strings.TrimPrefix(schema.Ref, "#/components/schemas/")
+		component, ok := d.Components.Schemas[name]
+		if !ok {
+			return fmt.Errorf("%s: unknown schema %s", at, schema.Ref)
+		}
+		return d.validate(component, value, at)
+	}
+	if value == nil {
+		if schema.Nullable || isEmpty(schema) {
+			return nil
+		}
+		return fmt.Errorf("%s: null is not allowed", at)
+	}
+	for _, sub := range schema.AllOf {
+		if err := d.validate(sub, value, at); err != nil {
+			return err
+		}
+	}
+
+	switch schema.Type {
+	case "":
+		return nil
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s: want a string, got %T", at, value)
+		}
+		if len(schema.Enum) > 0 && !contains(schema.Enum, s) {
+			return fmt.Errorf("%s: %q is not one of %s", at, s, strings.Join(schema.Enum, ", "))
+		}
+		if schema.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, s); err != nil {
+				return fmt.Errorf("%s: %q is not a date-time", at, s)
+			}
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s: want a boolean, got %T", at, value)
+		}
+	case "integer", "number":
+		n, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("%s: want a number, got %T", at, value)
+		}
+		if schema.Type == "integer" && n != float64(int64(n)) {
+			return fmt.Errorf("%s: want an integer, got %v", at, n)
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: want an array, got %T", at, value)
+		}
+		for i, item := range items {
+			if err := d.validate(schema.Items, item, fmt.Sprintf("%s[%d]", at, i)); err != nil {
+				return err
+			}
+		}
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: want an object, got %T", at, value)
+		}
+		return d.validateObject(schema, object, at)
+	default:
+		return fmt.Errorf("%s: unsupported schema type %q", at, schema.Type)
+	}
+	return nil
+}
+
+func (d *Document) validateObject(schema *Schema, object map[string]interface{}, at string) error {
+	for _, name := range schema.Required {
+		if _, ok := object[name]; !ok {
+			return fmt.Errorf("%s: missing required property %q", at, name)
+		}
+	}
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		property, ok := schema.Properties[name]
+		switch {
+		case ok:
+		case schema.AdditionalProperties != nil:
+			property = schema.AdditionalProperties
+		case schema.Properties == nil:
+			continue // A free-form object
+		default:
+			return fmt.Errorf("%s: undocumented property %q", at, name)
+		}
+		if err := d.validate(property, object[name], at+"."+name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isEmpty reports whether schema accepts any value.
+func isEmpty(schema *Schema) bool {
+	return schema.Type == "" && len(schema.AllOf) == 0
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
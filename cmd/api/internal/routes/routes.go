@@ -1,124 +1,184 @@
 package routes
 
 import (
+	"time"
+
 	"github.com/gin-gonic/gin"
 	devicehandlers "github.com/zjpiazza/plantastic/cmd/api/handlers"
 	"github.com/zjpiazza/plantastic/cmd/api/internal/handlers"
 	"github.com/zjpiazza/plantastic/cmd/api/internal/storage"
 )
 
-func SetupRoutes(gardenStore storage.GardenStorer, bedStore storage.BedStorer, taskStore storage.TaskStorer) *gin.Engine {
-	r := gin.Default()
+// Timeouts are the deadlines for the database queries of each kind of
+// protected route. A request that runs past its deadline fails with 504.
+type Timeouts struct {
+	Read  time.Duration // Gets and lists
+	Write time.Duration // Creates, updates, deletes and restores
+	Bulk  time.Duration // Bulk task operations and emptying the trash
+}
+
+// DefaultTimeouts are the deadlines used unless configured otherwise.
+var DefaultTimeouts = Timeouts{Read: 5 * time.Second, Write: 10 * time.Second, Bulk: 30 * time.Second}
+
+func SetupProtectedRoutes(rg *gin.RouterGroup, gardenStore storage.GardenStorer, bedStore storage.BedStorer, taskStore storage.TaskStorer, plantStore storage.PlantStorer, plantingStore storage.PlantingStorer, trashStore storage.TrashStorer, auditStore storage.AuditStorer, deviceHandler *devicehandlers.DeviceHandler, timeouts Timeouts) {
+	read := handlers.QueryTimeout(timeouts.Read)
+	write := handlers.QueryTimeout(timeouts.Write)
+	bulk := handlers.QueryTimeout(timeouts.Bulk)
 
 	// Garden Routes
-	r.GET("/gardens", func(c *gin.Context) {
+	rg.GET("/gardens", read, func(c *gin.Context) {
 		handlers.ListGardensHandler(gardenStore, c)
 	})
-	r.POST("/gardens", func(c *gin.Context) {
+	rg.POST("/gardens", write, func(c *gin.Context) {
 		handlers.CreateGardenHandler(gardenStore, c)
 	})
-	r.GET("/gardens/:garden_id", func(c *gin.Context) {
+	rg.GET("/gardens/:garden_id", read, func(c *gin.Context) {
 		handlers.GetGardenHandler(gardenStore, c)
 	})
-	r.PUT("/gardens/:garden_id", func(c *gin.Context) {
+	rg.PUT("/gardens/:garden_id", write, func(c *gin.Context) {
 		handlers.UpdateGardenHandler(gardenStore, c)
 	})
-	r.DELETE("/gardens/:garden_id", func(c *gin.Context) {
+	rg.PATCH("/gardens/:garden_id", write, func(c *gin.Context) {
+		handlers.PatchGardenHandler(gardenStore, c)
+	})
+	rg.DELETE("/gardens/:garden_id", write, func(c *gin.Context) {
 		handlers.DeleteGardenHandler(gardenStore, c)
 	})
+	rg.GET("/gardens/:garden_id/beds", read, func(c *gin.Context) {
+		handlers.ListGardenBedsHandler(bedStore, c)
+	})
+	rg.POST("/gardens/:garden_id/beds", write, func(c *gin.Context) {
+		handlers.CreateBedHandler(bedStore, c)
+	})
+	rg.GET("/gardens/:garden_id/tasks", read, func(c *gin.Context) {
+		handlers.ListGardenTasksHandler(taskStore, c)
+	})
+	rg.POST("/gardens/:garden_id/tasks", write, func(c *gin.Context) {
+		handlers.CreateTaskHandler(taskStore, c)
+	})
+	rg.GET("/gardens/:garden_id/beds/:bed_id/tasks", read, func(c *gin.Context) {
+		handlers.ListBedTasksHandler(taskStore, c)
+	})
+	rg.POST("/gardens/:garden_id/beds/:bed_id/tasks", write, func(c *gin.Context) {
+		handlers.CreateTaskHandler(taskStore, c)
+	})
 
 	// Bed Routes
-	r.GET("/beds", func(c *gin.Context) {
+	rg.GET("/beds", read, func(c *gin.Context) {
 		handlers.ListBedsHandler(bedStore, c)
 	})
-	r.POST("/beds", func(c *gin.Context) {
+	rg.POST("/beds", write, func(c *gin.Context) {
 		handlers.CreateBedHandler(bedStore, c)
 	})
-	r.GET("/beds/:bed_id", func(c *gin.Context) {
+	rg.GET("/beds/:bed_id", read, func(c *gin.Context) {
 		handlers.GetBedHandler(bedStore, c)
 	})
-	r.PUT("/beds/:bed_id", func(c *gin.Context) {
+	rg.PUT("/beds/:bed_id", write, func(c *gin.Context) {
 		handlers.UpdateBedHandler(bedStore, c)
 	})
-	r.DELETE("/beds/:bed_id", func(c *gin.Context) {
+	rg.PATCH("/beds/:bed_id", write, func(c *gin.Context) {
+		handlers.PatchBedHandler(bedStore, c)
+	})
+	rg.DELETE("/beds/:bed_id", write, func(c *gin.Context) {
 		handlers.DeleteBedHandler(bedStore, c)
 	})
 
 	// Task Routes
-	r.GET("/tasks", func(c *gin.Context) {
+	rg.GET("/tasks", read, func(c *gin.Context) {
 		handlers.ListTasksHandler(taskStore, c)
 	})
-	r.POST("/tasks", func(c *gin.Context) {
+	rg.POST("/tasks", write, func(c *gin.Context) {
 		handlers.CreateTaskHandler(taskStore, c)
 	})
-	r.GET("/tasks/:task_id", func(c *gin.Context) {
+	rg.POST("/tasks/bulk", bulk, func(c *gin.Context) {
+		handlers.BulkTasksHandler(taskStore, c)
+	})
+	rg.GET("/tasks/:task_id", read, func(c *gin.Context) {
 		handlers.GetTaskHandler(taskStore, c)
 	})
-	r.PUT("/tasks/:task_id", func(c *gin.Context) {
+	rg.GET("/tasks/:task_id/history", read, func(c *gin.Context) {
+		handlers.GetTaskHistoryHandler(taskStore, c)
+	})
+	rg.PUT("/tasks/:task_id", write, func(c *gin.Context) {
 		handlers.UpdateTaskHandler(taskStore, c)
 	})
-	r.DELETE("/tasks/:task_id", func(c *gin.Context) {
+	rg.PATCH("/tasks/:task_id", write, func(c *gin.Context) {
+		handlers.PatchTaskHandler(taskStore, c)
+	})
+	rg.DELETE("/tasks/:task_id", write, func(c *gin.Context) {
 		handlers.DeleteTaskHandler(taskStore, c)
 	})
 
-	return r
-}
-
-func SetupProtectedRoutes(rg *gin.RouterGroup, gardenStore storage.GardenStorer, bedStore storage.BedStorer, taskStore storage.TaskStorer, deviceHandler *devicehandlers.DeviceHandler) {
-	// Garden Routes
-	rg.GET("/gardens", func(c *gin.Context) {
-		handlers.ListGardensHandler(gardenStore, c)
+	// Plant Catalog Routes
+	rg.GET("/plants", read, func(c *gin.Context) {
+		handlers.ListPlantsHandler(plantStore, c)
 	})
-	rg.POST("/gardens", func(c *gin.Context) {
-		handlers.CreateGardenHandler(gardenStore, c)
+	rg.POST("/plants", write, func(c *gin.Context) {
+		handlers.CreatePlantHandler(plantStore, c)
 	})
-	rg.GET("/gardens/:garden_id", func(c *gin.Context) {
-		handlers.GetGardenHandler(gardenStore, c)
+	rg.GET("/plants/:plant_id", read, func(c *gin.Context) {
+		handlers.GetPlantHandler(plantStore, c)
 	})
-	rg.PUT("/gardens/:garden_id", func(c *gin.Context) {
-		handlers.UpdateGardenHandler(gardenStore, c)
+	rg.PUT("/plants/:plant_id", write, func(c *gin.Context) {
+		handlers.UpdatePlantHandler(plantStore, c)
 	})
-	rg.DELETE("/gardens/:garden_id", func(c *gin.Context) {
-		handlers.DeleteGardenHandler(gardenStore, c)
+	rg.DELETE("/plants/:plant_id", write, func(c *gin.Context) {
+		handlers.DeletePlantHandler(plantStore, c)
 	})
 
-	// Bed Routes
-	rg.GET("/beds", func(c *gin.Context) {
-		handlers.ListBedsHandler(bedStore, c)
+	// Planting Routes
+	rg.GET("/plantings", read, func(c *gin.Context) {
+		handlers.ListPlantingsHandler(plantingStore, c)
 	})
-	rg.POST("/beds", func(c *gin.Context) {
-		handlers.CreateBedHandler(bedStore, c)
+	rg.POST("/plantings", write, func(c *gin.Context) {
+		handlers.CreatePlantingHandler(plantingStore, c)
 	})
-	rg.GET("/beds/:bed_id", func(c *gin.Context) {
-		handlers.GetBedHandler(bedStore, c)
+	rg.GET("/plantings/:planting_id", read, func(c *gin.Context) {
+		handlers.GetPlantingHandler(plantingStore, c)
 	})
-	rg.PUT("/beds/:bed_id", func(c *gin.Context) {
-		handlers.UpdateBedHandler(bedStore, c)
+	rg.PUT("/plantings/:planting_id", write, func(c *gin.Context) {
+		handlers.UpdatePlantingHandler(plantingStore, c)
 	})
-	rg.DELETE("/beds/:bed_id", func(c *gin.Context) {
-		handlers.DeleteBedHandler(bedStore, c)
+	rg.DELETE("/plantings/:planting_id", write, func(c *gin.Context) {
+		handlers.DeletePlantingHandler(plantingStore, c)
 	})
 
-	// Task Routes
-	rg.GET("/tasks", func(c *gin.Context) {
-		handlers.ListTasksHandler(taskStore, c)
+	// Trash Routes: deleted gardens, beds and tasks, by type ("garden", "bed" or "task") and ID
+	rg.GET("/trash", read, func(c *gin.Context) {
+		handlers.ListTrashHandler(trashStore, c)
 	})
-	rg.POST("/tasks", func(c *gin.Context) {
-		handlers.CreateTaskHandler(taskStore, c)
+	rg.DELETE("/trash", bulk, func(c *gin.Context) {
+		handlers.EmptyTrashHandler(trashStore, c)
 	})
-	rg.GET("/tasks/:task_id", func(c *gin.Context) {
-		handlers.GetTaskHandler(taskStore, c)
+	rg.POST("/trash/:type/:id/restore", write, func(c *gin.Context) {
+		handlers.RestoreTrashHandler(trashStore, c)
 	})
-	rg.PUT("/tasks/:task_id", func(c *gin.Context) {
-		handlers.UpdateTaskHandler(taskStore, c)
+	rg.DELETE("/trash/:type/:id", write, func(c *gin.Context) {
+		handlers.PurgeTrashHandler(trashStore, c)
 	})
-	rg.DELETE("/tasks/:task_id", func(c *gin.Context) {
-		handlers.DeleteTaskHandler(taskStore, c)
+
+	// Audit Log Routes
+	rg.GET("/audit", read, func(c *gin.Context) {
+		handlers.ListAuditHandler(auditStore, c)
 	})
 
 	// Device Authentication Routes
 	deviceAuthGroup := rg.Group("/device") // Prefixing with /device
 	{
 		deviceAuthGroup.POST("/link", deviceHandler.AuthenticateDevice) // This remains protected
+		deviceAuthGroup.POST("/deny", deviceHandler.DenyDevice)
 	}
+
+	// Linked Device Management Routes
+	rg.GET("/devices", deviceHandler.ListDevices)
+	rg.PATCH("/devices/:device_id", deviceHandler.RenameDevice)
+	rg.DELETE("/devices/:device_id", deviceHandler.RevokeDevice)
+}
+
+// SetupDeviceRoutes registers the public endpoints of the OAuth 2.0 device
+// authorization grant (RFC 8628), which devices call before they have a token.
+func SetupDeviceRoutes(r gin.IRoutes, deviceHandler *devicehandlers.DeviceHandler) {
+	r.POST("/device/code", deviceHandler.DeviceAuthorization)
+	r.POST("/device/token", deviceHandler.Token)
+	r.POST("/device/token/refresh", deviceHandler.RefreshToken)
 }
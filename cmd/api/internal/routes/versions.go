@@ -0,0 +1,75 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	devicehandlers "github.com/zjpiazza/plantastic/cmd/api/handlers"
+	"github.com/zjpiazza/plantastic/cmd/api/internal/storage"
+)
+
+// V1 is the path prefix of version 1 of the API. Each version is served from
+// its own router group, so a /v2 can be registered beside it with its own
+// setup functions (reusing handlers that don't change) while clients move over.
+const V1 = "/v1"
+
+// The unversioned routes predate /v1 and serve the same handlers until they
+// are removed at LegacySunset.
+var (
+	LegacyDeprecated = time.Date(2026, time.October, 16, 0, 0, 0, 0, time.UTC)
+	LegacySunset     = time.Date(2027, time.April, 16, 0, 0, 0, 0, time.UTC)
+)
+
+// Deprecated returns middleware for routes that are going away. Responses
+// carry a Deprecation header (RFC 9745) dated deprecated, a Sunset header
+// (RFC 8594) for when the route stops working, and a successor-version Link
+// to the same path under successor.
+func Deprecated(deprecated, sunset time.Time, successor string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		h := c.Writer.Header()
+		h.Set("Deprecation", fmt.Sprintf("@%d", deprecated.Unix()))
+		h.Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+		h.Add("Link", fmt.Sprintf(`<%s%s>; rel="successor-version"`, successor, c.Request.URL.Path))
+		c.Next()
+	}
+}
+
+// Deps are the stores, handlers and middleware a version of the API is
+// served with.
+type Deps struct {
+	Gardens   storage.GardenStorer
+	Beds      storage.BedStorer
+	Tasks     storage.TaskStorer
+	Plants    storage.PlantStorer
+	Plantings storage.PlantingStorer
+	Trash     storage.TrashStorer
+	Audit     storage.AuditStorer
+
+	Devices     *devicehandlers.DeviceHandler
+	Maintenance *devicehandlers.MaintenanceHandler
+	LocalAuth   *devicehandlers.LocalAuthHandler // Only set when the local auth provider is configured
+
+	// Protected runs before every route that needs a bearer token: it
+	// authenticates the caller, then applies per-user middleware such as
+	// idempotency.
+	Protected []gin.HandlerFunc
+	Timeouts  Timeouts
+}
+
+// SetupV1 registers version 1 of the API on rg. main mounts it under V1 and
+// again, deprecated, at the root.
+func SetupV1(rg *gin.RouterGroup, deps Deps) {
+	// Public Device Routes (OAuth 2.0 device authorization grant, RFC 8628)
+	SetupDeviceRoutes(rg, deps.Devices)
+
+	// Local auth provider: mint tokens for the static user list (development/tests only)
+	if deps.LocalAuth != nil {
+		rg.POST("/auth/local/token", deps.LocalAuth.IssueToken)
+	}
+
+	protected := rg.Group("/", deps.Protected...)
+	SetupProtectedRoutes(protected, deps.Gardens, deps.Beds, deps.Tasks, deps.Plants, deps.Plantings, deps.Trash, deps.Audit, deps.Devices, deps.Timeouts)
+	protected.GET("/maintenance/jobs", deps.Maintenance.Jobs)
+}
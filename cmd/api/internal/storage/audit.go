@@ -0,0 +1,379 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zjpiazza/plantastic/internal/auth"
+	"github.com/zjpiazza/plantastic/internal/device"
+	"github.com/zjpiazza/plantastic/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// auditedTables maps the tables whose changes are audited to the entity name
+// the audit log records them under.
+var auditedTables = map[string]string{
+	"gardens":   "garden",
+	"beds":      "bed",
+	"tasks":     "task",
+	"plants":    "plant",
+	"plantings": "planting",
+}
+
+// unauditedFields change on every update, so are left out of the diffs.
+var unauditedFields = map[string]bool{"version": true, "updated_at": true}
+
+// AuditLog is a GORM plugin that records every create, update and delete of
+// the audited tables as a models.AuditEntry, in the same transaction as the
+// change. The actor is the auth.Principal in the statement's context, so
+// changes made with db.WithContext(ctx) for a request are attributed to its
+// caller; changes made without one are attributed to the system.
+type AuditLog struct{}
+
+// Name implements gorm.Plugin.
+func (AuditLog) Name() string { return "plantastic:audit_log" }
+
+// Initialize implements gorm.Plugin.
+func (AuditLog) Initialize(db *gorm.DB) error {
+	const commit = "gorm:commit_or_rollback_transaction"
+	create := db.Callback().Create()
+	if err := create.After("gorm:after_create").Before(commit).Register("audit:after_create", auditCreate); err != nil {
+		return err
+	}
+	update := db.Callback().Update()
+	if err := update.Before("gorm:update").Register("audit:before_update", auditLoadBefore); err != nil {
+		return err
+	}
+	if err := update.After("gorm:after_update").Before(commit).Register("audit:after_update", auditUpdate); err != nil {
+		return err
+	}
+	del := db.Callback().Delete()
+	if err := del.Before("gorm:delete").Register("audit:before_delete", auditLoadBefore); err != nil {
+		return err
+	}
+	return del.After("gorm:after_delete").Before(commit).Register("audit:after_delete", auditDelete)
+}
+
+// auditBeforeKey is the statement setting under which auditLoadBefore keeps
+// the rows as they were before an update or delete.
+const auditBeforeKey = "audit:before"
+
+// audited returns the entity name of the statement's table, if it is audited
+// and the statement has not failed.
+func audited(db *gorm.DB) (string, bool) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return "", false
+	}
+	entity, ok := auditedTables[db.Statement.Schema.Table]
+	return entity, ok
+}
+
+func auditCreate(db *gorm.DB) {
+	entity, ok := audited(db)
+	if !ok {
+		return
+	}
+	var entries []models.AuditEntry
+	eachRow(db.Statement.ReflectValue, func(row reflect.Value) {
+		after := auditState(row)
+		entries = append(entries, newAuditEntry(db, entity, models.AuditCreate, nil, after))
+	})
+	writeAuditEntries(db, entries)
+}
+
+// auditLoadBefore loads the rows an update or delete is about to change,
+// within its transaction, using the statement's conditions.
+func auditLoadBefore(db *gorm.DB) {
+	if _, ok := audited(db); !ok {
+		return
+	}
+	stmt := db.Statement
+	query := db.Session(&gorm.Session{NewDB: true}).Model(reflect.New(stmt.Schema.ModelType).Interface())
+	if stmt.Unscoped {
+		query = query.Unscoped()
+	}
+	conditions := 0
+	if where, ok := stmt.Clauses["WHERE"].Expression.(clause.Where); ok && len(where.Exprs) > 0 {
+		query = query.Clauses(where)
+		conditions++
+	}
+	// GORM also targets the primary key of a model passed with its ID set
+	for _, value := range []reflect.Value{stmt.ReflectValue, reflect.ValueOf(stmt.Model)} {
+		if ids := primaryKeys(stmt, value); len(ids) > 0 {
+			query = query.Where(clause.IN{Column: clause.PrimaryColumn, Values: ids})
+			conditions++
+		}
+	}
+	if conditions == 0 {
+		return // GORM refuses updates and deletes without conditions
+	}
+
+	rows := reflect.New(reflect.SliceOf(stmt.Schema.ModelType))
+	if err := query.Find(rows.Interface()).Error; err != nil {
+		db.AddError(err)
+		return
+	}
+	db.InstanceSet(auditBeforeKey, rows.Elem())
+}
+
+func auditUpdate(db *gorm.DB) {
+	entity, ok := audited(db)
+	if !ok || db.RowsAffected == 0 {
+		return
+	}
+	before, after, ok := auditReload(db)
+	if !ok {
+		return
+	}
+	deletedAt := db.Statement.Schema.LookUpField("deleted_at")
+
+	var entries []models.AuditEntry
+	for i := 0; i < before.Len(); i++ {
+		id := primaryKey(db.Statement, before.Index(i))
+		row, ok := after[id]
+		if !ok {
+			continue
+		}
+		action := models.AuditUpdate
+		if deletedAt != nil {
+			wasDeleted, isDeleted := inTrash(db, deletedAt, before.Index(i)), inTrash(db, deletedAt, row)
+			switch {
+			case !wasDeleted && isDeleted:
+				action = models.AuditDelete
+			case wasDeleted && !isDeleted:
+				action = models.AuditRestore
+			}
+		}
+		from, to := diffStates(auditState(before.Index(i)), auditState(row))
+		if len(from) == 0 && len(to) == 0 && action == models.AuditUpdate {
+			continue
+		}
+		entry := newAuditEntry(db, entity, action, from, to)
+		entry.EntityID, entry.UserID = id, stringField(row, "UserID")
+		entries = append(entries, entry)
+	}
+	writeAuditEntries(db, entries)
+}
+
+func auditDelete(db *gorm.DB) {
+	entity, ok := audited(db)
+	if !ok || db.RowsAffected == 0 {
+		return
+	}
+	value, ok := db.InstanceGet(auditBeforeKey)
+	if !ok {
+		return
+	}
+	before := value.(reflect.Value)
+
+	// A delete without Unscoped on a model with a DeletedAt moves it to the
+	// trash. Unscoped deletes are purges, whether of the trash itself or of
+	// plantings going with a purged bed; the rest are plain deletes
+	action := models.AuditDelete
+	if db.Statement.Unscoped {
+		action = models.AuditPurge
+	}
+	var entries []models.AuditEntry
+	for i := 0; i < before.Len(); i++ {
+		entries = append(entries, newAuditEntry(db, entity, action, auditState(before.Index(i)), nil))
+	}
+	writeAuditEntries(db, entries)
+}
+
+// auditReload fetches the rows auditLoadBefore loaded as they are now, by
+// primary key.
+func auditReload(db *gorm.DB) (before reflect.Value, after map[string]reflect.Value, ok bool) {
+	value, ok := db.InstanceGet(auditBeforeKey)
+	if !ok {
+		return reflect.Value{}, nil, false
+	}
+	before = value.(reflect.Value)
+	if before.Len() == 0 {
+		return before, nil, false
+	}
+	ids := primaryKeys(db.Statement, before)
+	rows := reflect.New(reflect.SliceOf(db.Statement.Schema.ModelType))
+	err := db.Session(&gorm.Session{NewDB: true}).Unscoped().
+		Where(clause.IN{Column: clause.PrimaryColumn, Values: ids}).
+		Find(rows.Interface()).Error
+	if err != nil {
+		db.AddError(err)
+		return before, nil, false
+	}
+	after = map[string]reflect.Value{}
+	for i := 0; i < rows.Elem().Len(); i++ {
+		row := rows.Elem().Index(i)
+		after[primaryKey(db.Statement, row)] = row
+	}
+	return before, after, true
+}
+
+// newAuditEntry returns an entry by the statement's actor. The entity's ID
+// and owner are taken from whichever of before and after is set.
+func newAuditEntry(db *gorm.DB, entity, action string, before, after models.AuditValues) models.AuditEntry {
+	actorType, actorID := auditActor(db.Statement.Context)
+	entry := models.AuditEntry{
+		ID:        uuid.New().String(),
+		ActorType: actorType,
+		ActorID:   actorID,
+		Entity:    entity,
+		Action:    action,
+		Before:    before,
+		After:     after,
+		CreatedAt: time.Now(),
+	}
+	for _, state := range []models.AuditValues{after, before} {
+		if id, ok := state["id"].(string); ok && entry.EntityID == "" {
+			entry.EntityID = id
+		}
+		if userID, ok := state["user_id"].(string); ok && entry.UserID == "" {
+			entry.UserID = userID
+		}
+	}
+	return entry
+}
+
+// auditActor identifies who is making the changes of a statement with ctx.
+func auditActor(ctx context.Context) (actorType, actorID string) {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return models.ActorSystem, ""
+	}
+	if principal.Provider == device.ProviderName {
+		return models.ActorDevice, principal.SessionID
+	}
+	return models.ActorUser, principal.UserID
+}
+
+func writeAuditEntries(db *gorm.DB, entries []models.AuditEntry) {
+	if len(entries) == 0 {
+		return
+	}
+	// Same connection, and so the same transaction, as the change itself
+	if err := db.Session(&gorm.Session{NewDB: true}).Create(&entries).Error; err != nil {
+		db.AddError(err)
+	}
+}
+
+// eachRow calls fn for the struct, or each struct in the slice, in value.
+func eachRow(value reflect.Value, fn func(reflect.Value)) {
+	value = reflect.Indirect(value)
+	switch value.Kind() {
+	case reflect.Struct:
+		fn(value)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			fn(reflect.Indirect(value.Index(i)))
+		}
+	}
+}
+
+// primaryKeys returns the non-zero primary keys of the rows in value.
+func primaryKeys(stmt *gorm.Statement, value reflect.Value) []interface{} {
+	var ids []interface{}
+	if !value.IsValid() || stmt.Schema.PrioritizedPrimaryField == nil {
+		return nil
+	}
+	eachRow(value, func(row reflect.Value) {
+		if row.Type() != stmt.Schema.ModelType {
+			return
+		}
+		if id, isZero := stmt.Schema.PrioritizedPrimaryField.ValueOf(stmt.Context, row); !isZero {
+			ids = append(ids, id)
+		}
+	})
+	return ids
+}
+
+func primaryKey(stmt *gorm.Statement, row reflect.Value) string {
+	id, _ := stmt.Schema.PrioritizedPrimaryField.ValueOf(stmt.Context, row)
+	s, _ := id.(string)
+	return s
+}
+
+func inTrash(db *gorm.DB, deletedAt *schema.Field, row reflect.Value) bool {
+	value, _ := deletedAt.ValueOf(db.Statement.Context, row)
+	d, ok := value.(gorm.DeletedAt)
+	return ok && d.Valid
+}
+
+func stringField(row reflect.Value, name string) string {
+	if f := row.FieldByName(name); f.IsValid() && f.Kind() == reflect.String {
+		return f.String()
+	}
+	return ""
+}
+
+// auditState returns a row's fields as the API serves them.
+func auditState(row reflect.Value) models.AuditValues {
+	data, err := json.Marshal(row.Interface())
+	if err != nil {
+		return nil
+	}
+	var state models.AuditValues
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil
+	}
+	return state
+}
+
+// diffStates returns the fields that differ between before and after, with
+// their values on each side.
+func diffStates(before, after models.AuditValues) (from, to models.AuditValues) {
+	from, to = models.AuditValues{}, models.AuditValues{}
+	for field, value := range after {
+		if !unauditedFields[field] && !reflect.DeepEqual(before[field], value) {
+			from[field], to[field] = before[field], value
+		}
+	}
+	for field, value := range before {
+		if _, ok := after[field]; !ok && !unauditedFields[field] {
+			from[field] = value
+		}
+	}
+	return from, to
+}
+
+// auditListSpec filters the audit log by entity, actor, action and time.
+var auditListSpec = listSpec[models.AuditEntry]{
+	filters: map[string]filterFunc{
+		"entity":       oneOfFilter("entity"),
+		"entity_id":    equalsFilter("entity_id"),
+		"actor":        equalsFilter("actor_id"),
+		"actor_type":   oneOfFilter("actor_type"),
+		"action":       oneOfFilter("action"),
+		"created_from": timeFromFilter("created_at"),
+		"created_to":   timeToFilter("created_at"),
+	},
+	sorts: map[string]sortField[models.AuditEntry]{
+		"created_at": {column: "created_at", value: func(e models.AuditEntry) any { return e.CreatedAt }, isTime: true},
+	},
+	defaultSort: "-created_at",
+	id:          func(e models.AuditEntry) string { return e.ID },
+}
+
+// AuditStorer reads the audit log recorded by the AuditLog plugin.
+type AuditStorer interface {
+	// ListAudit lists the entries for changes to the user's entities, newest
+	// first by default.
+	ListAudit(ctx context.Context, userID string, q ListQuery) (Page[models.AuditEntry], error)
+}
+
+// GormAuditStore implements AuditStorer using GORM.
+type GormAuditStore struct {
+	db *gorm.DB
+}
+
+// NewGormAuditStore creates a new GormAuditStore.
+func NewGormAuditStore(db *gorm.DB) *GormAuditStore {
+	return &GormAuditStore{db: db}
+}
+
+func (s *GormAuditStore) ListAudit(ctx context.Context, userID string, q ListQuery) (Page[models.AuditEntry], error) {
+	return paginate(s.db.WithContext(ctx).Where("user_id = ?", userID), auditListSpec, q)
+}
@@ -1,21 +1,25 @@
 package storage
 
 import (
+	"context"
 	"errors"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/zjpiazza/plantastic/internal/models"
 	"gorm.io/gorm"
 )
 
 // BedStorer defines the interface for bed data operations.
+// Beds inherit their owner from the parent garden and are scoped to that user.
 type BedStorer interface {
-	GetAllBeds() ([]models.Bed, error)
-	GetBedByID(bedID string) (models.Bed, error)
-	CreateBed(bed *models.Bed) error
-	UpdateBed(bed *models.Bed) error
-	DeleteBed(bedID string) error
-	GetBedsByGardenID(gardenID string) ([]models.Bed, error)
+	GetAllBeds(ctx context.Context, userID string) ([]models.Bed, error)
+	ListBeds(ctx context.Context, userID string, q ListQuery) (Page[models.Bed], error)
+	GetBedByID(ctx context.Context, userID, bedID string) (models.Bed, error)
+	CreateBed(ctx context.Context, userID string, bed *models.Bed) error
+	UpdateBed(ctx context.Context, userID string, bed *models.Bed) error
+	DeleteBed(ctx context.Context, userID, bedID string, version int) error
+	GetBedsByGardenID(ctx context.Context, userID, gardenID string, q ListQuery) (Page[models.Bed], error)
 }
 
 // GormBedStore implements BedStorer using GORM.
@@ -28,71 +32,116 @@ func NewGormBedStore(db *gorm.DB) BedStorer {
 	return &GormBedStore{db: db}
 }
 
-func (s *GormBedStore) GetAllBeds() ([]models.Bed, error) {
+func (s *GormBedStore) GetAllBeds(ctx context.Context, userID string) ([]models.Bed, error) {
 	var beds []models.Bed
-	result := s.db.Find(&beds)
+	result := s.db.WithContext(ctx).Where("user_id = ?", userID).Find(&beds)
 	if result.Error != nil {
-		return nil, ErrDatabase
+		return nil, ParseDatabaseError(result.Error)
 	}
 	return beds, nil
 }
 
-func (s *GormBedStore) GetBedByID(bedID string) (models.Bed, error) {
+// bedListSpec filters beds by garden, type and soil type.
+var bedListSpec = listSpec[models.Bed]{
+	filters: map[string]filterFunc{
+		"garden_id": equalsFilter("garden_id"),
+		"type":      equalsFilter("type"),
+		"soil_type": equalsFilter("soil_type"),
+	},
+	sorts: map[string]sortField[models.Bed]{
+		"name":       {column: "name", value: func(b models.Bed) any { return b.Name }},
+		"type":       {column: "type", value: func(b models.Bed) any { return b.Type }},
+		"soil_type":  {column: "soil_type", value: func(b models.Bed) any { return b.SoilType }},
+		"created_at": {column: "created_at", value: func(b models.Bed) any { return b.CreatedAt }, isTime: true},
+		"updated_at": {column: "updated_at", value: func(b models.Bed) any { return b.UpdatedAt }, isTime: true},
+	},
+	defaultSort: "created_at",
+	id:          func(b models.Bed) string { return b.ID },
+}
+
+func (s *GormBedStore) ListBeds(ctx context.Context, userID string, q ListQuery) (Page[models.Bed], error) {
+	return paginate(s.db.WithContext(ctx).Where("user_id = ?", userID), bedListSpec, q)
+}
+
+func (s *GormBedStore) GetBedByID(ctx context.Context, userID, bedID string) (models.Bed, error) {
 	var bed models.Bed
-	result := s.db.Where("id = ?", bedID).First(&bed)
+	result := s.db.WithContext(ctx).Where("id = ? AND user_id = ?", bedID, userID).First(&bed)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return models.Bed{}, ErrRecordNotFound
 		}
-		return models.Bed{}, ErrDatabase
+		return models.Bed{}, ParseDatabaseError(result.Error)
 	}
 	return bed, nil
 }
 
-func (s *GormBedStore) CreateBed(bed *models.Bed) error {
-	if bed.Name == "" || bed.GardenID == "" {
+// validateBed checks the fields every bed must have.
+func validateBed(bed *models.Bed) error {
+	var verr ValidationError
+	if bed.Name == "" {
+		verr.add("name", "is required")
+	}
+	if bed.GardenID == "" {
+		verr.add("garden_id", "is required")
+	}
+	return verr.err()
+}
+
+func (s *GormBedStore) CreateBed(ctx context.Context, userID string, bed *models.Bed) error {
+	db := s.db.WithContext(ctx)
+	if userID == "" {
 		return ErrValidation
 	}
+	if err := validateBed(bed); err != nil {
+		return err
+	}
 
-	// Check if referenced garden exists
+	// Check if referenced garden exists and belongs to the caller
 	var garden models.Garden
-	if err := s.db.First(&garden, "id = ?", bed.GardenID).Error; err != nil {
+	if err := db.First(&garden, "id = ? AND user_id = ?", bed.GardenID, userID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return ErrValidation // Garden not found is a validation issue for creating a bed
+			return invalidField("garden_id", "garden does not exist")
 		}
 		return ParseDatabaseError(err) // Other DB error
 	}
+	bed.UserID = userID
+	bed.Version = 1
+	bed.ID = uuid.New().String() // IDs are never taken from the caller
 
-	result := s.db.Create(bed)
+	result := db.Create(bed)
 	if result.Error != nil {
 		return ParseDatabaseError(result.Error)
 	}
 	return nil
 }
 
-func (s *GormBedStore) UpdateBed(bed *models.Bed) error {
+func (s *GormBedStore) UpdateBed(ctx context.Context, userID string, bed *models.Bed) error {
+	db := s.db.WithContext(ctx)
 	// Validate essential fields first
 	if bed.ID == "" { // ID must be present for an update
 		return ErrValidation
 	}
-	if bed.Name == "" || bed.GardenID == "" { // Name and GardenID are mandatory
-		return ErrValidation
+	if err := validateBed(bed); err != nil {
+		return err
 	}
 
 	// Check if the bed to be updated actually exists
 	var existingBed models.Bed
-	if err := s.db.First(&existingBed, "id = ?", bed.ID).Error; err != nil {
+	if err := db.First(&existingBed, "id = ? AND user_id = ?", bed.ID, userID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return ErrRecordNotFound
 		}
 		return ParseDatabaseError(err) // Other DB error during existence check
 	}
+	if bed.Version != 0 && bed.Version != existingBed.Version {
+		return ErrVersionConflict
+	}
 
 	// Check if the referenced garden exists (if GardenID is being changed or just to be sure)
 	var garden models.Garden
-	if err := s.db.First(&garden, "id = ?", bed.GardenID).Error; err != nil {
+	if err := db.First(&garden, "id = ? AND user_id = ?", bed.GardenID, userID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return ErrValidation // Referencing a non-existent garden is a validation issue
+			return invalidField("garden_id", "garden does not exist")
 		}
 		return ParseDatabaseError(err) // Other DB error during garden check
 	}
@@ -106,42 +155,43 @@ func (s *GormBedStore) UpdateBed(bed *models.Bed) error {
 		"size":       bed.Size,
 		"soil_type":  bed.SoilType,
 		"notes":      bed.Notes,
+		"version":    gorm.Expr("version + 1"),
 		"updated_at": time.Now(),
 	}
 
-	result := s.db.Model(&models.Bed{}).Where("id = ?", bed.ID).Updates(updateFields)
+	query := db.Model(&models.Bed{}).Where("id = ? AND user_id = ?", bed.ID, userID)
+	if bed.Version != 0 {
+		query = query.Where("version = ?", bed.Version)
+	}
+	result := query.Updates(updateFields)
 	if result.Error != nil {
 		return ParseDatabaseError(result.Error)
 	}
 	// GORM's Updates method with a map will not return ErrRecordNotFound if the record doesn't exist.
 	// The check for result.RowsAffected == 0 is crucial here.
 	if result.RowsAffected == 0 {
+		if bed.Version != 0 {
+			return ErrVersionConflict
+		}
 		return ErrRecordNotFound // Should have been caught by the .First call, but as a safeguard.
 	}
+	bed.Version = existingBed.Version + 1
 	return nil
 }
 
-func (s *GormBedStore) DeleteBed(bedID string) error {
-	result := s.db.Where("id = ?", bedID).Delete(&models.Bed{})
-	if result.Error != nil {
-		return ParseDatabaseError(result.Error)
-	}
-	if result.RowsAffected == 0 {
-		return ErrRecordNotFound
-	}
-	return nil
+// DeleteBed moves a bed to the trash, conditionally on its version if
+// version is non-zero.
+func (s *GormBedStore) DeleteBed(ctx context.Context, userID, bedID string, version int) error {
+	return deleteVersioned(s.db.WithContext(ctx), &models.Bed{}, userID, bedID, version)
 }
 
-func (s *GormBedStore) GetBedsByGardenID(gardenID string) ([]models.Bed, error) {
-	var beds []models.Bed
-	result := s.db.Where("garden_id = ?", gardenID).Find(&beds)
-	if result.Error != nil {
-		// Do not treat gorm.ErrRecordNotFound as an error here,
-		// an empty slice is a valid result if no beds are found for that garden.
-		// GORM .Find() does not return gorm.ErrRecordNotFound if no records are found, it just returns an empty slice.
-		// So, a specific check for gorm.ErrRecordNotFound is usually not needed for .Find().
-		// Any error here would be an actual database issue.
-		return nil, ErrDatabase // For other database errors
+// GetBedsByGardenID lists one page of the beds in a garden. It returns
+// ErrRecordNotFound if the garden does not exist or belongs to someone else,
+// so an empty page always means an empty garden.
+func (s *GormBedStore) GetBedsByGardenID(ctx context.Context, userID, gardenID string, q ListQuery) (Page[models.Bed], error) {
+	var garden models.Garden
+	if err := s.db.WithContext(ctx).First(&garden, "id = ? AND user_id = ?", gardenID, userID).Error; err != nil {
+		return Page[models.Bed]{}, ParseDatabaseError(err)
 	}
-	return beds, nil
+	return paginate(s.db.WithContext(ctx).Where("garden_id = ? AND user_id = ?", gardenID, userID), bedListSpec, q)
 }
@@ -1,6 +1,7 @@
 package storage_test
 
 import (
+	"context"
 	// "database/sql/driver" // Uncomment if AnyTime helper is used
 
 	"errors"
@@ -40,10 +41,10 @@ func TestGormBedStore_GetAllBeds_Success(t *testing.T) {
 		AddRow(expectedBeds[0].ID, expectedBeds[0].GardenID, expectedBeds[0].Name, expectedBeds[0].Type, expectedBeds[0].Size, expectedBeds[0].SoilType, expectedBeds[0].Notes, expectedBeds[0].CreatedAt, expectedBeds[0].UpdatedAt).
 		AddRow(expectedBeds[1].ID, expectedBeds[1].GardenID, expectedBeds[1].Name, expectedBeds[1].Type, expectedBeds[1].Size, expectedBeds[1].SoilType, expectedBeds[1].Notes, expectedBeds[1].CreatedAt, expectedBeds[1].UpdatedAt)
 
-	sql := `SELECT * FROM "beds"`
-	mock.ExpectQuery(regexp.QuoteMeta(sql)).WillReturnRows(rows)
+	sql := `SELECT * FROM "beds" WHERE user_id = $1`
+	mock.ExpectQuery(regexp.QuoteMeta(sql)).WithArgs(testUserID).WillReturnRows(rows)
 
-	actualBeds, err := store.GetAllBeds()
+	actualBeds, err := store.GetAllBeds(context.Background(), testUserID)
 	assert.NoError(t, err)
 	assert.ElementsMatch(t, expectedBeds, actualBeds)
 }
@@ -63,10 +64,10 @@ func TestGormBedStore_GetBedByID_Success(t *testing.T) {
 	rows := sqlmock.NewRows([]string{"id", "garden_id", "name", "type", "size", "soil_type", "notes", "created_at", "updated_at"}).
 		AddRow(expectedBed.ID, expectedBed.GardenID, expectedBed.Name, expectedBed.Type, expectedBed.Size, expectedBed.SoilType, expectedBed.Notes, expectedBed.CreatedAt, expectedBed.UpdatedAt)
 
-	sql := `SELECT * FROM "beds" WHERE id = $1 ORDER BY "beds"."id" LIMIT $2`
-	mock.ExpectQuery(regexp.QuoteMeta(sql)).WithArgs(bedID, 1).WillReturnRows(rows)
+	sql := `SELECT * FROM "beds" WHERE (id = $1 AND user_id = $2) AND "beds"."deleted_at" IS NULL ORDER BY "beds"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sql)).WithArgs(bedID, testUserID, 1).WillReturnRows(rows)
 
-	actualBed, err := store.GetBedByID(bedID)
+	actualBed, err := store.GetBedByID(context.Background(), testUserID, bedID)
 	assert.NoError(t, err)
 	assert.Equal(t, expectedBed, actualBed)
 }
@@ -83,18 +84,18 @@ func TestGormBedStore_CreateBed_Success(t *testing.T) {
 
 	// 1. Mock the Garden lookup
 	gardenRows := sqlmock.NewRows([]string{"id"}).AddRow("g1")
-	sqlGardenSelect := `SELECT * FROM "gardens" WHERE id = $1 ORDER BY "gardens"."id" LIMIT $2`
-	mock.ExpectQuery(regexp.QuoteMeta(sqlGardenSelect)).WithArgs(bedToCreate.GardenID, 1).WillReturnRows(gardenRows)
+	sqlGardenSelect := `SELECT * FROM "gardens" WHERE (id = $1 AND user_id = $2) AND "gardens"."deleted_at" IS NULL ORDER BY "gardens"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlGardenSelect)).WithArgs(bedToCreate.GardenID, testUserID, 1).WillReturnRows(gardenRows)
 
 	// 2. Mock the Bed INSERT
 	mock.ExpectBegin()
-	sqlBedInsert := `INSERT INTO "beds" ("id","garden_id","name","type","size","soil_type","notes","created_at","updated_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)`
+	sqlBedInsert := `INSERT INTO "beds" ("id","user_id","garden_id","name","type","size","soil_type","notes","version","created_at","updated_at","deleted_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)`
 	mock.ExpectExec(regexp.QuoteMeta(sqlBedInsert)).
-		WithArgs(bedToCreate.ID, bedToCreate.GardenID, bedToCreate.Name, bedToCreate.Type, bedToCreate.Size, bedToCreate.SoilType, bedToCreate.Notes, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WithArgs(sqlmock.AnyArg(), testUserID, bedToCreate.GardenID, bedToCreate.Name, bedToCreate.Type, bedToCreate.Size, bedToCreate.SoilType, bedToCreate.Notes, 1, sqlmock.AnyArg(), sqlmock.AnyArg(), nil).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
-	err := store.CreateBed(bedToCreate)
+	err := store.CreateBed(context.Background(), testUserID, bedToCreate)
 	assert.NoError(t, err)
 }
 
@@ -108,11 +109,12 @@ func TestGormBedStore_CreateBed_GardenNotFound(t *testing.T) {
 
 	bedToCreate := &models.Bed{GardenID: "nonexistent_g1", Name: "Orphan Bed"}
 
-	sqlGardenSelect := `SELECT * FROM "gardens" WHERE id = $1 ORDER BY "gardens"."id" LIMIT $2`
-	mock.ExpectQuery(regexp.QuoteMeta(sqlGardenSelect)).WithArgs(bedToCreate.GardenID, 1).WillReturnError(gorm.ErrRecordNotFound)
+	sqlGardenSelect := `SELECT * FROM "gardens" WHERE (id = $1 AND user_id = $2) AND "gardens"."deleted_at" IS NULL ORDER BY "gardens"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlGardenSelect)).WithArgs(bedToCreate.GardenID, testUserID, 1).WillReturnError(gorm.ErrRecordNotFound)
 
-	err := store.CreateBed(bedToCreate)
+	err := store.CreateBed(context.Background(), testUserID, bedToCreate)
 	assert.ErrorIs(t, err, storage.ErrValidation)
+	assert.Equal(t, []storage.FieldError{{Field: "garden_id", Message: "garden does not exist"}}, storage.FieldErrors(err))
 }
 
 func TestGormBedStore_GetBedsByGardenID_Success(t *testing.T) {
@@ -134,12 +136,17 @@ func TestGormBedStore_GetBedsByGardenID_Success(t *testing.T) {
 		AddRow(expectedBeds[0].ID, expectedBeds[0].GardenID, expectedBeds[0].Name, expectedBeds[0].Type, expectedBeds[0].Size, expectedBeds[0].SoilType, expectedBeds[0].Notes, expectedBeds[0].CreatedAt, expectedBeds[0].UpdatedAt).
 		AddRow(expectedBeds[1].ID, expectedBeds[1].GardenID, expectedBeds[1].Name, expectedBeds[1].Type, expectedBeds[1].Size, expectedBeds[1].SoilType, expectedBeds[1].Notes, expectedBeds[1].CreatedAt, expectedBeds[1].UpdatedAt)
 
-	sql := `SELECT * FROM "beds" WHERE garden_id = $1`
-	mock.ExpectQuery(regexp.QuoteMeta(sql)).WithArgs(gardenID).WillReturnRows(rows)
+	sqlGardenSelect := `SELECT * FROM "gardens" WHERE (id = $1 AND user_id = $2) AND "gardens"."deleted_at" IS NULL ORDER BY "gardens"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlGardenSelect)).WithArgs(gardenID, testUserID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(gardenID, testUserID))
 
-	actualBeds, err := store.GetBedsByGardenID(gardenID)
+	sql := `SELECT * FROM "beds" WHERE (garden_id = $1 AND user_id = $2) AND "beds"."deleted_at" IS NULL ORDER BY created_at ASC,id ASC LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sql)).WithArgs(gardenID, testUserID, storage.DefaultPageSize+1).WillReturnRows(rows)
+
+	page, err := store.GetBedsByGardenID(context.Background(), testUserID, gardenID, storage.ListQuery{})
 	assert.NoError(t, err)
-	assert.ElementsMatch(t, expectedBeds, actualBeds)
+	assert.ElementsMatch(t, expectedBeds, page.Items)
+	assert.Empty(t, page.NextCursor)
 }
 
 func TestGormBedStore_GetBedsByGardenID_NoneFound(t *testing.T) {
@@ -153,12 +160,16 @@ func TestGormBedStore_GetBedsByGardenID_NoneFound(t *testing.T) {
 	gardenID := "g2_empty"
 
 	rows := sqlmock.NewRows([]string{"id", "garden_id", "name", "type", "size", "soil_type", "notes", "created_at", "updated_at"})
-	sql := `SELECT * FROM "beds" WHERE garden_id = $1`
-	mock.ExpectQuery(regexp.QuoteMeta(sql)).WithArgs(gardenID).WillReturnRows(rows)
+	sqlGardenSelect := `SELECT * FROM "gardens" WHERE (id = $1 AND user_id = $2) AND "gardens"."deleted_at" IS NULL ORDER BY "gardens"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlGardenSelect)).WithArgs(gardenID, testUserID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(gardenID, testUserID))
 
-	actualBeds, err := store.GetBedsByGardenID(gardenID)
+	sql := `SELECT * FROM "beds" WHERE (garden_id = $1 AND user_id = $2) AND "beds"."deleted_at" IS NULL ORDER BY created_at ASC,id ASC LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sql)).WithArgs(gardenID, testUserID, storage.DefaultPageSize+1).WillReturnRows(rows)
+
+	page, err := store.GetBedsByGardenID(context.Background(), testUserID, gardenID, storage.ListQuery{})
 	assert.NoError(t, err)
-	assert.Empty(t, actualBeds)
+	assert.Empty(t, page.Items)
 }
 
 func TestGormBedStore_GetBedsByGardenID_DBError(t *testing.T) {
@@ -172,13 +183,33 @@ func TestGormBedStore_GetBedsByGardenID_DBError(t *testing.T) {
 	gardenID := "g_error"
 	dbErr := errors.New("fetch beds by garden failed")
 
-	sql := `SELECT * FROM "beds" WHERE garden_id = $1`
-	mock.ExpectQuery(regexp.QuoteMeta(sql)).WithArgs(gardenID).WillReturnError(dbErr)
+	sqlGardenSelect := `SELECT * FROM "gardens" WHERE (id = $1 AND user_id = $2) AND "gardens"."deleted_at" IS NULL ORDER BY "gardens"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlGardenSelect)).WithArgs(gardenID, testUserID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(gardenID, testUserID))
+
+	sql := `SELECT * FROM "beds" WHERE (garden_id = $1 AND user_id = $2) AND "beds"."deleted_at" IS NULL ORDER BY created_at ASC,id ASC LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sql)).WithArgs(gardenID, testUserID, storage.DefaultPageSize+1).WillReturnError(dbErr)
 
-	_, err = store.GetBedsByGardenID(gardenID)
+	_, err = store.GetBedsByGardenID(context.Background(), testUserID, gardenID, storage.ListQuery{})
 	assert.ErrorIs(t, err, storage.ErrDatabase)
 }
 
+func TestGormBedStore_GetBedsByGardenID_GardenNotFound(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormBedStore(db)
+	rawSqlDB, err := db.DB()
+	require.NoError(t, err)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	gardenID := "g_missing"
+	sqlGardenSelect := `SELECT * FROM "gardens" WHERE (id = $1 AND user_id = $2) AND "gardens"."deleted_at" IS NULL ORDER BY "gardens"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlGardenSelect)).WithArgs(gardenID, testUserID, 1).WillReturnError(gorm.ErrRecordNotFound)
+
+	_, err = store.GetBedsByGardenID(context.Background(), testUserID, gardenID, storage.ListQuery{})
+	assert.ErrorIs(t, err, storage.ErrRecordNotFound)
+}
+
 func TestGormBedStore_UpdateBed_Success(t *testing.T) {
 	db, mock := newMockDBForStorageTest(t)
 	store := storage.NewGormBedStore(db)
@@ -192,23 +223,23 @@ func TestGormBedStore_UpdateBed_Success(t *testing.T) {
 
 	// 1. Mock the First() call to check if record exists
 	existingRow := sqlmock.NewRows([]string{"id", "garden_id", "name"}).AddRow(bedToUpdate.ID, "g_original_for_select", "Old Rose Bed")
-	sqlSelectOne := `SELECT * FROM "beds" WHERE id = $1 ORDER BY "beds"."id" LIMIT $2`
-	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectOne)).WithArgs(bedToUpdate.ID, 1).WillReturnRows(existingRow)
+	sqlSelectOne := `SELECT * FROM "beds" WHERE (id = $1 AND user_id = $2) AND "beds"."deleted_at" IS NULL ORDER BY "beds"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectOne)).WithArgs(bedToUpdate.ID, testUserID, 1).WillReturnRows(existingRow)
 
 	// 2. Mock Garden validation lookup (for the new/updated GardenID)
 	gardenRows := sqlmock.NewRows([]string{"id"}).AddRow(bedToUpdate.GardenID)
-	sqlGardenSelect := `SELECT * FROM "gardens" WHERE id = $1 ORDER BY "gardens"."id" LIMIT $2`
-	mock.ExpectQuery(regexp.QuoteMeta(sqlGardenSelect)).WithArgs(bedToUpdate.GardenID, 1).WillReturnRows(gardenRows)
+	sqlGardenSelect := `SELECT * FROM "gardens" WHERE (id = $1 AND user_id = $2) AND "gardens"."deleted_at" IS NULL ORDER BY "gardens"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlGardenSelect)).WithArgs(bedToUpdate.GardenID, testUserID, 1).WillReturnRows(gardenRows)
 
 	// 3. Mock the UPDATE statement
 	mock.ExpectBegin()
-	sqlUpdate := `UPDATE "beds" SET "garden_id"=$1,"name"=$2,"notes"=$3,"size"=$4,"soil_type"=$5,"type"=$6,"updated_at"=$7 WHERE id = $8`
+	sqlUpdate := `UPDATE "beds" SET "garden_id"=$1,"name"=$2,"notes"=$3,"size"=$4,"soil_type"=$5,"type"=$6,"updated_at"=$7,"version"=version + 1 WHERE (id = $8 AND user_id = $9) AND "beds"."deleted_at" IS NULL`
 	mock.ExpectExec(regexp.QuoteMeta(sqlUpdate)).
-		WithArgs(bedToUpdate.GardenID, bedToUpdate.Name, bedToUpdate.Notes, bedToUpdate.Size, bedToUpdate.SoilType, bedToUpdate.Type, sqlmock.AnyArg(), bedToUpdate.ID).
+		WithArgs(bedToUpdate.GardenID, bedToUpdate.Name, bedToUpdate.Notes, bedToUpdate.Size, bedToUpdate.SoilType, bedToUpdate.Type, sqlmock.AnyArg(), bedToUpdate.ID, testUserID).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 	mock.ExpectCommit()
 
-	err := store.UpdateBed(bedToUpdate)
+	err := store.UpdateBed(context.Background(), testUserID, bedToUpdate)
 	assert.NoError(t, err)
 }
 
@@ -225,7 +256,7 @@ func TestGormBedStore_UpdateBed_NotFound(t *testing.T) {
 
 	// No DB calls expected due to early validation failure (bed.GardenID == "")
 
-	err := store.UpdateBed(bedToUpdate)
+	err := store.UpdateBed(context.Background(), testUserID, bedToUpdate)
 	assert.ErrorIs(t, err, storage.ErrValidation) // Expect ErrValidation due to empty GardenID
 }
 
@@ -241,12 +272,12 @@ func TestGormBedStore_UpdateBed_ActualRecordNotFound(t *testing.T) {
 	bedToUpdate := &models.Bed{ID: "b_truly_nonexistent", Name: "Valid Name", GardenID: "g1"} // Valid name and GardenID
 
 	// Mock Query 1 (Bed Lookup) to return gorm.ErrRecordNotFound
-	sqlSelectBed := `SELECT * FROM "beds" WHERE id = $1 ORDER BY "beds"."id" LIMIT $2`
-	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectBed)).WithArgs(bedToUpdate.ID, 1).WillReturnError(gorm.ErrRecordNotFound)
+	sqlSelectBed := `SELECT * FROM "beds" WHERE (id = $1 AND user_id = $2) AND "beds"."deleted_at" IS NULL ORDER BY "beds"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectBed)).WithArgs(bedToUpdate.ID, testUserID, 1).WillReturnError(gorm.ErrRecordNotFound)
 
 	// No further mocks needed as it should return ErrRecordNotFound from bed lookup
 
-	err := store.UpdateBed(bedToUpdate)
+	err := store.UpdateBed(context.Background(), testUserID, bedToUpdate)
 	assert.ErrorIs(t, err, storage.ErrRecordNotFound)
 }
 
@@ -261,7 +292,7 @@ func TestGormBedStore_UpdateBed_ValidationError_NameEmpty(t *testing.T) {
 
 	// No DB interaction expected, as validation (bed.Name == "") fails first.
 
-	err := store.UpdateBed(bedToUpdate)
+	err := store.UpdateBed(context.Background(), testUserID, bedToUpdate)
 	assert.ErrorIs(t, err, storage.ErrValidation)
 	// No mock.ExpectationsWereMet() needed as no mocks are set
 }
@@ -278,14 +309,14 @@ func TestGormBedStore_UpdateBed_GardenNotFound(t *testing.T) {
 
 	// 1. Mock Bed Select (succeeds)
 	existingBedRow := sqlmock.NewRows([]string{"id", "garden_id"}).AddRow(bedToUpdate.ID, "g_original")
-	sqlBedSelect := `SELECT * FROM "beds" WHERE id = $1 ORDER BY "beds"."id" LIMIT $2`
-	mock.ExpectQuery(regexp.QuoteMeta(sqlBedSelect)).WithArgs(bedToUpdate.ID, 1).WillReturnRows(existingBedRow)
+	sqlBedSelect := `SELECT * FROM "beds" WHERE (id = $1 AND user_id = $2) AND "beds"."deleted_at" IS NULL ORDER BY "beds"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlBedSelect)).WithArgs(bedToUpdate.ID, testUserID, 1).WillReturnRows(existingBedRow)
 
 	// 2. Mock Garden Select for validation (fails)
-	sqlGardenSelect := `SELECT * FROM "gardens" WHERE id = $1 ORDER BY "gardens"."id" LIMIT $2`
-	mock.ExpectQuery(regexp.QuoteMeta(sqlGardenSelect)).WithArgs(bedToUpdate.GardenID, 1).WillReturnError(gorm.ErrRecordNotFound)
+	sqlGardenSelect := `SELECT * FROM "gardens" WHERE (id = $1 AND user_id = $2) AND "gardens"."deleted_at" IS NULL ORDER BY "gardens"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlGardenSelect)).WithArgs(bedToUpdate.GardenID, testUserID, 1).WillReturnError(gorm.ErrRecordNotFound)
 
-	err := store.UpdateBed(bedToUpdate)
+	err := store.UpdateBed(context.Background(), testUserID, bedToUpdate)
 	assert.ErrorIs(t, err, storage.ErrValidation)
 }
 
@@ -303,7 +334,7 @@ func TestGormBedStore_UpdateBed_DBErrorOnSelect(t *testing.T) { // This test tit
 
 	// No DB calls expected due to early validation failure (bed.GardenID == "")
 
-	err := store.UpdateBed(bedToUpdate)
+	err := store.UpdateBed(context.Background(), testUserID, bedToUpdate)
 	assert.ErrorIs(t, err, storage.ErrValidation) // Expect ErrValidation due to empty GardenID
 }
 
@@ -320,12 +351,12 @@ func TestGormBedStore_UpdateBed_DBErrorOnActualBedSelect(t *testing.T) {
 	dbSelectErr := errors.New("db error on bed select")
 
 	// Mock Query 1 (Bed Lookup) to return a generic DB error
-	sqlSelectBed := `SELECT * FROM "beds" WHERE id = $1 ORDER BY "beds"."id" LIMIT $2`
-	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectBed)).WithArgs(bedToUpdate.ID, 1).WillReturnError(dbSelectErr)
+	sqlSelectBed := `SELECT * FROM "beds" WHERE (id = $1 AND user_id = $2) AND "beds"."deleted_at" IS NULL ORDER BY "beds"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectBed)).WithArgs(bedToUpdate.ID, testUserID, 1).WillReturnError(dbSelectErr)
 
 	// No further mocks needed
 
-	err := store.UpdateBed(bedToUpdate)
+	err := store.UpdateBed(context.Background(), testUserID, bedToUpdate)
 	assert.ErrorIs(t, err, storage.ErrDatabase) // Expect custom ErrDatabase after parsing
 }
 
@@ -342,23 +373,23 @@ func TestGormBedStore_UpdateBed_DBErrorOnUpdate(t *testing.T) {
 
 	// 1. Mock Bed Select (succeeds)
 	existingBedRow := sqlmock.NewRows([]string{"id", "garden_id"}).AddRow(bedToUpdate.ID, "g_original")
-	sqlBedSelect := `SELECT * FROM "beds" WHERE id = $1 ORDER BY "beds"."id" LIMIT $2`
-	mock.ExpectQuery(regexp.QuoteMeta(sqlBedSelect)).WithArgs(bedToUpdate.ID, 1).WillReturnRows(existingBedRow)
+	sqlBedSelect := `SELECT * FROM "beds" WHERE (id = $1 AND user_id = $2) AND "beds"."deleted_at" IS NULL ORDER BY "beds"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlBedSelect)).WithArgs(bedToUpdate.ID, testUserID, 1).WillReturnRows(existingBedRow)
 
 	// 2. Mock Garden validation lookup (succeeds)
 	gardenRows := sqlmock.NewRows([]string{"id"}).AddRow(bedToUpdate.GardenID)
-	sqlGardenSelect := `SELECT * FROM "gardens" WHERE id = $1 ORDER BY "gardens"."id" LIMIT $2`
-	mock.ExpectQuery(regexp.QuoteMeta(sqlGardenSelect)).WithArgs(bedToUpdate.GardenID, 1).WillReturnRows(gardenRows)
+	sqlGardenSelect := `SELECT * FROM "gardens" WHERE (id = $1 AND user_id = $2) AND "gardens"."deleted_at" IS NULL ORDER BY "gardens"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlGardenSelect)).WithArgs(bedToUpdate.GardenID, testUserID, 1).WillReturnRows(gardenRows)
 
 	// 3. Mock Update (fails)
 	mock.ExpectBegin()
-	sqlUpdate := `UPDATE "beds" SET "garden_id"=$1,"name"=$2,"notes"=$3,"size"=$4,"soil_type"=$5,"type"=$6,"updated_at"=$7 WHERE id = $8`
+	sqlUpdate := `UPDATE "beds" SET "garden_id"=$1,"name"=$2,"notes"=$3,"size"=$4,"soil_type"=$5,"type"=$6,"updated_at"=$7,"version"=version + 1 WHERE (id = $8 AND user_id = $9) AND "beds"."deleted_at" IS NULL`
 	mock.ExpectExec(regexp.QuoteMeta(sqlUpdate)).
-		WithArgs(bedToUpdate.GardenID, bedToUpdate.Name, bedToUpdate.Notes, bedToUpdate.Size, bedToUpdate.SoilType, bedToUpdate.Type, sqlmock.AnyArg(), bedToUpdate.ID).
+		WithArgs(bedToUpdate.GardenID, bedToUpdate.Name, bedToUpdate.Notes, bedToUpdate.Size, bedToUpdate.SoilType, bedToUpdate.Type, sqlmock.AnyArg(), bedToUpdate.ID, testUserID).
 		WillReturnError(dbUpdateErr)
 	mock.ExpectRollback()
 
-	err := store.UpdateBed(bedToUpdate)
+	err := store.UpdateBed(context.Background(), testUserID, bedToUpdate)
 	assert.ErrorIs(t, err, storage.ErrDatabase)
 }
 
@@ -373,11 +404,11 @@ func TestGormBedStore_DeleteBed_Success(t *testing.T) {
 	bedIDToDelete := "b1_delete"
 
 	mock.ExpectBegin()
-	sqlDelete := `DELETE FROM "beds" WHERE id = $1`
-	mock.ExpectExec(regexp.QuoteMeta(sqlDelete)).WithArgs(bedIDToDelete).WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlDelete := `UPDATE "beds" SET "deleted_at"=$1 WHERE (id = $2 AND user_id = $3) AND "beds"."deleted_at" IS NULL`
+	mock.ExpectExec(regexp.QuoteMeta(sqlDelete)).WithArgs(sqlmock.AnyArg(), bedIDToDelete, testUserID).WillReturnResult(sqlmock.NewResult(0, 1))
 	mock.ExpectCommit()
 
-	err := store.DeleteBed(bedIDToDelete)
+	err := store.DeleteBed(context.Background(), testUserID, bedIDToDelete, 0)
 	assert.NoError(t, err)
 }
 
@@ -392,11 +423,11 @@ func TestGormBedStore_DeleteBed_NotFound(t *testing.T) {
 	bedIDToDelete := "b_nonexistent_delete"
 
 	mock.ExpectBegin()
-	sqlDelete := `DELETE FROM "beds" WHERE id = $1`
-	mock.ExpectExec(regexp.QuoteMeta(sqlDelete)).WithArgs(bedIDToDelete).WillReturnResult(sqlmock.NewResult(0, 0))
+	sqlDelete := `UPDATE "beds" SET "deleted_at"=$1 WHERE (id = $2 AND user_id = $3) AND "beds"."deleted_at" IS NULL`
+	mock.ExpectExec(regexp.QuoteMeta(sqlDelete)).WithArgs(sqlmock.AnyArg(), bedIDToDelete, testUserID).WillReturnResult(sqlmock.NewResult(0, 0))
 	mock.ExpectCommit()
 
-	err := store.DeleteBed(bedIDToDelete)
+	err := store.DeleteBed(context.Background(), testUserID, bedIDToDelete, 0)
 	assert.ErrorIs(t, err, storage.ErrRecordNotFound)
 }
 
@@ -412,11 +443,11 @@ func TestGormBedStore_DeleteBed_DBError(t *testing.T) {
 	dbErr := errors.New("db error on delete bed")
 
 	mock.ExpectBegin()
-	sqlDelete := `DELETE FROM "beds" WHERE id = $1`
-	mock.ExpectExec(regexp.QuoteMeta(sqlDelete)).WithArgs(bedIDToDelete).WillReturnError(dbErr)
+	sqlDelete := `UPDATE "beds" SET "deleted_at"=$1 WHERE (id = $2 AND user_id = $3) AND "beds"."deleted_at" IS NULL`
+	mock.ExpectExec(regexp.QuoteMeta(sqlDelete)).WithArgs(sqlmock.AnyArg(), bedIDToDelete, testUserID).WillReturnError(dbErr)
 	mock.ExpectRollback()
 
-	err := store.DeleteBed(bedIDToDelete)
+	err := store.DeleteBed(context.Background(), testUserID, bedIDToDelete, 0)
 	assert.ErrorIs(t, err, storage.ErrDatabase)
 }
 
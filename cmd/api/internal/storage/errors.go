@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"context"
 	"errors"
 	"strings"
 
@@ -15,14 +16,90 @@ var (
 	ErrTimeout           = errors.New("timeout error")
 	ErrTransactionFailed = errors.New("transaction failed")
 	ErrInvalidQuery      = errors.New("invalid query parameter")
+	ErrVersionConflict   = errors.New("version conflict") // The record changed since the caller read it
+	ErrBulkAborted       = errors.New("not applied: another operation in the batch failed")
+	ErrCanceled          = errors.New("canceled") // The caller gave up on the query, e.g. the client disconnected
 )
 
+// FieldError describes a problem with one input field. Field is the name the
+// field has in the API's JSON, e.g. "garden_id".
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError is an ErrValidation that names the fields at fault.
+// errors.Is(err, ErrValidation) is true for it.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		parts[i] = f.Field + ": " + f.Message
+	}
+	return ErrValidation.Error() + ": " + strings.Join(parts, "; ")
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// add records a problem with field.
+func (e *ValidationError) add(field, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: message})
+}
+
+// err returns e, or nil if no problems were recorded.
+func (e *ValidationError) err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// invalidField returns a ValidationError for a single field.
+func invalidField(field, message string) error {
+	return &ValidationError{Fields: []FieldError{{Field: field, Message: message}}}
+}
+
+// FieldErrors returns the per-field detail of a validation error, or nil if
+// err carries none.
+func FieldErrors(err error) []FieldError {
+	var v *ValidationError
+	if errors.As(err, &v) {
+		return v.Fields
+	}
+	return nil
+}
+
 // ParseDatabaseError translates GORM and database driver errors into custom storage errors.
 func ParseDatabaseError(err error) error {
 	if err == nil {
 		return nil
 	}
 
+	// Returned from inside transactions as-is
+	if errors.Is(err, ErrVersionConflict) {
+		return ErrVersionConflict
+	}
+	if errors.Is(err, ErrRecordNotFound) || errors.Is(err, ErrGardenInTrash) ||
+		errors.Is(err, ErrTimeout) || errors.Is(err, ErrCanceled) {
+		return err
+	}
+	if errors.Is(err, ErrValidation) {
+		return err // Keep any field detail
+	}
+
+	// The query's context ended: its deadline passed, or it was canceled
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrTimeout
+	}
+	if errors.Is(err, context.Canceled) {
+		return ErrCanceled
+	}
+
 	// Check for GORM specific errors
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return ErrRecordNotFound
@@ -36,6 +113,11 @@ func ParseDatabaseError(err error) error {
 	if strings.Contains(errStr, "unique constraint failed") || strings.Contains(errStr, "duplicate key") {
 		return ErrConflict
 	}
+	// A write referencing a row that doesn't exist, or deleting one still referenced
+	// ("FOREIGN KEY constraint failed" on SQLite, "violates foreign key constraint" on Postgres)
+	if strings.Contains(errStr, "foreign key constraint") {
+		return ErrConflict
+	}
 
 	// Default to a generic database error
 	return ErrDatabase
@@ -3,23 +3,27 @@ package storage
 import (
 	"context"
 	"errors"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/zjpiazza/plantastic/internal/models"
 	"gorm.io/gorm"
 )
 
 // GardenStorer defines the interface for garden data operations.
+// Every method is scoped to the owning user; gardens belonging to other users
+// behave as if they do not exist.
 type GardenStorer interface {
-	GetAllGardens() ([]models.Garden, error)
-	CreateGarden(garden *models.Garden) error
-	GetGardenByID(gardenID string) (models.Garden, error)
-	UpdateGarden(garden *models.Garden) error
-	DeleteGarden(gardenID string) error
-	CreateGardenWithTransaction(garden *models.Garden, beds []models.Bed) error
-	GetAllGardensWithTimeout(timeout time.Duration) ([]models.Garden, error)
-	GetGardensByQuery(params map[string]string) ([]models.Garden, error)
+	GetAllGardens(ctx context.Context, userID string) ([]models.Garden, error)
+	ListGardens(ctx context.Context, userID string, q ListQuery) (Page[models.Garden], error)
+	CreateGarden(ctx context.Context, userID string, garden *models.Garden) error
+	GetGardenByID(ctx context.Context, userID, gardenID string) (models.Garden, error)
+	UpdateGarden(ctx context.Context, userID string, garden *models.Garden) error
+	DeleteGarden(ctx context.Context, userID, gardenID string, version int) error
+	CreateGardenWithTransaction(ctx context.Context, userID string, garden *models.Garden, beds []models.Bed) error
+	GetGardensByQuery(ctx context.Context, userID string, params map[string]string) ([]models.Garden, error)
 }
 
 // GormGardenStore implements GardenStorer using GORM.
@@ -33,44 +37,72 @@ func NewGormGardenStore(db *gorm.DB) GardenStorer {
 	return &GormGardenStore{db: db}
 }
 
-func (s *GormGardenStore) GetAllGardens() ([]models.Garden, error) {
+func (s *GormGardenStore) GetAllGardens(ctx context.Context, userID string) ([]models.Garden, error) {
 	var gardens []models.Garden
-	result := s.db.Find(&gardens)
+	result := s.db.WithContext(ctx).Where("user_id = ?", userID).Find(&gardens)
 	if result.Error != nil {
-		return nil, ErrDatabase
+		return nil, ParseDatabaseError(result.Error)
 	}
 	return gardens, nil
 }
 
-func (s *GormGardenStore) CreateGarden(garden *models.Garden) error {
-	if garden.Name == "" {
+// gardenListSpec filters gardens by name (substring) and creation time.
+var gardenListSpec = listSpec[models.Garden]{
+	filters: map[string]filterFunc{
+		"name":         containsFilter("name"),
+		"created_from": timeFromFilter("created_at"),
+		"created_to":   timeToFilter("created_at"),
+	},
+	sorts: map[string]sortField[models.Garden]{
+		"name":       {column: "name", value: func(g models.Garden) any { return g.Name }},
+		"created_at": {column: "created_at", value: func(g models.Garden) any { return g.CreatedAt }, isTime: true},
+		"updated_at": {column: "updated_at", value: func(g models.Garden) any { return g.UpdatedAt }, isTime: true},
+	},
+	defaultSort: "created_at",
+	id:          func(g models.Garden) string { return g.ID },
+}
+
+func (s *GormGardenStore) ListGardens(ctx context.Context, userID string, q ListQuery) (Page[models.Garden], error) {
+	return paginate(s.db.WithContext(ctx).Where("user_id = ?", userID), gardenListSpec, q)
+}
+
+func (s *GormGardenStore) CreateGarden(ctx context.Context, userID string, garden *models.Garden) error {
+	if userID == "" {
 		return ErrValidation
 	}
-	result := s.db.Create(garden)
+	if garden.Name == "" {
+		return invalidField("name", "is required")
+	}
+	// The owner always comes from the authenticated caller, never from the request body.
+	garden.UserID = userID
+	garden.Version = 1
+	garden.ID = uuid.New().String() // IDs are never taken from the caller
+	result := s.db.WithContext(ctx).Create(garden)
 	if result.Error != nil {
 		return ParseDatabaseError(result.Error)
 	}
 	return nil
 }
 
-func (s *GormGardenStore) GetGardenByID(gardenID string) (models.Garden, error) {
+func (s *GormGardenStore) GetGardenByID(ctx context.Context, userID, gardenID string) (models.Garden, error) {
 	var garden models.Garden
-	result := s.db.Where("id = ?", gardenID).First(&garden)
+	result := s.db.WithContext(ctx).Where("id = ? AND user_id = ?", gardenID, userID).First(&garden)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return models.Garden{}, ErrRecordNotFound
 		}
-		return models.Garden{}, ErrDatabase
+		return models.Garden{}, ParseDatabaseError(result.Error)
 	}
 	return garden, nil
 }
 
-func (s *GormGardenStore) UpdateGarden(garden *models.Garden) error {
+func (s *GormGardenStore) UpdateGarden(ctx context.Context, userID string, garden *models.Garden) error {
+	db := s.db.WithContext(ctx)
 	// First, check if the record exists to return ErrRecordNotFound if it doesn't.
 	// GORM's Updates method might not return an error for non-existent records if using a map or struct,
 	// depending on the configuration and whether primary keys are set.
 	var existingGarden models.Garden
-	if err := s.db.First(&existingGarden, "id = ?", garden.ID).Error; err != nil {
+	if err := db.First(&existingGarden, "id = ? AND user_id = ?", garden.ID, userID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return ErrRecordNotFound
 		}
@@ -79,7 +111,11 @@ func (s *GormGardenStore) UpdateGarden(garden *models.Garden) error {
 
 	// Validate before update (e.g., name not empty)
 	if garden.Name == "" {
-		return ErrValidation
+		return invalidField("name", "is required")
+	}
+
+	if garden.Version != 0 && garden.Version != existingGarden.Version {
+		return ErrVersionConflict
 	}
 
 	// Use a map to specify which fields to update, preventing zero values from overwriting fields unintentionally.
@@ -88,45 +124,67 @@ func (s *GormGardenStore) UpdateGarden(garden *models.Garden) error {
 		"name":        garden.Name,
 		"description": garden.Description,
 		"location":    garden.Location,
+		"version":     gorm.Expr("version + 1"),
 		"updated_at":  time.Now(), // Explicitly set updated_at
 	}
 
-	result := s.db.Model(&models.Garden{}).Where("id = ?", garden.ID).Updates(updateFields)
-	if result.Error != nil {
-		return ParseDatabaseError(result.Error)
+	query := db.Model(&models.Garden{}).Where("id = ? AND user_id = ?", garden.ID, userID)
+	if garden.Version != 0 {
+		query = query.Where("version = ?", garden.Version)
 	}
-	if result.RowsAffected == 0 {
-		// This case might indicate the record was deleted between the First() call and Updates(),
-		// or perhaps the update data was identical to existing data (though Updates usually still reports 1 row affected if matched).
-		// Considering we already checked existence, this might be redundant or indicate a concurrent modification.
-		// For now, we can rely on the earlier First() check for ErrRecordNotFound.
-	}
-	return nil
-}
-
-func (s *GormGardenStore) DeleteGarden(gardenID string) error {
-	result := s.db.Where("id = ?", gardenID).Delete(&models.Garden{})
+	result := query.Updates(updateFields)
 	if result.Error != nil {
 		return ParseDatabaseError(result.Error)
 	}
 	if result.RowsAffected == 0 {
+		// The garden existed a moment ago, so it was changed or deleted concurrently.
+		if garden.Version != 0 {
+			return ErrVersionConflict
+		}
 		return ErrRecordNotFound
 	}
+	garden.Version = existingGarden.Version + 1
 	return nil
 }
 
+// DeleteGarden moves a garden to the trash along with its beds and tasks. A
+// non-zero version makes the delete conditional on the garden still being at
+// that version.
+func (s *GormGardenStore) DeleteGarden(ctx context.Context, userID, gardenID string, version int) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := deleteVersioned(tx, &models.Garden{}, userID, gardenID, version); err != nil {
+			return err
+		}
+		var garden models.Garden
+		if err := tx.Unscoped().Select("deleted_at").First(&garden, "id = ? AND user_id = ?", gardenID, userID).Error; err != nil {
+			return ParseDatabaseError(err)
+		}
+		// The contents are stamped with the garden's deletion time, which is
+		// how restoring it tells them from beds and tasks deleted before it
+		for _, model := range []interface{}{&models.Bed{}, &models.Task{}} {
+			err := tx.Model(model).Where("garden_id = ? AND user_id = ?", gardenID, userID).
+				Update("deleted_at", garden.DeletedAt).Error
+			if err != nil {
+				return ParseDatabaseError(err)
+			}
+		}
+		return nil
+	})
+	return ParseDatabaseError(err)
+}
+
 // CreateGardenWithTransaction creates a garden with related structures in a transaction
 // TODO: This method needs to be adapted. If CreateBed becomes part of a BedStorer interface,
 // this GormGardenStore might need a BedStorer instance, or the transaction logic
 // needs to be handled at a higher service layer that coordinates both stores.
 // For now, assuming CreateBed is a package-level func in storage that can take a *gorm.DB (tx).
-func (s *GormGardenStore) CreateGardenWithTransaction(garden *models.Garden, beds []models.Bed) error {
+func (s *GormGardenStore) CreateGardenWithTransaction(ctx context.Context, userID string, garden *models.Garden, beds []models.Bed) error {
 	var innerError error
-	err := s.db.Transaction(func(tx *gorm.DB) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		// Temporarily create a new GormGardenStore with the transaction tx for the CreateGarden call
 		// This is not ideal if CreateGarden itself has complex logic relying on the 's' state, but for now:
 		tempStoreForTx := &GormGardenStore{db: tx}
-		if err := tempStoreForTx.CreateGarden(garden); err != nil { // Call the method on the store
+		if err := tempStoreForTx.CreateGarden(ctx, userID, garden); err != nil { // Call the method on the store
 			return err
 		}
 
@@ -137,7 +195,7 @@ func (s *GormGardenStore) CreateGardenWithTransaction(garden *models.Garden, bed
 			beds[i].GardenID = garden.ID
 			// Instantiate a BedStorer with the transaction
 			tempBedStoreForTx := NewGormBedStore(tx)
-			if err := tempBedStoreForTx.CreateBed(&beds[i]); err != nil {
+			if err := tempBedStoreForTx.CreateBed(ctx, userID, &beds[i]); err != nil {
 				innerError = err
 				return err
 			}
@@ -159,54 +217,40 @@ func (s *GormGardenStore) CreateGardenWithTransaction(garden *models.Garden, bed
 	return nil
 }
 
-func (s *GormGardenStore) GetAllGardensWithTimeout(timeout time.Duration) ([]models.Garden, error) {
-	var gardens []models.Garden
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
-
-	result := s.db.WithContext(ctx).Find(&gardens)
-	if result.Error != nil {
-		if errors.Is(result.Error, context.DeadlineExceeded) {
-			return nil, ErrTimeout
-		}
-		return nil, ErrDatabase
-	}
-	return gardens, nil
-}
-
-func (s *GormGardenStore) GetGardensByQuery(params map[string]string) ([]models.Garden, error) {
-	var gardens []models.Garden
-	allowedParams := map[string]bool{
-		"name": true, "createdStart": true, "createdEnd": true, "size": true,
-	}
-	for key := range params {
-		if !allowedParams[key] {
+// GetGardensByQuery filters gardens by name (substring) and by createdStart and
+// createdEnd (RFC 3339), returning the first size of them (DefaultPageSize if
+// unset), oldest first. It is a map-based front end to ListGardens.
+func (s *GormGardenStore) GetGardensByQuery(ctx context.Context, userID string, params map[string]string) ([]models.Garden, error) {
+	q := ListQuery{Filters: map[string]string{}}
+	for key, value := range params {
+		switch key {
+		case "name":
+			q.Filters["name"] = value
+		case "createdStart", "createdEnd":
+			if _, err := time.Parse(time.RFC3339, value); err != nil {
+				return nil, ErrValidation
+			}
+			if key == "createdStart" {
+				q.Filters["created_from"] = value
+			} else {
+				q.Filters["created_to"] = value
+			}
+		case "size":
+			size, err := strconv.Atoi(value)
+			if err != nil || size < 1 || size > MaxPageSize {
+				return nil, ErrValidation
+			}
+			q.Limit = size
+		default:
 			return nil, ErrInvalidQuery
 		}
 	}
 
-	query := s.db
-	if name, ok := params["name"]; ok {
-		query = query.Where("name LIKE ?", "%"+name+"%")
-	}
-	if createdStart, ok := params["createdStart"]; ok {
-		if _, err := time.Parse(time.RFC3339, createdStart); err != nil {
-			return nil, ErrValidation
-		}
-		query = query.Where("created_at >= ?", createdStart)
-	}
-	if createdEnd, ok := params["createdEnd"]; ok {
-		if _, err := time.Parse(time.RFC3339, createdEnd); err != nil {
-			return nil, ErrValidation
-		}
-		query = query.Where("created_at <= ?", createdEnd)
-	}
-
-	result := query.Find(&gardens)
-	if result.Error != nil {
-		return nil, ErrDatabase
+	page, err := s.ListGardens(ctx, userID, q)
+	if err != nil {
+		return nil, err
 	}
-	return gardens, nil
+	return page.Items, nil
 }
 
 // Note: The CreateBed function is assumed to exist elsewhere in the storage package for CreateGardenWithTransaction.
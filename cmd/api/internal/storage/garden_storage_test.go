@@ -1,6 +1,7 @@
 package storage_test
 
 import (
+	"context"
 	"errors"
 	"regexp"
 	"testing"
@@ -15,6 +16,9 @@ import (
 	"gorm.io/gorm"
 )
 
+// testUserID is the owner every storage test operates as.
+const testUserID = "user_test"
+
 // newMockDBForStorageTest creates a new GORM DB instance with sqlmock for storage tests.
 func newMockDBForStorageTest(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
 	sqlDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
@@ -49,10 +53,10 @@ func TestGormGardenStore_GetAllGardens_Success(t *testing.T) {
 		AddRow(expectedGardens[1].ID, expectedGardens[1].Name, expectedGardens[1].Location, expectedGardens[1].Description, expectedGardens[1].CreatedAt, expectedGardens[1].UpdatedAt)
 
 	// Assuming no soft delete for this basic query for now.
-	sql := `SELECT * FROM "gardens"`
-	mock.ExpectQuery(regexp.QuoteMeta(sql)).WillReturnRows(rows)
+	sql := `SELECT * FROM "gardens" WHERE user_id = $1`
+	mock.ExpectQuery(regexp.QuoteMeta(sql)).WithArgs(testUserID).WillReturnRows(rows)
 
-	actualGardens, err := store.GetAllGardens()
+	actualGardens, err := store.GetAllGardens(context.Background(), testUserID)
 
 	assert.NoError(t, err)
 	assert.ElementsMatch(t, expectedGardens, actualGardens)
@@ -68,10 +72,10 @@ func TestGormGardenStore_GetAllGardens_DBError(t *testing.T) {
 	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
 
 	dbErr := errors.New("database GetAllGardens error")
-	sql := `SELECT * FROM "gardens"`
-	mock.ExpectQuery(regexp.QuoteMeta(sql)).WillReturnError(dbErr)
+	sql := `SELECT * FROM "gardens" WHERE user_id = $1`
+	mock.ExpectQuery(regexp.QuoteMeta(sql)).WithArgs(testUserID).WillReturnError(dbErr)
 
-	actualGardens, err := store.GetAllGardens()
+	actualGardens, err := store.GetAllGardens(context.Background(), testUserID)
 
 	assert.Nil(t, actualGardens)
 	assert.ErrorIs(t, err, storage.ErrDatabase) // Expecting our custom wrapped error
@@ -94,10 +98,10 @@ func TestGormGardenStore_GetGardenByID_Success(t *testing.T) {
 
 	// GORM's First() method typically adds a LIMIT 1
 	// The regexp.QuoteMeta escapes special characters. The (.*) matches any conditions GORM might add for soft deletes if active.
-	sql := `SELECT * FROM "gardens" WHERE id = $1 ORDER BY "gardens"."id" LIMIT $2`
-	mock.ExpectQuery(regexp.QuoteMeta(sql)).WithArgs(gardenID, 1).WillReturnRows(rows)
+	sql := `SELECT * FROM "gardens" WHERE (id = $1 AND user_id = $2) AND "gardens"."deleted_at" IS NULL ORDER BY "gardens"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sql)).WithArgs(gardenID, testUserID, 1).WillReturnRows(rows)
 
-	actualGarden, err := store.GetGardenByID(gardenID)
+	actualGarden, err := store.GetGardenByID(context.Background(), testUserID, gardenID)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedGarden, actualGarden)
@@ -113,10 +117,31 @@ func TestGormGardenStore_GetGardenByID_NotFound(t *testing.T) {
 
 	gardenID := "nonexistent"
 
-	sql := `SELECT * FROM "gardens" WHERE id = $1 ORDER BY "gardens"."id" LIMIT $2`
-	mock.ExpectQuery(regexp.QuoteMeta(sql)).WithArgs(gardenID, 1).WillReturnError(gorm.ErrRecordNotFound)
+	sql := `SELECT * FROM "gardens" WHERE (id = $1 AND user_id = $2) AND "gardens"."deleted_at" IS NULL ORDER BY "gardens"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sql)).WithArgs(gardenID, testUserID, 1).WillReturnError(gorm.ErrRecordNotFound)
+
+	_, err = store.GetGardenByID(context.Background(), testUserID, gardenID)
+
+	assert.ErrorIs(t, err, storage.ErrRecordNotFound)
+}
+
+func TestGormGardenStore_GetGardenByID_OtherUser(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormGardenStore(db)
+	rawSqlDB, err := db.DB()
+	require.NoError(t, err)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	// The garden exists but belongs to someone else, so the scoped query finds nothing.
+	gardenID := "g1"
+	otherUserID := "user_other"
+
+	sql := `SELECT * FROM "gardens" WHERE (id = $1 AND user_id = $2) AND "gardens"."deleted_at" IS NULL ORDER BY "gardens"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sql)).WithArgs(gardenID, otherUserID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "name"}))
 
-	_, err = store.GetGardenByID(gardenID)
+	_, err = store.GetGardenByID(context.Background(), otherUserID, gardenID)
 
 	assert.ErrorIs(t, err, storage.ErrRecordNotFound)
 }
@@ -132,10 +157,10 @@ func TestGormGardenStore_GetGardenByID_DBError(t *testing.T) {
 	gardenID := "g1"
 	dbErr := errors.New("some db error")
 
-	sql := `SELECT * FROM "gardens" WHERE id = $1 ORDER BY "gardens"."id" LIMIT $2`
-	mock.ExpectQuery(regexp.QuoteMeta(sql)).WithArgs(gardenID, 1).WillReturnError(dbErr)
+	sql := `SELECT * FROM "gardens" WHERE (id = $1 AND user_id = $2) AND "gardens"."deleted_at" IS NULL ORDER BY "gardens"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sql)).WithArgs(gardenID, testUserID, 1).WillReturnError(dbErr)
 
-	_, err = store.GetGardenByID(gardenID)
+	_, err = store.GetGardenByID(context.Background(), testUserID, gardenID)
 
 	assert.ErrorIs(t, err, storage.ErrDatabase)
 }
@@ -151,13 +176,13 @@ func TestGormGardenStore_CreateGarden_Success(t *testing.T) {
 	gardenToCreate := &models.Garden{ID: "g_create_success", Name: "New Garden", Location: "New Loc", Description: "New Desc"}
 
 	mock.ExpectBegin()
-	sqlInsert := `INSERT INTO "gardens" ("id","name","location","description","created_at","updated_at") VALUES ($1,$2,$3,$4,$5,$6)`
+	sqlInsert := `INSERT INTO "gardens" ("id","user_id","name","location","description","version","created_at","updated_at","deleted_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)`
 	mock.ExpectExec(regexp.QuoteMeta(sqlInsert)).
-		WithArgs(gardenToCreate.ID, gardenToCreate.Name, gardenToCreate.Location, gardenToCreate.Description, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WithArgs(sqlmock.AnyArg(), testUserID, gardenToCreate.Name, gardenToCreate.Location, gardenToCreate.Description, 1, sqlmock.AnyArg(), sqlmock.AnyArg(), nil).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
-	err = store.CreateGarden(gardenToCreate)
+	err = store.CreateGarden(context.Background(), testUserID, gardenToCreate)
 	assert.NoError(t, err)
 	// Optionally, assert that gardenToCreate.CreatedAt, UpdatedAt are populated if your CreateGarden method does that.
 }
@@ -171,7 +196,7 @@ func TestGormGardenStore_CreateGarden_ValidationError(t *testing.T) {
 
 	// No DB interaction expected, so no mock expectations.
 	gardenToCreate := &models.Garden{Name: ""} // Invalid: Name is empty
-	err = store.CreateGarden(gardenToCreate)
+	err = store.CreateGarden(context.Background(), testUserID, gardenToCreate)
 	assert.ErrorIs(t, err, storage.ErrValidation)
 }
 
@@ -187,13 +212,13 @@ func TestGormGardenStore_CreateGarden_DBError(t *testing.T) {
 	dbErr := errors.New("create garden db error")
 
 	mock.ExpectBegin()
-	sqlInsert := `INSERT INTO "gardens" ("id","name","location","description","created_at","updated_at") VALUES ($1,$2,$3,$4,$5,$6)`
+	sqlInsert := `INSERT INTO "gardens" ("id","user_id","name","location","description","version","created_at","updated_at","deleted_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)`
 	mock.ExpectExec(regexp.QuoteMeta(sqlInsert)).
-		WithArgs(gardenToCreate.ID, gardenToCreate.Name, gardenToCreate.Location, gardenToCreate.Description, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WithArgs(sqlmock.AnyArg(), testUserID, gardenToCreate.Name, gardenToCreate.Location, gardenToCreate.Description, 1, sqlmock.AnyArg(), sqlmock.AnyArg(), nil).
 		WillReturnError(dbErr)
 	mock.ExpectRollback()
 
-	err = store.CreateGarden(gardenToCreate)
+	err = store.CreateGarden(context.Background(), testUserID, gardenToCreate)
 
 	parsedErr := storage.ParseDatabaseError(dbErr)
 	assert.ErrorIs(t, err, parsedErr)
@@ -213,20 +238,83 @@ func TestGormGardenStore_UpdateGarden_Success(t *testing.T) {
 	// 1. Mock the First() call to check if record exists
 	existingRow := sqlmock.NewRows([]string{"id", "name", "location", "description", "created_at", "updated_at"}).
 		AddRow(gardenToUpdate.ID, "Old Name", "Old Loc", "Old Desc", now.Add(-time.Hour), now.Add(-time.Hour))
-	sqlSelectOne := `SELECT * FROM "gardens" WHERE id = $1 ORDER BY "gardens"."id" LIMIT $2`
-	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectOne)).WithArgs(gardenToUpdate.ID, 1).WillReturnRows(existingRow)
+	sqlSelectOne := `SELECT * FROM "gardens" WHERE (id = $1 AND user_id = $2) AND "gardens"."deleted_at" IS NULL ORDER BY "gardens"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectOne)).WithArgs(gardenToUpdate.ID, testUserID, 1).WillReturnRows(existingRow)
 
 	mock.ExpectBegin()
-	sqlUpdate := `UPDATE "gardens" SET "description"=$1,"location"=$2,"name"=$3,"updated_at"=$4 WHERE id = $5`
+	sqlUpdate := `UPDATE "gardens" SET "description"=$1,"location"=$2,"name"=$3,"updated_at"=$4,"version"=version + 1 WHERE (id = $5 AND user_id = $6) AND "gardens"."deleted_at" IS NULL`
 	mock.ExpectExec(regexp.QuoteMeta(sqlUpdate)).
-		WithArgs(gardenToUpdate.Description, gardenToUpdate.Location, gardenToUpdate.Name, sqlmock.AnyArg(), gardenToUpdate.ID).
+		WithArgs(gardenToUpdate.Description, gardenToUpdate.Location, gardenToUpdate.Name, sqlmock.AnyArg(), gardenToUpdate.ID, testUserID).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 	mock.ExpectCommit()
 
-	err = store.UpdateGarden(gardenToUpdate)
+	err = store.UpdateGarden(context.Background(), testUserID, gardenToUpdate)
 	assert.NoError(t, err)
 }
 
+func TestGormGardenStore_UpdateGarden_IfMatchVersion(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormGardenStore(db)
+	rawSqlDB, err := db.DB()
+	require.NoError(t, err)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	gardenToUpdate := &models.Garden{ID: "g1", Name: "Updated Name", Version: 3}
+
+	sqlSelectOne := `SELECT * FROM "gardens" WHERE (id = $1 AND user_id = $2) AND "gardens"."deleted_at" IS NULL ORDER BY "gardens"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectOne)).WithArgs(gardenToUpdate.ID, testUserID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "version"}).AddRow("g1", "Old Name", 3))
+
+	mock.ExpectBegin()
+	sqlUpdate := `UPDATE "gardens" SET "description"=$1,"location"=$2,"name"=$3,"updated_at"=$4,"version"=version + 1 WHERE (id = $5 AND user_id = $6) AND version = $7`
+	mock.ExpectExec(regexp.QuoteMeta(sqlUpdate)).
+		WithArgs("", "", gardenToUpdate.Name, sqlmock.AnyArg(), gardenToUpdate.ID, testUserID, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = store.UpdateGarden(context.Background(), testUserID, gardenToUpdate)
+	require.NoError(t, err)
+	assert.Equal(t, 4, gardenToUpdate.Version)
+}
+
+func TestGormGardenStore_UpdateGarden_StaleVersion(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormGardenStore(db)
+	rawSqlDB, err := db.DB()
+	require.NoError(t, err)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	sqlSelectOne := `SELECT * FROM "gardens" WHERE (id = $1 AND user_id = $2) AND "gardens"."deleted_at" IS NULL ORDER BY "gardens"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectOne)).WithArgs("g1", testUserID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "version"}).AddRow("g1", "Old Name", 5))
+
+	err = store.UpdateGarden(context.Background(), testUserID, &models.Garden{ID: "g1", Name: "Updated Name", Version: 4})
+	assert.ErrorIs(t, err, storage.ErrVersionConflict)
+}
+
+func TestGormGardenStore_UpdateGarden_ChangedConcurrently(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormGardenStore(db)
+	rawSqlDB, err := db.DB()
+	require.NoError(t, err)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	sqlSelectOne := `SELECT * FROM "gardens" WHERE (id = $1 AND user_id = $2) AND "gardens"."deleted_at" IS NULL ORDER BY "gardens"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectOne)).WithArgs("g1", testUserID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "version"}).AddRow("g1", "Old Name", 2))
+
+	// Another writer bumps the version between the read and the update
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "gardens" SET`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	err = store.UpdateGarden(context.Background(), testUserID, &models.Garden{ID: "g1", Name: "Updated Name", Version: 2})
+	assert.ErrorIs(t, err, storage.ErrVersionConflict)
+}
+
 func TestGormGardenStore_UpdateGarden_ValidationError(t *testing.T) {
 	// This test focuses on validation errors.
 	// Case 1: Validation error before any DB call (e.g. empty ID if checked by UpdateGarden first)
@@ -247,11 +335,11 @@ func TestGormGardenStore_UpdateGarden_ValidationError(t *testing.T) {
 	existingRow := sqlmock.NewRows([]string{"id", "name", "location", "description", "created_at", "updated_at"}).
 		AddRow("g1", "Old Name", "Old Loc", "Old Desc", time.Now(), time.Now()) // Add all columns expected by First
 
-	sqlSelectOne := `SELECT * FROM "gardens" WHERE id = $1 ORDER BY "gardens"."id" LIMIT $2`
-	mockForSubTest.ExpectQuery(regexp.QuoteMeta(sqlSelectOne)).WithArgs(gardenWithEmptyName.ID, 1).WillReturnRows(existingRow)
+	sqlSelectOne := `SELECT * FROM "gardens" WHERE (id = $1 AND user_id = $2) AND "gardens"."deleted_at" IS NULL ORDER BY "gardens"."id" LIMIT $3`
+	mockForSubTest.ExpectQuery(regexp.QuoteMeta(sqlSelectOne)).WithArgs(gardenWithEmptyName.ID, testUserID, 1).WillReturnRows(existingRow)
 	// No EXEC expected as it should fail validation before the update call.
 
-	err = storeWithMockedDb.UpdateGarden(gardenWithEmptyName)
+	err = storeWithMockedDb.UpdateGarden(context.Background(), testUserID, gardenWithEmptyName)
 	assert.ErrorIs(t, err, storage.ErrValidation, "Expected validation error for empty name")
 	assert.NoError(t, mockForSubTest.ExpectationsWereMet(), "Sub-test mock expectations not met")
 }
@@ -267,10 +355,10 @@ func TestGormGardenStore_UpdateGarden_NotFound(t *testing.T) {
 	gardenToUpdate := &models.Garden{ID: "nonexistent", Name: "Updated Name"}
 
 	// Mock the First() call to return ErrRecordNotFound
-	sqlSelectOne := `SELECT * FROM "gardens" WHERE id = $1 ORDER BY "gardens"."id" LIMIT $2`
-	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectOne)).WithArgs(gardenToUpdate.ID, 1).WillReturnError(gorm.ErrRecordNotFound)
+	sqlSelectOne := `SELECT * FROM "gardens" WHERE (id = $1 AND user_id = $2) AND "gardens"."deleted_at" IS NULL ORDER BY "gardens"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectOne)).WithArgs(gardenToUpdate.ID, testUserID, 1).WillReturnError(gorm.ErrRecordNotFound)
 
-	err = store.UpdateGarden(gardenToUpdate)
+	err = store.UpdateGarden(context.Background(), testUserID, gardenToUpdate)
 	assert.ErrorIs(t, err, storage.ErrRecordNotFound)
 }
 
@@ -285,10 +373,10 @@ func TestGormGardenStore_UpdateGarden_DBErrorOnSelect(t *testing.T) {
 	gardenToUpdate := &models.Garden{ID: "g1", Name: "Updated Name"}
 	dbErr := errors.New("db error on select")
 
-	sqlSelectOne := `SELECT * FROM "gardens" WHERE id = $1 ORDER BY "gardens"."id" LIMIT $2`
-	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectOne)).WithArgs(gardenToUpdate.ID, 1).WillReturnError(dbErr)
+	sqlSelectOne := `SELECT * FROM "gardens" WHERE (id = $1 AND user_id = $2) AND "gardens"."deleted_at" IS NULL ORDER BY "gardens"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectOne)).WithArgs(gardenToUpdate.ID, testUserID, 1).WillReturnError(dbErr)
 
-	err = store.UpdateGarden(gardenToUpdate)
+	err = store.UpdateGarden(context.Background(), testUserID, gardenToUpdate)
 	parsedErr := storage.ParseDatabaseError(dbErr)
 	assert.ErrorIs(t, err, parsedErr)
 }
@@ -307,17 +395,17 @@ func TestGormGardenStore_UpdateGarden_DBErrorOnUpdate(t *testing.T) {
 
 	// 1. Mock the First() call
 	existingRow := sqlmock.NewRows([]string{"id"}).AddRow(gardenToUpdate.ID)
-	sqlSelectOne := `SELECT * FROM "gardens" WHERE id = $1 ORDER BY "gardens"."id" LIMIT $2`
-	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectOne)).WithArgs(gardenToUpdate.ID, 1).WillReturnRows(existingRow)
+	sqlSelectOne := `SELECT * FROM "gardens" WHERE (id = $1 AND user_id = $2) AND "gardens"."deleted_at" IS NULL ORDER BY "gardens"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectOne)).WithArgs(gardenToUpdate.ID, testUserID, 1).WillReturnRows(existingRow)
 
 	mock.ExpectBegin()
-	sqlUpdate := `UPDATE "gardens" SET "description"=$1,"location"=$2,"name"=$3,"updated_at"=$4 WHERE id = $5`
+	sqlUpdate := `UPDATE "gardens" SET "description"=$1,"location"=$2,"name"=$3,"updated_at"=$4,"version"=version + 1 WHERE (id = $5 AND user_id = $6) AND "gardens"."deleted_at" IS NULL`
 	mock.ExpectExec(regexp.QuoteMeta(sqlUpdate)).
-		WithArgs(gardenToUpdate.Description, gardenToUpdate.Location, gardenToUpdate.Name, sqlmock.AnyArg(), gardenToUpdate.ID).
+		WithArgs(gardenToUpdate.Description, gardenToUpdate.Location, gardenToUpdate.Name, sqlmock.AnyArg(), gardenToUpdate.ID, testUserID).
 		WillReturnError(dbUpdateErr)
 	mock.ExpectRollback()
 
-	err = store.UpdateGarden(gardenToUpdate)
+	err = store.UpdateGarden(context.Background(), testUserID, gardenToUpdate)
 	parsedErr := storage.ParseDatabaseError(dbUpdateErr)
 	assert.ErrorIs(t, err, parsedErr)
 }
@@ -333,11 +421,20 @@ func TestGormGardenStore_DeleteGarden_Success(t *testing.T) {
 	gardenIDToDelete := "g1"
 
 	mock.ExpectBegin()
-	sqlDelete := `DELETE FROM "gardens" WHERE id = $1`
-	mock.ExpectExec(regexp.QuoteMeta(sqlDelete)).WithArgs(gardenIDToDelete).WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlDelete := `UPDATE "gardens" SET "deleted_at"=$1 WHERE (id = $2 AND user_id = $3) AND "gardens"."deleted_at" IS NULL`
+	mock.ExpectExec(regexp.QuoteMeta(sqlDelete)).WithArgs(sqlmock.AnyArg(), gardenIDToDelete, testUserID).WillReturnResult(sqlmock.NewResult(0, 1))
+	// Its beds and tasks follow it into the trash, stamped with the same time
+	deletedAt := time.Now()
+	sqlDeletedAt := `SELECT "deleted_at" FROM "gardens" WHERE id = $1 AND user_id = $2 ORDER BY "gardens"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlDeletedAt)).WithArgs(gardenIDToDelete, testUserID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"deleted_at"}).AddRow(deletedAt))
+	for _, table := range []string{"beds", "tasks"} {
+		sqlContents := `UPDATE "` + table + `" SET "deleted_at"=$1,"updated_at"=$2 WHERE (garden_id = $3 AND user_id = $4) AND "` + table + `"."deleted_at" IS NULL`
+		mock.ExpectExec(regexp.QuoteMeta(sqlContents)).WithArgs(deletedAt, sqlmock.AnyArg(), gardenIDToDelete, testUserID).WillReturnResult(sqlmock.NewResult(0, 2))
+	}
 	mock.ExpectCommit()
 
-	err = store.DeleteGarden(gardenIDToDelete)
+	err = store.DeleteGarden(context.Background(), testUserID, gardenIDToDelete, 0)
 	assert.NoError(t, err)
 }
 
@@ -352,11 +449,11 @@ func TestGormGardenStore_DeleteGarden_NotFound(t *testing.T) {
 	gardenIDToDelete := "nonexistent"
 
 	mock.ExpectBegin()
-	sqlDelete := `DELETE FROM "gardens" WHERE id = $1`
-	mock.ExpectExec(regexp.QuoteMeta(sqlDelete)).WithArgs(gardenIDToDelete).WillReturnResult(sqlmock.NewResult(0, 0))
-	mock.ExpectCommit()
+	sqlDelete := `UPDATE "gardens" SET "deleted_at"=$1 WHERE (id = $2 AND user_id = $3) AND "gardens"."deleted_at" IS NULL`
+	mock.ExpectExec(regexp.QuoteMeta(sqlDelete)).WithArgs(sqlmock.AnyArg(), gardenIDToDelete, testUserID).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
 
-	err = store.DeleteGarden(gardenIDToDelete)
+	err = store.DeleteGarden(context.Background(), testUserID, gardenIDToDelete, 0)
 	assert.ErrorIs(t, err, storage.ErrRecordNotFound)
 }
 
@@ -372,11 +469,11 @@ func TestGormGardenStore_DeleteGarden_DBError(t *testing.T) {
 	dbErr := errors.New("delete failed")
 
 	mock.ExpectBegin()
-	sqlDelete := `DELETE FROM "gardens" WHERE id = $1`
-	mock.ExpectExec(regexp.QuoteMeta(sqlDelete)).WithArgs(gardenIDToDelete).WillReturnError(dbErr)
+	sqlDelete := `UPDATE "gardens" SET "deleted_at"=$1 WHERE (id = $2 AND user_id = $3) AND "gardens"."deleted_at" IS NULL`
+	mock.ExpectExec(regexp.QuoteMeta(sqlDelete)).WithArgs(sqlmock.AnyArg(), gardenIDToDelete, testUserID).WillReturnError(dbErr)
 	mock.ExpectRollback()
 
-	err = store.DeleteGarden(gardenIDToDelete)
+	err = store.DeleteGarden(context.Background(), testUserID, gardenIDToDelete, 0)
 	assert.ErrorIs(t, err, storage.ErrDatabase) // Assuming ParseDatabaseError maps it
 }
 
@@ -398,31 +495,31 @@ func TestGormGardenStore_CreateGardenWithTransaction_Success(t *testing.T) {
 	mock.ExpectBegin()
 
 	// Garden insert
-	sqlGardenInsert := `INSERT INTO "gardens" ("id","name","location","description","created_at","updated_at") VALUES ($1,$2,$3,$4,$5,$6)`
+	sqlGardenInsert := `INSERT INTO "gardens" ("id","user_id","name","location","description","version","created_at","updated_at","deleted_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)`
 	mock.ExpectExec(regexp.QuoteMeta(sqlGardenInsert)).
-		WithArgs(gardenToCreate.ID, gardenToCreate.Name, gardenToCreate.Location, gardenToCreate.Description, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WithArgs(sqlmock.AnyArg(), testUserID, gardenToCreate.Name, gardenToCreate.Location, gardenToCreate.Description, 1, sqlmock.AnyArg(), sqlmock.AnyArg(), nil).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	// Mock Bed creations (loop)
 	for _, bed := range bedsToCreate {
 		// BedStorer.CreateBed (called with tx)
 		// 1. Mock Garden lookup by BedStorer.CreateBed
-		sqlGardenSelectForBed := `SELECT * FROM "gardens" WHERE id = $1 ORDER BY "gardens"."id" LIMIT $2`
+		sqlGardenSelectForBed := `SELECT * FROM "gardens" WHERE (id = $1 AND user_id = $2) AND "gardens"."deleted_at" IS NULL ORDER BY "gardens"."id" LIMIT $3`
 		mock.ExpectQuery(regexp.QuoteMeta(sqlGardenSelectForBed)).
-			WithArgs(gardenToCreate.ID, 1).
+			WithArgs(sqlmock.AnyArg(), testUserID, 1).
 			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(gardenToCreate.ID))
 
 		// 2. Mock Bed INSERT by BedStorer.CreateBed
-		sqlBedInsert := `INSERT INTO "beds" ("id","garden_id","name","type","size","soil_type","notes","created_at","updated_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)`
+		sqlBedInsert := `INSERT INTO "beds" ("id","user_id","garden_id","name","type","size","soil_type","notes","version","created_at","updated_at","deleted_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)`
 		mock.ExpectExec(regexp.QuoteMeta(sqlBedInsert)).
-			WithArgs(bed.ID, gardenToCreate.ID, bed.Name, bed.Type, bed.Size, bed.SoilType, bed.Notes, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WithArgs(sqlmock.AnyArg(), testUserID, sqlmock.AnyArg(), bed.Name, bed.Type, bed.Size, bed.SoilType, bed.Notes, 1, sqlmock.AnyArg(), sqlmock.AnyArg(), nil).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 	}
 
 	// Expect transaction to commit
 	mock.ExpectCommit()
 
-	err = store.CreateGardenWithTransaction(gardenToCreate, bedsToCreate)
+	err = store.CreateGardenWithTransaction(context.Background(), testUserID, gardenToCreate, bedsToCreate)
 	assert.NoError(t, err)
 }
 
@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zjpiazza/plantastic/internal/models"
+	"gorm.io/gorm"
+)
+
+// StatIdempotencyKeysExpired is the stats key reported by
+// GormIdempotencyStore.Sweep.
+const StatIdempotencyKeysExpired = "idempotency_keys_expired"
+
+// IdempotencyLease is how long a request may hold its key without storing a
+// response. After that it is presumed lost, for instance to a crash between
+// Reserve and Complete, and a retry takes the key over. It outlasts the
+// longest query deadline.
+const IdempotencyLease = 5 * time.Minute
+
+// IdempotencyStorer persists the responses to requests made with an
+// Idempotency-Key.
+type IdempotencyStorer interface {
+	// Reserve claims rec's key for a new request. If the key is already held
+	// by an unexpired record, Reserve claims nothing and returns that record;
+	// a record still without a response is only held for IdempotencyLease.
+	Reserve(ctx context.Context, rec *models.IdempotencyRecord) (*models.IdempotencyRecord, error)
+	// Complete stores the response for a key claimed with Reserve.
+	Complete(ctx context.Context, rec *models.IdempotencyRecord) error
+	// Release gives up a claimed key, so a retry is handled afresh.
+	Release(ctx context.Context, userID, key string) error
+}
+
+// GormIdempotencyStore implements IdempotencyStorer using GORM.
+type GormIdempotencyStore struct {
+	db *gorm.DB
+}
+
+// NewGormIdempotencyStore creates a new GormIdempotencyStore.
+func NewGormIdempotencyStore(db *gorm.DB) *GormIdempotencyStore {
+	return &GormIdempotencyStore{db: db}
+}
+
+func (s *GormIdempotencyStore) Reserve(ctx context.Context, rec *models.IdempotencyRecord) (*models.IdempotencyRecord, error) {
+	db := s.db.WithContext(ctx)
+	now := time.Now()
+	var existing models.IdempotencyRecord
+	err := db.First(&existing, "user_id = ? AND idempotency_key = ?", rec.UserID, rec.Key).Error
+	switch {
+	case err == nil && !isStale(existing, now):
+		return &existing, nil
+	case err == nil:
+		// Expired but not swept yet, or its request was lost; the key is free
+		// again. Only a record that is still stale goes, so a retry that took
+		// the key over in the meantime keeps it.
+		err := db.Where("user_id = ? AND idempotency_key = ?", rec.UserID, rec.Key).
+			Where("expires_at <= ? OR (status_code = 0 AND created_at <= ?)", now, now.Add(-IdempotencyLease)).
+			Delete(&models.IdempotencyRecord{}).Error
+		if err != nil {
+			return nil, ParseDatabaseError(err)
+		}
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, ParseDatabaseError(err)
+	}
+
+	if err := db.Create(rec).Error; err != nil {
+		if ParseDatabaseError(err) != ErrConflict {
+			return nil, ParseDatabaseError(err)
+		}
+		// Another request with the same key got there first
+		if err := db.First(&existing, "user_id = ? AND idempotency_key = ?", rec.UserID, rec.Key).Error; err != nil {
+			return nil, ParseDatabaseError(err)
+		}
+		return &existing, nil
+	}
+	return nil, nil
+}
+
+// isStale reports whether rec no longer holds its key at now.
+func isStale(rec models.IdempotencyRecord, now time.Time) bool {
+	if !rec.ExpiresAt.After(now) {
+		return true
+	}
+	return !rec.Completed() && !rec.CreatedAt.After(now.Add(-IdempotencyLease))
+}
+
+func (s *GormIdempotencyStore) Complete(ctx context.Context, rec *models.IdempotencyRecord) error {
+	result := s.db.WithContext(ctx).Model(&models.IdempotencyRecord{}).
+		Where("user_id = ? AND idempotency_key = ?", rec.UserID, rec.Key).
+		Updates(map[string]interface{}{
+			"status_code":  rec.StatusCode,
+			"content_type": rec.ContentType,
+			"etag":         rec.ETag,
+			"body":         rec.Body,
+		})
+	if result.Error != nil {
+		return ParseDatabaseError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+func (s *GormIdempotencyStore) Release(ctx context.Context, userID, key string) error {
+	if err := s.db.WithContext(ctx).Where("user_id = ? AND idempotency_key = ?", userID, key).Delete(&models.IdempotencyRecord{}).Error; err != nil {
+		return ParseDatabaseError(err)
+	}
+	return nil
+}
+
+// Sweep deletes expired records. Its signature matches scheduler.JobFunc.
+func (s *GormIdempotencyStore) Sweep(ctx context.Context) (map[string]int64, error) {
+	result := s.db.WithContext(ctx).Where("expires_at < ?", time.Now()).Delete(&models.IdempotencyRecord{})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to delete expired idempotency keys: %w", result.Error)
+	}
+	return map[string]int64{StatIdempotencyKeysExpired: result.RowsAffected}, nil
+}
@@ -0,0 +1,124 @@
+package storage_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zjpiazza/plantastic/cmd/api/internal/storage"
+	"github.com/zjpiazza/plantastic/internal/models"
+)
+
+const sqlStaleDelete = `DELETE FROM "idempotency_records" WHERE (user_id = $1 AND idempotency_key = $2) AND (expires_at <= $3 OR (status_code = 0 AND created_at <= $4))`
+
+func TestGormIdempotencyStore_Reserve_NewKey(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormIdempotencyStore(db)
+	rawSqlDB, err := db.DB()
+	require.NoError(t, err)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	sqlSelect := `SELECT * FROM "idempotency_records" WHERE user_id = $1 AND idempotency_key = $2`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelect)).WithArgs(testUserID, "k1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "idempotency_records"`)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	existing, err := store.Reserve(context.Background(), &models.IdempotencyRecord{
+		UserID: testUserID, Key: "k1", RequestHash: "abc", ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	assert.Nil(t, existing)
+}
+
+func TestGormIdempotencyStore_Reserve_KeyInUse(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormIdempotencyStore(db)
+	rawSqlDB, err := db.DB()
+	require.NoError(t, err)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	sqlSelect := `SELECT * FROM "idempotency_records" WHERE user_id = $1 AND idempotency_key = $2`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelect)).WithArgs(testUserID, "k1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "idempotency_key", "request_hash", "status_code", "body", "expires_at"}).
+			AddRow(testUserID, "k1", "abc", 201, []byte(`{"id":"g1"}`), time.Now().Add(time.Hour)))
+
+	existing, err := store.Reserve(context.Background(), &models.IdempotencyRecord{UserID: testUserID, Key: "k1", RequestHash: "abc"})
+	require.NoError(t, err)
+	require.NotNil(t, existing)
+	assert.Equal(t, 201, existing.StatusCode)
+	assert.JSONEq(t, `{"id":"g1"}`, string(existing.Body))
+}
+
+func TestGormIdempotencyStore_Reserve_ExpiredKeyIsFree(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormIdempotencyStore(db)
+	rawSqlDB, err := db.DB()
+	require.NoError(t, err)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "idempotency_records"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "idempotency_key", "status_code", "expires_at"}).
+			AddRow(testUserID, "k1", 201, time.Now().Add(-time.Minute)))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(sqlStaleDelete)).WithArgs(testUserID, "k1", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "idempotency_records"`)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	existing, err := store.Reserve(context.Background(), &models.IdempotencyRecord{UserID: testUserID, Key: "k1", RequestHash: "def"})
+	require.NoError(t, err)
+	assert.Nil(t, existing)
+}
+
+func TestGormIdempotencyStore_Reserve_InFlightWithinLease(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormIdempotencyStore(db)
+	rawSqlDB, err := db.DB()
+	require.NoError(t, err)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "idempotency_records"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "idempotency_key", "status_code", "created_at", "expires_at"}).
+			AddRow(testUserID, "k1", 0, time.Now().Add(-time.Minute), time.Now().Add(time.Hour)))
+
+	existing, err := store.Reserve(context.Background(), &models.IdempotencyRecord{UserID: testUserID, Key: "k1", RequestHash: "abc"})
+	require.NoError(t, err)
+	require.NotNil(t, existing)
+	assert.False(t, existing.Completed())
+}
+
+func TestGormIdempotencyStore_Reserve_TakesOverLostRequest(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormIdempotencyStore(db)
+	rawSqlDB, err := db.DB()
+	require.NoError(t, err)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	// Reserved long ago and never completed: the server died mid-request
+	started := time.Now().Add(-storage.IdempotencyLease - time.Minute)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "idempotency_records"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "idempotency_key", "status_code", "created_at", "expires_at"}).
+			AddRow(testUserID, "k1", 0, started, time.Now().Add(time.Hour)))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(sqlStaleDelete)).WithArgs(testUserID, "k1", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "idempotency_records"`)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	existing, err := store.Reserve(context.Background(), &models.IdempotencyRecord{UserID: testUserID, Key: "k1", RequestHash: "abc"})
+	require.NoError(t, err)
+	assert.Nil(t, existing)
+}
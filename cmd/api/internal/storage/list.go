@@ -0,0 +1,242 @@
+package storage
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Page size limits for list endpoints.
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+)
+
+// ListQuery selects one page of a filtered, sorted list.
+type ListQuery struct {
+	Filters map[string]string // Filter name to value; each resource whitelists its own filters
+	Sort    string            // Whitelisted sort field, prefixed with "-" for descending; empty for the default
+	Limit   int               // Page size; 0 means DefaultPageSize
+	Cursor  string            // Opaque cursor from a previous page's NextCursor
+}
+
+// Page is one page of a list. NextCursor is empty on the last page.
+type Page[T any] struct {
+	Items      []T
+	NextCursor string
+}
+
+// sortField is a column a list may be sorted by.
+type sortField[T any] struct {
+	column string
+	value  func(T) any // Value of the column for an item, used to build cursors
+	isTime bool
+}
+
+// filterFunc narrows a list query by one filter value. It returns
+// ErrValidation for malformed values.
+type filterFunc func(db *gorm.DB, value string) (*gorm.DB, error)
+
+// listSpec describes how a resource can be filtered, sorted and paged.
+type listSpec[T any] struct {
+	filters     map[string]filterFunc
+	sorts       map[string]sortField[T]
+	defaultSort string
+	id          func(T) string
+}
+
+// ListOptions names the filters and sort fields a list endpoint accepts.
+type ListOptions struct {
+	Filters     []string
+	Sorts       []string
+	DefaultSort string
+}
+
+func (spec listSpec[T]) options() ListOptions {
+	opts := ListOptions{DefaultSort: spec.defaultSort}
+	for name := range spec.filters {
+		opts.Filters = append(opts.Filters, name)
+	}
+	for name := range spec.sorts {
+		opts.Sorts = append(opts.Sorts, name)
+	}
+	sort.Strings(opts.Filters)
+	sort.Strings(opts.Sorts)
+	return opts
+}
+
+// List options of each resource, for documenting the list endpoints.
+func GardenListOptions() ListOptions   { return gardenListSpec.options() }
+func BedListOptions() ListOptions      { return bedListSpec.options() }
+func TaskListOptions() ListOptions     { return taskListSpec.options() }
+func PlantListOptions() ListOptions    { return plantListSpec.options() }
+func PlantingListOptions() ListOptions { return plantingListSpec.options() }
+func AuditListOptions() ListOptions    { return auditListSpec.options() }
+
+// cursor identifies the last item of a page: its sort value and ID, which
+// break ties between items with equal sort values.
+type cursor struct {
+	Sort  string `json:"s"`
+	Value any    `json:"v"`
+	ID    string `json:"id"`
+}
+
+// paginate applies q to db using keyset pagination on (sort column, id), so
+// pages stay stable while rows are added or removed. Unknown filters, sort
+// fields or malformed cursors give ErrInvalidQuery; malformed filter values
+// give ErrValidation.
+func paginate[T any](db *gorm.DB, spec listSpec[T], q ListQuery) (Page[T], error) {
+	// Apply filters in name order so the same query always yields the same SQL.
+	names := make([]string, 0, len(q.Filters))
+	for name := range q.Filters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var err error
+	for _, name := range names {
+		filter, ok := spec.filters[name]
+		if !ok {
+			return Page[T]{}, ErrInvalidQuery
+		}
+		if db, err = filter(db, q.Filters[name]); err != nil {
+			return Page[T]{}, err
+		}
+	}
+
+	sortKey := q.Sort
+	if sortKey == "" {
+		sortKey = spec.defaultSort
+	}
+	desc := strings.HasPrefix(sortKey, "-")
+	field, ok := spec.sorts[strings.TrimPrefix(sortKey, "-")]
+	if !ok {
+		return Page[T]{}, ErrInvalidQuery
+	}
+
+	limit := q.Limit
+	if limit < 0 || limit > MaxPageSize {
+		return Page[T]{}, ErrInvalidQuery
+	}
+	if limit == 0 {
+		limit = DefaultPageSize
+	}
+
+	op, dir := ">", "ASC"
+	if desc {
+		op, dir = "<", "DESC"
+	}
+	if q.Cursor != "" {
+		after, err := decodeCursor(q.Cursor, sortKey, field.isTime)
+		if err != nil {
+			return Page[T]{}, err
+		}
+		db = db.Where(fmt.Sprintf("%[1]s %[2]s ? OR (%[1]s = ? AND id %[2]s ?)", field.column, op),
+			after.Value, after.Value, after.ID)
+	}
+
+	var items []T
+	result := db.Order(field.column + " " + dir).Order("id " + dir).Limit(limit + 1).Find(&items)
+	if result.Error != nil {
+		return Page[T]{}, ParseDatabaseError(result.Error)
+	}
+
+	page := Page[T]{Items: items}
+	if len(items) > limit {
+		last := items[limit-1]
+		page.Items = items[:limit]
+		page.NextCursor = encodeCursor(cursor{Sort: sortKey, Value: field.value(last), ID: spec.id(last)})
+	}
+	return page, nil
+}
+
+func encodeCursor(c cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor parses a cursor, which is only valid for the sort it was issued for.
+func decodeCursor(s, sortKey string, isTime bool) (cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor{}, ErrInvalidQuery
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Sort != sortKey || c.ID == "" {
+		return cursor{}, ErrInvalidQuery
+	}
+	str, ok := c.Value.(string)
+	if !ok {
+		return cursor{}, ErrInvalidQuery
+	}
+	if isTime {
+		t, err := time.Parse(time.RFC3339Nano, str)
+		if err != nil {
+			return cursor{}, ErrInvalidQuery
+		}
+		c.Value = t
+	}
+	return c, nil
+}
+
+// equalsFilter matches column exactly.
+func equalsFilter(column string) filterFunc {
+	return func(db *gorm.DB, value string) (*gorm.DB, error) {
+		return db.Where(column+" = ?", value), nil
+	}
+}
+
+// oneOfFilter matches any of a comma-separated list of values.
+func oneOfFilter(column string) filterFunc {
+	return func(db *gorm.DB, value string) (*gorm.DB, error) {
+		return db.Where(column+" IN ?", strings.Split(value, ",")), nil
+	}
+}
+
+// containsFilter matches column containing value.
+func containsFilter(column string) filterFunc {
+	return func(db *gorm.DB, value string) (*gorm.DB, error) {
+		return db.Where(column+" LIKE ?", "%"+value+"%"), nil
+	}
+}
+
+// timeFromFilter matches column at or after value, an RFC 3339 time or YYYY-MM-DD date.
+func timeFromFilter(column string) filterFunc {
+	return func(db *gorm.DB, value string) (*gorm.DB, error) {
+		t, _, err := parseTimeFilter(value)
+		if err != nil {
+			return nil, err
+		}
+		return db.Where(column+" >= ?", t), nil
+	}
+}
+
+// timeToFilter matches column at or before value, an RFC 3339 time or
+// YYYY-MM-DD date; a date includes the whole day.
+func timeToFilter(column string) filterFunc {
+	return func(db *gorm.DB, value string) (*gorm.DB, error) {
+		t, isDate, err := parseTimeFilter(value)
+		if err != nil {
+			return nil, err
+		}
+		if isDate {
+			return db.Where(column+" < ?", t.AddDate(0, 0, 1)), nil
+		}
+		return db.Where(column+" <= ?", t), nil
+	}
+}
+
+func parseTimeFilter(value string) (t time.Time, isDate bool, err error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, false, nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, true, nil
+	}
+	return time.Time{}, false, ErrValidation
+}
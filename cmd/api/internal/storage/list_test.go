@@ -0,0 +1,160 @@
+package storage_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zjpiazza/plantastic/cmd/api/internal/storage"
+	"github.com/zjpiazza/plantastic/internal/models"
+)
+
+func TestGormTaskStore_ListTasks_FiltersAndPages(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormTaskStore(db)
+	rawSqlDB, errDB := db.DB()
+	require.NoError(t, errDB)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	due := time.Date(2030, 5, 1, 9, 0, 0, 0, time.UTC)
+	columns := []string{"id", "user_id", "garden_id", "description", "due_date", "status"}
+	firstPage := sqlmock.NewRows(columns).
+		AddRow("t1", testUserID, "g1", "Water", due, models.TaskStatusCompleted).
+		AddRow("t2", testUserID, "g1", "Weed", due, models.TaskStatusCompleted).
+		AddRow("t3", testUserID, "g1", "Mulch", due.Add(time.Hour), models.TaskStatusCompleted)
+
+	// The status filter matches the status a read reports, not the stored one
+	sql := regexp.QuoteMeta(`SELECT * FROM "tasks" WHERE user_id = $1 AND garden_id = $2 AND `) + currentStatusIn(15) +
+		regexp.QuoteMeta(` AND "tasks"."deleted_at" IS NULL ORDER BY due_date ASC,id ASC LIMIT $16`)
+	args := append([]driver.Value{testUserID, "g1"}, currentStatusArgs...)
+	mock.ExpectQuery(sql).
+		WithArgs(append(args, models.TaskStatusCompleted, 3)...).
+		WillReturnRows(firstPage)
+
+	q := storage.ListQuery{
+		Filters: map[string]string{"garden_id": "g1", "status": models.TaskStatusCompleted},
+		Limit:   2,
+	}
+	page, err := store.ListTasks(context.Background(), testUserID, q)
+	require.NoError(t, err)
+	require.Len(t, page.Items, 2)
+	assert.Equal(t, "t2", page.Items[1].ID)
+	require.NotEmpty(t, page.NextCursor)
+
+	sql = regexp.QuoteMeta(`SELECT * FROM "tasks" WHERE user_id = $1 AND garden_id = $2 AND `) + currentStatusIn(15) +
+		regexp.QuoteMeta(` AND (due_date > $16 OR (due_date = $17 AND id > $18)) AND "tasks"."deleted_at" IS NULL ORDER BY due_date ASC,id ASC LIMIT $19`)
+	mock.ExpectQuery(sql).
+		WithArgs(append(args, models.TaskStatusCompleted, due, due, "t2", 3)...).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("t3", testUserID, "g1", "Mulch", due.Add(time.Hour), models.TaskStatusCompleted))
+
+	q.Cursor = page.NextCursor
+	page, err = store.ListTasks(context.Background(), testUserID, q)
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, "t3", page.Items[0].ID)
+	assert.Empty(t, page.NextCursor)
+}
+
+func TestGormTaskStore_ListTasks_SortDescendingWithDueRange(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormTaskStore(db)
+	rawSqlDB, errDB := db.DB()
+	require.NoError(t, errDB)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	from := time.Date(2030, 5, 1, 0, 0, 0, 0, time.UTC)
+	sql := `SELECT * FROM "tasks" WHERE user_id = $1 AND due_date >= $2 AND due_date < $3 AND "tasks"."deleted_at" IS NULL ORDER BY description DESC,id DESC LIMIT $4`
+	mock.ExpectQuery(regexp.QuoteMeta(sql)).
+		WithArgs(testUserID, from, from.AddDate(0, 0, 7), storage.DefaultPageSize+1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	page, err := store.ListTasks(context.Background(), testUserID, storage.ListQuery{
+		Filters: map[string]string{"due_from": "2030-05-01", "due_to": "2030-05-07"},
+		Sort:    "-description",
+	})
+	require.NoError(t, err)
+	assert.Empty(t, page.Items)
+	assert.Empty(t, page.NextCursor)
+}
+
+func TestGormTaskStore_ListTasks_InvalidQueries(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormTaskStore(db)
+	rawSqlDB, errDB := db.DB()
+	require.NoError(t, errDB)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	tests := []struct {
+		name string
+		q    storage.ListQuery
+		err  error
+	}{
+		{"unknown filter", storage.ListQuery{Filters: map[string]string{"colour": "red"}}, storage.ErrInvalidQuery},
+		{"unknown sort", storage.ListQuery{Sort: "user_id"}, storage.ErrInvalidQuery},
+		{"limit too large", storage.ListQuery{Limit: storage.MaxPageSize + 1}, storage.ErrInvalidQuery},
+		{"malformed cursor", storage.ListQuery{Cursor: "not-a-cursor"}, storage.ErrInvalidQuery},
+		{"malformed date", storage.ListQuery{Filters: map[string]string{"due_from": "May 1"}}, storage.ErrValidation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := store.ListTasks(context.Background(), testUserID, tt.q)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestGormTaskStore_ListTasks_CursorFromOtherSort(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormTaskStore(db)
+	rawSqlDB, errDB := db.DB()
+	require.NoError(t, errDB)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	due := time.Date(2030, 5, 1, 9, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "tasks"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "due_date", "status"}).
+			AddRow("t1", due, models.TaskStatusCompleted).
+			AddRow("t2", due, models.TaskStatusCompleted))
+
+	page, err := store.ListTasks(context.Background(), testUserID, storage.ListQuery{Limit: 1})
+	require.NoError(t, err)
+	require.NotEmpty(t, page.NextCursor)
+
+	_, err = store.ListTasks(context.Background(), testUserID, storage.ListQuery{Limit: 1, Sort: "-due_date", Cursor: page.NextCursor})
+	assert.Equal(t, storage.ErrInvalidQuery, err)
+}
+
+func TestGormGardenStore_GetGardensByQuery_HonorsSize(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormGardenStore(db)
+	rawSqlDB, errDB := db.DB()
+	require.NoError(t, errDB)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	sql := `SELECT * FROM "gardens" WHERE user_id = $1 AND created_at >= $2 AND "gardens"."deleted_at" IS NULL ORDER BY created_at ASC,id ASC LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sql)).
+		WithArgs(testUserID, start, 6).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow("g1", "Backyard"))
+
+	gardens, err := store.GetGardensByQuery(context.Background(), testUserID, map[string]string{
+		"createdStart": start.Format(time.RFC3339),
+		"size":         "5",
+	})
+	require.NoError(t, err)
+	assert.Len(t, gardens, 1)
+
+	_, err = store.GetGardensByQuery(context.Background(), testUserID, map[string]string{"size": "0"})
+	assert.Equal(t, storage.ErrValidation, err)
+}
@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zjpiazza/plantastic/internal/models"
+	"gorm.io/gorm"
+)
+
+// PlantStorer defines the interface for plant catalog operations.
+// Each user has their own catalog.
+type PlantStorer interface {
+	GetAllPlants(ctx context.Context, userID string) ([]models.Plant, error)
+	ListPlants(ctx context.Context, userID string, q ListQuery) (Page[models.Plant], error)
+	GetPlantByID(ctx context.Context, userID, plantID string) (models.Plant, error)
+	CreatePlant(ctx context.Context, userID string, plant *models.Plant) error
+	UpdatePlant(ctx context.Context, userID string, plant *models.Plant) error
+	DeletePlant(ctx context.Context, userID, plantID string) error
+}
+
+// GormPlantStore implements PlantStorer using GORM.
+type GormPlantStore struct {
+	db *gorm.DB
+}
+
+// NewGormPlantStore creates a new GormPlantStore.
+func NewGormPlantStore(db *gorm.DB) PlantStorer {
+	return &GormPlantStore{db: db}
+}
+
+func (s *GormPlantStore) GetAllPlants(ctx context.Context, userID string) ([]models.Plant, error) {
+	var plants []models.Plant
+	result := s.db.WithContext(ctx).Where("user_id = ?", userID).Find(&plants)
+	if result.Error != nil {
+		return nil, ParseDatabaseError(result.Error)
+	}
+	return plants, nil
+}
+
+// plantListSpec filters the plant catalog by name (substring) and sun requirement.
+var plantListSpec = listSpec[models.Plant]{
+	filters: map[string]filterFunc{
+		"name": containsFilter("name"),
+		"sun":  equalsFilter("sun"),
+	},
+	sorts: map[string]sortField[models.Plant]{
+		"name":       {column: "name", value: func(p models.Plant) any { return p.Name }},
+		"species":    {column: "species", value: func(p models.Plant) any { return p.Species }},
+		"created_at": {column: "created_at", value: func(p models.Plant) any { return p.CreatedAt }, isTime: true},
+		"updated_at": {column: "updated_at", value: func(p models.Plant) any { return p.UpdatedAt }, isTime: true},
+	},
+	defaultSort: "name",
+	id:          func(p models.Plant) string { return p.ID },
+}
+
+func (s *GormPlantStore) ListPlants(ctx context.Context, userID string, q ListQuery) (Page[models.Plant], error) {
+	return paginate(s.db.WithContext(ctx).Where("user_id = ?", userID), plantListSpec, q)
+}
+
+func (s *GormPlantStore) GetPlantByID(ctx context.Context, userID, plantID string) (models.Plant, error) {
+	var plant models.Plant
+	result := s.db.WithContext(ctx).Where("id = ? AND user_id = ?", plantID, userID).First(&plant)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return models.Plant{}, ErrRecordNotFound
+		}
+		return models.Plant{}, ParseDatabaseError(result.Error)
+	}
+	return plant, nil
+}
+
+// validatePlant checks the fields shared by create and update.
+func validatePlant(plant *models.Plant) error {
+	var verr ValidationError
+	if plant.Name == "" {
+		verr.add("name", "is required")
+	}
+	if plant.DaysToMaturity < 0 {
+		verr.add("days_to_maturity", "must not be negative")
+	}
+	return verr.err()
+}
+
+func (s *GormPlantStore) CreatePlant(ctx context.Context, userID string, plant *models.Plant) error {
+	if userID == "" {
+		return ErrValidation
+	}
+	if err := validatePlant(plant); err != nil {
+		return err
+	}
+	plant.UserID = userID
+	plant.ID = uuid.New().String() // IDs are never taken from the caller
+
+	result := s.db.WithContext(ctx).Create(plant)
+	if result.Error != nil {
+		return ParseDatabaseError(result.Error)
+	}
+	return nil
+}
+
+func (s *GormPlantStore) UpdatePlant(ctx context.Context, userID string, plant *models.Plant) error {
+	if plant.ID == "" { // ID must be present for an update
+		return ErrValidation
+	}
+	if err := validatePlant(plant); err != nil {
+		return err
+	}
+
+	updateFields := map[string]interface{}{
+		"name":             plant.Name,
+		"species":          plant.Species,
+		"variety":          plant.Variety,
+		"days_to_maturity": plant.DaysToMaturity,
+		"spacing":          plant.Spacing,
+		"sun":              plant.Sun,
+		"notes":            plant.Notes,
+		"updated_at":       time.Now(),
+	}
+
+	result := s.db.WithContext(ctx).Model(&models.Plant{}).Where("id = ? AND user_id = ?", plant.ID, userID).Updates(updateFields)
+	if result.Error != nil {
+		return ParseDatabaseError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+// DeletePlant removes a catalog entry. Plants still placed in a bed cannot be
+// deleted; their plantings must be removed first.
+func (s *GormPlantStore) DeletePlant(ctx context.Context, userID, plantID string) error {
+	db := s.db.WithContext(ctx)
+	var plantings int64
+	if err := db.Model(&models.Planting{}).Where("plant_id = ? AND user_id = ?", plantID, userID).Count(&plantings).Error; err != nil {
+		return ParseDatabaseError(err)
+	}
+	if plantings > 0 {
+		return ErrConflict
+	}
+
+	result := db.Where("id = ? AND user_id = ?", plantID, userID).Delete(&models.Plant{})
+	if result.Error != nil {
+		return ParseDatabaseError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
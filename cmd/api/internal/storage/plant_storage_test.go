@@ -0,0 +1,165 @@
+package storage_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zjpiazza/plantastic/cmd/api/internal/storage"
+	"github.com/zjpiazza/plantastic/internal/models"
+	"gorm.io/gorm"
+)
+
+var plantColumns = []string{"id", "user_id", "name", "species", "variety", "days_to_maturity", "spacing", "sun", "notes", "created_at", "updated_at"}
+
+func TestGormPlantStore_GetAllPlants_Success(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormPlantStore(db)
+	rawSqlDB, errDB := db.DB()
+	require.NoError(t, errDB)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	now := time.Now()
+	expectedPlants := []models.Plant{
+		{ID: "p1", UserID: testUserID, Name: "Tomato", Species: "Solanum lycopersicum", Variety: "Cherokee Purple", DaysToMaturity: 80, Spacing: "24in", Sun: "Full sun", CreatedAt: now, UpdatedAt: now},
+		{ID: "p2", UserID: testUserID, Name: "Basil", Variety: "Genovese", DaysToMaturity: 60, CreatedAt: now, UpdatedAt: now},
+	}
+
+	rows := sqlmock.NewRows(plantColumns)
+	for _, p := range expectedPlants {
+		rows.AddRow(p.ID, p.UserID, p.Name, p.Species, p.Variety, p.DaysToMaturity, p.Spacing, p.Sun, p.Notes, p.CreatedAt, p.UpdatedAt)
+	}
+
+	sql := `SELECT * FROM "plants" WHERE user_id = $1`
+	mock.ExpectQuery(regexp.QuoteMeta(sql)).WithArgs(testUserID).WillReturnRows(rows)
+
+	actualPlants, err := store.GetAllPlants(context.Background(), testUserID)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, expectedPlants, actualPlants)
+}
+
+func TestGormPlantStore_GetPlantByID_NotFound(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormPlantStore(db)
+	rawSqlDB, errDB := db.DB()
+	require.NoError(t, errDB)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	sql := `SELECT * FROM "plants" WHERE id = $1 AND user_id = $2 ORDER BY "plants"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sql)).WithArgs("p_other", testUserID, 1).WillReturnError(gorm.ErrRecordNotFound)
+
+	_, err := store.GetPlantByID(context.Background(), testUserID, "p_other")
+	assert.ErrorIs(t, err, storage.ErrRecordNotFound)
+}
+
+func TestGormPlantStore_CreatePlant_Success(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormPlantStore(db)
+	rawSqlDB, errDB := db.DB()
+	require.NoError(t, errDB)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	plant := &models.Plant{ID: "p_new", Name: "Pepper", Variety: "Jalapeño", DaysToMaturity: 70}
+
+	mock.ExpectBegin()
+	sql := `INSERT INTO "plants" ("id","user_id","name","species","variety","days_to_maturity","spacing","sun","notes","created_at","updated_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)`
+	mock.ExpectExec(regexp.QuoteMeta(sql)).
+		WithArgs(sqlmock.AnyArg(), testUserID, plant.Name, "", plant.Variety, plant.DaysToMaturity, "", "", "", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err := store.CreatePlant(context.Background(), testUserID, plant)
+	assert.NoError(t, err)
+	assert.Equal(t, testUserID, plant.UserID)
+}
+
+func TestGormPlantStore_CreatePlant_ValidationError(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormPlantStore(db)
+	rawSqlDB, errDB := db.DB()
+	require.NoError(t, errDB)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	assert.ErrorIs(t, store.CreatePlant(context.Background(), testUserID, &models.Plant{Name: ""}), storage.ErrValidation)
+	assert.ErrorIs(t, store.CreatePlant(context.Background(), testUserID, &models.Plant{Name: "Kale", DaysToMaturity: -1}), storage.ErrValidation)
+}
+
+func TestGormPlantStore_UpdatePlant_NotFound(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormPlantStore(db)
+	rawSqlDB, errDB := db.DB()
+	require.NoError(t, errDB)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	plant := &models.Plant{ID: "p_other", Name: "Kale"}
+
+	mock.ExpectBegin()
+	sql := `UPDATE "plants" SET "days_to_maturity"=$1,"name"=$2,"notes"=$3,"spacing"=$4,"species"=$5,"sun"=$6,"updated_at"=$7,"variety"=$8 WHERE id = $9 AND user_id = $10`
+	mock.ExpectExec(regexp.QuoteMeta(sql)).
+		WithArgs(0, plant.Name, "", "", "", "", sqlmock.AnyArg(), "", plant.ID, testUserID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	err := store.UpdatePlant(context.Background(), testUserID, plant)
+	assert.ErrorIs(t, err, storage.ErrRecordNotFound)
+}
+
+func TestGormPlantStore_DeletePlant_Success(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormPlantStore(db)
+	rawSqlDB, errDB := db.DB()
+	require.NoError(t, errDB)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	countSQL := `SELECT count(*) FROM "plantings" WHERE plant_id = $1 AND user_id = $2`
+	mock.ExpectQuery(regexp.QuoteMeta(countSQL)).WithArgs("p1", testUserID).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	mock.ExpectBegin()
+	deleteSQL := `DELETE FROM "plants" WHERE id = $1 AND user_id = $2`
+	mock.ExpectExec(regexp.QuoteMeta(deleteSQL)).WithArgs("p1", testUserID).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := store.DeletePlant(context.Background(), testUserID, "p1")
+	assert.NoError(t, err)
+}
+
+func TestGormPlantStore_DeletePlant_StillPlanted(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormPlantStore(db)
+	rawSqlDB, errDB := db.DB()
+	require.NoError(t, errDB)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	countSQL := `SELECT count(*) FROM "plantings" WHERE plant_id = $1 AND user_id = $2`
+	mock.ExpectQuery(regexp.QuoteMeta(countSQL)).WithArgs("p1", testUserID).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+	err := store.DeletePlant(context.Background(), testUserID, "p1")
+	assert.ErrorIs(t, err, storage.ErrConflict)
+}
+
+func TestGormPlantStore_DeletePlant_DBError(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormPlantStore(db)
+	rawSqlDB, errDB := db.DB()
+	require.NoError(t, errDB)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	countSQL := `SELECT count(*) FROM "plantings" WHERE plant_id = $1 AND user_id = $2`
+	mock.ExpectQuery(regexp.QuoteMeta(countSQL)).WithArgs("p1", testUserID).WillReturnError(errors.New("connection lost"))
+
+	err := store.DeletePlant(context.Background(), testUserID, "p1")
+	assert.ErrorIs(t, err, storage.ErrDatabase)
+}
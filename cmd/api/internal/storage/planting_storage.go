@@ -0,0 +1,215 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zjpiazza/plantastic/internal/models"
+	"gorm.io/gorm"
+)
+
+// PlantingStorer defines the interface for planting data operations.
+// Plantings inherit their owner and garden from the parent bed, and are out
+// of sight while it is in the trash.
+type PlantingStorer interface {
+	GetAllPlantings(ctx context.Context, userID string) ([]models.Planting, error)
+	ListPlantings(ctx context.Context, userID string, q ListQuery) (Page[models.Planting], error)
+	GetPlantingByID(ctx context.Context, userID, plantingID string) (models.Planting, error)
+	CreatePlanting(ctx context.Context, userID string, planting *models.Planting) error
+	UpdatePlanting(ctx context.Context, userID string, planting *models.Planting) error
+	DeletePlanting(ctx context.Context, userID, plantingID string) error
+	GetPlantingsByBedID(ctx context.Context, userID, bedID string) ([]models.Planting, error)
+}
+
+// GormPlantingStore implements PlantingStorer using GORM.
+type GormPlantingStore struct {
+	db *gorm.DB
+}
+
+// NewGormPlantingStore creates a new GormPlantingStore.
+func NewGormPlantingStore(db *gorm.DB) PlantingStorer {
+	return &GormPlantingStore{db: db}
+}
+
+// inLiveBed limits a plantings query to those whose bed is not in the trash.
+// A garden's beds go to the trash with it, so this also leaves out the
+// plantings in a trashed garden.
+func inLiveBed(db *gorm.DB) *gorm.DB {
+	return db.Where("bed_id IN (?)", db.Session(&gorm.Session{NewDB: true}).Model(&models.Bed{}).Select("id"))
+}
+
+func (s *GormPlantingStore) GetAllPlantings(ctx context.Context, userID string) ([]models.Planting, error) {
+	var plantings []models.Planting
+	result := s.db.WithContext(ctx).Scopes(inLiveBed).Where("user_id = ?", userID).Find(&plantings)
+	if result.Error != nil {
+		return nil, ParseDatabaseError(result.Error)
+	}
+	return plantings, nil
+}
+
+// plantingListSpec filters plantings by location, plant and status. status
+// accepts a comma-separated list.
+var plantingListSpec = listSpec[models.Planting]{
+	filters: map[string]filterFunc{
+		"garden_id": equalsFilter("garden_id"),
+		"bed_id":    equalsFilter("bed_id"),
+		"plant_id":  equalsFilter("plant_id"),
+		"status":    oneOfFilter("status"),
+	},
+	sorts: map[string]sortField[models.Planting]{
+		"status":     {column: "status", value: func(p models.Planting) any { return p.Status }},
+		"created_at": {column: "created_at", value: func(p models.Planting) any { return p.CreatedAt }, isTime: true},
+		"updated_at": {column: "updated_at", value: func(p models.Planting) any { return p.UpdatedAt }, isTime: true},
+	},
+	defaultSort: "created_at",
+	id:          func(p models.Planting) string { return p.ID },
+}
+
+func (s *GormPlantingStore) ListPlantings(ctx context.Context, userID string, q ListQuery) (Page[models.Planting], error) {
+	return paginate(s.db.WithContext(ctx).Scopes(inLiveBed).Where("user_id = ?", userID), plantingListSpec, q)
+}
+
+func (s *GormPlantingStore) GetPlantingByID(ctx context.Context, userID, plantingID string) (models.Planting, error) {
+	var planting models.Planting
+	result := s.db.WithContext(ctx).Scopes(inLiveBed).Where("id = ? AND user_id = ?", plantingID, userID).First(&planting)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return models.Planting{}, ErrRecordNotFound
+		}
+		return models.Planting{}, ParseDatabaseError(result.Error)
+	}
+	return planting, nil
+}
+
+// normalizePlanting fills in defaults and validates the fields shared by
+// create and update.
+func normalizePlanting(planting *models.Planting) error {
+	if planting.Status == "" {
+		planting.Status = models.PlantingStatusPlanned
+	}
+	if planting.Quantity == 0 {
+		planting.Quantity = 1
+	}
+	var verr ValidationError
+	if planting.BedID == "" {
+		verr.add("bed_id", "is required")
+	}
+	if planting.PlantID == "" {
+		verr.add("plant_id", "is required")
+	}
+	if planting.Quantity < 0 {
+		verr.add("quantity", "must not be negative")
+	}
+	if !models.IsValidPlantingStatus(planting.Status) {
+		verr.add("status", "must be one of "+strings.Join(models.PlantingStatuses, ", "))
+	}
+	return verr.err()
+}
+
+// resolvePlantingRefs checks that the planting's bed and plant belong to the
+// caller and copies the bed's garden onto the planting.
+func (s *GormPlantingStore) resolvePlantingRefs(ctx context.Context, userID string, planting *models.Planting) error {
+	db := s.db.WithContext(ctx)
+	var bed models.Bed
+	if err := db.First(&bed, "id = ? AND user_id = ?", planting.BedID, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return invalidField("bed_id", "bed does not exist")
+		}
+		return ParseDatabaseError(err)
+	}
+	var plant models.Plant
+	if err := db.First(&plant, "id = ? AND user_id = ?", planting.PlantID, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return invalidField("plant_id", "plant is not in your catalog")
+		}
+		return ParseDatabaseError(err)
+	}
+	planting.GardenID = bed.GardenID
+	return nil
+}
+
+func (s *GormPlantingStore) CreatePlanting(ctx context.Context, userID string, planting *models.Planting) error {
+	if userID == "" {
+		return ErrValidation
+	}
+	if err := normalizePlanting(planting); err != nil {
+		return err
+	}
+	if err := s.resolvePlantingRefs(ctx, userID, planting); err != nil {
+		return err
+	}
+	planting.UserID = userID
+	planting.ID = uuid.New().String() // IDs are never taken from the caller
+
+	result := s.db.WithContext(ctx).Create(planting)
+	if result.Error != nil {
+		return ParseDatabaseError(result.Error)
+	}
+	return nil
+}
+
+func (s *GormPlantingStore) UpdatePlanting(ctx context.Context, userID string, planting *models.Planting) error {
+	db := s.db.WithContext(ctx)
+	if planting.ID == "" { // ID must be present for an update
+		return ErrValidation
+	}
+	if err := normalizePlanting(planting); err != nil {
+		return err
+	}
+
+	// Check if the planting to be updated actually exists, outside the trash
+	var existingPlanting models.Planting
+	if err := db.Scopes(inLiveBed).First(&existingPlanting, "id = ? AND user_id = ?", planting.ID, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRecordNotFound
+		}
+		return ParseDatabaseError(err)
+	}
+	if err := s.resolvePlantingRefs(ctx, userID, planting); err != nil {
+		return err
+	}
+
+	updateFields := map[string]interface{}{
+		"garden_id":       planting.GardenID,
+		"bed_id":          planting.BedID,
+		"plant_id":        planting.PlantID,
+		"quantity":        planting.Quantity,
+		"sow_date":        planting.SowDate,
+		"transplant_date": planting.TransplantDate,
+		"status":          planting.Status,
+		"notes":           planting.Notes,
+		"updated_at":      time.Now(),
+	}
+
+	result := db.Model(&models.Planting{}).Where("id = ? AND user_id = ?", planting.ID, userID).Updates(updateFields)
+	if result.Error != nil {
+		return ParseDatabaseError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrRecordNotFound // Should have been caught by First, but safeguard
+	}
+	return nil
+}
+
+func (s *GormPlantingStore) DeletePlanting(ctx context.Context, userID, plantingID string) error {
+	result := s.db.WithContext(ctx).Scopes(inLiveBed).Where("id = ? AND user_id = ?", plantingID, userID).Delete(&models.Planting{})
+	if result.Error != nil {
+		return ParseDatabaseError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+func (s *GormPlantingStore) GetPlantingsByBedID(ctx context.Context, userID, bedID string) ([]models.Planting, error) {
+	var plantings []models.Planting
+	result := s.db.WithContext(ctx).Scopes(inLiveBed).Where("bed_id = ? AND user_id = ?", bedID, userID).Find(&plantings)
+	if result.Error != nil {
+		return nil, ParseDatabaseError(result.Error)
+	}
+	return plantings, nil
+}
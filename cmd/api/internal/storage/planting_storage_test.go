@@ -0,0 +1,146 @@
+package storage_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zjpiazza/plantastic/cmd/api/internal/storage"
+	"github.com/zjpiazza/plantastic/internal/models"
+	"gorm.io/gorm"
+)
+
+var plantingColumns = []string{"id", "user_id", "garden_id", "bed_id", "plant_id", "quantity", "sow_date", "transplant_date", "status", "notes", "created_at", "updated_at"}
+
+const (
+	sqlPlantingBedSelect   = `SELECT * FROM "beds" WHERE (id = $1 AND user_id = $2) AND "beds"."deleted_at" IS NULL ORDER BY "beds"."id" LIMIT $3`
+	sqlPlantingPlantSelect = `SELECT * FROM "plants" WHERE id = $1 AND user_id = $2 ORDER BY "plants"."id" LIMIT $3`
+	sqlPlantingInLiveBed   = ` AND bed_id IN (SELECT "id" FROM "beds" WHERE "beds"."deleted_at" IS NULL)`
+)
+
+func TestGormPlantingStore_GetPlantingsByBedID_Success(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormPlantingStore(db)
+	rawSqlDB, errDB := db.DB()
+	require.NoError(t, errDB)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	now := time.Now()
+	sown := now.AddDate(0, 0, -30)
+	expectedPlantings := []models.Planting{
+		{ID: "pl1", UserID: testUserID, GardenID: "g1", BedID: "b1", PlantID: "p1", Quantity: 6, SowDate: &sown, Status: models.PlantingStatusGrowing, CreatedAt: now, UpdatedAt: now},
+		{ID: "pl2", UserID: testUserID, GardenID: "g1", BedID: "b1", PlantID: "p2", Quantity: 1, Status: models.PlantingStatusPlanned, CreatedAt: now, UpdatedAt: now},
+	}
+
+	rows := sqlmock.NewRows(plantingColumns)
+	for _, p := range expectedPlantings {
+		rows.AddRow(p.ID, p.UserID, p.GardenID, p.BedID, p.PlantID, p.Quantity, p.SowDate, p.TransplantDate, p.Status, p.Notes, p.CreatedAt, p.UpdatedAt)
+	}
+
+	sql := `SELECT * FROM "plantings" WHERE (bed_id = $1 AND user_id = $2)` + sqlPlantingInLiveBed
+	mock.ExpectQuery(regexp.QuoteMeta(sql)).WithArgs("b1", testUserID).WillReturnRows(rows)
+
+	actualPlantings, err := store.GetPlantingsByBedID(context.Background(), testUserID, "b1")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, expectedPlantings, actualPlantings)
+}
+
+func TestGormPlantingStore_CreatePlanting_Success(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormPlantingStore(db)
+	rawSqlDB, errDB := db.DB()
+	require.NoError(t, errDB)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	planting := &models.Planting{ID: "pl_new", BedID: "b1", PlantID: "p1"}
+
+	mock.ExpectQuery(regexp.QuoteMeta(sqlPlantingBedSelect)).WithArgs("b1", testUserID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "garden_id"}).AddRow("b1", "g1"))
+	mock.ExpectQuery(regexp.QuoteMeta(sqlPlantingPlantSelect)).WithArgs("p1", testUserID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("p1"))
+
+	mock.ExpectBegin()
+	sql := `INSERT INTO "plantings" ("id","user_id","garden_id","bed_id","plant_id","quantity","sow_date","transplant_date","status","notes","created_at","updated_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)`
+	mock.ExpectExec(regexp.QuoteMeta(sql)).
+		WithArgs(sqlmock.AnyArg(), testUserID, "g1", "b1", "p1", 1, nil, nil, models.PlantingStatusPlanned, "", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err := store.CreatePlanting(context.Background(), testUserID, planting)
+	assert.NoError(t, err)
+	assert.Equal(t, "g1", planting.GardenID, "garden is inherited from the bed")
+	assert.Equal(t, 1, planting.Quantity)
+	assert.Equal(t, models.PlantingStatusPlanned, planting.Status)
+}
+
+func TestGormPlantingStore_CreatePlanting_PlantNotInCatalog(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormPlantingStore(db)
+	rawSqlDB, errDB := db.DB()
+	require.NoError(t, errDB)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	planting := &models.Planting{BedID: "b1", PlantID: "p_other_user"}
+
+	mock.ExpectQuery(regexp.QuoteMeta(sqlPlantingBedSelect)).WithArgs("b1", testUserID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "garden_id"}).AddRow("b1", "g1"))
+	mock.ExpectQuery(regexp.QuoteMeta(sqlPlantingPlantSelect)).WithArgs("p_other_user", testUserID, 1).
+		WillReturnError(gorm.ErrRecordNotFound)
+
+	err := store.CreatePlanting(context.Background(), testUserID, planting)
+	assert.ErrorIs(t, err, storage.ErrValidation)
+}
+
+func TestGormPlantingStore_CreatePlanting_ValidationError(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormPlantingStore(db)
+	rawSqlDB, errDB := db.DB()
+	require.NoError(t, errDB)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	assert.ErrorIs(t, store.CreatePlanting(context.Background(), testUserID, &models.Planting{PlantID: "p1"}), storage.ErrValidation)
+	assert.ErrorIs(t, store.CreatePlanting(context.Background(), testUserID, &models.Planting{BedID: "b1", PlantID: "p1", Quantity: -2}), storage.ErrValidation)
+	assert.ErrorIs(t, store.CreatePlanting(context.Background(), testUserID, &models.Planting{BedID: "b1", PlantID: "p1", Status: "Wilted"}), storage.ErrValidation)
+}
+
+func TestGormPlantingStore_UpdatePlanting_NotFound(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormPlantingStore(db)
+	rawSqlDB, errDB := db.DB()
+	require.NoError(t, errDB)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	planting := &models.Planting{ID: "pl_missing", BedID: "b1", PlantID: "p1"}
+
+	sql := `SELECT * FROM "plantings" WHERE (id = $1 AND user_id = $2)` + sqlPlantingInLiveBed + ` ORDER BY "plantings"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sql)).WithArgs(planting.ID, testUserID, 1).WillReturnError(gorm.ErrRecordNotFound)
+
+	err := store.UpdatePlanting(context.Background(), testUserID, planting)
+	assert.ErrorIs(t, err, storage.ErrRecordNotFound)
+}
+
+func TestGormPlantingStore_DeletePlanting_NotFound(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormPlantingStore(db)
+	rawSqlDB, errDB := db.DB()
+	require.NoError(t, errDB)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	mock.ExpectBegin()
+	sql := `DELETE FROM "plantings" WHERE (id = $1 AND user_id = $2)` + sqlPlantingInLiveBed
+	mock.ExpectExec(regexp.QuoteMeta(sql)).WithArgs("pl1", testUserID).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	err := store.DeletePlanting(context.Background(), testUserID, "pl1")
+	assert.ErrorIs(t, err, storage.ErrRecordNotFound)
+}
@@ -0,0 +1,603 @@
+package storage_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zjpiazza/plantastic/cmd/api/internal/storage"
+	"github.com/zjpiazza/plantastic/internal/auth"
+	"github.com/zjpiazza/plantastic/internal/database"
+	"github.com/zjpiazza/plantastic/internal/device"
+	"github.com/zjpiazza/plantastic/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// The tests below run the stores against a real, in-memory SQLite database
+// rather than sqlmock expectations, so they check behaviour instead of the
+// exact SQL.
+
+// newSQLiteDB returns a migrated in-memory database that is closed when the
+// test ends. Like the API's, it records changes in the audit log.
+func newSQLiteDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := database.Open(database.Config{Driver: database.DriverSQLite, DSN: database.Memory}, &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+	_, err = database.MigrateUp(db)
+	require.NoError(t, err)
+	require.NoError(t, db.Use(storage.AuditLog{}))
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// createSQLiteGarden stores a garden named name for testUserID.
+func createSQLiteGarden(t *testing.T, store storage.GardenStorer, name string) models.Garden {
+	t.Helper()
+	garden := models.Garden{Name: name}
+	require.NoError(t, store.CreateGarden(context.Background(), testUserID, &garden))
+	return garden
+}
+
+func TestSQLite_GardenLifecycle(t *testing.T) {
+	store := storage.NewGormGardenStore(newSQLiteDB(t))
+
+	garden := createSQLiteGarden(t, store, "Backyard")
+	require.NotEmpty(t, garden.ID)
+	assert.Equal(t, 1, garden.Version)
+
+	got, err := store.GetGardenByID(context.Background(), testUserID, garden.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Backyard", got.Name)
+
+	// Other users cannot see it
+	_, err = store.GetGardenByID(context.Background(), "someone_else", garden.ID)
+	assert.ErrorIs(t, err, storage.ErrRecordNotFound)
+
+	got.Name = "Back garden"
+	require.NoError(t, store.UpdateGarden(context.Background(), testUserID, &got))
+	assert.Equal(t, 2, got.Version)
+
+	// A write based on the version read before the update is refused
+	stale := garden
+	stale.Name = "Stale"
+	assert.ErrorIs(t, store.UpdateGarden(context.Background(), testUserID, &stale), storage.ErrVersionConflict)
+	assert.ErrorIs(t, store.DeleteGarden(context.Background(), testUserID, garden.ID, 1), storage.ErrVersionConflict)
+
+	require.NoError(t, store.DeleteGarden(context.Background(), testUserID, garden.ID, 2))
+	_, err = store.GetGardenByID(context.Background(), testUserID, garden.ID)
+	assert.ErrorIs(t, err, storage.ErrRecordNotFound)
+}
+
+func TestSQLite_CreateGeneratesIDs(t *testing.T) {
+	db := newSQLiteDB(t)
+	ctx := context.Background()
+	garden := createSQLiteGarden(t, storage.NewGormGardenStore(db), "Backyard")
+	bed := models.Bed{GardenID: garden.ID, Name: "North"}
+	require.NoError(t, storage.NewGormBedStore(db).CreateBed(ctx, testUserID, &bed))
+	plant := models.Plant{Name: "Tomato"}
+	require.NoError(t, storage.NewGormPlantStore(db).CreatePlant(ctx, testUserID, &plant))
+	planting := models.Planting{BedID: bed.ID, PlantID: plant.ID}
+	require.NoError(t, storage.NewGormPlantingStore(db).CreatePlanting(ctx, testUserID, &planting))
+
+	ids := map[string]bool{}
+	for _, id := range []string{garden.ID, bed.ID, plant.ID, planting.ID} {
+		_, err := uuid.Parse(id)
+		assert.NoError(t, err, "id %q", id)
+		ids[id] = true
+	}
+	assert.Len(t, ids, 4)
+
+	// An ID the caller chose is replaced, so callers cannot probe for IDs
+	// other users have
+	chosen := models.Garden{ID: garden.ID, Name: "Allotment"}
+	require.NoError(t, storage.NewGormGardenStore(db).CreateGarden(ctx, "someone_else", &chosen))
+	assert.NotEqual(t, garden.ID, chosen.ID)
+}
+
+func TestSQLite_ListGardens_PagesAndFilters(t *testing.T) {
+	store := storage.NewGormGardenStore(newSQLiteDB(t))
+	for _, name := range []string{"Carrot patch", "Allotment", "Balcony", "Rooftop", "Backyard"} {
+		createSQLiteGarden(t, store, name)
+	}
+
+	var names []string
+	q := storage.ListQuery{Sort: "name", Limit: 2}
+	for {
+		page, err := store.ListGardens(context.Background(), testUserID, q)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(page.Items), 2)
+		for _, g := range page.Items {
+			names = append(names, g.Name)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		q.Cursor = page.NextCursor
+	}
+	assert.Equal(t, []string{"Allotment", "Backyard", "Balcony", "Carrot patch", "Rooftop"}, names)
+
+	page, err := store.ListGardens(context.Background(), testUserID, storage.ListQuery{Filters: map[string]string{"name": "ba"}, Sort: "-name"})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 2)
+	assert.Equal(t, "Balcony", page.Items[0].Name)
+	assert.Equal(t, "Backyard", page.Items[1].Name)
+}
+
+func TestSQLite_RecurringTask_CompletingSchedulesNext(t *testing.T) {
+	db := newSQLiteDB(t)
+	garden := createSQLiteGarden(t, storage.NewGormGardenStore(db), "Backyard")
+	store := storage.NewGormTaskStore(db)
+
+	due := time.Now().Add(48 * time.Hour).Truncate(time.Second)
+	task := models.Task{GardenID: garden.ID, Description: "Water", DueDate: due, Priority: models.PriorityLow, Recurrence: "every 2 days"}
+	require.NoError(t, store.CreateTask(context.Background(), testUserID, &task))
+	require.NotNil(t, task.SeriesID)
+
+	task.Status = models.TaskStatusCompleted
+	require.NoError(t, store.UpdateTask(context.Background(), testUserID, &task))
+
+	page, err := store.ListTasks(context.Background(), testUserID, storage.ListQuery{Filters: map[string]string{"series_id": *task.SeriesID}, Sort: "due_date"})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 2)
+	assert.Equal(t, models.TaskStatusCompleted, page.Items[0].Status)
+	assert.Equal(t, models.TaskStatusPending, page.Items[1].Status)
+	assert.True(t, due.AddDate(0, 0, 2).Equal(page.Items[1].DueDate), "next due %s", page.Items[1].DueDate)
+}
+
+func TestSQLite_UpdateTask_MovingGardenChecksItsBed(t *testing.T) {
+	db := newSQLiteDB(t)
+	gardens := storage.NewGormGardenStore(db)
+	from := createSQLiteGarden(t, gardens, "Backyard")
+	to := createSQLiteGarden(t, gardens, "Allotment")
+	bed := models.Bed{GardenID: from.ID, Name: "North"}
+	require.NoError(t, storage.NewGormBedStore(db).CreateBed(context.Background(), testUserID, &bed))
+	store := storage.NewGormTaskStore(db)
+
+	task := models.Task{GardenID: from.ID, BedID: &bed.ID, Description: "Weed", DueDate: time.Now().Add(24 * time.Hour), Status: models.TaskStatusPending, Priority: models.PriorityLow}
+	require.NoError(t, store.CreateTask(context.Background(), testUserID, &task))
+
+	// Only the garden changes; the task keeps a bed of the old one
+	task.GardenID = to.ID
+	err := store.UpdateTask(context.Background(), testUserID, &task)
+	require.ErrorIs(t, err, storage.ErrValidation)
+	assert.Equal(t, []storage.FieldError{{Field: "garden_bed_id", Message: "bed does not exist in this garden"}}, storage.FieldErrors(err))
+
+	got, err := store.GetTaskByID(context.Background(), testUserID, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, from.ID, got.GardenID)
+}
+
+func TestSQLite_BulkTasks_AtomicRollsBack(t *testing.T) {
+	db := newSQLiteDB(t)
+	garden := createSQLiteGarden(t, storage.NewGormGardenStore(db), "Backyard")
+	store := storage.NewGormTaskStore(db)
+
+	task := models.Task{GardenID: garden.ID, Description: "Weed", DueDate: time.Now().Add(24 * time.Hour), Status: models.TaskStatusPending, Priority: models.PriorityLow}
+	require.NoError(t, store.CreateTask(context.Background(), testUserID, &task))
+
+	results, err := store.BulkTasks(context.Background(), testUserID, []storage.BulkTaskOp{
+		{Op: storage.BulkOpComplete, ID: task.ID},
+		{Op: storage.BulkOpDelete, ID: "missing"},
+	}, true)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.ErrorIs(t, results[0].Err, storage.ErrBulkAborted)
+	assert.ErrorIs(t, results[1].Err, storage.ErrRecordNotFound)
+
+	got, err := store.GetTaskByID(context.Background(), testUserID, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TaskStatusPending, got.Status, "the completion must have been rolled back")
+	assert.Equal(t, 1, got.Version)
+}
+
+func TestSQLite_TaskStatusSweep_ComparesTimesAcrossZones(t *testing.T) {
+	db := newSQLiteDB(t)
+	garden := createSQLiteGarden(t, storage.NewGormGardenStore(db), "Backyard")
+	store := storage.NewGormTaskStore(db)
+
+	// Due an hour ago, written with a far-east offset: compared as text
+	// without normalising, it would sort after the sweep's "now"
+	tokyo := time.FixedZone("JST", 9*60*60)
+	require.NoError(t, db.Create(&models.Task{
+		ID: "t1", UserID: testUserID, GardenID: garden.ID, Description: "Harvest",
+		DueDate: time.Now().Add(-time.Hour).In(tokyo), Status: models.TaskStatusPending, Priority: models.PriorityHigh, Version: 1,
+	}).Error)
+
+	stats, err := storage.NewTaskStatusSweeper(db).Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats[storage.StatTasksMarkedOverdue])
+
+	got, err := store.GetTaskByID(context.Background(), testUserID, "t1")
+	require.NoError(t, err)
+	assert.Equal(t, models.TaskStatusOverdue, got.Status)
+}
+
+func TestSQLite_ListTasks_FiltersOnCurrentStatus(t *testing.T) {
+	db := newSQLiteDB(t)
+	ctx := context.Background()
+	garden := createSQLiteGarden(t, storage.NewGormGardenStore(db), "Backyard")
+	store := storage.NewGormTaskStore(db)
+
+	// A Pending task that fell due since the last sweep, and one still ahead
+	stale := models.Task{GardenID: garden.ID, Description: "Water", DueDate: time.Now().Add(time.Hour), Status: models.TaskStatusPending, Priority: models.PriorityLow}
+	require.NoError(t, store.CreateTask(ctx, testUserID, &stale))
+	require.NoError(t, db.Exec("UPDATE tasks SET due_date = ? WHERE id = ?", time.Now().Add(-time.Hour), stale.ID).Error)
+	ahead := models.Task{GardenID: garden.ID, Description: "Weed", DueDate: time.Now().Add(time.Hour), Status: models.TaskStatusPending, Priority: models.PriorityLow}
+	require.NoError(t, store.CreateTask(ctx, testUserID, &ahead))
+
+	page, err := store.ListTasks(ctx, testUserID, storage.ListQuery{Filters: map[string]string{"status": models.TaskStatusOverdue}})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, stale.ID, page.Items[0].ID)
+	assert.Equal(t, models.TaskStatusOverdue, page.Items[0].Status)
+
+	// Reading reports the transition but leaves storing it to the sweeper
+	var stored models.Task
+	require.NoError(t, db.First(&stored, "id = ?", stale.ID).Error)
+	assert.Equal(t, models.TaskStatusPending, stored.Status)
+	assert.Equal(t, 1, stored.Version)
+	events, err := store.GetTaskHistory(ctx, testUserID, stale.ID)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	page, err = store.GetTasksByGardenID(ctx, testUserID, garden.ID, storage.ListQuery{Filters: map[string]string{"status": models.TaskStatusPending}, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, ahead.ID, page.Items[0].ID)
+	assert.Empty(t, page.NextCursor)
+}
+
+func TestSQLite_RescheduledOverdueTask_ReopensAsItWas(t *testing.T) {
+	db := newSQLiteDB(t)
+	ctx := context.Background()
+	garden := createSQLiteGarden(t, storage.NewGormGardenStore(db), "Backyard")
+	store := storage.NewGormTaskStore(db)
+
+	task := models.Task{GardenID: garden.ID, Description: "Prune", DueDate: time.Now().Add(time.Hour), Status: models.TaskStatusInProgress, Priority: models.PriorityLow}
+	require.NoError(t, store.CreateTask(ctx, testUserID, &task))
+	require.NoError(t, db.Exec("UPDATE tasks SET due_date = ? WHERE id = ?", time.Now().Add(-time.Hour), task.ID).Error)
+	got, err := store.GetTaskByID(ctx, testUserID, task.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.TaskStatusOverdue, got.Status)
+
+	results, err := store.BulkTasks(ctx, testUserID, []storage.BulkTaskOp{{Op: storage.BulkOpReschedule, ID: task.ID, ShiftDays: 2}}, false)
+	require.NoError(t, err)
+	require.NoError(t, results[0].Err)
+	assert.Equal(t, models.TaskStatusInProgress, results[0].Task.Status)
+
+	// As does one the sweeper marked Overdue whose due date is moved some other way
+	require.NoError(t, db.Exec("UPDATE tasks SET due_date = ? WHERE id = ?", time.Now().Add(-time.Hour), task.ID).Error)
+	_, err = storage.NewTaskStatusSweeper(db).Run(ctx)
+	require.NoError(t, err)
+	require.NoError(t, db.Exec("UPDATE tasks SET due_date = ? WHERE id = ?", time.Now().Add(time.Hour), task.ID).Error)
+	got, err = store.GetTaskByID(ctx, testUserID, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TaskStatusInProgress, got.Status)
+}
+
+func TestSQLite_DeleteGarden_RemovesItsBedsAndTasks(t *testing.T) {
+	db := newSQLiteDB(t)
+	gardens := storage.NewGormGardenStore(db)
+	garden := createSQLiteGarden(t, gardens, "Backyard")
+	kept := createSQLiteGarden(t, gardens, "Allotment")
+
+	beds := storage.NewGormBedStore(db)
+	bed := models.Bed{GardenID: garden.ID, Name: "North"}
+	require.NoError(t, beds.CreateBed(context.Background(), testUserID, &bed))
+	tasks := storage.NewGormTaskStore(db)
+	for _, gardenID := range []string{garden.ID, kept.ID} {
+		task := models.Task{GardenID: gardenID, Description: "Water", DueDate: time.Now().Add(24 * time.Hour), Status: models.TaskStatusPending, Priority: models.PriorityLow}
+		require.NoError(t, tasks.CreateTask(context.Background(), testUserID, &task))
+	}
+
+	require.NoError(t, gardens.DeleteGarden(context.Background(), testUserID, garden.ID, 0))
+
+	_, err := beds.GetBedByID(context.Background(), testUserID, bed.ID)
+	assert.ErrorIs(t, err, storage.ErrRecordNotFound)
+	page, err := tasks.ListTasks(context.Background(), testUserID, storage.ListQuery{})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, kept.ID, page.Items[0].GardenID)
+}
+
+func TestSQLite_Trash_RestoresGardenWithItsContents(t *testing.T) {
+	db := newSQLiteDB(t)
+	gardens := storage.NewGormGardenStore(db)
+	garden := createSQLiteGarden(t, gardens, "Backyard")
+	beds := storage.NewGormBedStore(db)
+	tasks := storage.NewGormTaskStore(db)
+	trash := storage.NewGormTrashStore(db, storage.DefaultTrashRetention)
+
+	north := models.Bed{GardenID: garden.ID, Name: "North"}
+	south := models.Bed{GardenID: garden.ID, Name: "South"}
+	require.NoError(t, beds.CreateBed(context.Background(), testUserID, &north))
+	require.NoError(t, beds.CreateBed(context.Background(), testUserID, &south))
+	task := models.Task{GardenID: garden.ID, Description: "Water", DueDate: time.Now().Add(24 * time.Hour), Status: models.TaskStatusPending, Priority: models.PriorityLow}
+	require.NoError(t, tasks.CreateTask(context.Background(), testUserID, &task))
+
+	// South was deleted on its own before the garden, so stays in the trash
+	require.NoError(t, beds.DeleteBed(context.Background(), testUserID, south.ID, 0))
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, gardens.DeleteGarden(context.Background(), testUserID, garden.ID, 0))
+
+	// Only the garden is listed: what was in it comes back with it
+	items, err := trash.ListTrash(context.Background(), testUserID, "")
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, storage.TrashGarden, items[0].Type)
+	assert.Equal(t, garden.ID, items[0].ID)
+	assert.Equal(t, items[0].DeletedAt.Add(storage.DefaultTrashRetention), items[0].PurgeAfter)
+
+	_, err = trash.Restore(context.Background(), testUserID, storage.TrashBed, south.ID)
+	assert.ErrorIs(t, err, storage.ErrGardenInTrash)
+	_, err = trash.Restore(context.Background(), "someone_else", storage.TrashGarden, garden.ID)
+	assert.ErrorIs(t, err, storage.ErrRecordNotFound)
+
+	result, err := trash.Restore(context.Background(), testUserID, storage.TrashGarden, garden.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), result.RestoredBeds)
+	assert.Equal(t, int64(1), result.RestoredTasks)
+
+	got, err := gardens.GetGardenByID(context.Background(), testUserID, garden.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, got.Version, "restoring moves the version on")
+	_, err = beds.GetBedByID(context.Background(), testUserID, north.ID)
+	assert.NoError(t, err)
+	_, err = tasks.GetTaskByID(context.Background(), testUserID, task.ID)
+	assert.NoError(t, err)
+	_, err = beds.GetBedByID(context.Background(), testUserID, south.ID)
+	assert.ErrorIs(t, err, storage.ErrRecordNotFound)
+
+	// Now its garden is back, South can be restored on its own
+	items, err = trash.ListTrash(context.Background(), testUserID, storage.TrashBed)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, south.ID, items[0].ID)
+	_, err = trash.Restore(context.Background(), testUserID, storage.TrashBed, south.ID)
+	require.NoError(t, err)
+
+	_, err = trash.ListTrash(context.Background(), testUserID, "plant")
+	assert.ErrorIs(t, err, storage.ErrValidation)
+}
+
+func TestSQLite_Trash_HidesPlantingsInTrashedBeds(t *testing.T) {
+	db := newSQLiteDB(t)
+	ctx := context.Background()
+	gardens := storage.NewGormGardenStore(db)
+	beds := storage.NewGormBedStore(db)
+	plantings := storage.NewGormPlantingStore(db)
+	plant := models.Plant{Name: "Tomato"}
+	require.NoError(t, storage.NewGormPlantStore(db).CreatePlant(ctx, testUserID, &plant))
+	garden := createSQLiteGarden(t, gardens, "Backyard")
+	bed := models.Bed{GardenID: garden.ID, Name: "North"}
+	require.NoError(t, beds.CreateBed(ctx, testUserID, &bed))
+	planting := models.Planting{BedID: bed.ID, PlantID: plant.ID}
+	require.NoError(t, plantings.CreatePlanting(ctx, testUserID, &planting))
+
+	require.NoError(t, beds.DeleteBed(ctx, testUserID, bed.ID, 0))
+	page, err := plantings.ListPlantings(ctx, testUserID, storage.ListQuery{})
+	require.NoError(t, err)
+	assert.Empty(t, page.Items)
+	all, err := plantings.GetAllPlantings(ctx, testUserID)
+	require.NoError(t, err)
+	assert.Empty(t, all)
+	_, err = plantings.GetPlantingByID(ctx, testUserID, planting.ID)
+	assert.ErrorIs(t, err, storage.ErrRecordNotFound)
+	planting.Notes = "Staked"
+	assert.ErrorIs(t, plantings.UpdatePlanting(ctx, testUserID, &planting), storage.ErrRecordNotFound)
+
+	// Restoring the bed brings its plantings back
+	trash := storage.NewGormTrashStore(db, storage.DefaultTrashRetention)
+	_, err = trash.Restore(ctx, testUserID, storage.TrashBed, bed.ID)
+	require.NoError(t, err)
+	page, err = plantings.ListPlantings(ctx, testUserID, storage.ListQuery{})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, planting.ID, page.Items[0].ID)
+
+	// A garden going to the trash takes its beds' plantings out of sight too
+	require.NoError(t, gardens.DeleteGarden(ctx, testUserID, garden.ID, 0))
+	byBed, err := plantings.GetPlantingsByBedID(ctx, testUserID, bed.ID)
+	require.NoError(t, err)
+	assert.Empty(t, byBed)
+}
+
+func TestSQLite_Trash_PurgeAndSweep(t *testing.T) {
+	db := newSQLiteDB(t)
+	gardens := storage.NewGormGardenStore(db)
+	old := createSQLiteGarden(t, gardens, "Backyard")
+	recent := createSQLiteGarden(t, gardens, "Allotment")
+	purged := createSQLiteGarden(t, gardens, "Balcony")
+	beds := storage.NewGormBedStore(db)
+	bed := models.Bed{GardenID: old.ID, Name: "North"}
+	require.NoError(t, beds.CreateBed(context.Background(), testUserID, &bed))
+	trash := storage.NewGormTrashStore(db, storage.DefaultTrashRetention)
+
+	for _, g := range []models.Garden{old, recent, purged} {
+		require.NoError(t, gardens.DeleteGarden(context.Background(), testUserID, g.ID, 0))
+	}
+
+	// Purging needs the item to be in the trash
+	assert.ErrorIs(t, trash.Purge(context.Background(), testUserID, storage.TrashGarden, "missing"), storage.ErrRecordNotFound)
+	require.NoError(t, trash.Purge(context.Background(), testUserID, storage.TrashGarden, purged.ID))
+	_, err := trash.Restore(context.Background(), testUserID, storage.TrashGarden, purged.ID)
+	assert.ErrorIs(t, err, storage.ErrRecordNotFound)
+
+	// Backdate one garden past the retention period
+	longAgo := time.Now().Add(-storage.DefaultTrashRetention - time.Hour)
+	for _, table := range []string{"gardens", "beds"} {
+		require.NoError(t, db.Exec("UPDATE "+table+" SET deleted_at = ? WHERE id IN (?, ?)", longAgo, old.ID, bed.ID).Error)
+	}
+
+	stats, err := trash.Sweep(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats[storage.StatGardensPurged])
+	assert.Equal(t, int64(1), stats[storage.StatBedsPurged])
+
+	items, err := trash.ListTrash(context.Background(), testUserID, "")
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, recent.ID, items[0].ID)
+
+	n, err := trash.EmptyTrash(context.Background(), testUserID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	items, err = trash.ListTrash(context.Background(), testUserID, "")
+	require.NoError(t, err)
+	assert.Empty(t, items)
+}
+
+func TestSQLite_AuditLog_RecordsChangesByActor(t *testing.T) {
+	db := newSQLiteDB(t)
+	gardens := storage.NewGormGardenStore(db)
+	tasks := storage.NewGormTaskStore(db)
+	audit := storage.NewGormAuditStore(db)
+	asUser := auth.WithPrincipal(context.Background(), &auth.Principal{UserID: testUserID, Provider: auth.ProviderClerk})
+	asDevice := auth.WithPrincipal(context.Background(), &auth.Principal{UserID: testUserID, SessionID: "dev1", Provider: device.ProviderName})
+
+	garden := models.Garden{Name: "Backyard", Location: "Home"}
+	require.NoError(t, gardens.CreateGarden(asUser, testUserID, &garden))
+	task := models.Task{GardenID: garden.ID, Description: "Water", DueDate: time.Now().Add(24 * time.Hour), Status: models.TaskStatusPending, Priority: models.PriorityLow}
+	require.NoError(t, tasks.CreateTask(asUser, testUserID, &task))
+
+	task.Description = "Water deeply"
+	require.NoError(t, tasks.UpdateTask(asDevice, testUserID, &task))
+	require.NoError(t, gardens.DeleteGarden(asUser, testUserID, garden.ID, 0))
+	_, err := storage.NewGormTrashStore(db, storage.DefaultTrashRetention).Restore(asUser, testUserID, storage.TrashGarden, garden.ID)
+	require.NoError(t, err)
+
+	page, err := audit.ListAudit(context.Background(), testUserID, storage.ListQuery{Filters: map[string]string{"entity": "task", "entity_id": task.ID}, Sort: "created_at"})
+	require.NoError(t, err)
+	var actions []string
+	for _, e := range page.Items {
+		actions = append(actions, e.Action)
+	}
+	assert.Equal(t, []string{models.AuditCreate, models.AuditUpdate, models.AuditDelete, models.AuditRestore}, actions)
+
+	update := page.Items[1]
+	assert.Equal(t, models.ActorDevice, update.ActorType)
+	assert.Equal(t, "dev1", update.ActorID)
+	assert.Equal(t, models.AuditValues{"description": "Water"}, update.Before)
+	assert.Equal(t, models.AuditValues{"description": "Water deeply"}, update.After)
+	assert.Equal(t, "Water", page.Items[0].After["description"], "a create records the whole task")
+
+	// The device's changes, by actor
+	page, err = audit.ListAudit(context.Background(), testUserID, storage.ListQuery{Filters: map[string]string{"actor": "dev1"}})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, task.ID, page.Items[0].EntityID)
+
+	// Changes made outside a request, like the sweeper's, are the system's;
+	// and other users see none of it
+	require.NoError(t, db.Model(&models.Garden{}).Where("id = ?", garden.ID).Update("location", "Allotment").Error)
+	page, err = audit.ListAudit(context.Background(), testUserID, storage.ListQuery{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, models.ActorSystem, page.Items[0].ActorType)
+	page, err = audit.ListAudit(context.Background(), "someone_else", storage.ListQuery{})
+	require.NoError(t, err)
+	assert.Empty(t, page.Items)
+}
+
+func TestSQLite_AuditLog_RolledBackWithTheChange(t *testing.T) {
+	db := newSQLiteDB(t)
+	garden := createSQLiteGarden(t, storage.NewGormGardenStore(db), "Backyard")
+	store := storage.NewGormTaskStore(db)
+
+	_, err := store.BulkTasks(context.Background(), testUserID, []storage.BulkTaskOp{
+		{Op: storage.BulkOpCreate, Task: &models.Task{GardenID: garden.ID, Description: "Weed", DueDate: time.Now(), Priority: models.PriorityLow}},
+		{Op: storage.BulkOpDelete, ID: "missing"},
+	}, true)
+	require.NoError(t, err)
+
+	page, err := storage.NewGormAuditStore(db).ListAudit(context.Background(), testUserID, storage.ListQuery{Filters: map[string]string{"entity": "task"}})
+	require.NoError(t, err)
+	assert.Empty(t, page.Items)
+}
+
+func TestSQLite_AuditLog_PurgeRecordsContents(t *testing.T) {
+	db := newSQLiteDB(t)
+	ctx := context.Background()
+	gardens := storage.NewGormGardenStore(db)
+	beds := storage.NewGormBedStore(db)
+	tasks := storage.NewGormTaskStore(db)
+	plantings := storage.NewGormPlantingStore(db)
+	trash := storage.NewGormTrashStore(db, storage.DefaultTrashRetention)
+	plant := models.Plant{Name: "Tomato"}
+	require.NoError(t, storage.NewGormPlantStore(db).CreatePlant(ctx, testUserID, &plant))
+
+	garden := createSQLiteGarden(t, gardens, "Backyard")
+	bed := models.Bed{GardenID: garden.ID, Name: "North"}
+	require.NoError(t, beds.CreateBed(ctx, testUserID, &bed))
+	planting := models.Planting{BedID: bed.ID, PlantID: plant.ID}
+	require.NoError(t, plantings.CreatePlanting(ctx, testUserID, &planting))
+	task := models.Task{GardenID: garden.ID, BedID: &bed.ID, Description: "Water", DueDate: time.Now().Add(24 * time.Hour), Priority: models.PriorityLow}
+	require.NoError(t, tasks.CreateTask(ctx, testUserID, &task))
+
+	// Purging a bed deletes its plantings and detaches its tasks
+	require.NoError(t, beds.DeleteBed(ctx, testUserID, bed.ID, 0))
+	require.NoError(t, trash.Purge(ctx, testUserID, storage.TrashBed, bed.ID))
+	lastAction := func(entity, id string) string {
+		t.Helper()
+		page, err := storage.NewGormAuditStore(db).ListAudit(ctx, testUserID, storage.ListQuery{Filters: map[string]string{"entity": entity, "entity_id": id}})
+		require.NoError(t, err)
+		require.NotEmpty(t, page.Items, "%s %s", entity, id)
+		return page.Items[0].Action
+	}
+	assert.Equal(t, models.AuditPurge, lastAction("planting", planting.ID))
+	assert.Equal(t, models.AuditPurge, lastAction("bed", bed.ID))
+	assert.Equal(t, models.AuditUpdate, lastAction("task", task.ID))
+
+	// Purging a garden deletes everything in it, each row audited
+	bed = models.Bed{GardenID: garden.ID, Name: "South"}
+	require.NoError(t, beds.CreateBed(ctx, testUserID, &bed))
+	planting = models.Planting{BedID: bed.ID, PlantID: plant.ID}
+	require.NoError(t, plantings.CreatePlanting(ctx, testUserID, &planting))
+	require.NoError(t, gardens.DeleteGarden(ctx, testUserID, garden.ID, 0))
+	require.NoError(t, trash.Purge(ctx, testUserID, storage.TrashGarden, garden.ID))
+	for entity, id := range map[string]string{"garden": garden.ID, "bed": bed.ID, "task": task.ID, "planting": planting.ID} {
+		assert.Equal(t, models.AuditPurge, lastAction(entity, id), entity)
+	}
+}
+
+func TestSQLite_EndedContextStopsQueries(t *testing.T) {
+	db := newSQLiteDB(t)
+	gardens := storage.NewGormGardenStore(db)
+	tasks := storage.NewGormTaskStore(db)
+	garden := createSQLiteGarden(t, gardens, "Backyard")
+
+	// The client disconnected
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := gardens.ListGardens(canceled, testUserID, storage.ListQuery{})
+	assert.ErrorIs(t, err, storage.ErrCanceled)
+	_, err = tasks.GetTasksByGardenID(canceled, testUserID, garden.ID, storage.ListQuery{})
+	assert.ErrorIs(t, err, storage.ErrCanceled)
+
+	// The route's deadline passed
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	_, err = gardens.GetGardenByID(expired, testUserID, garden.ID)
+	assert.ErrorIs(t, err, storage.ErrTimeout)
+	assert.ErrorIs(t, gardens.CreateGarden(expired, testUserID, &models.Garden{Name: "Too late"}), storage.ErrTimeout)
+	renamed := garden
+	renamed.Name = "Too late"
+	assert.ErrorIs(t, gardens.UpdateGarden(expired, testUserID, &renamed), storage.ErrTimeout)
+	assert.ErrorIs(t, gardens.DeleteGarden(expired, testUserID, garden.ID, 0), storage.ErrTimeout)
+
+	// None of the writes went through
+	all, err := gardens.GetAllGardens(context.Background(), testUserID)
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+	assert.Equal(t, "Backyard", all[0].Name)
+}
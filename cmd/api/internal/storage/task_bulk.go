@@ -0,0 +1,165 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/zjpiazza/plantastic/internal/models"
+	"gorm.io/gorm"
+)
+
+// Operations accepted by BulkTasks.
+const (
+	BulkOpCreate     = "create"
+	BulkOpUpdate     = "update"
+	BulkOpDelete     = "delete"
+	BulkOpComplete   = "complete"
+	BulkOpReschedule = "reschedule"
+)
+
+// MaxBulkTaskOps caps the number of operations in one BulkTasks call.
+const MaxBulkTaskOps = 100
+
+// BulkTaskOp is one operation of a bulk task request.
+//
+// create takes the new task in Task, and update takes the full replacement in
+// Task with the task's ID. delete, complete and reschedule only need ID.
+// reschedule takes either a new DueDate or ShiftDays to move the due date by.
+// A non-zero Version makes every op but create conditional on the task still
+// being at that version.
+type BulkTaskOp struct {
+	Op        string       `json:"op"`
+	ID        string       `json:"id,omitempty"`
+	Task      *models.Task `json:"task,omitempty"`
+	DueDate   *time.Time   `json:"due_date,omitempty"`
+	ShiftDays int          `json:"shift_days,omitempty"`
+	Version   int          `json:"version,omitempty"`
+}
+
+// BulkTaskResult is the outcome of one BulkTaskOp. Task holds the task as
+// created or changed, and is nil for a delete or a failed operation.
+type BulkTaskResult struct {
+	Op   string
+	ID   string
+	Task *models.Task
+	Err  error
+}
+
+// BulkTasks applies ops in order and reports one result per op.
+//
+// With atomic set, all ops run in a single transaction: if any fails, none
+// are applied, the failing op reports its error and every other op reports
+// ErrBulkAborted. Otherwise each op is applied on its own and failures are
+// reported per op. The returned error is for the batch as a whole: ErrValidation
+// for an empty or oversized batch, or a database error committing it.
+func (s *GormTaskStore) BulkTasks(ctx context.Context, userID string, ops []BulkTaskOp, atomic bool) ([]BulkTaskResult, error) {
+	if len(ops) == 0 || len(ops) > MaxBulkTaskOps {
+		return nil, invalidField("operations", fmt.Sprintf("must contain between 1 and %d operations", MaxBulkTaskOps))
+	}
+	results := make([]BulkTaskResult, len(ops))
+	if !atomic {
+		for i, op := range ops {
+			results[i] = s.applyBulkOp(ctx, userID, op)
+		}
+		return results, nil
+	}
+
+	failed := -1
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		txStore := &GormTaskStore{db: tx}
+		for i, op := range ops {
+			results[i] = txStore.applyBulkOp(ctx, userID, op)
+			if results[i].Err != nil {
+				failed = i
+				return results[i].Err
+			}
+		}
+		return nil
+	})
+	if failed >= 0 {
+		for i, op := range ops {
+			if i != failed {
+				results[i] = BulkTaskResult{Op: op.Op, ID: op.ID, Err: ErrBulkAborted}
+			}
+		}
+		return results, nil
+	}
+	if err != nil {
+		return nil, ParseDatabaseError(err)
+	}
+	return results, nil
+}
+
+// applyBulkOp runs one op through the store's single-task methods, so bulk
+// changes get the same validation, versioning and recurrence handling.
+func (s *GormTaskStore) applyBulkOp(ctx context.Context, userID string, op BulkTaskOp) BulkTaskResult {
+	result := BulkTaskResult{Op: op.Op, ID: op.ID}
+	var task models.Task
+	switch op.Op {
+	case BulkOpCreate:
+		if op.Task == nil {
+			result.Err = invalidField("task", "is required")
+			return result
+		}
+		task = *op.Task
+		result.Err = s.CreateTask(ctx, userID, &task)
+		result.ID = task.ID
+	case BulkOpUpdate:
+		var verr ValidationError
+		if op.Task == nil {
+			verr.add("task", "is required")
+		}
+		if op.ID == "" {
+			verr.add("id", "is required")
+		}
+		if result.Err = verr.err(); result.Err != nil {
+			return result
+		}
+		task = *op.Task
+		task.ID = op.ID
+		task.Version = op.Version
+		result.Err = s.UpdateTask(ctx, userID, &task)
+	case BulkOpDelete:
+		result.Err = s.DeleteTask(ctx, userID, op.ID, op.Version)
+		return result
+	case BulkOpComplete, BulkOpReschedule:
+		var err error
+		// The stored status, so that rescheduling reopens a task as it was
+		if task, err = s.getStoredTask(ctx, userID, op.ID); err != nil {
+			result.Err = err
+			return result
+		}
+		if op.Op == BulkOpComplete {
+			task.Status = models.TaskStatusCompleted
+		} else {
+			if (op.DueDate == nil) == (op.ShiftDays == 0) {
+				result.Err = invalidField("due_date", "exactly one of due_date and shift_days is required")
+				return result
+			}
+			if op.DueDate != nil {
+				task.DueDate = *op.DueDate
+			} else {
+				task.DueDate = task.DueDate.AddDate(0, 0, op.ShiftDays)
+			}
+			// Moving an overdue task into the future reopens it as it was
+			now := time.Now()
+			previous, err := statusesBeforeOverdue(s.db.WithContext(ctx), []models.Task{task}, now)
+			if err != nil {
+				result.Err = ParseDatabaseError(err)
+				return result
+			}
+			task.Status = task.EvaluateStatus(now, previous[task.ID])
+		}
+		task.Version = op.Version
+		result.Err = s.UpdateTask(ctx, userID, &task)
+	default:
+		result.Err = invalidField("op", "unknown operation "+strconv.Quote(op.Op))
+		return result
+	}
+	if result.Err == nil {
+		result.Task = &task
+	}
+	return result
+}
@@ -0,0 +1,85 @@
+package storage_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zjpiazza/plantastic/cmd/api/internal/storage"
+)
+
+func TestGormTaskStore_BulkTasks_AtomicRollsBack(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormTaskStore(db)
+	rawSqlDB, err := db.DB()
+	require.NoError(t, err)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	sqlDelete := `UPDATE "tasks" SET "deleted_at"=$1 WHERE (id = $2 AND user_id = $3) AND "tasks"."deleted_at" IS NULL`
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(sqlDelete)).WithArgs(sqlmock.AnyArg(), "t1", testUserID).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(sqlDelete)).WithArgs(sqlmock.AnyArg(), "t2", testUserID).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	results, err := store.BulkTasks(context.Background(), testUserID, []storage.BulkTaskOp{
+		{Op: storage.BulkOpDelete, ID: "t1"},
+		{Op: storage.BulkOpDelete, ID: "t2"},
+		{Op: storage.BulkOpDelete, ID: "t3"},
+	}, true)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.Equal(t, storage.ErrBulkAborted, results[0].Err)
+	assert.Equal(t, storage.ErrRecordNotFound, results[1].Err)
+	assert.Equal(t, storage.ErrBulkAborted, results[2].Err)
+	assert.Equal(t, "t3", results[2].ID)
+}
+
+func TestGormTaskStore_BulkTasks_PartialReportsEachOp(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormTaskStore(db)
+	rawSqlDB, err := db.DB()
+	require.NoError(t, err)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	sqlDelete := `UPDATE "tasks" SET "deleted_at"=$1 WHERE (id = $2 AND user_id = $3) AND "tasks"."deleted_at" IS NULL`
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(sqlDelete)).WithArgs(sqlmock.AnyArg(), "t1", testUserID).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(sqlDelete)).WithArgs(sqlmock.AnyArg(), "t2", testUserID).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	results, err := store.BulkTasks(context.Background(), testUserID, []storage.BulkTaskOp{
+		{Op: storage.BulkOpDelete, ID: "t1"},
+		{Op: "archive", ID: "t9"},
+		{Op: storage.BulkOpDelete, ID: "t2"},
+		{Op: storage.BulkOpUpdate, ID: "t3"}, // No task to update with
+	}, false)
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+	assert.Equal(t, storage.ErrRecordNotFound, results[0].Err)
+	assert.ErrorIs(t, results[1].Err, storage.ErrValidation)
+	assert.NoError(t, results[2].Err)
+	assert.Equal(t, []storage.FieldError{{Field: "task", Message: "is required"}}, storage.FieldErrors(results[3].Err))
+}
+
+func TestGormTaskStore_BulkTasks_BatchSize(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormTaskStore(db)
+	rawSqlDB, err := db.DB()
+	require.NoError(t, err)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	_, err = store.BulkTasks(context.Background(), testUserID, nil, true)
+	assert.ErrorIs(t, err, storage.ErrValidation)
+
+	ops := make([]storage.BulkTaskOp, storage.MaxBulkTaskOps+1)
+	_, err = store.BulkTasks(context.Background(), testUserID, ops, false)
+	assert.ErrorIs(t, err, storage.ErrValidation)
+}
@@ -0,0 +1,177 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zjpiazza/plantastic/internal/models"
+	"gorm.io/gorm"
+)
+
+// sweepBatchSize caps how many tasks one sweeper run transitions; the rest are
+// picked up by the next run.
+const sweepBatchSize = 500
+
+// Sweeper stats keys reported by TaskStatusSweeper.Run.
+const (
+	StatTasksMarkedOverdue = "tasks_marked_overdue"
+	StatTasksReopened      = "tasks_reopened"
+)
+
+// dueForTransition limits a tasks query to those whose status
+// models.Task.EvaluateStatus changes at now: open tasks past their due date,
+// and Overdue tasks due again.
+func dueForTransition(now time.Time) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("due_date > ? AND ((status IN ? AND due_date < ?) OR (status = ? AND due_date >= ?))",
+			time.Time{}, []string{models.TaskStatusPending, models.TaskStatusInProgress}, now, models.TaskStatusOverdue, now)
+	}
+}
+
+// applyStatusTransitions moves each task to the status models.Task.EvaluateStatus
+// gives for now, recording every change as a models.TaskEvent. Each update is
+// conditional on the status it was evaluated from, so a task edited in the
+// meantime is left alone. tasks is updated in place.
+func applyStatusTransitions(db *gorm.DB, tasks []models.Task, now time.Time) (overdue, reopened int64, err error) {
+	previous, err := statusesBeforeOverdue(db, tasks, now)
+	if err != nil {
+		return 0, 0, err
+	}
+	for i := range tasks {
+		task := &tasks[i]
+		next := task.EvaluateStatus(now, previous[task.ID])
+		if next == task.Status {
+			continue
+		}
+		reason := models.TaskEventReasonDuePassed
+		if next != models.TaskStatusOverdue {
+			reason = models.TaskEventReasonDueMoved
+		}
+
+		applied := false
+		err := db.Transaction(func(tx *gorm.DB) error {
+			result := tx.Model(&models.Task{}).
+				Where("id = ? AND user_id = ? AND status = ?", task.ID, task.UserID, task.Status).
+				Updates(map[string]interface{}{"status": next, "version": gorm.Expr("version + 1"), "updated_at": now})
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+			event := models.NewTaskEvent(*task, next, reason, now)
+			if err := tx.Create(&event).Error; err != nil {
+				return err
+			}
+			applied = true
+			return nil
+		})
+		if err != nil {
+			return overdue, reopened, fmt.Errorf("failed to update status of task %s: %w", task.ID, err)
+		}
+		if !applied {
+			continue
+		}
+
+		task.Status = next
+		task.Version++
+		task.UpdatedAt = now
+		if next == models.TaskStatusOverdue {
+			overdue++
+		} else {
+			reopened++
+		}
+	}
+	return overdue, reopened, nil
+}
+
+// statusesBeforeOverdue returns, by task ID, the status each of the Overdue
+// tasks that reopen at now had before it last became Overdue, from its
+// history. Tasks without such an event are left out.
+func statusesBeforeOverdue(db *gorm.DB, tasks []models.Task, now time.Time) (map[string]string, error) {
+	previous := map[string]string{}
+	var ids []string
+	for _, task := range tasks {
+		if task.Status == models.TaskStatusOverdue && task.EvaluateStatus(now, "") != task.Status {
+			ids = append(ids, task.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return previous, nil
+	}
+
+	var events []models.TaskEvent
+	err := db.Where("task_id IN ? AND to_status = ?", ids, models.TaskStatusOverdue).Order("created_at").Find(&events).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the history of overdue tasks: %w", err)
+	}
+	for _, event := range events {
+		previous[event.TaskID] = event.FromStatus // The latest wins
+	}
+	return previous, nil
+}
+
+// evaluateStatuses sets each task's status to the one
+// models.Task.EvaluateStatus gives at now, without storing it.
+func evaluateStatuses(db *gorm.DB, tasks []models.Task, now time.Time) error {
+	previous, err := statusesBeforeOverdue(db, tasks, now)
+	if err != nil {
+		return ParseDatabaseError(err)
+	}
+	for i := range tasks {
+		tasks[i].Status = tasks[i].EvaluateStatus(now, previous[tasks[i].ID])
+	}
+	return nil
+}
+
+// currentStatusSQL is models.Task.EvaluateStatus at @now in SQL, taking the
+// status an Overdue task reopens with from its history as
+// statusesBeforeOverdue does.
+const currentStatusSQL = `CASE
+	WHEN due_date > @zero AND status IN @open AND due_date < @now THEN @overdue
+	WHEN due_date > @zero AND status = @overdue AND due_date >= @now THEN
+		CASE (SELECT from_status FROM task_events WHERE task_events.task_id = tasks.id AND to_status = @overdue ORDER BY created_at DESC LIMIT 1)
+		WHEN @in_progress THEN @in_progress ELSE @pending END
+	ELSE status END`
+
+// currentStatusFilter matches tasks whose current status is any of a
+// comma-separated list, whether or not the sweeper has stored it yet.
+func currentStatusFilter(db *gorm.DB, value string) (*gorm.DB, error) {
+	return db.Where("("+currentStatusSQL+") IN @statuses", map[string]interface{}{
+		"zero":        time.Time{},
+		"now":         time.Now(),
+		"open":        []string{models.TaskStatusPending, models.TaskStatusInProgress},
+		"overdue":     models.TaskStatusOverdue,
+		"in_progress": models.TaskStatusInProgress,
+		"pending":     models.TaskStatusPending,
+		"statuses":    strings.Split(value, ","),
+	}), nil
+}
+
+// TaskStatusSweeper applies automatic task status transitions for every user,
+// so tasks become Overdue even when nobody is reading them.
+type TaskStatusSweeper struct {
+	db *gorm.DB
+}
+
+// NewTaskStatusSweeper creates a new TaskStatusSweeper.
+func NewTaskStatusSweeper(db *gorm.DB) *TaskStatusSweeper {
+	return &TaskStatusSweeper{db: db}
+}
+
+// Run performs one sweep. Its signature matches scheduler.JobFunc.
+func (s *TaskStatusSweeper) Run(ctx context.Context) (map[string]int64, error) {
+	now := time.Now()
+	db := s.db.WithContext(ctx)
+	stats := map[string]int64{}
+
+	var tasks []models.Task
+	err := db.Scopes(dueForTransition(now)).Limit(sweepBatchSize).Find(&tasks).Error
+	if err != nil {
+		return stats, fmt.Errorf("failed to find tasks due for a status change: %w", err)
+	}
+
+	overdue, reopened, err := applyStatusTransitions(db, tasks, now)
+	stats[StatTasksMarkedOverdue] = overdue
+	stats[StatTasksReopened] = reopened
+	return stats, err
+}
@@ -0,0 +1,162 @@
+package storage_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zjpiazza/plantastic/cmd/api/internal/storage"
+	"github.com/zjpiazza/plantastic/internal/models"
+	"gorm.io/gorm"
+)
+
+const (
+	sqlStatusTransition = `UPDATE "tasks" SET "status"=$1,"updated_at"=$2,"version"=version + 1 WHERE (id = $3 AND user_id = $4 AND status = $5) AND "tasks"."deleted_at" IS NULL`
+	sqlTaskEventInsert  = `INSERT INTO "task_events" ("id","user_id","task_id","from_status","to_status","reason","created_at") VALUES ($1,$2,$3,$4,$5,$6,$7)`
+	sqlOverdueHistory   = `SELECT * FROM "task_events" WHERE task_id IN ($1) AND to_status = $2 ORDER BY created_at`
+)
+
+// currentStatusIn matches a status filter's SQL, whose list of statuses is
+// placeholder n. Its other arguments are currentStatusArgs.
+func currentStatusIn(n int) string {
+	return fmt.Sprintf(`\(\(CASE .* END\) IN \(\$%d\)\)`, n)
+}
+
+// currentStatusArgs matches the arguments of a status filter's SQL before its
+// list of statuses.
+var currentStatusArgs = []driver.Value{
+	sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+	sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+}
+
+func TestGormTaskStore_GetTaskByID_ReportsPastDueTaskOverdueWithoutStoringIt(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormTaskStore(db)
+	rawSqlDB, errDB := db.DB()
+	require.NoError(t, errDB)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	rows := sqlmock.NewRows([]string{"id", "user_id", "garden_id", "description", "due_date", "status", "version"}).
+		AddRow("t1", testUserID, "g1", "Water roses", time.Now().Add(-time.Hour), models.TaskStatusPending, 3)
+	sqlSelect := `SELECT * FROM "tasks" WHERE (id = $1 AND user_id = $2) AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelect)).WithArgs("t1", testUserID, 1).WillReturnRows(rows)
+	// No update: the sweeper stores the transition
+
+	task, err := store.GetTaskByID(context.Background(), testUserID, "t1")
+	require.NoError(t, err)
+	assert.Equal(t, models.TaskStatusOverdue, task.Status)
+	assert.Equal(t, 3, task.Version, "reading a task must not change its version")
+}
+
+func TestGormTaskStore_GetAllTasks_ReopensRescheduledOverdueTaskAsItWas(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormTaskStore(db)
+	rawSqlDB, errDB := db.DB()
+	require.NoError(t, errDB)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	rows := sqlmock.NewRows([]string{"id", "user_id", "garden_id", "description", "due_date", "status"}).
+		AddRow("t1", testUserID, "g1", "Water roses", time.Now().Add(time.Hour), models.TaskStatusOverdue)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "tasks" WHERE user_id = $1`)).WithArgs(testUserID).WillReturnRows(rows)
+	mock.ExpectQuery(regexp.QuoteMeta(sqlOverdueHistory)).WithArgs("t1", models.TaskStatusOverdue).
+		WillReturnRows(sqlmock.NewRows([]string{"task_id", "from_status", "to_status"}).
+			AddRow("t1", models.TaskStatusInProgress, models.TaskStatusOverdue))
+
+	tasks, err := store.GetAllTasks(context.Background(), testUserID)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, models.TaskStatusInProgress, tasks[0].Status)
+}
+
+func TestTaskStatusSweeper_Run(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	sweeper := storage.NewTaskStatusSweeper(db)
+	rawSqlDB, errDB := db.DB()
+	require.NoError(t, errDB)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	rows := sqlmock.NewRows([]string{"id", "user_id", "due_date", "status"}).
+		AddRow("t_late", "user_a", time.Now().Add(-24*time.Hour), models.TaskStatusPending).
+		AddRow("t_moved", "user_b", time.Now().Add(24*time.Hour), models.TaskStatusOverdue)
+	sqlSelect := `SELECT * FROM "tasks" WHERE (due_date > $1 AND ((status IN ($2,$3) AND due_date < $4) OR (status = $5 AND due_date >= $6))) AND "tasks"."deleted_at" IS NULL LIMIT $7`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelect)).
+		WithArgs(time.Time{}, models.TaskStatusPending, models.TaskStatusInProgress, sqlmock.AnyArg(), models.TaskStatusOverdue, sqlmock.AnyArg(), 500).
+		WillReturnRows(rows)
+
+	// t_moved was In Progress when it became overdue, so it reopens as such
+	mock.ExpectQuery(regexp.QuoteMeta(sqlOverdueHistory)).WithArgs("t_moved", models.TaskStatusOverdue).
+		WillReturnRows(sqlmock.NewRows([]string{"task_id", "from_status", "to_status"}).
+			AddRow("t_moved", models.TaskStatusPending, models.TaskStatusOverdue).
+			AddRow("t_moved", models.TaskStatusInProgress, models.TaskStatusOverdue))
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(sqlStatusTransition)).
+		WithArgs(models.TaskStatusOverdue, sqlmock.AnyArg(), "t_late", "user_a", models.TaskStatusPending).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(sqlTaskEventInsert)).
+		WithArgs(sqlmock.AnyArg(), "user_a", "t_late", models.TaskStatusPending, models.TaskStatusOverdue, models.TaskEventReasonDuePassed, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(sqlStatusTransition)).
+		WithArgs(models.TaskStatusInProgress, sqlmock.AnyArg(), "t_moved", "user_b", models.TaskStatusOverdue).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(sqlTaskEventInsert)).
+		WithArgs(sqlmock.AnyArg(), "user_b", "t_moved", models.TaskStatusOverdue, models.TaskStatusInProgress, models.TaskEventReasonDueMoved, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	stats, err := sweeper.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats[storage.StatTasksMarkedOverdue])
+	assert.Equal(t, int64(1), stats[storage.StatTasksReopened])
+}
+
+func TestGormTaskStore_GetTaskHistory(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormTaskStore(db)
+	rawSqlDB, errDB := db.DB()
+	require.NoError(t, errDB)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	sqlSelectTask := `SELECT * FROM "tasks" WHERE (id = $1 AND user_id = $2) AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectTask)).WithArgs("t1", testUserID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("t1"))
+
+	at := time.Now()
+	eventRows := sqlmock.NewRows([]string{"id", "user_id", "task_id", "from_status", "to_status", "reason", "created_at"}).
+		AddRow("e1", testUserID, "t1", models.TaskStatusPending, models.TaskStatusOverdue, models.TaskEventReasonDuePassed, at)
+	sqlSelectEvents := `SELECT * FROM "task_events" WHERE task_id = $1 AND user_id = $2 ORDER BY created_at`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectEvents)).WithArgs("t1", testUserID).WillReturnRows(eventRows)
+
+	events, err := store.GetTaskHistory(context.Background(), testUserID, "t1")
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, models.TaskStatusOverdue, events[0].ToStatus)
+}
+
+func TestGormTaskStore_GetTaskHistory_TaskNotFound(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormTaskStore(db)
+	rawSqlDB, errDB := db.DB()
+	require.NoError(t, errDB)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	sqlSelectTask := `SELECT * FROM "tasks" WHERE (id = $1 AND user_id = $2) AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectTask)).WithArgs("t_other", testUserID, 1).WillReturnError(gorm.ErrRecordNotFound)
+
+	_, err := store.GetTaskHistory(context.Background(), testUserID, "t_other")
+	assert.ErrorIs(t, err, storage.ErrRecordNotFound)
+}
@@ -1,20 +1,41 @@
 package storage
 
 import (
+	"context"
 	"errors"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/zjpiazza/plantastic/internal/models"
+	"github.com/zjpiazza/plantastic/internal/recurrence"
 	"gorm.io/gorm"
 )
 
 // TaskStorer defines the interface for task data operations.
+// Tasks inherit their owner from the parent garden and are scoped to that user.
+//
+// A task created with a Recurrence rule starts a models.TaskSeries. UpdateTask
+// edits a single occurrence, and closing one (Completed or Cancelled) creates
+// the next; UpdateTaskSeries edits the series and all of its open occurrences.
+//
+// Reads return the status models.Task.EvaluateStatus gives a task now, and
+// lists filter on it, without storing it: TaskStatusSweeper does that, so
+// reading a task never changes its version. GetTaskHistory lists the
+// transitions stored so far.
+//
+// BulkTasks applies a batch of operations, either all-or-nothing or one by one.
 type TaskStorer interface {
-	GetAllTasks() ([]models.Task, error)
-	GetTaskByID(taskID string) (models.Task, error)
-	CreateTask(task *models.Task) error
-	UpdateTask(task *models.Task) error
-	DeleteTask(taskID string) error
+	GetAllTasks(ctx context.Context, userID string) ([]models.Task, error)
+	ListTasks(ctx context.Context, userID string, q ListQuery) (Page[models.Task], error)
+	GetTasksByGardenID(ctx context.Context, userID, gardenID string, q ListQuery) (Page[models.Task], error)
+	GetTasksByBedID(ctx context.Context, userID, gardenID, bedID string, q ListQuery) (Page[models.Task], error)
+	GetTaskByID(ctx context.Context, userID, taskID string) (models.Task, error)
+	GetTaskHistory(ctx context.Context, userID, taskID string) ([]models.TaskEvent, error)
+	CreateTask(ctx context.Context, userID string, task *models.Task) error
+	UpdateTask(ctx context.Context, userID string, task *models.Task) error
+	UpdateTaskSeries(ctx context.Context, userID string, task *models.Task) error
+	DeleteTask(ctx context.Context, userID, taskID string, version int) error
+	BulkTasks(ctx context.Context, userID string, ops []BulkTaskOp, atomic bool) ([]BulkTaskResult, error)
 }
 
 // GormTaskStore implements TaskStorer using GORM.
@@ -27,95 +48,220 @@ func NewGormTaskStore(db *gorm.DB) TaskStorer {
 	return &GormTaskStore{db: db}
 }
 
-func (s *GormTaskStore) GetAllTasks() ([]models.Task, error) {
+func (s *GormTaskStore) GetAllTasks(ctx context.Context, userID string) ([]models.Task, error) {
 	var tasks []models.Task
-	result := s.db.Find(&tasks)
+	db := s.db.WithContext(ctx)
+	result := db.Where("user_id = ?", userID).Find(&tasks)
 	if result.Error != nil {
-		// Use custom ErrDatabase for consistency, assuming result.Error is a generic DB error
-		return nil, ErrDatabase
+		return nil, ParseDatabaseError(result.Error)
+	}
+	if err := evaluateStatuses(db, tasks, time.Now()); err != nil {
+		return nil, err
 	}
 	return tasks, nil
 }
 
-func (s *GormTaskStore) CreateTask(task *models.Task) error {
-	// Basic validation (can be expanded based on model requirements)
-	if task.Description == "" || task.GardenID == "" { // Assuming Description and GardenID are mandatory
+// taskListSpec filters tasks by location, status, priority, series and due
+// date. status and priority accept comma-separated lists.
+var taskListSpec = listSpec[models.Task]{
+	filters: map[string]filterFunc{
+		"garden_id":     equalsFilter("garden_id"),
+		"garden_bed_id": equalsFilter("bed_id"),
+		"series_id":     equalsFilter("series_id"),
+		"status":        currentStatusFilter,
+		"priority":      oneOfFilter("priority"),
+		"due_from":      timeFromFilter("due_date"),
+		"due_to":        timeToFilter("due_date"),
+	},
+	sorts: map[string]sortField[models.Task]{
+		"due_date":    {column: "due_date", value: func(t models.Task) any { return t.DueDate }, isTime: true},
+		"status":      {column: "status", value: func(t models.Task) any { return t.Status }},
+		"description": {column: "description", value: func(t models.Task) any { return t.Description }},
+		"created_at":  {column: "created_at", value: func(t models.Task) any { return t.CreatedAt }, isTime: true},
+		"updated_at":  {column: "updated_at", value: func(t models.Task) any { return t.UpdatedAt }, isTime: true},
+	},
+	defaultSort: "due_date",
+	id:          func(t models.Task) string { return t.ID },
+}
+
+func (s *GormTaskStore) ListTasks(ctx context.Context, userID string, q ListQuery) (Page[models.Task], error) {
+	return s.listTasks(ctx, s.db.WithContext(ctx).Where("user_id = ?", userID), q)
+}
+
+// GetTasksByGardenID lists one page of the tasks in a garden. It returns
+// ErrRecordNotFound if the garden does not exist or belongs to someone else.
+func (s *GormTaskStore) GetTasksByGardenID(ctx context.Context, userID, gardenID string, q ListQuery) (Page[models.Task], error) {
+	if err := s.checkTaskParents(ctx, userID, gardenID, nil); err != nil {
+		return Page[models.Task]{}, err
+	}
+	return s.listTasks(ctx, s.db.WithContext(ctx).Where("garden_id = ? AND user_id = ?", gardenID, userID), q)
+}
+
+// GetTasksByBedID lists one page of the tasks for a bed. It returns
+// ErrRecordNotFound if the garden or bed does not exist, belongs to someone
+// else, or the bed is not in the garden.
+func (s *GormTaskStore) GetTasksByBedID(ctx context.Context, userID, gardenID, bedID string, q ListQuery) (Page[models.Task], error) {
+	if err := s.checkTaskParents(ctx, userID, gardenID, &bedID); err != nil {
+		return Page[models.Task]{}, err
+	}
+	return s.listTasks(ctx, s.db.WithContext(ctx).Where("garden_id = ? AND bed_id = ? AND user_id = ?", gardenID, bedID, userID), q)
+}
+
+// checkTaskParents is checkTaskRefs for nested routes, where a missing parent
+// means the requested collection does not exist.
+func (s *GormTaskStore) checkTaskParents(ctx context.Context, userID, gardenID string, bedID *string) error {
+	if err := s.checkTaskRefs(ctx, userID, gardenID, bedID); err != nil {
+		if errors.Is(err, ErrValidation) {
+			return ErrRecordNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// listTasks reads one page of the tasks db selects, with their current
+// statuses. Pages are sorted on the stored status, which can lag behind
+// until the sweeper stores the current one.
+func (s *GormTaskStore) listTasks(ctx context.Context, db *gorm.DB, q ListQuery) (Page[models.Task], error) {
+	page, err := paginate(db, taskListSpec, q)
+	if err != nil {
+		return page, err
+	}
+	if err := evaluateStatuses(s.db.WithContext(ctx), page.Items, time.Now()); err != nil {
+		return Page[models.Task]{}, err
+	}
+	return page, nil
+}
+
+func (s *GormTaskStore) CreateTask(ctx context.Context, userID string, task *models.Task) error {
+	db := s.db.WithContext(ctx)
+	if userID == "" {
 		return ErrValidation
 	}
-	// Potentially check if GardenID (and BedID if not nil) exist
-	var garden models.Garden
-	if err := s.db.First(&garden, "id = ?", task.GardenID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return ErrValidation // Referencing a non-existent garden
+	var verr ValidationError
+	validateTaskFields(task, &verr)
+	var rule recurrence.Rule
+	if task.Recurrence != "" {
+		var err error
+		if rule, err = recurrence.Parse(task.Recurrence); err != nil {
+			verr.add("recurrence", err.Error())
 		}
-		return ParseDatabaseError(err)
 	}
-	if task.BedID != nil && *task.BedID != "" {
-		var bed models.Bed
-		if err := s.db.First(&bed, "id = ? AND garden_id = ?", *task.BedID, task.GardenID).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return ErrValidation // Referencing a non-existent bed or bed not in the specified garden
-			}
-			return ParseDatabaseError(err)
+	if err := verr.err(); err != nil {
+		return err
+	}
+	// Check that GardenID (and BedID if not nil) exist and belong to the caller
+	if err := s.checkTaskRefs(ctx, userID, task.GardenID, task.BedID); err != nil {
+		return err
+	}
+	task.UserID = userID
+	task.Version = 1
+	task.ID = uuid.New().String() // IDs are never taken from the caller
+
+	if task.Recurrence == "" {
+		result := db.Create(task)
+		if result.Error != nil {
+			return ParseDatabaseError(result.Error)
 		}
+		return nil
 	}
 
-	result := s.db.Create(task)
-	if result.Error != nil {
-		return ParseDatabaseError(result.Error)
+	// A recurring task starts a new series with this task as its first occurrence
+	series := models.TaskSeries{
+		ID:          uuid.New().String(),
+		UserID:      userID,
+		GardenID:    task.GardenID,
+		BedID:       task.BedID,
+		Description: task.Description,
+		Priority:    task.Priority,
+		Recurrence:  rule.String(),
+		Start:       task.DueDate,
+	}
+	dueDate := task.DueDate
+	task.Recurrence = series.Recurrence
+	task.SeriesID = &series.ID
+	task.Occurrence = 1
+	task.RecurrenceID = &dueDate
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&series).Error; err != nil {
+			return err
+		}
+		return tx.Create(task).Error
+	})
+	if err != nil {
+		return ParseDatabaseError(err)
 	}
 	return nil
 }
 
-func (s *GormTaskStore) GetTaskByID(taskID string) (models.Task, error) {
+func (s *GormTaskStore) GetTaskByID(ctx context.Context, userID, taskID string) (models.Task, error) {
+	task, err := s.getStoredTask(ctx, userID, taskID)
+	if err != nil {
+		return models.Task{}, err
+	}
+	tasks := []models.Task{task}
+	if err := evaluateStatuses(s.db.WithContext(ctx), tasks, time.Now()); err != nil {
+		return models.Task{}, err
+	}
+	return tasks[0], nil
+}
+
+// getStoredTask reads a task as it is stored, with the status last stored
+// rather than its current one.
+func (s *GormTaskStore) getStoredTask(ctx context.Context, userID, taskID string) (models.Task, error) {
 	var task models.Task
-	result := s.db.Where("id = ?", taskID).First(&task)
+	result := s.db.WithContext(ctx).Where("id = ? AND user_id = ?", taskID, userID).First(&task)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return models.Task{}, ErrRecordNotFound
 		}
-		return models.Task{}, ErrDatabase // Use custom error
+		return models.Task{}, ParseDatabaseError(result.Error)
 	}
 	return task, nil
 }
 
-func (s *GormTaskStore) UpdateTask(task *models.Task) error {
+func (s *GormTaskStore) GetTaskHistory(ctx context.Context, userID, taskID string) ([]models.TaskEvent, error) {
+	db := s.db.WithContext(ctx)
+	var task models.Task
+	if err := db.First(&task, "id = ? AND user_id = ?", taskID, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, ParseDatabaseError(err)
+	}
+	var events []models.TaskEvent
+	if err := db.Where("task_id = ? AND user_id = ?", taskID, userID).Order("created_at").Find(&events).Error; err != nil {
+		return nil, ParseDatabaseError(err)
+	}
+	return events, nil
+}
+
+func (s *GormTaskStore) UpdateTask(ctx context.Context, userID string, task *models.Task) error {
+	db := s.db.WithContext(ctx)
 	if task.ID == "" { // ID must be present for an update
 		return ErrValidation
 	}
-	if task.Description == "" || task.GardenID == "" { // Assuming Description and GardenID are mandatory
-		return ErrValidation
+	var verr ValidationError
+	validateTaskFields(task, &verr)
+	if err := verr.err(); err != nil {
+		return err
 	}
 
 	// Check if the task to be updated actually exists
 	var existingTask models.Task
-	if err := s.db.First(&existingTask, "id = ?", task.ID).Error; err != nil {
+	if err := db.First(&existingTask, "id = ? AND user_id = ?", task.ID, userID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return ErrRecordNotFound
 		}
 		return ParseDatabaseError(err)
 	}
-
-	// Potentially check if referenced GardenID (and BedID if not nil) exist if they are being changed
-	if task.GardenID != existingTask.GardenID { // If GardenID is part of the update
-		var garden models.Garden
-		if err := s.db.First(&garden, "id = ?", task.GardenID).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return ErrValidation // Referencing a non-existent garden
-			}
-			return ParseDatabaseError(err)
-		}
+	if task.Version != 0 && task.Version != existingTask.Version {
+		return ErrVersionConflict
 	}
-	if task.BedID != nil && (existingTask.BedID == nil || *task.BedID != *existingTask.BedID) {
-		var bed models.Bed
-		if err := s.db.First(&bed, "id = ? AND garden_id = ?", *task.BedID, task.GardenID).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return ErrValidation // Referencing a non-existent bed or bed not in the specified garden
-			}
-			return ParseDatabaseError(err)
-		}
-	} else if task.BedID == nil && existingTask.BedID != nil { // BedID is being set to null
-		// This is allowed, but if there was a check to ensure BedID always belongs to GardenID, it would be here.
+
+	if err := s.checkTaskRefs(ctx, userID, task.GardenID, task.BedID); err != nil {
+		return err
 	}
 
 	// Use a map for updates for explicit field changes and correct handling of zero values.
@@ -129,23 +275,241 @@ func (s *GormTaskStore) UpdateTask(task *models.Task) error {
 		"updated_at":  time.Now(),
 	}
 
-	result := s.db.Model(&models.Task{}).Where("id = ?", task.ID).Updates(updateFields)
-	if result.Error != nil {
-		return ParseDatabaseError(result.Error)
+	// Closing an occurrence of a series schedules the next one
+	var err error
+	if !existingTask.IsRecurring() || existingTask.IsClosed() || !task.IsClosed() {
+		err = updateTaskRow(db, userID, task.ID, task.Version, updateFields)
+	} else {
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := updateTaskRow(tx, userID, task.ID, task.Version, updateFields); err != nil {
+				return err
+			}
+			return createNextOccurrence(tx, userID, existingTask)
+		})
 	}
-	if result.RowsAffected == 0 {
-		return ErrRecordNotFound // Should have been caught by First, but safeguard
+	if err != nil {
+		return ParseDatabaseError(err)
+	}
+	task.Version = existingTask.Version + 1
+	return nil
+}
+
+// UpdateTaskSeries applies task's description, priority, garden, bed and
+// recurrence rule to the series task belongs to and to every open occurrence
+// of it. If task.DueDate differs from the stored occurrence, the whole series
+// is shifted by the same amount. Status is per occurrence and is not changed.
+// An empty Recurrence ends the series after its open occurrences.
+func (s *GormTaskStore) UpdateTaskSeries(ctx context.Context, userID string, task *models.Task) error {
+	db := s.db.WithContext(ctx)
+	if task.ID == "" {
+		return ErrValidation
+	}
+	var verr ValidationError
+	validateTaskFields(task, &verr)
+	ruleString := ""
+	if task.Recurrence != "" {
+		if rule, err := recurrence.Parse(task.Recurrence); err != nil {
+			verr.add("recurrence", err.Error())
+		} else {
+			ruleString = rule.String()
+		}
+	}
+	if err := verr.err(); err != nil {
+		return err
+	}
+
+	var existingTask models.Task
+	if err := db.First(&existingTask, "id = ? AND user_id = ?", task.ID, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRecordNotFound
+		}
+		return ParseDatabaseError(err)
+	}
+	if !existingTask.IsRecurring() {
+		return invalidField("scope", "only occurrences of a recurring task can be edited as a series")
+	}
+	if task.Version != 0 && task.Version != existingTask.Version {
+		return ErrVersionConflict
+	}
+	if err := s.checkTaskRefs(ctx, userID, task.GardenID, task.BedID); err != nil {
+		return err
+	}
+
+	var series models.TaskSeries
+	if err := db.First(&series, "id = ? AND user_id = ?", *existingTask.SeriesID, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRecordNotFound
+		}
+		return ParseDatabaseError(err)
 	}
+	var shift time.Duration
+	if !task.DueDate.IsZero() {
+		shift = task.DueDate.Sub(existingTask.DueDate)
+	}
+
+	now := time.Now()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var open []models.Task
+		if err := tx.Where("series_id = ? AND user_id = ? AND status NOT IN ?", series.ID, userID,
+			[]string{models.TaskStatusCompleted, models.TaskStatusCancelled}).Find(&open).Error; err != nil {
+			return err
+		}
+
+		seriesFields := map[string]interface{}{
+			"description": task.Description,
+			"priority":    task.Priority,
+			"garden_id":   task.GardenID,
+			"bed_id":      task.BedID,
+			"recurrence":  ruleString,
+			"start":       series.Start.Add(shift),
+			"updated_at":  now,
+		}
+		if err := tx.Model(&models.TaskSeries{}).Where("id = ? AND user_id = ?", series.ID, userID).Updates(seriesFields).Error; err != nil {
+			return err
+		}
+
+		for _, occurrence := range open {
+			fields := map[string]interface{}{
+				"description": task.Description,
+				"priority":    task.Priority,
+				"garden_id":   task.GardenID,
+				"bed_id":      task.BedID,
+				"recurrence":  ruleString,
+				"due_date":    occurrence.DueDate.Add(shift),
+				"updated_at":  now,
+			}
+			if occurrence.RecurrenceID != nil {
+				fields["recurrence_id"] = occurrence.RecurrenceID.Add(shift)
+			}
+			// Only the occurrence the caller read is held to its version
+			version := 0
+			if occurrence.ID == task.ID {
+				version = task.Version
+			}
+			if err := updateTaskRow(tx, userID, occurrence.ID, version, fields); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return ParseDatabaseError(err)
+	}
+
+	task.Recurrence = ruleString
+	task.Version = existingTask.Version + 1
+	task.SeriesID = existingTask.SeriesID
+	task.Occurrence = existingTask.Occurrence
+	task.Status = existingTask.Status
+	task.DueDate = existingTask.DueDate.Add(shift)
 	return nil
 }
 
-func (s *GormTaskStore) DeleteTask(taskID string) error {
-	result := s.db.Where("id = ?", taskID).Delete(&models.Task{})
+// checkTaskRefs verifies that the garden, and the bed if set, belong to the user
+// and that the bed is in the garden.
+func (s *GormTaskStore) checkTaskRefs(ctx context.Context, userID, gardenID string, bedID *string) error {
+	db := s.db.WithContext(ctx)
+	var garden models.Garden
+	if err := db.First(&garden, "id = ? AND user_id = ?", gardenID, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return invalidField("garden_id", "garden does not exist")
+		}
+		return ParseDatabaseError(err)
+	}
+	if bedID != nil && *bedID != "" {
+		var bed models.Bed
+		if err := db.First(&bed, "id = ? AND garden_id = ? AND user_id = ?", *bedID, gardenID, userID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return invalidField("garden_bed_id", "bed does not exist in this garden")
+			}
+			return ParseDatabaseError(err)
+		}
+	}
+	return nil
+}
+
+// validateTaskFields records a problem for each field every task must have.
+func validateTaskFields(task *models.Task, verr *ValidationError) {
+	if task.Description == "" {
+		verr.add("description", "is required")
+	}
+	if task.GardenID == "" {
+		verr.add("garden_id", "is required")
+	}
+}
+
+// updateTaskRow applies fields to a single task owned by userID and bumps its
+// version. A non-zero version makes the update conditional on it, returning
+// ErrVersionConflict if the task has moved on. Other errors are raw GORM
+// errors so it can run inside a transaction; callers translate them.
+func updateTaskRow(db *gorm.DB, userID, taskID string, version int, fields map[string]interface{}) error {
+	fields["version"] = gorm.Expr("version + 1")
+	query := db.Model(&models.Task{}).Where("id = ? AND user_id = ?", taskID, userID)
+	if version != 0 {
+		query = query.Where("version = ?", version)
+	}
+	result := query.Updates(fields)
 	if result.Error != nil {
-		return ParseDatabaseError(result.Error) // Use custom error parser
+		return result.Error
 	}
 	if result.RowsAffected == 0 {
-		return ErrRecordNotFound
+		if version != 0 {
+			return ErrVersionConflict
+		}
+		return gorm.ErrRecordNotFound // Should have been caught by First, but safeguard
 	}
 	return nil
 }
+
+// createNextOccurrence adds the occurrence that follows prev in its series,
+// unless the series has ended or that occurrence already exists. The next
+// slot is computed from prev's original slot, so rescheduling one occurrence
+// does not move the rest of the series.
+func createNextOccurrence(tx *gorm.DB, userID string, prev models.Task) error {
+	var series models.TaskSeries
+	if err := tx.First(&series, "id = ? AND user_id = ?", *prev.SeriesID, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil // Series was deleted; nothing to schedule
+		}
+		return err
+	}
+	if series.Recurrence == "" {
+		return nil
+	}
+	rule, err := recurrence.Parse(series.Recurrence)
+	if err != nil {
+		return nil // Stored rules are validated on write; treat a bad one as ended
+	}
+
+	slot := prev.DueDate
+	if prev.RecurrenceID != nil {
+		slot = *prev.RecurrenceID
+	}
+	next, ok := rule.Next(slot, prev.Occurrence)
+	if !ok {
+		return nil
+	}
+
+	var count int64
+	if err := tx.Model(&models.Task{}).Where("series_id = ? AND occurrence = ? AND user_id = ?", series.ID, prev.Occurrence+1, userID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil // Closing, reopening and closing again must not duplicate it
+	}
+
+	occurrence := models.NewTask(series.GardenID, series.BedID, series.Description, next, models.TaskStatusPending, series.Priority)
+	occurrence.UserID = userID
+	occurrence.Recurrence = series.Recurrence
+	occurrence.SeriesID = &series.ID
+	occurrence.Occurrence = prev.Occurrence + 1
+	occurrence.RecurrenceID = &next
+	return tx.Create(&occurrence).Error
+}
+
+// DeleteTask moves a task to the trash, conditionally on its version if
+// version is non-zero.
+func (s *GormTaskStore) DeleteTask(ctx context.Context, userID, taskID string, version int) error {
+	return deleteVersioned(s.db.WithContext(ctx), &models.Task{}, userID, taskID, version)
+}
@@ -1,6 +1,8 @@
 package storage_test
 
 import (
+	"context"
+	"database/sql/driver"
 	"errors"
 	"regexp"
 	"testing"
@@ -35,10 +37,10 @@ func TestGormTaskStore_GetAllTasks_Success(t *testing.T) {
 		AddRow(expectedTasks[0].ID, expectedTasks[0].GardenID, expectedTasks[0].BedID, expectedTasks[0].Description, expectedTasks[0].DueDate, expectedTasks[0].Status, expectedTasks[0].Priority, expectedTasks[0].CreatedAt, expectedTasks[0].UpdatedAt).
 		AddRow(expectedTasks[1].ID, expectedTasks[1].GardenID, expectedTasks[1].BedID, expectedTasks[1].Description, expectedTasks[1].DueDate, expectedTasks[1].Status, expectedTasks[1].Priority, expectedTasks[1].CreatedAt, expectedTasks[1].UpdatedAt)
 
-	sql := `SELECT * FROM "tasks"`
-	mock.ExpectQuery(regexp.QuoteMeta(sql)).WillReturnRows(rows)
+	sql := `SELECT * FROM "tasks" WHERE user_id = $1`
+	mock.ExpectQuery(regexp.QuoteMeta(sql)).WithArgs(testUserID).WillReturnRows(rows)
 
-	actualTasks, err := store.GetAllTasks()
+	actualTasks, err := store.GetAllTasks(context.Background(), testUserID)
 	assert.NoError(t, err)
 	assert.ElementsMatch(t, expectedTasks, actualTasks)
 }
@@ -59,10 +61,10 @@ func TestGormTaskStore_GetTaskByID_Success(t *testing.T) {
 	rows := sqlmock.NewRows([]string{"id", "garden_id", "bed_id", "description", "due_date", "status", "priority", "created_at", "updated_at"}).
 		AddRow(expectedTask.ID, expectedTask.GardenID, expectedTask.BedID, expectedTask.Description, expectedTask.DueDate, expectedTask.Status, expectedTask.Priority, expectedTask.CreatedAt, expectedTask.UpdatedAt)
 
-	sqlSelect := `SELECT * FROM "tasks" WHERE id = $1 ORDER BY "tasks"."id" LIMIT $2`
-	mock.ExpectQuery(regexp.QuoteMeta(sqlSelect)).WithArgs(taskID, 1).WillReturnRows(rows)
+	sqlSelect := `SELECT * FROM "tasks" WHERE (id = $1 AND user_id = $2) AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelect)).WithArgs(taskID, testUserID, 1).WillReturnRows(rows)
 
-	actualTask, err := store.GetTaskByID(taskID)
+	actualTask, err := store.GetTaskByID(context.Background(), testUserID, taskID)
 	assert.NoError(t, err)
 	// Using assert.True and comparing fields individually to avoid time.Location issues in assert.Equal
 	assert.True(t, expectedTask.ID == actualTask.ID &&
@@ -87,23 +89,23 @@ func TestGormTaskStore_CreateTask_Success(t *testing.T) {
 
 	// 1. Mock Garden lookup
 	gardenRows := sqlmock.NewRows([]string{"id"}).AddRow("g1")
-	sqlGardenSelect := `SELECT * FROM "gardens" WHERE id = $1 ORDER BY "gardens"."id" LIMIT $2`
-	mock.ExpectQuery(regexp.QuoteMeta(sqlGardenSelect)).WithArgs(taskToCreate.GardenID, 1).WillReturnRows(gardenRows)
+	sqlGardenSelect := `SELECT * FROM "gardens" WHERE (id = $1 AND user_id = $2) AND "gardens"."deleted_at" IS NULL ORDER BY "gardens"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlGardenSelect)).WithArgs(taskToCreate.GardenID, testUserID, 1).WillReturnRows(gardenRows)
 
 	// 2. Mock Bed lookup (since BedID is provided)
 	bedRows := sqlmock.NewRows([]string{"id", "garden_id"}).AddRow(*taskToCreate.BedID, taskToCreate.GardenID)
-	sqlBedSelect := `SELECT * FROM "beds" WHERE id = $1 AND garden_id = $2 ORDER BY "beds"."id" LIMIT $3`
-	mock.ExpectQuery(regexp.QuoteMeta(sqlBedSelect)).WithArgs(*taskToCreate.BedID, taskToCreate.GardenID, 1).WillReturnRows(bedRows)
+	sqlBedSelect := `SELECT * FROM "beds" WHERE (id = $1 AND garden_id = $2 AND user_id = $3) AND "beds"."deleted_at" IS NULL ORDER BY "beds"."id" LIMIT $4`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlBedSelect)).WithArgs(*taskToCreate.BedID, taskToCreate.GardenID, testUserID, 1).WillReturnRows(bedRows)
 
 	// 3. Mock Task INSERT
 	mock.ExpectBegin()
-	sqlTaskInsert := `INSERT INTO "tasks" ("id","garden_id","bed_id","description","due_date","status","priority","created_at","updated_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)`
+	sqlTaskInsert := `INSERT INTO "tasks" ("id","user_id","garden_id","bed_id","description","due_date","status","priority","recurrence","series_id","occurrence","recurrence_id","version","created_at","updated_at","deleted_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16)`
 	mock.ExpectExec(regexp.QuoteMeta(sqlTaskInsert)).
-		WithArgs(taskToCreate.ID, taskToCreate.GardenID, taskToCreate.BedID, taskToCreate.Description, taskToCreate.DueDate, taskToCreate.Status, taskToCreate.Priority, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WithArgs(sqlmock.AnyArg(), testUserID, taskToCreate.GardenID, taskToCreate.BedID, taskToCreate.Description, taskToCreate.DueDate, taskToCreate.Status, taskToCreate.Priority, "", nil, 0, nil, 1, sqlmock.AnyArg(), sqlmock.AnyArg(), nil).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
-	err := store.CreateTask(taskToCreate)
+	err := store.CreateTask(context.Background(), testUserID, taskToCreate)
 	assert.NoError(t, err)
 }
 
@@ -119,20 +121,20 @@ func TestGormTaskStore_CreateTask_Success_NoBedID(t *testing.T) {
 
 	// 1. Mock Garden lookup
 	gardenRows := sqlmock.NewRows([]string{"id"}).AddRow("g1")
-	sqlGardenSelect := `SELECT * FROM "gardens" WHERE id = $1 ORDER BY "gardens"."id" LIMIT $2`
-	mock.ExpectQuery(regexp.QuoteMeta(sqlGardenSelect)).WithArgs(taskToCreate.GardenID, 1).WillReturnRows(gardenRows)
+	sqlGardenSelect := `SELECT * FROM "gardens" WHERE (id = $1 AND user_id = $2) AND "gardens"."deleted_at" IS NULL ORDER BY "gardens"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlGardenSelect)).WithArgs(taskToCreate.GardenID, testUserID, 1).WillReturnRows(gardenRows)
 
 	// No Bed lookup expected as BedID is nil
 
 	// 2. Mock Task INSERT
 	mock.ExpectBegin()
-	sqlTaskInsert := `INSERT INTO "tasks" ("id","garden_id","bed_id","description","due_date","status","priority","created_at","updated_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)`
+	sqlTaskInsert := `INSERT INTO "tasks" ("id","user_id","garden_id","bed_id","description","due_date","status","priority","recurrence","series_id","occurrence","recurrence_id","version","created_at","updated_at","deleted_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16)`
 	mock.ExpectExec(regexp.QuoteMeta(sqlTaskInsert)).
-		WithArgs(taskToCreate.ID, taskToCreate.GardenID, taskToCreate.BedID, taskToCreate.Description, taskToCreate.DueDate, taskToCreate.Status, taskToCreate.Priority, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WithArgs(sqlmock.AnyArg(), testUserID, taskToCreate.GardenID, taskToCreate.BedID, taskToCreate.Description, taskToCreate.DueDate, taskToCreate.Status, taskToCreate.Priority, "", nil, 0, nil, 1, sqlmock.AnyArg(), sqlmock.AnyArg(), nil).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
-	err := store.CreateTask(taskToCreate)
+	err := store.CreateTask(context.Background(), testUserID, taskToCreate)
 	assert.NoError(t, err)
 }
 
@@ -146,11 +148,12 @@ func TestGormTaskStore_CreateTask_GardenNotFound(t *testing.T) {
 
 	taskToCreate := &models.Task{GardenID: "nonexistent_g1", Description: "Task for non-existent garden"}
 
-	sqlGardenSelect := `SELECT * FROM "gardens" WHERE id = $1 ORDER BY "gardens"."id" LIMIT $2`
-	mock.ExpectQuery(regexp.QuoteMeta(sqlGardenSelect)).WithArgs(taskToCreate.GardenID, 1).WillReturnError(gorm.ErrRecordNotFound)
+	sqlGardenSelect := `SELECT * FROM "gardens" WHERE (id = $1 AND user_id = $2) AND "gardens"."deleted_at" IS NULL ORDER BY "gardens"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlGardenSelect)).WithArgs(taskToCreate.GardenID, testUserID, 1).WillReturnError(gorm.ErrRecordNotFound)
 
-	err := store.CreateTask(taskToCreate)
+	err := store.CreateTask(context.Background(), testUserID, taskToCreate)
 	assert.ErrorIs(t, err, storage.ErrValidation)
+	assert.Equal(t, []storage.FieldError{{Field: "garden_id", Message: "garden does not exist"}}, storage.FieldErrors(err))
 }
 
 func TestGormTaskStore_CreateTask_BedNotFound(t *testing.T) {
@@ -166,15 +169,16 @@ func TestGormTaskStore_CreateTask_BedNotFound(t *testing.T) {
 
 	// 1. Mock Garden lookup (success)
 	gardenRows := sqlmock.NewRows([]string{"id"}).AddRow("g1")
-	sqlGardenSelect := `SELECT * FROM "gardens" WHERE id = $1 ORDER BY "gardens"."id" LIMIT $2`
-	mock.ExpectQuery(regexp.QuoteMeta(sqlGardenSelect)).WithArgs(taskToCreate.GardenID, 1).WillReturnRows(gardenRows)
+	sqlGardenSelect := `SELECT * FROM "gardens" WHERE (id = $1 AND user_id = $2) AND "gardens"."deleted_at" IS NULL ORDER BY "gardens"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlGardenSelect)).WithArgs(taskToCreate.GardenID, testUserID, 1).WillReturnRows(gardenRows)
 
 	// 2. Mock Bed lookup (fail)
-	sqlBedSelect := `SELECT * FROM "beds" WHERE id = $1 AND garden_id = $2 ORDER BY "beds"."id" LIMIT $3`
-	mock.ExpectQuery(regexp.QuoteMeta(sqlBedSelect)).WithArgs(*taskToCreate.BedID, taskToCreate.GardenID, 1).WillReturnError(gorm.ErrRecordNotFound)
+	sqlBedSelect := `SELECT * FROM "beds" WHERE (id = $1 AND garden_id = $2 AND user_id = $3) AND "beds"."deleted_at" IS NULL ORDER BY "beds"."id" LIMIT $4`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlBedSelect)).WithArgs(*taskToCreate.BedID, taskToCreate.GardenID, testUserID, 1).WillReturnError(gorm.ErrRecordNotFound)
 
-	err := store.CreateTask(taskToCreate)
+	err := store.CreateTask(context.Background(), testUserID, taskToCreate)
 	assert.ErrorIs(t, err, storage.ErrValidation)
+	assert.Equal(t, []storage.FieldError{{Field: "garden_bed_id", Message: "bed does not exist in this garden"}}, storage.FieldErrors(err))
 }
 
 func TestGormTaskStore_CreateTask_MissingFieldsValidationError(t *testing.T) {
@@ -188,13 +192,21 @@ func TestGormTaskStore_CreateTask_MissingFieldsValidationError(t *testing.T) {
 
 	// Test case 1: Missing Description
 	taskMissingDesc := &models.Task{GardenID: "g1"}
-	err := store.CreateTask(taskMissingDesc)
+	err := store.CreateTask(context.Background(), testUserID, taskMissingDesc)
 	assert.ErrorIs(t, err, storage.ErrValidation, "Expected ErrValidation for missing description")
 
 	// Test case 2: Missing GardenID
 	taskMissingGardenID := &models.Task{Description: "Valid Description"}
-	err = store.CreateTask(taskMissingGardenID)
+	err = store.CreateTask(context.Background(), testUserID, taskMissingGardenID)
 	assert.ErrorIs(t, err, storage.ErrValidation, "Expected ErrValidation for missing GardenID")
+
+	// Test case 3: Every problem is reported, not just the first
+	err = store.CreateTask(context.Background(), testUserID, &models.Task{Recurrence: "FREQ=HOURLY"})
+	assert.Equal(t, []storage.FieldError{
+		{Field: "description", Message: "is required"},
+		{Field: "garden_id", Message: "is required"},
+		{Field: "recurrence", Message: "invalid recurrence rule: unsupported FREQ HOURLY"},
+	}, storage.FieldErrors(err))
 }
 
 func TestGormTaskStore_CreateTask_DBErrorOnInsert(t *testing.T) {
@@ -210,20 +222,20 @@ func TestGormTaskStore_CreateTask_DBErrorOnInsert(t *testing.T) {
 
 	// 1. Mock Garden lookup (success)
 	gardenRows := sqlmock.NewRows([]string{"id"}).AddRow("g1")
-	sqlGardenSelect := `SELECT * FROM "gardens" WHERE id = $1 ORDER BY "gardens"."id" LIMIT $2`
-	mock.ExpectQuery(regexp.QuoteMeta(sqlGardenSelect)).WithArgs(taskToCreate.GardenID, 1).WillReturnRows(gardenRows)
+	sqlGardenSelect := `SELECT * FROM "gardens" WHERE (id = $1 AND user_id = $2) AND "gardens"."deleted_at" IS NULL ORDER BY "gardens"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlGardenSelect)).WithArgs(taskToCreate.GardenID, testUserID, 1).WillReturnRows(gardenRows)
 
 	// No Bed lookup if BedID is nil
 
 	// 2. Mock Task INSERT (fail)
 	mock.ExpectBegin()
-	sqlTaskInsert := `INSERT INTO "tasks" ("id","garden_id","bed_id","description","due_date","status","priority","created_at","updated_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)`
+	sqlTaskInsert := `INSERT INTO "tasks" ("id","user_id","garden_id","bed_id","description","due_date","status","priority","recurrence","series_id","occurrence","recurrence_id","version","created_at","updated_at","deleted_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16)`
 	mock.ExpectExec(regexp.QuoteMeta(sqlTaskInsert)).
-		WithArgs(taskToCreate.ID, taskToCreate.GardenID, taskToCreate.BedID, taskToCreate.Description, taskToCreate.DueDate, taskToCreate.Status, taskToCreate.Priority, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WithArgs(sqlmock.AnyArg(), testUserID, taskToCreate.GardenID, taskToCreate.BedID, taskToCreate.Description, taskToCreate.DueDate, taskToCreate.Status, taskToCreate.Priority, "", nil, 0, nil, 1, sqlmock.AnyArg(), sqlmock.AnyArg(), nil).
 		WillReturnError(dbErr)
 	mock.ExpectRollback()
 
-	err := store.CreateTask(taskToCreate)
+	err := store.CreateTask(context.Background(), testUserID, taskToCreate)
 	assert.ErrorIs(t, err, storage.ErrDatabase)
 }
 
@@ -257,23 +269,28 @@ func TestGormTaskStore_UpdateTask_Success(t *testing.T) {
 		now.Add(-24*time.Hour), "Todo", "Medium", now.Add(-48*time.Hour), now.Add(-48*time.Hour),
 	)
 	// NOTE: GORM for PostgreSQL doesn't add table name to the column name in the WHERE clause
-	sqlSelectTask := `SELECT * FROM "tasks" WHERE id = $1 ORDER BY "tasks"."id" LIMIT $2`
-	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectTask)).WithArgs(taskID, 1).WillReturnRows(existingTaskRows)
+	sqlSelectTask := `SELECT * FROM "tasks" WHERE (id = $1 AND user_id = $2) AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectTask)).WithArgs(taskID, testUserID, 1).WillReturnRows(existingTaskRows)
+
+	// The garden and bed are checked whether or not they changed
+	gardenRows := sqlmock.NewRows([]string{"id"}).AddRow("g1")
+	sqlGardenSelect := `SELECT * FROM "gardens" WHERE (id = $1 AND user_id = $2) AND "gardens"."deleted_at" IS NULL ORDER BY "gardens"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlGardenSelect)).WithArgs("g1", testUserID, 1).WillReturnRows(gardenRows)
 
 	// 2a. Mock Bed Lookup (validateTaskDataAndRefs - s.db.First(&bed...))
 	bedRows := sqlmock.NewRows([]string{"id", "garden_id"}).AddRow(*taskToUpdate.BedID, taskToUpdate.GardenID)
-	sqlBedSelect := `SELECT * FROM "beds" WHERE id = $1 AND garden_id = $2 ORDER BY "beds"."id" LIMIT $3`
-	mock.ExpectQuery(regexp.QuoteMeta(sqlBedSelect)).WithArgs(*taskToUpdate.BedID, taskToUpdate.GardenID, 1).WillReturnRows(bedRows)
+	sqlBedSelect := `SELECT * FROM "beds" WHERE (id = $1 AND garden_id = $2 AND user_id = $3) AND "beds"."deleted_at" IS NULL ORDER BY "beds"."id" LIMIT $4`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlBedSelect)).WithArgs(*taskToUpdate.BedID, taskToUpdate.GardenID, testUserID, 1).WillReturnRows(bedRows)
 
 	// 3. Mock Task UPDATE
 	mock.ExpectBegin()
-	sqlTaskUpdate := `UPDATE "tasks" SET "bed_id"=$1,"description"=$2,"due_date"=$3,"garden_id"=$4,"priority"=$5,"status"=$6,"updated_at"=$7 WHERE id = $8`
+	sqlTaskUpdate := `UPDATE "tasks" SET "bed_id"=$1,"description"=$2,"due_date"=$3,"garden_id"=$4,"priority"=$5,"status"=$6,"updated_at"=$7,"version"=version + 1 WHERE (id = $8 AND user_id = $9) AND "tasks"."deleted_at" IS NULL`
 	mock.ExpectExec(regexp.QuoteMeta(sqlTaskUpdate)).
-		WithArgs(taskToUpdate.BedID, taskToUpdate.Description, taskToUpdate.DueDate, taskToUpdate.GardenID, taskToUpdate.Priority, taskToUpdate.Status, sqlmock.AnyArg(), taskToUpdate.ID).
+		WithArgs(taskToUpdate.BedID, taskToUpdate.Description, taskToUpdate.DueDate, taskToUpdate.GardenID, taskToUpdate.Priority, taskToUpdate.Status, sqlmock.AnyArg(), taskToUpdate.ID, testUserID).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 	mock.ExpectCommit()
 
-	err := store.UpdateTask(taskToUpdate)
+	err := store.UpdateTask(context.Background(), testUserID, taskToUpdate)
 	assert.NoError(t, err)
 }
 
@@ -305,21 +322,26 @@ func TestGormTaskStore_UpdateTask_Success_BedIDToNil(t *testing.T) {
 		time.Now().Add(-48*time.Hour), time.Now().Add(-48*time.Hour),
 	)
 	// NOTE: GORM for PostgreSQL doesn't add table name to the column name in the WHERE clause
-	sqlSelectTask := `SELECT * FROM "tasks" WHERE id = $1 ORDER BY "tasks"."id" LIMIT $2`
-	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectTask)).WithArgs(taskID, 1).WillReturnRows(existingTaskRows)
+	sqlSelectTask := `SELECT * FROM "tasks" WHERE (id = $1 AND user_id = $2) AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectTask)).WithArgs(taskID, testUserID, 1).WillReturnRows(existingTaskRows)
+
+	// The garden and bed are checked whether or not they changed
+	gardenRows := sqlmock.NewRows([]string{"id"}).AddRow("g1")
+	sqlGardenSelect := `SELECT * FROM "gardens" WHERE (id = $1 AND user_id = $2) AND "gardens"."deleted_at" IS NULL ORDER BY "gardens"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlGardenSelect)).WithArgs("g1", testUserID, 1).WillReturnRows(gardenRows)
 
 	// 3. Mock Task UPDATE
 	mock.ExpectBegin()
 	// updateFields in GormTaskStore.UpdateTask for nil BedID will include "bed_id": nil
 	// Alphabetical order of likely fields being updated (assuming others are zero/empty and included):
 	// bed_id, description, due_date (zero), garden_id, priority (empty), status (empty), updated_at
-	sqlTaskUpdate := `UPDATE "tasks" SET "bed_id"=$1,"description"=$2,"due_date"=$3,"garden_id"=$4,"priority"=$5,"status"=$6,"updated_at"=$7 WHERE id = $8`
+	sqlTaskUpdate := `UPDATE "tasks" SET "bed_id"=$1,"description"=$2,"due_date"=$3,"garden_id"=$4,"priority"=$5,"status"=$6,"updated_at"=$7,"version"=version + 1 WHERE (id = $8 AND user_id = $9) AND "tasks"."deleted_at" IS NULL`
 	mock.ExpectExec(regexp.QuoteMeta(sqlTaskUpdate)).
-		WithArgs(nil, taskToUpdate.Description, taskToUpdate.DueDate, taskToUpdate.GardenID, taskToUpdate.Priority, taskToUpdate.Status, sqlmock.AnyArg(), taskToUpdate.ID).
+		WithArgs(nil, taskToUpdate.Description, taskToUpdate.DueDate, taskToUpdate.GardenID, taskToUpdate.Priority, taskToUpdate.Status, sqlmock.AnyArg(), taskToUpdate.ID, testUserID).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 	mock.ExpectCommit()
 
-	err := store.UpdateTask(taskToUpdate)
+	err := store.UpdateTask(context.Background(), testUserID, taskToUpdate)
 	assert.NoError(t, err)
 }
 
@@ -337,7 +359,7 @@ func TestGormTaskStore_UpdateTask_NotFound(t *testing.T) {
 
 	// No DB mocks needed, as initial validation (empty GardenID) should fail.
 
-	err := store.UpdateTask(taskToUpdate)
+	err := store.UpdateTask(context.Background(), testUserID, taskToUpdate)
 	assert.ErrorIs(t, err, storage.ErrValidation) // Expect ErrValidation due to empty GardenID
 }
 
@@ -354,10 +376,10 @@ func TestGormTaskStore_UpdateTask_ActualRecordNotFound(t *testing.T) {
 
 	// 1. Mock Task Lookup to return gorm.ErrRecordNotFound
 	// NOTE: GORM for PostgreSQL doesn't add table name to the column name in the WHERE clause
-	sqlSelectTask := `SELECT * FROM "tasks" WHERE id = $1 ORDER BY "tasks"."id" LIMIT $2`
-	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectTask)).WithArgs(taskToUpdate.ID, 1).WillReturnError(gorm.ErrRecordNotFound)
+	sqlSelectTask := `SELECT * FROM "tasks" WHERE (id = $1 AND user_id = $2) AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectTask)).WithArgs(taskToUpdate.ID, testUserID, 1).WillReturnError(gorm.ErrRecordNotFound)
 
-	err := store.UpdateTask(taskToUpdate)
+	err := store.UpdateTask(context.Background(), testUserID, taskToUpdate)
 	assert.ErrorIs(t, err, storage.ErrRecordNotFound)
 }
 
@@ -376,7 +398,7 @@ func TestGormTaskStore_UpdateTask_ValidationError_EmptyDescription(t *testing.T)
 
 	// No DB mocks needed, as initial validation (empty Description) should fail.
 
-	err := store.UpdateTask(taskToUpdate)
+	err := store.UpdateTask(context.Background(), testUserID, taskToUpdate)
 	assert.ErrorIs(t, err, storage.ErrValidation)
 }
 
@@ -401,14 +423,14 @@ func TestGormTaskStore_UpdateTask_ValidationError_GardenNotFound(t *testing.T) {
 		time.Now().Add(-48*time.Hour), time.Now().Add(-48*time.Hour),
 	)
 	// NOTE: GORM for PostgreSQL doesn't add table name to the column name in the WHERE clause
-	sqlSelectTask := `SELECT * FROM "tasks" WHERE id = $1 ORDER BY "tasks"."id" LIMIT $2`
-	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectTask)).WithArgs(taskID, 1).WillReturnRows(existingTaskRows)
+	sqlSelectTask := `SELECT * FROM "tasks" WHERE (id = $1 AND user_id = $2) AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectTask)).WithArgs(taskID, testUserID, 1).WillReturnRows(existingTaskRows)
 
 	// 2. Mock Garden lookup (fails) for the new GardenID
-	sqlGardenSelect := `SELECT * FROM "gardens" WHERE id = $1 ORDER BY "gardens"."id" LIMIT $2`
-	mock.ExpectQuery(regexp.QuoteMeta(sqlGardenSelect)).WithArgs(taskToUpdate.GardenID, 1).WillReturnError(gorm.ErrRecordNotFound)
+	sqlGardenSelect := `SELECT * FROM "gardens" WHERE (id = $1 AND user_id = $2) AND "gardens"."deleted_at" IS NULL ORDER BY "gardens"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlGardenSelect)).WithArgs(taskToUpdate.GardenID, testUserID, 1).WillReturnError(gorm.ErrRecordNotFound)
 
-	err := store.UpdateTask(taskToUpdate)
+	err := store.UpdateTask(context.Background(), testUserID, taskToUpdate)
 	assert.ErrorIs(t, err, storage.ErrValidation)
 }
 
@@ -433,16 +455,19 @@ func TestGormTaskStore_UpdateTask_ValidationError_BedNotFound(t *testing.T) {
 		time.Now().Add(-48*time.Hour), time.Now().Add(-48*time.Hour),
 	)
 	// NOTE: GORM for PostgreSQL doesn't add table name to the column name in the WHERE clause
-	sqlSelectTask := `SELECT * FROM "tasks" WHERE id = $1 ORDER BY "tasks"."id" LIMIT $2`
-	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectTask)).WithArgs(taskID, 1).WillReturnRows(existingTaskRows)
+	sqlSelectTask := `SELECT * FROM "tasks" WHERE (id = $1 AND user_id = $2) AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectTask)).WithArgs(taskID, testUserID, 1).WillReturnRows(existingTaskRows)
 
-	// 2. Mock Bed lookup (fails) for BedID "nonexistent_b_update" in Garden "g1"
-	sqlBedSelect := `SELECT * FROM "beds" WHERE id = $1 AND garden_id = $2 ORDER BY "beds"."id" LIMIT $3`
-	mock.ExpectQuery(regexp.QuoteMeta(sqlBedSelect)).WithArgs(*taskToUpdate.BedID, taskToUpdate.GardenID, 1).WillReturnError(gorm.ErrRecordNotFound)
+	// The garden and bed are checked whether or not they changed
+	gardenRows := sqlmock.NewRows([]string{"id"}).AddRow("g1")
+	sqlGardenSelect := `SELECT * FROM "gardens" WHERE (id = $1 AND user_id = $2) AND "gardens"."deleted_at" IS NULL ORDER BY "gardens"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlGardenSelect)).WithArgs("g1", testUserID, 1).WillReturnRows(gardenRows)
 
-	// No garden lookup should happen as validation fails on bed lookup
+	// 2. Mock Bed lookup (fails) for BedID "nonexistent_b_update" in Garden "g1"
+	sqlBedSelect := `SELECT * FROM "beds" WHERE (id = $1 AND garden_id = $2 AND user_id = $3) AND "beds"."deleted_at" IS NULL ORDER BY "beds"."id" LIMIT $4`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlBedSelect)).WithArgs(*taskToUpdate.BedID, taskToUpdate.GardenID, testUserID, 1).WillReturnError(gorm.ErrRecordNotFound)
 
-	err := store.UpdateTask(taskToUpdate)
+	err := store.UpdateTask(context.Background(), testUserID, taskToUpdate)
 	assert.ErrorIs(t, err, storage.ErrValidation)
 }
 
@@ -458,10 +483,10 @@ func TestGormTaskStore_UpdateTask_DBErrorOnSelect(t *testing.T) {
 	dbErr := errors.New("select task for update failed")
 
 	// NOTE: GORM for PostgreSQL doesn't add table name to the column name in the WHERE clause
-	sqlSelectTask := `SELECT * FROM "tasks" WHERE id = $1 ORDER BY "tasks"."id" LIMIT $2`
-	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectTask)).WithArgs(taskToUpdate.ID, 1).WillReturnError(dbErr)
+	sqlSelectTask := `SELECT * FROM "tasks" WHERE (id = $1 AND user_id = $2) AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectTask)).WithArgs(taskToUpdate.ID, testUserID, 1).WillReturnError(dbErr)
 
-	err := store.UpdateTask(taskToUpdate)
+	err := store.UpdateTask(context.Background(), testUserID, taskToUpdate)
 	assert.ErrorIs(t, err, storage.ErrDatabase)
 }
 
@@ -505,24 +530,29 @@ func TestGormTaskStore_UpdateTask_DBErrorOnUpdate(t *testing.T) {
 		originalTaskDueDate, originalTaskStatus, originalTaskPriority, originalTaskCreatedAt, originalTaskUpdatedAt,
 	)
 	// NOTE: GORM for PostgreSQL doesn't add table name to the column name in the WHERE clause
-	sqlSelectTask := `SELECT * FROM "tasks" WHERE id = $1 ORDER BY "tasks"."id" LIMIT $2`
-	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectTask)).WithArgs(taskID, 1).WillReturnRows(existingTaskRows)
+	sqlSelectTask := `SELECT * FROM "tasks" WHERE (id = $1 AND user_id = $2) AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectTask)).WithArgs(taskID, testUserID, 1).WillReturnRows(existingTaskRows)
+
+	// The garden and bed are checked whether or not they changed
+	gardenRows := sqlmock.NewRows([]string{"id"}).AddRow("g1")
+	sqlGardenSelect := `SELECT * FROM "gardens" WHERE (id = $1 AND user_id = $2) AND "gardens"."deleted_at" IS NULL ORDER BY "gardens"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlGardenSelect)).WithArgs("g1", testUserID, 1).WillReturnRows(gardenRows)
 
 	// 2a. Mock Bed Lookup (succeeds, since BedID is provided in taskToUpdate) - for validating *taskToUpdate.BedID
 	// Note: This is moved before garden lookup based on the implementation sequence
 	bedRows := sqlmock.NewRows([]string{"id", "garden_id"}).AddRow(*taskToUpdate.BedID, taskToUpdate.GardenID)
-	sqlBedSelect := `SELECT * FROM "beds" WHERE id = $1 AND garden_id = $2 ORDER BY "beds"."id" LIMIT $3`
-	mock.ExpectQuery(regexp.QuoteMeta(sqlBedSelect)).WithArgs(*taskToUpdate.BedID, taskToUpdate.GardenID, 1).WillReturnRows(bedRows)
+	sqlBedSelect := `SELECT * FROM "beds" WHERE (id = $1 AND garden_id = $2 AND user_id = $3) AND "beds"."deleted_at" IS NULL ORDER BY "beds"."id" LIMIT $4`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlBedSelect)).WithArgs(*taskToUpdate.BedID, taskToUpdate.GardenID, testUserID, 1).WillReturnRows(bedRows)
 
 	// 3. Mock Task UPDATE (fails)
 	mock.ExpectBegin()
-	sqlTaskUpdate := `UPDATE "tasks" SET "bed_id"=$1,"description"=$2,"due_date"=$3,"garden_id"=$4,"priority"=$5,"status"=$6,"updated_at"=$7 WHERE id = $8`
+	sqlTaskUpdate := `UPDATE "tasks" SET "bed_id"=$1,"description"=$2,"due_date"=$3,"garden_id"=$4,"priority"=$5,"status"=$6,"updated_at"=$7,"version"=version + 1 WHERE (id = $8 AND user_id = $9) AND "tasks"."deleted_at" IS NULL`
 	mock.ExpectExec(regexp.QuoteMeta(sqlTaskUpdate)).
-		WithArgs(taskToUpdate.BedID, taskToUpdate.Description, taskToUpdate.DueDate, taskToUpdate.GardenID, taskToUpdate.Priority, taskToUpdate.Status, sqlmock.AnyArg(), taskToUpdate.ID).
+		WithArgs(taskToUpdate.BedID, taskToUpdate.Description, taskToUpdate.DueDate, taskToUpdate.GardenID, taskToUpdate.Priority, taskToUpdate.Status, sqlmock.AnyArg(), taskToUpdate.ID, testUserID).
 		WillReturnError(dbUpdateErr)
 	mock.ExpectRollback()
 
-	err := store.UpdateTask(taskToUpdate)
+	err := store.UpdateTask(context.Background(), testUserID, taskToUpdate)
 	assert.ErrorIs(t, err, storage.ErrDatabase)
 }
 
@@ -537,11 +567,11 @@ func TestGormTaskStore_DeleteTask_Success(t *testing.T) {
 	taskIDToDelete := "t1_delete"
 
 	mock.ExpectBegin()
-	sqlDelete := `DELETE FROM "tasks" WHERE id = $1`
-	mock.ExpectExec(regexp.QuoteMeta(sqlDelete)).WithArgs(taskIDToDelete).WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlDelete := `UPDATE "tasks" SET "deleted_at"=$1 WHERE (id = $2 AND user_id = $3) AND "tasks"."deleted_at" IS NULL`
+	mock.ExpectExec(regexp.QuoteMeta(sqlDelete)).WithArgs(sqlmock.AnyArg(), taskIDToDelete, testUserID).WillReturnResult(sqlmock.NewResult(0, 1))
 	mock.ExpectCommit()
 
-	err := store.DeleteTask(taskIDToDelete)
+	err := store.DeleteTask(context.Background(), testUserID, taskIDToDelete, 0)
 	assert.NoError(t, err)
 }
 
@@ -556,11 +586,11 @@ func TestGormTaskStore_DeleteTask_NotFound(t *testing.T) {
 	taskIDToDelete := "nonexistent_task_delete"
 
 	mock.ExpectBegin()
-	sqlDelete := `DELETE FROM "tasks" WHERE id = $1`
-	mock.ExpectExec(regexp.QuoteMeta(sqlDelete)).WithArgs(taskIDToDelete).WillReturnResult(sqlmock.NewResult(0, 0))
+	sqlDelete := `UPDATE "tasks" SET "deleted_at"=$1 WHERE (id = $2 AND user_id = $3) AND "tasks"."deleted_at" IS NULL`
+	mock.ExpectExec(regexp.QuoteMeta(sqlDelete)).WithArgs(sqlmock.AnyArg(), taskIDToDelete, testUserID).WillReturnResult(sqlmock.NewResult(0, 0))
 	mock.ExpectCommit()
 
-	err := store.DeleteTask(taskIDToDelete)
+	err := store.DeleteTask(context.Background(), testUserID, taskIDToDelete, 0)
 	assert.ErrorIs(t, err, storage.ErrRecordNotFound)
 }
 
@@ -576,30 +606,262 @@ func TestGormTaskStore_DeleteTask_DBError(t *testing.T) {
 	dbErr := errors.New("DB delete error")
 
 	mock.ExpectBegin()
-	sqlDelete := `DELETE FROM "tasks" WHERE id = $1`
-	mock.ExpectExec(regexp.QuoteMeta(sqlDelete)).WithArgs(taskIDToDelete).WillReturnError(dbErr)
+	sqlDelete := `UPDATE "tasks" SET "deleted_at"=$1 WHERE (id = $2 AND user_id = $3) AND "tasks"."deleted_at" IS NULL`
+	mock.ExpectExec(regexp.QuoteMeta(sqlDelete)).WithArgs(sqlmock.AnyArg(), taskIDToDelete, testUserID).WillReturnError(dbErr)
 	mock.ExpectRollback()
 
-	err := store.DeleteTask(taskIDToDelete)
+	err := store.DeleteTask(context.Background(), testUserID, taskIDToDelete, 0)
 	assert.ErrorIs(t, err, storage.ErrDatabase)
 }
 
-// Test for GetTasksByBedID - if you add this method to TaskStorer
-/*
 func TestGormTaskStore_GetTasksByBedID_Success(t *testing.T) {
-    // ... setup ...
-    bedID := "b1"
-    // ... mock expectations for SELECT * FROM "tasks" WHERE bed_id = $1 ...
-    // ... assertions ...
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormTaskStore(db)
+	rawSqlDB, errDB := db.DB()
+	require.NoError(t, errDB)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	gardenID, bedID := "g1", "b1"
+	sqlGardenSelect := `SELECT * FROM "gardens" WHERE (id = $1 AND user_id = $2) AND "gardens"."deleted_at" IS NULL ORDER BY "gardens"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlGardenSelect)).WithArgs(gardenID, testUserID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(gardenID))
+	sqlBedSelect := `SELECT * FROM "beds" WHERE (id = $1 AND garden_id = $2 AND user_id = $3) AND "beds"."deleted_at" IS NULL ORDER BY "beds"."id" LIMIT $4`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlBedSelect)).WithArgs(bedID, gardenID, testUserID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(bedID))
+
+	due := time.Now().Add(24 * time.Hour)
+	sql := `SELECT * FROM "tasks" WHERE (garden_id = $1 AND bed_id = $2 AND user_id = $3) AND "tasks"."deleted_at" IS NULL ORDER BY due_date ASC,id ASC LIMIT $4`
+	mock.ExpectQuery(regexp.QuoteMeta(sql)).WithArgs(gardenID, bedID, testUserID, storage.DefaultPageSize+1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "garden_id", "bed_id", "due_date", "status"}).
+			AddRow("t1", gardenID, bedID, due, models.TaskStatusPending))
+
+	page, err := store.GetTasksByBedID(context.Background(), testUserID, gardenID, bedID, storage.ListQuery{})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, "t1", page.Items[0].ID)
+}
+
+func TestGormTaskStore_GetTasksByBedID_BedNotInGarden(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormTaskStore(db)
+	rawSqlDB, errDB := db.DB()
+	require.NoError(t, errDB)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	gardenID, bedID := "g1", "b_other"
+	sqlGardenSelect := `SELECT * FROM "gardens" WHERE (id = $1 AND user_id = $2) AND "gardens"."deleted_at" IS NULL ORDER BY "gardens"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlGardenSelect)).WithArgs(gardenID, testUserID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(gardenID))
+	sqlBedSelect := `SELECT * FROM "beds" WHERE (id = $1 AND garden_id = $2 AND user_id = $3) AND "beds"."deleted_at" IS NULL ORDER BY "beds"."id" LIMIT $4`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlBedSelect)).WithArgs(bedID, gardenID, testUserID, 1).
+		WillReturnError(gorm.ErrRecordNotFound)
+
+	_, err := store.GetTasksByBedID(context.Background(), testUserID, gardenID, bedID, storage.ListQuery{})
+	assert.ErrorIs(t, err, storage.ErrRecordNotFound)
 }
-*/
 
-// Test for GetTasksByGardenID - if you add this method to TaskStorer
-/*
 func TestGormTaskStore_GetTasksByGardenID_Success(t *testing.T) {
-    // ... setup ...
-    gardenID := "g1"
-    // ... mock expectations for SELECT * FROM "tasks" WHERE garden_id = $1 ...
-    // ... assertions ...
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormTaskStore(db)
+	rawSqlDB, errDB := db.DB()
+	require.NoError(t, errDB)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	gardenID := "g1"
+	sqlGardenSelect := `SELECT * FROM "gardens" WHERE (id = $1 AND user_id = $2) AND "gardens"."deleted_at" IS NULL ORDER BY "gardens"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlGardenSelect)).WithArgs(gardenID, testUserID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(gardenID))
+
+	sql := regexp.QuoteMeta(`SELECT * FROM "tasks" WHERE (garden_id = $1 AND user_id = $2) AND `) + currentStatusIn(15) +
+		regexp.QuoteMeta(` AND "tasks"."deleted_at" IS NULL ORDER BY due_date ASC,id ASC LIMIT $16`)
+	args := append([]driver.Value{gardenID, testUserID}, currentStatusArgs...)
+	mock.ExpectQuery(sql).WithArgs(append(args, models.TaskStatusCompleted, storage.DefaultPageSize+1)...).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	page, err := store.GetTasksByGardenID(context.Background(), testUserID, gardenID, storage.ListQuery{
+		Filters: map[string]string{"status": models.TaskStatusCompleted},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, page.Items)
+}
+
+func TestGormTaskStore_GetTasksByGardenID_GardenNotFound(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormTaskStore(db)
+	rawSqlDB, errDB := db.DB()
+	require.NoError(t, errDB)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	sqlGardenSelect := `SELECT * FROM "gardens" WHERE (id = $1 AND user_id = $2) AND "gardens"."deleted_at" IS NULL ORDER BY "gardens"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlGardenSelect)).WithArgs("g_missing", testUserID, 1).
+		WillReturnError(gorm.ErrRecordNotFound)
+
+	_, err := store.GetTasksByGardenID(context.Background(), testUserID, "g_missing", storage.ListQuery{})
+	assert.ErrorIs(t, err, storage.ErrRecordNotFound)
+}
+
+func TestGormTaskStore_CreateTask_RecurringStartsSeries(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormTaskStore(db)
+	rawSqlDB, errDB := db.DB()
+	require.NoError(t, errDB)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	dueDate := time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC)
+	taskToCreate := &models.Task{ID: "t_water", GardenID: "g1", Description: "Water tomatoes", DueDate: dueDate, Status: "Pending", Priority: "High", Recurrence: "every 2 days"}
+
+	gardenRows := sqlmock.NewRows([]string{"id"}).AddRow("g1")
+	sqlGardenSelect := `SELECT * FROM "gardens" WHERE (id = $1 AND user_id = $2) AND "gardens"."deleted_at" IS NULL ORDER BY "gardens"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlGardenSelect)).WithArgs("g1", testUserID, 1).WillReturnRows(gardenRows)
+
+	mock.ExpectBegin()
+	sqlSeriesInsert := `INSERT INTO "task_series" ("id","user_id","garden_id","bed_id","description","priority","recurrence","start","created_at","updated_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)`
+	mock.ExpectExec(regexp.QuoteMeta(sqlSeriesInsert)).
+		WithArgs(sqlmock.AnyArg(), testUserID, "g1", nil, "Water tomatoes", "High", "FREQ=DAILY;INTERVAL=2", dueDate, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	sqlTaskInsert := `INSERT INTO "tasks" ("id","user_id","garden_id","bed_id","description","due_date","status","priority","recurrence","series_id","occurrence","recurrence_id","version","created_at","updated_at","deleted_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16)`
+	mock.ExpectExec(regexp.QuoteMeta(sqlTaskInsert)).
+		WithArgs(sqlmock.AnyArg(), testUserID, "g1", nil, "Water tomatoes", dueDate, "Pending", "High", "FREQ=DAILY;INTERVAL=2", sqlmock.AnyArg(), 1, dueDate, 1, sqlmock.AnyArg(), sqlmock.AnyArg(), nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err := store.CreateTask(context.Background(), testUserID, taskToCreate)
+	require.NoError(t, err)
+	assert.True(t, taskToCreate.IsRecurring())
+	assert.Equal(t, 1, taskToCreate.Occurrence)
+	assert.Equal(t, "FREQ=DAILY;INTERVAL=2", taskToCreate.Recurrence)
+}
+
+func TestGormTaskStore_CreateTask_InvalidRecurrence(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormTaskStore(db)
+	rawSqlDB, errDB := db.DB()
+	require.NoError(t, errDB)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	taskToCreate := &models.Task{GardenID: "g1", Description: "Prune", Recurrence: "FREQ=HOURLY"}
+
+	err := store.CreateTask(context.Background(), testUserID, taskToCreate)
+	assert.ErrorIs(t, err, storage.ErrValidation)
+}
+
+func TestGormTaskStore_UpdateTask_CompletingOccurrenceCreatesNext(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormTaskStore(db)
+	rawSqlDB, errDB := db.DB()
+	require.NoError(t, errDB)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	slot := time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC)
+	seriesID := "s1"
+	// The occurrence was moved a day later, but the next one follows the original slot
+	taskToUpdate := &models.Task{ID: "t1", GardenID: "g1", Description: "Water tomatoes", DueDate: slot.AddDate(0, 0, 1), Status: models.TaskStatusCompleted, Priority: "High"}
+
+	existingTaskRows := sqlmock.NewRows([]string{
+		"id", "garden_id", "bed_id", "description", "due_date", "status", "priority", "recurrence", "series_id", "occurrence", "recurrence_id",
+	}).AddRow("t1", "g1", nil, "Water tomatoes", slot.AddDate(0, 0, 1), models.TaskStatusPending, "High", "FREQ=DAILY;INTERVAL=2", seriesID, 1, slot)
+	sqlSelectTask := `SELECT * FROM "tasks" WHERE (id = $1 AND user_id = $2) AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectTask)).WithArgs("t1", testUserID, 1).WillReturnRows(existingTaskRows)
+
+	// The garden and bed are checked whether or not they changed
+	gardenRows := sqlmock.NewRows([]string{"id"}).AddRow("g1")
+	sqlGardenSelect := `SELECT * FROM "gardens" WHERE (id = $1 AND user_id = $2) AND "gardens"."deleted_at" IS NULL ORDER BY "gardens"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlGardenSelect)).WithArgs("g1", testUserID, 1).WillReturnRows(gardenRows)
+
+	mock.ExpectBegin()
+	sqlTaskUpdate := `UPDATE "tasks" SET "bed_id"=$1,"description"=$2,"due_date"=$3,"garden_id"=$4,"priority"=$5,"status"=$6,"updated_at"=$7,"version"=version + 1 WHERE (id = $8 AND user_id = $9) AND "tasks"."deleted_at" IS NULL`
+	mock.ExpectExec(regexp.QuoteMeta(sqlTaskUpdate)).
+		WithArgs(nil, "Water tomatoes", taskToUpdate.DueDate, "g1", "High", models.TaskStatusCompleted, sqlmock.AnyArg(), "t1", testUserID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	seriesRows := sqlmock.NewRows([]string{"id", "user_id", "garden_id", "bed_id", "description", "priority", "recurrence", "start"}).
+		AddRow(seriesID, testUserID, "g1", nil, "Water tomatoes", "High", "FREQ=DAILY;INTERVAL=2", slot)
+	sqlSelectSeries := `SELECT * FROM "task_series" WHERE id = $1 AND user_id = $2 ORDER BY "task_series"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectSeries)).WithArgs(seriesID, testUserID, 1).WillReturnRows(seriesRows)
+
+	sqlCount := `SELECT count(*) FROM "tasks" WHERE (series_id = $1 AND occurrence = $2 AND user_id = $3) AND "tasks"."deleted_at" IS NULL`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlCount)).WithArgs(seriesID, 2, testUserID).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	next := slot.AddDate(0, 0, 2)
+	sqlTaskInsert := `INSERT INTO "tasks" ("id","user_id","garden_id","bed_id","description","due_date","status","priority","recurrence","series_id","occurrence","recurrence_id","version","created_at","updated_at","deleted_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16)`
+	mock.ExpectExec(regexp.QuoteMeta(sqlTaskInsert)).
+		WithArgs(sqlmock.AnyArg(), testUserID, "g1", nil, "Water tomatoes", next, models.TaskStatusPending, "High", "FREQ=DAILY;INTERVAL=2", seriesID, 2, next, 1, sqlmock.AnyArg(), sqlmock.AnyArg(), nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err := store.UpdateTask(context.Background(), testUserID, taskToUpdate)
+	assert.NoError(t, err)
+}
+
+func TestGormTaskStore_UpdateTaskSeries_NotRecurring(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormTaskStore(db)
+	rawSqlDB, errDB := db.DB()
+	require.NoError(t, errDB)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	taskToUpdate := &models.Task{ID: "t1", GardenID: "g1", Description: "One-off task"}
+
+	existingTaskRows := sqlmock.NewRows([]string{"id", "garden_id", "description", "status"}).
+		AddRow("t1", "g1", "One-off task", models.TaskStatusPending)
+	sqlSelectTask := `SELECT * FROM "tasks" WHERE (id = $1 AND user_id = $2) AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectTask)).WithArgs("t1", testUserID, 1).WillReturnRows(existingTaskRows)
+
+	err := store.UpdateTaskSeries(context.Background(), testUserID, taskToUpdate)
+	assert.ErrorIs(t, err, storage.ErrValidation)
+}
+
+func TestGormTaskStore_UpdateTaskSeries_ShiftsOpenOccurrences(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormTaskStore(db)
+	rawSqlDB, errDB := db.DB()
+	require.NoError(t, errDB)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	slot := time.Date(2025, 6, 3, 8, 0, 0, 0, time.UTC)
+	start := time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC)
+	seriesID := "s1"
+	taskToUpdate := &models.Task{ID: "t2", GardenID: "g1", Description: "Deep water tomatoes", DueDate: slot.Add(2 * time.Hour), Priority: "High", Recurrence: "weekly"}
+
+	existingTaskRows := sqlmock.NewRows([]string{"id", "garden_id", "description", "due_date", "status", "series_id", "occurrence", "recurrence_id"}).
+		AddRow("t2", "g1", "Water tomatoes", slot, models.TaskStatusPending, seriesID, 2, slot)
+	sqlSelectTask := `SELECT * FROM "tasks" WHERE (id = $1 AND user_id = $2) AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectTask)).WithArgs("t2", testUserID, 1).WillReturnRows(existingTaskRows)
+
+	sqlGardenSelect := `SELECT * FROM "gardens" WHERE (id = $1 AND user_id = $2) AND "gardens"."deleted_at" IS NULL ORDER BY "gardens"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlGardenSelect)).WithArgs("g1", testUserID, 1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("g1"))
+
+	seriesRows := sqlmock.NewRows([]string{"id", "garden_id", "recurrence", "start"}).AddRow(seriesID, "g1", "FREQ=DAILY;INTERVAL=2", start)
+	sqlSelectSeries := `SELECT * FROM "task_series" WHERE id = $1 AND user_id = $2 ORDER BY "task_series"."id" LIMIT $3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectSeries)).WithArgs(seriesID, testUserID, 1).WillReturnRows(seriesRows)
+
+	mock.ExpectBegin()
+	sqlOpen := `SELECT * FROM "tasks" WHERE (series_id = $1 AND user_id = $2 AND status NOT IN ($3,$4)) AND "tasks"."deleted_at" IS NULL`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlOpen)).WithArgs(seriesID, testUserID, models.TaskStatusCompleted, models.TaskStatusCancelled).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "due_date", "recurrence_id"}).AddRow("t2", slot, slot))
+
+	sqlSeriesUpdate := `UPDATE "task_series" SET "bed_id"=$1,"description"=$2,"garden_id"=$3,"priority"=$4,"recurrence"=$5,"start"=$6,"updated_at"=$7 WHERE id = $8 AND user_id = $9`
+	mock.ExpectExec(regexp.QuoteMeta(sqlSeriesUpdate)).
+		WithArgs(nil, "Deep water tomatoes", "g1", "High", "FREQ=WEEKLY", start.Add(2*time.Hour), sqlmock.AnyArg(), seriesID, testUserID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	sqlTaskUpdate := `UPDATE "tasks" SET "bed_id"=$1,"description"=$2,"due_date"=$3,"garden_id"=$4,"priority"=$5,"recurrence"=$6,"recurrence_id"=$7,"updated_at"=$8,"version"=version + 1 WHERE (id = $9 AND user_id = $10) AND "tasks"."deleted_at" IS NULL`
+	mock.ExpectExec(regexp.QuoteMeta(sqlTaskUpdate)).
+		WithArgs(nil, "Deep water tomatoes", slot.Add(2*time.Hour), "g1", "High", "FREQ=WEEKLY", slot.Add(2*time.Hour), sqlmock.AnyArg(), "t2", testUserID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := store.UpdateTaskSeries(context.Background(), testUserID, taskToUpdate)
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=WEEKLY", taskToUpdate.Recurrence)
+	assert.Equal(t, models.TaskStatusPending, taskToUpdate.Status)
 }
-*/
@@ -0,0 +1,310 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/zjpiazza/plantastic/internal/models"
+	"gorm.io/gorm"
+)
+
+// Types of item that can be in the trash.
+const (
+	TrashGarden = "garden"
+	TrashBed    = "bed"
+	TrashTask   = "task"
+)
+
+// DefaultTrashRetention is how long deleted items can be restored before
+// GormTrashStore.Sweep purges them.
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+// Sweeper stats keys reported by GormTrashStore.Sweep.
+const (
+	StatGardensPurged = "gardens_purged"
+	StatBedsPurged    = "beds_purged"
+	StatTasksPurged   = "tasks_purged"
+)
+
+// ErrGardenInTrash is returned when restoring a bed or task whose garden is
+// itself in the trash; restoring the garden brings them back together.
+var ErrGardenInTrash = errors.New("the garden is in the trash")
+
+// TrashItem is a deleted garden, bed or task.
+type TrashItem struct {
+	Type       string    `json:"type"` // TrashGarden, TrashBed or TrashTask
+	ID         string    `json:"id"`
+	Name       string    `json:"name"` // A task's description
+	GardenID   string    `json:"garden_id,omitempty"`
+	DeletedAt  time.Time `json:"deleted_at"`
+	PurgeAfter time.Time `json:"purge_after"` // When it stops being restorable
+}
+
+// RestoreResult reports what a restore brought back: the item itself, and
+// for a garden the beds and tasks deleted along with it.
+type RestoreResult struct {
+	Type          string `json:"type"`
+	ID            string `json:"id"`
+	RestoredBeds  int64  `json:"restored_beds"`
+	RestoredTasks int64  `json:"restored_tasks"`
+}
+
+// TrashStorer lists, restores and purges deleted gardens, beds and tasks.
+// Like the other storers, every method is scoped to the owning user.
+type TrashStorer interface {
+	// ListTrash lists deleted items of itemType (every type if empty), most
+	// recently deleted first. Beds and tasks in a deleted garden are left out,
+	// as they are restored and purged with it.
+	ListTrash(ctx context.Context, userID, itemType string) ([]TrashItem, error)
+	// Restore takes an item out of the trash. Restoring a garden also
+	// restores the beds and tasks that were deleted with it.
+	Restore(ctx context.Context, userID, itemType, id string) (RestoreResult, error)
+	// Purge deletes an item in the trash for good, along with everything in
+	// it: a garden's beds, tasks and plantings, or a bed's plantings.
+	Purge(ctx context.Context, userID, itemType, id string) error
+	// EmptyTrash purges every item in the trash, returning how many.
+	EmptyTrash(ctx context.Context, userID string) (int64, error)
+}
+
+// GormTrashStore implements TrashStorer using GORM.
+type GormTrashStore struct {
+	db        *gorm.DB
+	retention time.Duration
+}
+
+// NewGormTrashStore creates a new GormTrashStore that keeps deleted items
+// for retention before Sweep purges them.
+func NewGormTrashStore(db *gorm.DB, retention time.Duration) *GormTrashStore {
+	return &GormTrashStore{db: db, retention: retention}
+}
+
+// trashModel returns an empty model of itemType, for queries.
+func trashModel(itemType string) (interface{}, error) {
+	switch itemType {
+	case TrashGarden:
+		return &models.Garden{}, nil
+	case TrashBed:
+		return &models.Bed{}, nil
+	case TrashTask:
+		return &models.Task{}, nil
+	}
+	return nil, invalidField("type", "must be garden, bed or task")
+}
+
+func (s *GormTrashStore) ListTrash(ctx context.Context, userID, itemType string) ([]TrashItem, error) {
+	if itemType != "" {
+		if _, err := trashModel(itemType); err != nil {
+			return nil, err
+		}
+	}
+	trashed := s.db.WithContext(ctx).Unscoped().Where("user_id = ? AND deleted_at IS NOT NULL", userID)
+	trashedGardens := s.db.Unscoped().Model(&models.Garden{}).Select("id").Where("user_id = ? AND deleted_at IS NOT NULL", userID)
+	items := []TrashItem{}
+
+	if itemType == "" || itemType == TrashGarden {
+		var gardens []models.Garden
+		if err := trashed.Session(&gorm.Session{}).Find(&gardens).Error; err != nil {
+			return nil, ParseDatabaseError(err)
+		}
+		for _, g := range gardens {
+			items = append(items, s.item(TrashGarden, g.ID, g.Name, "", g.DeletedAt))
+		}
+	}
+	if itemType == "" || itemType == TrashBed {
+		var beds []models.Bed
+		if err := trashed.Session(&gorm.Session{}).Where("garden_id NOT IN (?)", trashedGardens).Find(&beds).Error; err != nil {
+			return nil, ParseDatabaseError(err)
+		}
+		for _, b := range beds {
+			items = append(items, s.item(TrashBed, b.ID, b.Name, b.GardenID, b.DeletedAt))
+		}
+	}
+	if itemType == "" || itemType == TrashTask {
+		var tasks []models.Task
+		if err := trashed.Session(&gorm.Session{}).Where("garden_id NOT IN (?)", trashedGardens).Find(&tasks).Error; err != nil {
+			return nil, ParseDatabaseError(err)
+		}
+		for _, t := range tasks {
+			items = append(items, s.item(TrashTask, t.ID, t.Description, t.GardenID, t.DeletedAt))
+		}
+	}
+
+	slices.SortStableFunc(items, func(a, b TrashItem) int { return b.DeletedAt.Compare(a.DeletedAt) })
+	return items, nil
+}
+
+func (s *GormTrashStore) item(itemType, id, name, gardenID string, deletedAt gorm.DeletedAt) TrashItem {
+	return TrashItem{
+		Type:       itemType,
+		ID:         id,
+		Name:       name,
+		GardenID:   gardenID,
+		DeletedAt:  deletedAt.Time,
+		PurgeAfter: deletedAt.Time.Add(s.retention),
+	}
+}
+
+func (s *GormTrashStore) Restore(ctx context.Context, userID, itemType, id string) (RestoreResult, error) {
+	model, err := trashModel(itemType)
+	if err != nil {
+		return RestoreResult{}, err
+	}
+	result := RestoreResult{Type: itemType, ID: id}
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Unscoped throughout: GORM would otherwise only look outside the trash.
+		// A new session, so that each query below starts without conditions.
+		tx = tx.Unscoped().Session(&gorm.Session{})
+		if err := tx.Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", id, userID).First(model).Error; err != nil {
+			return ParseDatabaseError(err)
+		}
+
+		switch item := model.(type) {
+		case *models.Garden:
+			for _, contents := range []struct {
+				model interface{}
+				count *int64
+			}{{&models.Bed{}, &result.RestoredBeds}, {&models.Task{}, &result.RestoredTasks}} {
+				restored := tx.Model(contents.model).
+					Where("garden_id = ? AND user_id = ? AND deleted_at = ?", id, userID, item.DeletedAt).
+					Updates(map[string]interface{}{"deleted_at": nil, "version": gorm.Expr("version + 1")})
+				if restored.Error != nil {
+					return ParseDatabaseError(restored.Error)
+				}
+				*contents.count = restored.RowsAffected
+			}
+		case *models.Bed:
+			if err := checkGardenNotTrashed(tx, userID, item.GardenID); err != nil {
+				return err
+			}
+		case *models.Task:
+			if err := checkGardenNotTrashed(tx, userID, item.GardenID); err != nil {
+				return err
+			}
+		}
+
+		// The version moves on, so an If-Match from before the delete no longer applies
+		restored := tx.Model(model).Where("id = ? AND user_id = ?", id, userID).
+			Updates(map[string]interface{}{"deleted_at": nil, "version": gorm.Expr("version + 1")})
+		return ParseDatabaseError(restored.Error)
+	})
+	if err != nil {
+		return RestoreResult{}, ParseDatabaseError(err)
+	}
+	return result, nil
+}
+
+// checkGardenNotTrashed returns ErrGardenInTrash if the user's garden is in
+// the trash. tx must be unscoped.
+func checkGardenNotTrashed(tx *gorm.DB, userID, gardenID string) error {
+	var count int64
+	err := tx.Model(&models.Garden{}).Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", gardenID, userID).Count(&count).Error
+	if err != nil {
+		return ParseDatabaseError(err)
+	}
+	if count > 0 {
+		return ErrGardenInTrash
+	}
+	return nil
+}
+
+func (s *GormTrashStore) Purge(ctx context.Context, userID, itemType, id string) error {
+	model, err := trashModel(itemType)
+	if err != nil {
+		return err
+	}
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		tx = tx.Unscoped().Session(&gorm.Session{})
+		if err := tx.Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", id, userID).First(model).Error; err != nil {
+			return ParseDatabaseError(err)
+		}
+
+		// What the item contains is deleted here rather than by the foreign
+		// keys' cascades, which would leave it out of the audit log
+		switch itemType {
+		case TrashGarden:
+			if err := tx.Where("garden_id = ? AND user_id = ?", id, userID).Delete(&models.Task{}).Error; err != nil {
+				return ParseDatabaseError(err)
+			}
+			beds := tx.Model(&models.Bed{}).Select("id").Where("garden_id = ? AND user_id = ?", id, userID)
+			if err := purgeBedContents(tx, beds); err != nil {
+				return err
+			}
+			if err := tx.Where("garden_id = ? AND user_id = ?", id, userID).Delete(&models.Bed{}).Error; err != nil {
+				return ParseDatabaseError(err)
+			}
+		case TrashBed:
+			if err := purgeBedContents(tx, []string{id}); err != nil {
+				return err
+			}
+		}
+		return ParseDatabaseError(tx.Delete(model).Error)
+	})
+	return ParseDatabaseError(err)
+}
+
+func (s *GormTrashStore) EmptyTrash(ctx context.Context, userID string) (int64, error) {
+	var purged int64
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		stats, err := purgeTrash(tx.Where("user_id = ?", userID))
+		for _, n := range stats {
+			purged += n
+		}
+		return err
+	})
+	if err != nil {
+		return 0, ParseDatabaseError(err)
+	}
+	return purged, nil
+}
+
+// Sweep purges items that have been in the trash for longer than the
+// retention period, for every user. Its signature matches scheduler.JobFunc.
+func (s *GormTrashStore) Sweep(ctx context.Context) (map[string]int64, error) {
+	stats, err := purgeTrash(s.db.WithContext(ctx).Where("deleted_at < ?", time.Now().Add(-s.retention)))
+	if err != nil {
+		return stats, fmt.Errorf("failed to purge the trash: %w", err)
+	}
+	return stats, nil
+}
+
+// purgeTrash deletes the trashed tasks, beds and gardens matching scope, in
+// that order so that each is counted under its own type rather than
+// disappearing in its parent's cascade. The purged beds' plantings are
+// deleted along with them, so that they too are audited.
+func purgeTrash(scope *gorm.DB) (map[string]int64, error) {
+	stats := map[string]int64{}
+	for _, step := range []struct {
+		model interface{}
+		stat  string
+	}{{&models.Task{}, StatTasksPurged}, {&models.Bed{}, StatBedsPurged}, {&models.Garden{}, StatGardensPurged}} {
+		if _, ok := step.model.(*models.Bed); ok {
+			beds := scope.Session(&gorm.Session{}).Unscoped().Model(&models.Bed{}).Select("id").Where("deleted_at IS NOT NULL")
+			tx := scope.Session(&gorm.Session{NewDB: true}).Unscoped().Session(&gorm.Session{})
+			if err := purgeBedContents(tx, beds); err != nil {
+				return stats, err
+			}
+		}
+		result := scope.Session(&gorm.Session{}).Unscoped().Where("deleted_at IS NOT NULL").Delete(step.model)
+		if result.Error != nil {
+			return stats, ParseDatabaseError(result.Error)
+		}
+		stats[step.stat] = result.RowsAffected
+	}
+	return stats, nil
+}
+
+// purgeBedContents deletes the plantings in the beds about to be purged and
+// detaches their tasks, as the foreign keys would, but through GORM so that
+// each row is audited. bedIDs is a list of IDs or a subquery; tx must be an
+// unscoped session.
+func purgeBedContents(tx *gorm.DB, bedIDs interface{}) error {
+	if err := tx.Where("bed_id IN (?)", bedIDs).Delete(&models.Planting{}).Error; err != nil {
+		return ParseDatabaseError(err)
+	}
+	detached := tx.Model(&models.Task{}).Where("bed_id IN (?)", bedIDs).
+		Updates(map[string]interface{}{"bed_id": nil, "version": gorm.Expr("version + 1")})
+	return ParseDatabaseError(detached.Error)
+}
@@ -0,0 +1,33 @@
+package storage
+
+import "gorm.io/gorm"
+
+// deleteVersioned deletes the row of model with the given ID owned by userID;
+// for models with a DeletedAt field that moves it to the trash.
+// A non-zero version makes the delete conditional on the row still being at
+// that version; if it has moved on, ErrVersionConflict is returned.
+func deleteVersioned(db *gorm.DB, model interface{}, userID, id string, version int) error {
+	query := db.Where("id = ? AND user_id = ?", id, userID)
+	if version != 0 {
+		query = query.Where("version = ?", version)
+	}
+	result := query.Delete(model)
+	if result.Error != nil {
+		return ParseDatabaseError(result.Error)
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+	if version == 0 {
+		return ErrRecordNotFound
+	}
+
+	var count int64
+	if err := db.Model(model).Where("id = ? AND user_id = ?", id, userID).Count(&count).Error; err != nil {
+		return ParseDatabaseError(err)
+	}
+	if count > 0 {
+		return ErrVersionConflict
+	}
+	return ErrRecordNotFound
+}
@@ -0,0 +1,64 @@
+package storage_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zjpiazza/plantastic/cmd/api/internal/storage"
+)
+
+func TestGormTaskStore_DeleteTask_IfMatchVersion(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormTaskStore(db)
+	rawSqlDB, err := db.DB()
+	require.NoError(t, err)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	mock.ExpectBegin()
+	sqlDelete := `UPDATE "tasks" SET "deleted_at"=$1 WHERE (id = $2 AND user_id = $3) AND version = $4 AND "tasks"."deleted_at" IS NULL`
+	mock.ExpectExec(regexp.QuoteMeta(sqlDelete)).WithArgs(sqlmock.AnyArg(), "t1", testUserID, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, store.DeleteTask(context.Background(), testUserID, "t1", 2))
+}
+
+func TestGormTaskStore_DeleteTask_StaleVersion(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormTaskStore(db)
+	rawSqlDB, err := db.DB()
+	require.NoError(t, err)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	mock.ExpectBegin()
+	sqlDelete := `UPDATE "tasks" SET "deleted_at"=$1 WHERE (id = $2 AND user_id = $3) AND version = $4 AND "tasks"."deleted_at" IS NULL`
+	mock.ExpectExec(regexp.QuoteMeta(sqlDelete)).WithArgs(sqlmock.AnyArg(), "t1", testUserID, 2).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	sqlCount := `SELECT count(*) FROM "tasks" WHERE (id = $1 AND user_id = $2) AND "tasks"."deleted_at" IS NULL`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlCount)).WithArgs("t1", testUserID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	assert.ErrorIs(t, store.DeleteTask(context.Background(), testUserID, "t1", 2), storage.ErrVersionConflict)
+}
+
+func TestGormTaskStore_DeleteTask_VersionedNotFound(t *testing.T) {
+	db, mock := newMockDBForStorageTest(t)
+	store := storage.NewGormTaskStore(db)
+	rawSqlDB, err := db.DB()
+	require.NoError(t, err)
+	defer rawSqlDB.Close()
+	defer func() { assert.NoError(t, mock.ExpectationsWereMet()) }()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "tasks" SET "deleted_at"`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "tasks"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	assert.ErrorIs(t, store.DeleteTask(context.Background(), testUserID, "t_gone", 2), storage.ErrRecordNotFound)
+}
@@ -3,104 +3,225 @@ package main
 
 import (
 	// Keep for future use, though not directly by Clerk in this pattern
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
-
-	// "time" // Not explicitly needed by Clerk in this pattern, unless for WithLeeway if we re-add it
-
-	"github.com/clerk/clerk-sdk-go/v2"                // Main Clerk package
-	clerkhttp "github.com/clerk/clerk-sdk-go/v2/http" // For WithHeaderAuthorization
+	"strings"
+	"time"
 
 	"github.com/gin-contrib/cors" // Import CORS middleware
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 	"github.com/zjpiazza/plantastic/cmd/api/handlers"
+	apihandlers "github.com/zjpiazza/plantastic/cmd/api/internal/handlers"
+	"github.com/zjpiazza/plantastic/cmd/api/internal/openapi"
 	"github.com/zjpiazza/plantastic/cmd/api/internal/routes"
 	"github.com/zjpiazza/plantastic/cmd/api/internal/storage"
+	"github.com/zjpiazza/plantastic/internal/auth"
+	"github.com/zjpiazza/plantastic/internal/database"
 	"github.com/zjpiazza/plantastic/internal/device"
-	"github.com/zjpiazza/plantastic/internal/models"
-	"gorm.io/driver/postgres"
+	"github.com/zjpiazza/plantastic/internal/scheduler"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
-const clerkSessionKey = "clerk_session_claims" // For storing claims in Gin's context
-
 func main() {
 	// Load .env file
 	if err := godotenv.Load(); err != nil {
 		log.Println("Warning: Error loading .env file, proceeding with environment variables if set.")
 	}
 
-	// Initialize Clerk client globally (as per your example)
-	clerkKey := os.Getenv("CLERK_SECRET_KEY")
-	if clerkKey == "" {
-		log.Fatal("CLERK_SECRET_KEY environment variable not set.")
+	// "plantastic-api migrate ..." manages the schema and exits
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:], os.Stdout); err != nil {
+			if !errors.Is(err, errMigrateUsage) {
+				log.Println("Migration failed:", err)
+			}
+			os.Exit(1)
+		}
+		return
 	}
-	clerk.SetKey(clerkKey) // Set the key globally
 
-	// Initialize database connection
-	dsn := os.Getenv("DATABASE_URL")
-	if dsn == "" {
-		dsn = "host=localhost user=postgres password=postgres dbname=plantastic port=5432 sslmode=disable TimeZone=UTC"
+	// Initialize the auth provider selected by AUTH_PROVIDER (Clerk by default)
+	authenticator, err := auth.NewFromEnv()
+	if err != nil {
+		log.Fatal("Failed to initialize auth provider:", err)
 	}
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+	fmt.Printf("Using %s auth provider.\n", authenticator.Name())
+
+	// Initialize database connection (DATABASE_DRIVER selects Postgres or SQLite)
+	dbConfig := database.ConfigFromEnv()
+	db, err := database.Open(dbConfig, &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
 	})
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
 	}
-	fmt.Println("Database connection successful.")
+	fmt.Printf("Database connection successful (%s).\n", dbConfig.Driver)
 
-	// AutoMigrate
-	db.AutoMigrate(&models.Garden{}, &models.Bed{}, &models.Task{}, &models.Device{})
-	fmt.Println("Database migration complete")
+	// Apply pending migrations; "migrate down" is there for rolling back by hand
+	applied, err := database.MigrateUp(db)
+	if err != nil {
+		log.Fatal("Failed to migrate database:", err)
+	}
+	for _, m := range applied {
+		fmt.Printf("Applied migration %04d_%s\n", m.Version, m.Name)
+	}
+	fmt.Println("Database schema is up to date")
+	if unowned, err := database.UnownedRows(db); err != nil {
+		log.Println("Warning: could not check for rows without an owner:", err)
+	} else if len(unowned) > 0 {
+		log.Printf("Warning: rows without an owner are hidden from every user: %v; give them to a user with \"plantastic-api migrate assign-owner -user <user ID>\"", unowned)
+	}
+
+	// Record every change to gardens, beds, tasks, plants and plantings in the audit log
+	if err := db.Use(storage.AuditLog{}); err != nil {
+		log.Fatal("Failed to set up the audit log:", err)
+	}
 
 	// Create storage instances
 	gardenStore := storage.NewGormGardenStore(db)
 	bedStore := storage.NewGormBedStore(db)
 	taskStore := storage.NewGormTaskStore(db)
+	plantStore := storage.NewGormPlantStore(db)
+	plantingStore := storage.NewGormPlantingStore(db)
+	idempotencyStore := storage.NewGormIdempotencyStore(db)
+	auditStore := storage.NewGormAuditStore(db)
+
+	// How long deleted gardens, beds and tasks can be restored before they are purged
+	trashRetention := storage.DefaultTrashRetention
+	if v := os.Getenv("TRASH_RETENTION"); v != "" {
+		if trashRetention, err = time.ParseDuration(v); err != nil || trashRetention <= 0 {
+			log.Fatal("Invalid TRASH_RETENTION:", v)
+		}
+	}
+	trashStore := storage.NewGormTrashStore(db, trashRetention)
+
+	// How long responses to requests with an Idempotency-Key are kept for replay
+	idempotencyTTL := apihandlers.DefaultIdempotencyTTL
+	if v := os.Getenv("IDEMPOTENCY_TTL"); v != "" {
+		if idempotencyTTL, err = time.ParseDuration(v); err != nil || idempotencyTTL <= 0 {
+			log.Fatal("Invalid IDEMPOTENCY_TTL:", v)
+		}
+	}
+
+	// Deadlines for the database queries of each kind of route
+	timeouts := routes.DefaultTimeouts
+	for name, timeout := range map[string]*time.Duration{
+		"READ_QUERY_TIMEOUT":  &timeouts.Read,
+		"WRITE_QUERY_TIMEOUT": &timeouts.Write,
+		"BULK_QUERY_TIMEOUT":  &timeouts.Bulk,
+	} {
+		if v := os.Getenv(name); v != "" {
+			if *timeout, err = time.ParseDuration(v); err != nil || *timeout <= 0 {
+				log.Fatal("Invalid "+name+":", v)
+			}
+		}
+	}
 
 	// Initialize device manager
 	deviceManager := device.NewManager(db)
 
+	// Background maintenance jobs; register further periodic jobs here
+	janitorInterval := time.Hour
+	if v := os.Getenv("JANITOR_INTERVAL"); v != "" {
+		if janitorInterval, err = time.ParseDuration(v); err != nil {
+			log.Fatal("Invalid JANITOR_INTERVAL:", err)
+		}
+	}
+	jobs := scheduler.New()
+	if err := jobs.Register("device-janitor", janitorInterval, deviceManager.RunJanitor); err != nil {
+		log.Fatal("Failed to register device janitor:", err)
+	}
+	if err := jobs.Register("task-status-sweep", 5*time.Minute, storage.NewTaskStatusSweeper(db).Run); err != nil {
+		log.Fatal("Failed to register task status sweeper:", err)
+	}
+	if err := jobs.Register("idempotency-sweep", time.Hour, idempotencyStore.Sweep); err != nil {
+		log.Fatal("Failed to register idempotency key sweep:", err)
+	}
+	if err := jobs.Register("trash-purge", time.Hour, trashStore.Sweep); err != nil {
+		log.Fatal("Failed to register trash purge:", err)
+	}
+	deviceLimits := handlers.DefaultDeviceRateLimits()
+	if err := jobs.Register("ratelimit-sweep", 10*time.Minute, deviceLimits.Sweep); err != nil {
+		log.Fatal("Failed to register rate limit sweep:", err)
+	}
+	jobs.Start(context.Background())
+	defer jobs.Stop()
+
+	// Public URL of the web app, used for the device flow's verification_uri
+	webURL := os.Getenv("PLANTASTIC_WEB_URL")
+	if webURL == "" {
+		webURL = "http://localhost:8080"
+	}
+
 	// Initialize handlers
-	deviceApiHandler, err := handlers.NewDeviceHandler(deviceManager)
+	deviceApiHandler, err := handlers.NewDeviceHandler(deviceManager, authenticator, webURL, deviceLimits)
 	if err != nil {
 		log.Fatal("Failed to initialize API device handler:", err)
 	}
+	// Users allowed to inspect the background jobs, as comma-separated user IDs
+	maintenanceHandler := handlers.NewMaintenanceHandler(jobs, strings.Split(os.Getenv("ADMIN_USER_IDS"), ","))
 
 	// Initialize Gin router
 	router := gin.Default()
+	router.Use(apihandlers.RequestID())
 
 	// Apply CORS middleware
 	// This allows http://localhost:8080, specified methods, and specified headers.
 	cDefault := cors.DefaultConfig()
-	cDefault.AllowOrigins = []string{"http://localhost:8080"} // Your web app's origin
-	cDefault.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
-	cDefault.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization"}
+	cDefault.AllowOrigins = []string{webURL} // Your web app's origin
+	cDefault.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	cDefault.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", "If-Match", apihandlers.IdempotencyKeyHeader, apihandlers.RequestIDHeader}
+	cDefault.ExposeHeaders = []string{"ETag", "Link", apihandlers.NextCursorHeader, apihandlers.RequestIDHeader, apihandlers.IdempotentReplayedHeader, "Deprecation", "Sunset"}
 	// Allow credentials (cookies, authorization headers, etc.)
 	cDefault.AllowCredentials = true
 	router.Use(cors.New(cDefault))
 
-	// Public Device Routes
-	router.POST("/device/request-code", deviceApiHandler.GenerateCode)
-	router.GET("/device/check-status", deviceApiHandler.CheckStatus)
-
 	// Public routes (example)
 	router.GET("/", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"message": "Welcome to Plantastic API!"})
 	})
 
-	// Protected route group
-	protected := router.Group("/")
-	// ClerkMiddleware might not need the client passed if SetKey is global
-	protected.Use(ClerkMiddleware())
+	// The document is built once every route is registered, below
+	var spec *openapi.Document
+	router.GET(openapi.Path, func(c *gin.Context) {
+		openapi.Handler(spec)(c)
+	})
+
+	// Version 1 of the API; a later version gets its own setup, mounted beside it
+	v1 := routes.Deps{
+		Gardens:     gardenStore,
+		Beds:        bedStore,
+		Tasks:       taskStore,
+		Plants:      plantStore,
+		Plantings:   plantingStore,
+		Trash:       trashStore,
+		Audit:       auditStore,
+		Devices:     deviceApiHandler,
+		Maintenance: maintenanceHandler,
+		Protected: []gin.HandlerFunc{
+			// Accept Plantastic-issued device tokens alongside the provider's own sessions
+			AuthMiddleware(auth.NewChain(device.NewAuthenticator(deviceManager), authenticator), deviceManager),
+			// Keys are per user, so this has to come after authentication
+			apihandlers.Idempotency(idempotencyStore, idempotencyTTL),
+		},
+		Timeouts: timeouts,
+	}
+	if local, ok := authenticator.(*auth.LocalAuthenticator); ok {
+		v1.LocalAuth = handlers.NewLocalAuthHandler(local)
+	}
+	routes.SetupV1(router.Group(routes.V1), v1)
+	// Clients from before /v1 keep working until the sunset, warned by headers
+	routes.SetupV1(router.Group("/", routes.Deprecated(routes.LegacyDeprecated, routes.LegacySunset, routes.V1)), v1)
 
-	// Initialize routes
-	routes.SetupProtectedRoutes(protected, gardenStore, bedStore, taskStore, deviceApiHandler)
+	spec, err = openapi.Build(router.Routes(), routes.V1)
+	if err != nil {
+		log.Fatal("Failed to build the OpenAPI document:", err)
+	}
 
 	// Start server
 	port := os.Getenv("API_PORT")
@@ -113,48 +234,37 @@ func main() {
 	}
 }
 
-// ClerkMiddleware creates a Gin middleware for Clerk authentication
-func ClerkMiddleware() gin.HandlerFunc { // Removed clerkClient from params
-	// This returns a function: func(next http.Handler) http.Handler
-	clerkMiddlewareWrapper := clerkhttp.WithHeaderAuthorization()
-
+// AuthMiddleware creates a Gin middleware that verifies the bearer token with
+// the configured auth provider and stores the resulting principal. Requests
+// made with a device token also update that device's LastUsedAt.
+func AuthMiddleware(authenticator auth.Authenticator, deviceManager *device.Manager) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		nextHttpHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// SessionClaimsFromContext is from the main clerk package, as per your example
-			claims, ok := clerk.SessionClaimsFromContext(r.Context())
-			if !ok {
-				log.Println("Clerk middleware: SessionClaimsFromContext found no claims or 'ok' is false.")
-				// Since we are in an http.HandlerFunc, we write directly to the ResponseWriter
-				// Gin's context `c` is available via closure, but c.AbortWithStatusJSON might not be suitable here.
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusUnauthorized) // Use the http.ResponseWriter from this scope
-				fmt.Fprintln(w, `{"error":"Unauthorized: No valid session claims found in context"}`)
-				return
-			}
-
-			log.Printf("Clerk middleware: Successfully authenticated session for user %s, session %s\n", claims.Subject, claims.SessionID)
-
-			// Store claims in Gin's context for easier access in Gin handlers
-			c.Set(clerkSessionKey, claims)
-
-			// Ensure Gin uses the request that Clerk's middleware might have modified (e.g., context values)
-			c.Request = r
-			c.Next() // Tell Gin to proceed to the next handler in its chain
-		})
+		token := auth.BearerToken(c.Request)
+		if token == "" {
+			apihandlers.WriteProblem(c, http.StatusUnauthorized, apihandlers.CodeUnauthorized, "Unauthorized: missing bearer token")
+			return
+		}
 
-		// Apply the clerkMiddlewareWrapper to our nextHttpHandler
-		wrappedHandler := clerkMiddlewareWrapper(nextHttpHandler)
+		principal, err := authenticator.Authenticate(c.Request.Context(), token)
+		if err != nil {
+			log.Printf("Auth middleware: %s provider rejected token: %v\n", authenticator.Name(), err)
+			apihandlers.WriteProblem(c, http.StatusUnauthorized, apihandlers.CodeUnauthorized, "Unauthorized: No valid session found for token")
+			return
+		}
 
-		// Serve the request through the wrapped handler
-		wrappedHandler.ServeHTTP(c.Writer, c.Request)
+		log.Printf("Auth middleware: Successfully authenticated user %s via %s\n", principal.UserID, principal.Provider)
 
-		// If the response was already written by Clerk or our nextHttpHandler (due to error),
-		// we should abort Gin's chain to prevent further processing (like Gin writing its own 404).
-		if c.Writer.Written() {
-			c.Abort()
+		if principal.Provider == device.ProviderName {
+			client := device.ClientInfo{IPAddress: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+			if err := deviceManager.TouchDevice(c.Request.Context(), principal.SessionID, client); err != nil {
+				// Usage tracking is best-effort; never fail the request over it
+				log.Printf("Auth middleware: %v\n", err)
+			}
 		}
-		// If c.Next() was called in nextHttpHandler, Gin will continue.
-		// If an error was written and returned from nextHttpHandler, c.Next() wasn't called,
-		// and c.Abort() here ensures no further Gin handlers run.
+
+		// Store the principal in Gin's context; resource handlers scope every query to principal.UserID
+		c.Set(apihandlers.PrincipalKey, principal)
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principal))
+		c.Next()
 	}
 }
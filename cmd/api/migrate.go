@@ -0,0 +1,159 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/zjpiazza/plantastic/internal/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const migrateUsage = `usage: plantastic-api migrate <command>
+
+Commands:
+  up                 apply every pending migration
+  down [-steps N]    revert the last N applied migrations (default 1)
+  status             list migrations and whether each is applied
+  assign-owner -user ID
+                     give rows from before users had accounts to user ID
+
+The database is selected with DATABASE_DRIVER and DATABASE_URL, as for the server.
+`
+
+var errMigrateUsage = errors.New("invalid arguments")
+
+// runMigrate implements "plantastic-api migrate", which manages the schema
+// without starting the server.
+func runMigrate(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(out, migrateUsage)
+		return errMigrateUsage
+	}
+
+	command, args := args[0], args[1:]
+	flags := flag.NewFlagSet("migrate "+command, flag.ContinueOnError)
+	flags.SetOutput(out)
+	steps := 1
+	var owner string
+	switch command {
+	case "down":
+		flags.IntVar(&steps, "steps", 1, "number of migrations to revert")
+	case "assign-owner":
+		flags.StringVar(&owner, "user", "", "user ID to give unowned rows to")
+	}
+	if err := flags.Parse(args); err != nil {
+		return errMigrateUsage
+	}
+	if flags.NArg() > 0 {
+		fmt.Fprintf(out, "unexpected argument %q\n\n%s", flags.Arg(0), migrateUsage)
+		return errMigrateUsage
+	}
+	if command == "assign-owner" && owner == "" {
+		fmt.Fprintf(out, "assign-owner needs -user\n\n%s", migrateUsage)
+		return errMigrateUsage
+	}
+
+	var run func(db *gorm.DB) error
+	switch command {
+	case "up":
+		run = func(db *gorm.DB) error {
+			applied, err := database.MigrateUp(db)
+			for _, m := range applied {
+				fmt.Fprintf(out, "applied  %04d_%s\n", m.Version, m.Name)
+			}
+			if err != nil {
+				return err
+			}
+			if len(applied) == 0 {
+				fmt.Fprintln(out, "schema is up to date")
+			}
+			return reportUnownedRows(db, out)
+		}
+	case "down":
+		run = func(db *gorm.DB) error {
+			reverted, err := database.MigrateDown(db, steps)
+			for _, m := range reverted {
+				fmt.Fprintf(out, "reverted %04d_%s\n", m.Version, m.Name)
+			}
+			if err == nil && len(reverted) == 0 {
+				fmt.Fprintln(out, "no migrations are applied")
+			}
+			return err
+		}
+	case "status":
+		run = func(db *gorm.DB) error {
+			statuses, err := database.MigrateStatus(db)
+			if err != nil {
+				return err
+			}
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "VERSION\tNAME\tSTATE\tAPPLIED AT")
+			for _, s := range statuses {
+				appliedAt := "-"
+				if !s.AppliedAt.IsZero() {
+					appliedAt = s.AppliedAt.Local().Format("2006-01-02 15:04:05")
+				}
+				fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", s.Version, s.Name, s.State, appliedAt)
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			if len(statuses) == 0 || statuses[0].State == database.MigrationPending {
+				return nil // The tables don't exist before the first migration
+			}
+			return reportUnownedRows(db, out)
+		}
+	case "assign-owner":
+		run = func(db *gorm.DB) error {
+			counts, err := database.AssignOwner(db, owner)
+			if err != nil {
+				return err
+			}
+			if len(counts) == 0 {
+				fmt.Fprintln(out, "every row has an owner")
+			}
+			for _, table := range slices.Sorted(maps.Keys(counts)) {
+				fmt.Fprintf(out, "assigned %d %s to %s\n", counts[table], table, owner)
+			}
+			return nil
+		}
+	default:
+		fmt.Fprintf(out, "unknown migrate command %q\n\n%s", command, migrateUsage)
+		return errMigrateUsage
+	}
+
+	db, err := database.Open(database.ConfigFromEnv(), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Warn),
+	})
+	if err != nil {
+		return fmt.Errorf("connecting to database: %w", err)
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+	return run(db)
+}
+
+// reportUnownedRows warns about rows without a user_id, which no user can
+// see until they are assigned an owner.
+func reportUnownedRows(db *gorm.DB, out io.Writer) error {
+	counts, err := database.UnownedRows(db)
+	if err != nil {
+		return err
+	}
+	if len(counts) == 0 {
+		return nil
+	}
+	fmt.Fprintln(out, "\nwarning: some rows have no owner and are hidden from every user:")
+	for _, table := range slices.Sorted(maps.Keys(counts)) {
+		fmt.Fprintf(out, "  %s: %d\n", table, counts[table])
+	}
+	fmt.Fprintln(out, "give them to a user with: plantastic-api migrate assign-owner -user <user ID>")
+	return nil
+}
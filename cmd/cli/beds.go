@@ -1,73 +1,69 @@
 package main
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
+	"net/url"
 	"os"
+	"strconv"
 
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 	"github.com/zjpiazza/plantastic/internal/models"
 )
 
-func bedsCmd(apiUrl string) *cobra.Command {
+func bedsCmd() *cobra.Command {
 	bedsCmd := &cobra.Command{
 		Use:   "beds",
 		Short: "Manage garden beds",
 		Long:  `Create, list, update and delete garden beds`,
 	}
 
-	bedsCmd.AddCommand(listBedsCmd(apiUrl))
-	bedsCmd.AddCommand(createBedCmd(apiUrl))
-	bedsCmd.AddCommand(updateBedCmd(apiUrl))
-	bedsCmd.AddCommand(deleteBedCmd(apiUrl))
+	bedsCmd.AddCommand(listBedsCmd())
+	bedsCmd.AddCommand(createBedCmd())
+	bedsCmd.AddCommand(updateBedCmd())
+	bedsCmd.AddCommand(deleteBedCmd())
 
 	return bedsCmd
 }
 
-func listBedsCmd(apiUrl string) *cobra.Command {
-	return &cobra.Command{
+func listBedsCmd() *cobra.Command {
+	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List all garden beds",
 		Run: func(cmd *cobra.Command, args []string) {
-			response, err := http.Get(fmt.Sprintf("%s/beds", apiUrl))
-			if err != nil {
-				fmt.Println("Error getting beds:", err)
-				os.Exit(1)
-			}
-
-			defer response.Body.Close()
-			body, err := io.ReadAll(response.Body)
-			if err != nil {
-				fmt.Println("Error reading response body:", err)
-				os.Exit(1)
-			}
+			filters := url.Values{}
+			setFilter(cmd, filters, "garden-id", "garden_id")
+			setFilter(cmd, filters, "type", "type")
+			setFilter(cmd, filters, "soil-type", "soil_type")
 
-			var beds []models.Bed
-			err = json.Unmarshal(body, &beds)
+			beds, next, err := fetchList(cmd, apiClient().ListBeds, filters)
 			if err != nil {
-				fmt.Println("Error unmarshalling response body:", err)
+				fmt.Println("Error getting beds:", err)
 				os.Exit(1)
 			}
 			table := tablewriter.NewWriter(os.Stdout)
 			table.SetHeader(
-				[]string{"ID", "Garden ID", "Name", "Type", "Size", "Soil Type", "Notes"},
+				[]string{"ID", "Garden ID", "Name", "Type", "Size", "Soil Type", "Notes", "Version"},
 			)
 
 			for _, v := range beds {
 				table.Append(
-					[]string{v.ID, v.GardenID, v.Name, v.Type, v.Size, v.SoilType, v.Notes},
+					[]string{v.ID, v.GardenID, v.Name, v.Type, v.Size, v.SoilType, v.Notes, strconv.Itoa(v.Version)},
 				)
 			}
 			table.Render()
+			printNextCursor(next)
 		},
 	}
+	cmd.Flags().StringP("garden-id", "g", "", "Only list beds in this garden")
+	cmd.Flags().StringP("type", "t", "", "Only list beds of this type")
+	cmd.Flags().StringP("soil-type", "s", "", "Only list beds with this soil type")
+	addListFlags(cmd)
+
+	return cmd
 }
 
-func createBedCmd(apiUrl string) *cobra.Command {
+func createBedCmd() *cobra.Command {
 	createBedCmd := &cobra.Command{
 		Use:   "create",
 		Short: "Create a new garden bed",
@@ -88,45 +84,11 @@ func createBedCmd(apiUrl string) *cobra.Command {
 				GardenID: gardenID,
 			}
 
-			jsonData, err := json.Marshal(bed)
-			if err != nil {
-				fmt.Println("Error marshalling bed:", err)
-				os.Exit(1)
-			}
-
-			response, err := http.Post(
-				fmt.Sprintf("%s/beds", apiUrl),
-				"application/json",
-				bytes.NewBuffer(jsonData),
-			)
-			if err != nil {
-				fmt.Println("Error creating bed:", err)
-				os.Exit(1)
-			}
-			defer response.Body.Close()
-
-			body, err := io.ReadAll(response.Body)
-			if err != nil {
-				fmt.Println("Error reading response:", err)
-				os.Exit(1)
-			}
-
-			if response.StatusCode != http.StatusCreated && response.StatusCode != http.StatusOK {
-				fmt.Printf(
-					"Error: Server returned status code %d: %s\n",
-					response.StatusCode,
-					string(body),
-				)
-				os.Exit(1)
-			}
+			createdBed, err := apiClient().CreateBed(cmd.Context(), bed)
+			exitOnError(err, "bed", "Error creating bed")
 
 			fmt.Println("Garden bed created successfully!")
-
-			// Try to display the created bed details if possible
-			var createdBed models.Bed
-			if err := json.Unmarshal(body, &createdBed); err == nil {
-				fmt.Printf("Created bed: %s (ID: %s)\n", createdBed.Name, createdBed.ID)
-			}
+			fmt.Printf("Created bed: %s (ID: %s)\n", createdBed.Name, createdBed.ID)
 		},
 	}
 	createBedCmd.Flags().StringP("name", "n", "", "Name of the bed")
@@ -145,75 +107,30 @@ func createBedCmd(apiUrl string) *cobra.Command {
 	return createBedCmd
 }
 
-func updateBedCmd(apiUrl string) *cobra.Command {
+func updateBedCmd() *cobra.Command {
 	updateBedCmd := &cobra.Command{
 		Use:   "update <bed-id>",
 		Short: "Update a garden bed",
 		Args:  cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			name, _ := cmd.Flags().GetString("name")
-			bedType, _ := cmd.Flags().GetString("type")
-			size, _ := cmd.Flags().GetString("size")
-			soilType, _ := cmd.Flags().GetString("soil-type")
-			notes, _ := cmd.Flags().GetString("notes")
-			gardenID, _ := cmd.Flags().GetString("garden-id")
-
-			bed := models.Bed{
-				Name:     name,
-				Type:     bedType,
-				Size:     size,
-				SoilType: soilType,
-				Notes:    notes,
-				GardenID: gardenID,
-			}
-
-			jsonData, err := json.Marshal(bed)
-			if err != nil {
-				fmt.Println("Error marshalling bed:", err)
-				os.Exit(1)
-			}
-
-			req, err := http.NewRequest(
-				"PUT",
-				fmt.Sprintf("%s/beds/%s", apiUrl, args[0]),
-				bytes.NewBuffer(jsonData),
-			)
-			if err != nil {
-				fmt.Println("Error creating request:", err)
-				os.Exit(1)
-			}
-
-			req.Header.Set("Content-Type", "application/json")
-
-			response, err := http.DefaultClient.Do(req)
-			if err != nil {
-				fmt.Println("Error creating bed:", err)
-				os.Exit(1)
-			}
-			defer response.Body.Close()
-
-			body, err := io.ReadAll(response.Body)
-			if err != nil {
-				fmt.Println("Error reading response:", err)
+			patch := patchFromFlags(cmd, map[string]string{
+				"name":      "name",
+				"type":      "type",
+				"size":      "size",
+				"soil-type": "soil_type",
+				"notes":     "notes",
+				"garden-id": "garden_id",
+			})
+			if len(patch) == 0 {
+				fmt.Println("Nothing to update: set at least one flag")
 				os.Exit(1)
 			}
 
-			if response.StatusCode != http.StatusOK {
-				fmt.Printf(
-					"Error: Server returned status code %d: %s\n",
-					response.StatusCode,
-					string(body),
-				)
-				os.Exit(1)
-			}
+			updatedBed, err := apiClient().PatchBed(cmd.Context(), args[0], patch, ifMatchVersion(cmd))
+			exitOnError(err, "bed", "Error updating bed")
 
 			fmt.Println("Garden bed updated successfully!")
-
-			// Try to display the updated bed details if possible
-			var updatedBed models.Bed
-			if err := json.Unmarshal(body, &updatedBed); err == nil {
-				fmt.Printf("Updated bed: %s (ID: %s)\n", updatedBed.Name, updatedBed.ID)
-			}
+			fmt.Printf("Updated bed: %s (ID: %s)\n", updatedBed.Name, updatedBed.ID)
 		},
 	}
 	updateBedCmd.Flags().StringP("name", "n", "", "Name of the bed")
@@ -223,34 +140,23 @@ func updateBedCmd(apiUrl string) *cobra.Command {
 	updateBedCmd.Flags().StringP("notes", "N", "", "Notes of the bed")
 	updateBedCmd.Flags().StringP("garden-id", "g", "", "ID of the garden this bed belongs to")
 
+	addIfMatchFlag(updateBedCmd, "bed")
+
 	return updateBedCmd
 }
 
-func deleteBedCmd(apiUrl string) *cobra.Command {
-	return &cobra.Command{
+func deleteBedCmd() *cobra.Command {
+	cmd := &cobra.Command{
 		Use:   "delete",
 		Short: "Delete a garden bed",
 		Run: func(cmd *cobra.Command, args []string) {
-			req, err := http.NewRequest("DELETE", fmt.Sprintf("%s/beds/%s", apiUrl, args[0]), nil)
-			if err != nil {
-				fmt.Println("Error deleting bed:", err)
-				os.Exit(1)
-			}
-			req.Header.Set("Content-Type", "application/json")
-
-			response, err := http.DefaultClient.Do(req)
-			if err != nil {
-				fmt.Println("Error deleting bed:", err)
-				os.Exit(1)
-			}
-			defer response.Body.Close()
+			err := apiClient().DeleteBed(cmd.Context(), args[0], ifMatchVersion(cmd))
+			exitOnError(err, "bed", "Error deleting bed")
 
-			if response.StatusCode != http.StatusOK {
-				fmt.Printf("Error: Server returned status code %d\n", response.StatusCode)
-				os.Exit(1)
-			}
-
-			fmt.Println("Garden bed deleted successfully!")
+			fmt.Printf("Garden bed moved to the trash. Restore it with: plantastic trash restore bed %s\n", args[0])
 		},
 	}
+	addIfMatchFlag(cmd, "bed")
+
+	return cmd
 }
@@ -1,12 +1,10 @@
 package main
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
+	"net/url"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/olekukonko/tablewriter"
@@ -14,49 +12,38 @@ import (
 	"github.com/zjpiazza/plantastic/internal/models"
 )
 
-func gardensCmd(apiUrl string) *cobra.Command {
+func gardensCmd() *cobra.Command {
 	gardensCmd := &cobra.Command{
 		Use:   "gardens",
 		Short: "Manage your gardens",
 		Long:  `Create, list, update, and delete your gardens.`,
 	}
 
-	gardensCmd.AddCommand(listGardensCmd(apiUrl))
-	gardensCmd.AddCommand(createGardenCmd(apiUrl))
-	gardensCmd.AddCommand(updateGardenCmd(apiUrl))
-	gardensCmd.AddCommand(deleteGardenCmd(apiUrl))
+	gardensCmd.AddCommand(listGardensCmd())
+	gardensCmd.AddCommand(createGardenCmd())
+	gardensCmd.AddCommand(updateGardenCmd())
+	gardensCmd.AddCommand(deleteGardenCmd())
 
 	return gardensCmd
 }
 
-func listGardensCmd(apiUrl string) *cobra.Command {
-	return &cobra.Command{
+func listGardensCmd() *cobra.Command {
+	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List all gardens",
 		Run: func(cmd *cobra.Command, args []string) {
-			response, err := http.Get(apiUrl + "/gardens")
-			if err != nil {
-				fmt.Println("Error getting gardens:", err)
-				os.Exit(1)
-			}
+			filters := url.Values{}
+			setFilter(cmd, filters, "name", "name")
 
-			defer response.Body.Close()
-			body, err := io.ReadAll(response.Body)
+			gardens, next, err := fetchList(cmd, apiClient().ListGardens, filters)
 			if err != nil {
-				fmt.Println("Error reading response body:", err)
-				os.Exit(1)
-			}
-
-			var gardens []models.Garden
-			err = json.Unmarshal(body, &gardens)
-			if err != nil {
-				fmt.Println("Error unmarshalling response body:", err)
+				fmt.Println("Error getting gardens:", err)
 				os.Exit(1)
 			}
 			table := tablewriter.NewWriter(os.Stdout)
 
 			table.SetHeader(
-				[]string{"ID", "Name", "Location", "Description", "Created At", "Updated At"},
+				[]string{"ID", "Name", "Location", "Description", "Version", "Created At", "Updated At"},
 			)
 
 			for _, v := range gardens {
@@ -65,16 +52,22 @@ func listGardensCmd(apiUrl string) *cobra.Command {
 					v.Name,
 					v.Location,
 					v.Description,
+					strconv.Itoa(v.Version),
 					v.CreatedAt.Format(time.RFC822),
 					v.UpdatedAt.Format(time.RFC822),
 				})
 			}
 			table.Render()
+			printNextCursor(next)
 		},
 	}
+	cmd.Flags().StringP("name", "n", "", "Only list gardens whose name contains this text")
+	addListFlags(cmd)
+
+	return cmd
 }
 
-func createGardenCmd(apiUrl string) *cobra.Command {
+func createGardenCmd() *cobra.Command {
 	createGardenCmd := &cobra.Command{
 		Use:   "create",
 		Short: "Create a new garden",
@@ -89,37 +82,8 @@ func createGardenCmd(apiUrl string) *cobra.Command {
 				Description: description,
 			}
 
-			jsonData, err := json.Marshal(garden)
-			if err != nil {
-				fmt.Println("Error marshalling garden:", err)
-				os.Exit(1)
-			}
-
-			response, err := http.Post(
-				fmt.Sprintf("%s/gardens", apiUrl),
-				"application/json",
-				bytes.NewBuffer(jsonData),
-			)
-			if err != nil {
-				fmt.Println("Error creating garden:", err)
-				os.Exit(1)
-			}
-			defer response.Body.Close()
-
-			body, err := io.ReadAll(response.Body)
-			if err != nil {
-				fmt.Println("Error reading response:", err)
-				os.Exit(1)
-			}
-
-			if response.StatusCode != http.StatusCreated {
-				fmt.Printf(
-					"Error: Server returned status code %d: %s\n",
-					response.StatusCode,
-					string(body),
-				)
-				os.Exit(1)
-			}
+			_, err := apiClient().CreateGarden(cmd.Context(), garden)
+			exitOnError(err, "garden", "Error creating garden")
 
 			fmt.Println("Garden created successfully!")
 		},
@@ -135,63 +99,24 @@ func createGardenCmd(apiUrl string) *cobra.Command {
 	return createGardenCmd
 }
 
-func updateGardenCmd(apiUrl string) *cobra.Command {
+func updateGardenCmd() *cobra.Command {
 	updateGardenCmd := &cobra.Command{
 		Use:   "update",
 		Short: "Update a garden",
 		Args:  cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			id := args[0]
-			name, _ := cmd.Flags().GetString("name")
-			location, _ := cmd.Flags().GetString("location")
-			description, _ := cmd.Flags().GetString("description")
-
-			garden := models.Garden{
-				Name:        name,
-				Location:    location,
-				Description: description,
-			}
-
-			jsonData, err := json.Marshal(garden)
-			if err != nil {
-				fmt.Println("Error marshalling garden:", err)
-				os.Exit(1)
-			}
-
-			request, err := http.NewRequest(
-				"PUT",
-				fmt.Sprintf("%s/gardens/%s", apiUrl, id),
-				bytes.NewBuffer(jsonData),
-			)
-			if err != nil {
-				fmt.Println("Error updating garden:", err)
-				os.Exit(1)
-			}
-
-			request.Header.Set("Content-Type", "application/json")
-
-			client := &http.Client{}
-			response, err := client.Do(request)
-			if err != nil {
-				fmt.Println("Error updating garden:", err)
+			patch := patchFromFlags(cmd, map[string]string{
+				"name":        "name",
+				"location":    "location",
+				"description": "description",
+			})
+			if len(patch) == 0 {
+				fmt.Println("Nothing to update: set at least one of --name, --location or --description")
 				os.Exit(1)
 			}
-			defer response.Body.Close()
 
-			body, err := io.ReadAll(response.Body)
-			if err != nil {
-				fmt.Println("Error reading response:", err)
-				os.Exit(1)
-			}
-
-			if response.StatusCode != http.StatusNoContent {
-				fmt.Printf(
-					"Error: Server returned status code %d: %s\n",
-					response.StatusCode,
-					string(body),
-				)
-				os.Exit(1)
-			}
+			_, err := apiClient().PatchGarden(cmd.Context(), args[0], patch, ifMatchVersion(cmd))
+			exitOnError(err, "garden", "Error updating garden")
 
 			fmt.Println("Garden updated successfully!")
 		},
@@ -201,51 +126,25 @@ func updateGardenCmd(apiUrl string) *cobra.Command {
 	updateGardenCmd.Flags().StringP("location", "l", "", "Location of the garden")
 	updateGardenCmd.Flags().StringP("description", "d", "", "Description of the garden")
 
+	addIfMatchFlag(updateGardenCmd, "garden")
+
 	return updateGardenCmd
 }
 
-func deleteGardenCmd(apiUrl string) *cobra.Command {
+func deleteGardenCmd() *cobra.Command {
 	deleteGardenCmd := &cobra.Command{
 		Use:   "delete",
 		Short: "Delete a garden",
 		Args:  cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			id := args[0]
-			request, err := http.NewRequest("DELETE", fmt.Sprintf("%s/gardens/%s", apiUrl, id), nil)
-			if err != nil {
-				fmt.Println("Error deleting garden:", err)
-				os.Exit(1)
-			}
-
-			request.Header.Set("Content-Type", "application/json")
-
-			client := &http.Client{}
-			response, err := client.Do(request)
-			if err != nil {
-				fmt.Println("Error deleting garden:", err)
-				os.Exit(1)
-			}
-
-			defer response.Body.Close()
+			err := apiClient().DeleteGarden(cmd.Context(), args[0], ifMatchVersion(cmd))
+			exitOnError(err, "garden", "Error deleting garden")
 
-			body, err := io.ReadAll(response.Body)
-			if err != nil {
-				fmt.Println("Error reading response:", err)
-				os.Exit(1)
-			}
-
-			if response.StatusCode != http.StatusNoContent {
-				fmt.Printf(
-					"Error: Server returned status code %d: %s\n",
-					response.StatusCode,
-					string(body),
-				)
-				os.Exit(1)
-			}
-
-			fmt.Println("Garden deleted successfully!")
+			fmt.Printf("Garden moved to the trash. Restore it with: plantastic trash restore garden %s\n", args[0])
 		},
 	}
 
+	addIfMatchFlag(deleteGardenCmd, "garden")
+
 	return deleteGardenCmd
 }
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zjpiazza/plantastic/internal/client"
+)
+
+// addListFlags adds the paging and sorting flags shared by every list command.
+func addListFlags(cmd *cobra.Command) {
+	cmd.Flags().String("sort", "", "Field to sort by; prefix with - for descending order")
+	cmd.Flags().Int("limit", 0, "Maximum number of results per page (server default if unset)")
+	cmd.Flags().String("cursor", "", "Cursor from a previous list to continue from")
+	cmd.Flags().Bool("all", false, "Fetch every page instead of just the first")
+}
+
+// setFilter adds the value of a string flag to filters under key, if it is set.
+func setFilter(cmd *cobra.Command, filters url.Values, flag, key string) {
+	if value, _ := cmd.Flags().GetString(flag); value != "" {
+		filters.Set(key, value)
+	}
+}
+
+// setDateFilter is setFilter for MM-DD-YYYY flags, converting them to the
+// YYYY-MM-DD dates the API expects.
+func setDateFilter(cmd *cobra.Command, filters url.Values, flag, key string) error {
+	value, _ := cmd.Flags().GetString(flag)
+	if value == "" {
+		return nil
+	}
+	t, err := time.Parse("01-02-2006", value)
+	if err != nil {
+		return fmt.Errorf("invalid --%s, expected MM-DD-YYYY: %w", flag, err)
+	}
+	filters.Set(key, t.Format("2006-01-02"))
+	return nil
+}
+
+// fetchList calls a list method with the given filters and the command's
+// paging flags. With --all it follows cursors until the last page; otherwise
+// it returns one page along with the cursor for the next, if any.
+func fetchList[T any](cmd *cobra.Command, list func(context.Context, client.ListOptions) (client.Page[T], error), filters url.Values) ([]T, string, error) {
+	opts := client.ListOptions{Filters: filters}
+	opts.Sort, _ = cmd.Flags().GetString("sort")
+	opts.Cursor, _ = cmd.Flags().GetString("cursor")
+	opts.Limit, _ = cmd.Flags().GetInt("limit")
+
+	if all, _ := cmd.Flags().GetBool("all"); all {
+		items, err := client.Collect(client.All(cmd.Context(), list, opts))
+		return items, "", err
+	}
+	page, err := list(cmd.Context(), opts)
+	return page.Items, page.NextCursor, err
+}
+
+// printNextCursor tells the user how to see the next page, if there is one.
+func printNextCursor(next string) {
+	if next != "" {
+		fmt.Printf("More results available: rerun with --cursor %s, or use --all\n", next)
+	}
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/zjpiazza/plantastic/internal/client"
+)
+
+// patchFromFlags builds a merge patch from the string flags the user set,
+// mapping each flag name to the JSON member it updates. Flags left unset are
+// not in the patch, so the server keeps their current values.
+func patchFromFlags(cmd *cobra.Command, members map[string]string) client.Patch {
+	patch := client.Patch{}
+	for flag, member := range members {
+		if cmd.Flags().Changed(flag) {
+			value, _ := cmd.Flags().GetString(flag)
+			patch[member] = value
+		}
+	}
+	return patch
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"github.com/zjpiazza/plantastic/internal/models"
+)
+
+func plantingsCmd() *cobra.Command {
+	plantingsCmd := &cobra.Command{
+		Use:   "plantings",
+		Short: "Manage what is planted in your beds",
+		Long:  `Create, list, update and delete plantings: plants from your catalog placed in a bed`,
+	}
+
+	plantingsCmd.AddCommand(listPlantingsCmd())
+	plantingsCmd.AddCommand(createPlantingCmd())
+	plantingsCmd.AddCommand(updatePlantingCmd())
+	plantingsCmd.AddCommand(deletePlantingCmd())
+
+	return plantingsCmd
+}
+
+// formatOptionalDate renders an optional date in the CLI's date format.
+func formatOptionalDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("01-02-2006")
+}
+
+// parseOptionalDate parses an MM-DD-YYYY flag value; an empty value means no date.
+func parseOptionalDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse("01-02-2006", value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func listPlantingsCmd() *cobra.Command {
+	listPlantingsCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List plantings",
+		Run: func(cmd *cobra.Command, args []string) {
+			filters := url.Values{}
+			setFilter(cmd, filters, "garden-id", "garden_id")
+			setFilter(cmd, filters, "bed-id", "bed_id")
+			setFilter(cmd, filters, "plant-id", "plant_id")
+			setFilter(cmd, filters, "status", "status")
+
+			plantings, next, err := fetchList(cmd, apiClient().ListPlantings, filters)
+			if err != nil {
+				fmt.Println("Error getting plantings:", err)
+				os.Exit(1)
+			}
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetHeader(
+				[]string{"ID", "Bed ID", "Plant ID", "Quantity", "Sown", "Transplanted", "Status", "Notes"},
+			)
+
+			for _, v := range plantings {
+				table.Append(
+					[]string{v.ID, v.BedID, v.PlantID, strconv.Itoa(v.Quantity), formatOptionalDate(v.SowDate), formatOptionalDate(v.TransplantDate), v.Status, v.Notes},
+				)
+			}
+			table.Render()
+			printNextCursor(next)
+		},
+	}
+	listPlantingsCmd.Flags().StringP("garden-id", "g", "", "Only list plantings in this garden")
+	listPlantingsCmd.Flags().StringP("bed-id", "b", "", "Only list plantings in this bed")
+	listPlantingsCmd.Flags().StringP("plant-id", "p", "", "Only list plantings of this plant")
+	listPlantingsCmd.Flags().StringP("status", "s", "", "Only list plantings with these statuses (comma-separated)")
+	addListFlags(listPlantingsCmd)
+
+	return listPlantingsCmd
+}
+
+// plantingFromFlags builds a Planting from the flags shared by create and update.
+func plantingFromFlags(cmd *cobra.Command) (models.Planting, error) {
+	bedID, _ := cmd.Flags().GetString("bed-id")
+	plantID, _ := cmd.Flags().GetString("plant-id")
+	quantity, _ := cmd.Flags().GetInt("quantity")
+	sowDateStr, _ := cmd.Flags().GetString("sow-date")
+	transplantDateStr, _ := cmd.Flags().GetString("transplant-date")
+	status, _ := cmd.Flags().GetString("status")
+	notes, _ := cmd.Flags().GetString("notes")
+
+	sowDate, err := parseOptionalDate(sowDateStr)
+	if err != nil {
+		return models.Planting{}, fmt.Errorf("invalid sow date, expected MM-DD-YYYY: %w", err)
+	}
+	transplantDate, err := parseOptionalDate(transplantDateStr)
+	if err != nil {
+		return models.Planting{}, fmt.Errorf("invalid transplant date, expected MM-DD-YYYY: %w", err)
+	}
+
+	return models.Planting{
+		BedID:          bedID,
+		PlantID:        plantID,
+		Quantity:       quantity,
+		SowDate:        sowDate,
+		TransplantDate: transplantDate,
+		Status:         status,
+		Notes:          notes,
+	}, nil
+}
+
+func addPlantingFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("bed-id", "b", "", "ID of the bed the plants are in")
+	cmd.Flags().StringP("plant-id", "p", "", "ID of the plant from your catalog")
+	cmd.Flags().IntP("quantity", "q", 1, "Number of plants")
+	cmd.Flags().String("sow-date", "", "Date the seeds were sown (MM-DD-YYYY)")
+	cmd.Flags().String("transplant-date", "", "Date the plants were transplanted (MM-DD-YYYY)")
+	cmd.Flags().StringP("status", "s", "", "Status: "+strings.Join(models.PlantingStatuses, ", "))
+	cmd.Flags().StringP("notes", "N", "", "Notes about the planting")
+}
+
+func createPlantingCmd() *cobra.Command {
+	createPlantingCmd := &cobra.Command{
+		Use:   "create",
+		Short: "Plant something in a bed",
+		Run: func(cmd *cobra.Command, args []string) {
+			planting, err := plantingFromFlags(cmd)
+			if err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+
+			createdPlanting, err := apiClient().CreatePlanting(cmd.Context(), planting)
+			exitOnError(err, "planting", "Error creating planting")
+
+			fmt.Println("Planting created successfully!")
+			fmt.Printf("Created planting: %d x %s in bed %s (ID: %s)\n",
+				createdPlanting.Quantity, createdPlanting.PlantID, createdPlanting.BedID, createdPlanting.ID)
+		},
+	}
+	addPlantingFlags(createPlantingCmd)
+	createPlantingCmd.MarkFlagRequired("bed-id")
+	createPlantingCmd.MarkFlagRequired("plant-id")
+
+	return createPlantingCmd
+}
+
+func updatePlantingCmd() *cobra.Command {
+	updatePlantingCmd := &cobra.Command{
+		Use:   "update <planting-id>",
+		Short: "Update a planting",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			planting, err := plantingFromFlags(cmd)
+			if err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+
+			planting.ID = args[0]
+
+			err = apiClient().UpdatePlanting(cmd.Context(), planting)
+			exitOnError(err, "planting", "Error updating planting")
+
+			fmt.Println("Planting updated successfully!")
+		},
+	}
+	addPlantingFlags(updatePlantingCmd)
+
+	return updatePlantingCmd
+}
+
+func deletePlantingCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <planting-id>",
+		Short: "Delete a planting",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			err := apiClient().DeletePlanting(cmd.Context(), args[0])
+			exitOnError(err, "planting", "Error deleting planting")
+
+			fmt.Println("Planting deleted successfully!")
+		},
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"github.com/zjpiazza/plantastic/internal/models"
+)
+
+func plantsCmd() *cobra.Command {
+	plantsCmd := &cobra.Command{
+		Use:   "plants",
+		Short: "Manage your plant catalog",
+		Long:  `Create, list, update and delete the plants (species and varieties) you grow`,
+	}
+
+	plantsCmd.AddCommand(listPlantsCmd())
+	plantsCmd.AddCommand(createPlantCmd())
+	plantsCmd.AddCommand(updatePlantCmd())
+	plantsCmd.AddCommand(deletePlantCmd())
+
+	return plantsCmd
+}
+
+func listPlantsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List all plants in the catalog",
+		Run: func(cmd *cobra.Command, args []string) {
+			filters := url.Values{}
+			setFilter(cmd, filters, "name", "name")
+			setFilter(cmd, filters, "sun", "sun")
+
+			plants, next, err := fetchList(cmd, apiClient().ListPlants, filters)
+			if err != nil {
+				fmt.Println("Error getting plants:", err)
+				os.Exit(1)
+			}
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetHeader(
+				[]string{"ID", "Name", "Variety", "Species", "Days to Maturity", "Spacing", "Sun"},
+			)
+
+			for _, v := range plants {
+				table.Append(
+					[]string{v.ID, v.Name, v.Variety, v.Species, strconv.Itoa(v.DaysToMaturity), v.Spacing, v.Sun},
+				)
+			}
+			table.Render()
+			printNextCursor(next)
+		},
+	}
+	cmd.Flags().StringP("name", "n", "", "Only list plants whose name contains this text")
+	cmd.Flags().String("sun", "", "Only list plants needing this much sun")
+	addListFlags(cmd)
+
+	return cmd
+}
+
+// plantFromFlags builds a Plant from the flags shared by create and update.
+func plantFromFlags(cmd *cobra.Command) models.Plant {
+	name, _ := cmd.Flags().GetString("name")
+	species, _ := cmd.Flags().GetString("species")
+	variety, _ := cmd.Flags().GetString("variety")
+	daysToMaturity, _ := cmd.Flags().GetInt("days-to-maturity")
+	spacing, _ := cmd.Flags().GetString("spacing")
+	sun, _ := cmd.Flags().GetString("sun")
+	notes, _ := cmd.Flags().GetString("notes")
+
+	return models.Plant{
+		Name:           name,
+		Species:        species,
+		Variety:        variety,
+		DaysToMaturity: daysToMaturity,
+		Spacing:        spacing,
+		Sun:            sun,
+		Notes:          notes,
+	}
+}
+
+func addPlantFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("name", "n", "", "Common name of the plant, e.g. Tomato")
+	cmd.Flags().StringP("species", "s", "", "Botanical name of the plant")
+	cmd.Flags().StringP("variety", "v", "", "Variety or cultivar")
+	cmd.Flags().IntP("days-to-maturity", "d", 0, "Days from planting to first harvest")
+	cmd.Flags().StringP("spacing", "S", "", "Recommended spacing between plants")
+	cmd.Flags().String("sun", "", "Light requirement, e.g. Full sun")
+	cmd.Flags().StringP("notes", "N", "", "Notes about the plant")
+}
+
+func createPlantCmd() *cobra.Command {
+	createPlantCmd := &cobra.Command{
+		Use:   "create",
+		Short: "Add a plant to the catalog",
+		Run: func(cmd *cobra.Command, args []string) {
+			plant := plantFromFlags(cmd)
+
+			createdPlant, err := apiClient().CreatePlant(cmd.Context(), plant)
+			exitOnError(err, "plant", "Error creating plant")
+
+			fmt.Println("Plant created successfully!")
+			fmt.Printf("Created plant: %s (ID: %s)\n", createdPlant.DisplayName(), createdPlant.ID)
+		},
+	}
+	addPlantFlags(createPlantCmd)
+	createPlantCmd.MarkFlagRequired("name")
+
+	return createPlantCmd
+}
+
+func updatePlantCmd() *cobra.Command {
+	updatePlantCmd := &cobra.Command{
+		Use:   "update <plant-id>",
+		Short: "Update a plant in the catalog",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			plant := plantFromFlags(cmd)
+			plant.ID = args[0]
+
+			err := apiClient().UpdatePlant(cmd.Context(), plant)
+			exitOnError(err, "plant", "Error updating plant")
+
+			fmt.Println("Plant updated successfully!")
+		},
+	}
+	addPlantFlags(updatePlantCmd)
+
+	return updatePlantCmd
+}
+
+func deletePlantCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <plant-id>",
+		Short: "Delete a plant from the catalog",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			err := apiClient().DeletePlant(cmd.Context(), args[0])
+			exitOnError(err, "plant", "Error deleting plant")
+
+			fmt.Println("Plant deleted successfully!")
+		},
+	}
+}
@@ -1,11 +1,15 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"github.com/zjpiazza/plantastic/internal/client"
 )
 
 var (
@@ -22,7 +26,10 @@ including beds, plants, and tasks.`,
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	// Ctrl-C cancels the request in flight, retries included
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
@@ -30,13 +37,26 @@ func Execute() {
 
 func init() {
 	// cobra.OnInitialize(initConfig)
-	rootCmd.PersistentFlags().StringVarP(&projectBase, "api-url", "u", "", "Plantastic API URL")
+	rootCmd.PersistentFlags().StringVarP(&projectBase, "api-url", "u", "", "Plantastic API URL (or PLANTASTIC_API_URL)")
+	rootCmd.PersistentFlags().String("token", "", "Bearer token to authenticate with (or PLANTASTIC_TOKEN)")
 	viper.BindPFlag("api-url", rootCmd.PersistentFlags().Lookup("api-url"))
-	viper.SetDefault("api-url", "http://localhost:8080")
+	viper.BindPFlag("token", rootCmd.PersistentFlags().Lookup("token"))
+	viper.SetDefault("api-url", client.DefaultBaseURL)
+	viper.SetEnvPrefix("plantastic")
+	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
+	viper.AutomaticEnv()
 
-	apiUrl := viper.GetString("api-url")
 	// Add subcommands
-	rootCmd.AddCommand(bedsCmd(apiUrl))
-	rootCmd.AddCommand(gardensCmd(apiUrl))
-	rootCmd.AddCommand(tasksCmd(apiUrl))
+	rootCmd.AddCommand(bedsCmd())
+	rootCmd.AddCommand(gardensCmd())
+	rootCmd.AddCommand(plantsCmd())
+	rootCmd.AddCommand(plantingsCmd())
+	rootCmd.AddCommand(tasksCmd())
+	rootCmd.AddCommand(trashCmd())
+}
+
+// apiClient returns a client for the API selected by the flags and
+// environment. It is called once the flags have been parsed.
+func apiClient() *client.Client {
+	return client.New(viper.GetString("api-url"), viper.GetString("token"))
 }
@@ -1,56 +1,59 @@
 package main
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
+	"net/url"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
+	"github.com/zjpiazza/plantastic/internal/client"
 	"github.com/zjpiazza/plantastic/internal/models"
+	"github.com/zjpiazza/plantastic/internal/recurrence"
 )
 
-func tasksCmd(apiUrl string) *cobra.Command {
+func tasksCmd() *cobra.Command {
 	tasksCmd := &cobra.Command{
 		Use:   "tasks",
 		Short: "Manage your garden tasks",
 		Long:  `Create, update, and delete garden tasks`,
 	}
 
-	tasksCmd.AddCommand(listTasksCmd(apiUrl))
-	tasksCmd.AddCommand(createTaskCmd(apiUrl))
-	tasksCmd.AddCommand(updateTaskCmd(apiUrl))
-	tasksCmd.AddCommand(deleteTaskCmd(apiUrl))
+	tasksCmd.AddCommand(listTasksCmd())
+	tasksCmd.AddCommand(createTaskCmd())
+	tasksCmd.AddCommand(updateTaskCmd())
+	tasksCmd.AddCommand(completeTaskCmd())
+	tasksCmd.AddCommand(bulkTasksCmd())
+	tasksCmd.AddCommand(taskHistoryCmd())
+	tasksCmd.AddCommand(deleteTaskCmd())
 
 	return tasksCmd
 }
 
-func listTasksCmd(apiUrl string) *cobra.Command {
-	return &cobra.Command{
+func listTasksCmd() *cobra.Command {
+	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List all tasks",
 		Run: func(cmd *cobra.Command, args []string) {
-			response, err := http.Get(fmt.Sprintf("%s/tasks", apiUrl))
-			if err != nil {
-				fmt.Println("Error getting tasks:", err)
+			filters := url.Values{}
+			setFilter(cmd, filters, "garden-id", "garden_id")
+			setFilter(cmd, filters, "bed-id", "garden_bed_id")
+			setFilter(cmd, filters, "status", "status")
+			setFilter(cmd, filters, "priority", "priority")
+			if err := setDateFilter(cmd, filters, "due-from", "due_from"); err != nil {
+				fmt.Println("Error:", err)
 				os.Exit(1)
 			}
-
-			defer response.Body.Close()
-			body, err := io.ReadAll(response.Body)
-			if err != nil {
-				fmt.Println("Error reading response body:", err)
+			if err := setDateFilter(cmd, filters, "due-to", "due_to"); err != nil {
+				fmt.Println("Error:", err)
 				os.Exit(1)
 			}
 
-			var gardens []models.Task
-			err = json.Unmarshal(body, &gardens)
+			tasks, next, err := fetchList(cmd, apiClient().ListTasks, filters)
 			if err != nil {
-				fmt.Println("Error unmarshalling response body:", err)
+				fmt.Println("Error getting tasks:", err)
 				os.Exit(1)
 			}
 			table := tablewriter.NewWriter(os.Stdout)
@@ -62,192 +65,266 @@ func listTasksCmd(apiUrl string) *cobra.Command {
 					"Due Date",
 					"Status",
 					"Priority",
+					"Repeats",
+					"Version",
 					"Created At",
 					"Updated At",
 				},
 			)
 
-			for _, v := range gardens {
+			for _, v := range tasks {
 				table.Append([]string{
 					v.ID,
 					v.Description,
 					v.DueDate.Format(time.RFC822),
 					v.Status,
 					v.Priority,
+					describeRecurrence(v.Recurrence),
+					strconv.Itoa(v.Version),
 					v.CreatedAt.Format(time.RFC822),
 					v.UpdatedAt.Format(time.RFC822),
 				})
 			}
 			table.Render()
+			printNextCursor(next)
 		},
 	}
+	cmd.Flags().StringP("garden-id", "g", "", "Only list tasks in this garden")
+	cmd.Flags().StringP("bed-id", "b", "", "Only list tasks for this bed")
+	cmd.Flags().StringP("status", "s", "", "Only list tasks with these statuses (comma-separated)")
+	cmd.Flags().StringP("priority", "p", "", "Only list tasks with these priorities (comma-separated)")
+	cmd.Flags().String("due-from", "", "Only list tasks due on or after this date (MM-DD-YYYY)")
+	cmd.Flags().String("due-to", "", "Only list tasks due on or before this date (MM-DD-YYYY)")
+	addListFlags(cmd)
+
+	return cmd
 }
 
-func createTaskCmd(apiUrl string) *cobra.Command {
-	cmd := &cobra.Command{
-		Use:   "create",
-		Short: "Create a new garden task",
-		Run: func(cmd *cobra.Command, args []string) {
-			description, _ := cmd.Flags().GetString("description")
-			dueDateStr, _ := cmd.Flags().GetString("due-date")
+// describeRecurrence renders a task's recurrence rule for display.
+func describeRecurrence(rule string) string {
+	if rule == "" {
+		return ""
+	}
+	parsed, err := recurrence.Parse(rule)
+	if err != nil {
+		return rule
+	}
+	return parsed.Describe()
+}
 
-			// Check that dueDate can be converted to a datetime object
-			dueDate, err := time.Parse("01-02-2006", dueDateStr)
-			if err != nil {
-				fmt.Println("Due date is not properly formatted")
-				os.Exit(1)
-			}
+// taskFromFlags builds a Task from the flags shared by create and update.
+func taskFromFlags(cmd *cobra.Command) (models.Task, error) {
+	gardenID, _ := cmd.Flags().GetString("garden-id")
+	bedID, _ := cmd.Flags().GetString("bed-id")
+	description, _ := cmd.Flags().GetString("description")
+	dueDateStr, _ := cmd.Flags().GetString("due-date")
+	status, _ := cmd.Flags().GetString("status")
+	priority, _ := cmd.Flags().GetString("priority")
+	rule, _ := cmd.Flags().GetString("recurrence")
+
+	// Check that dueDate can be converted to a datetime object
+	dueDate, err := parseOptionalDate(dueDateStr)
+	if err != nil {
+		return models.Task{}, fmt.Errorf("invalid due date, expected MM-DD-YYYY: %w", err)
+	}
+	if rule != "" {
+		if _, err := recurrence.Parse(rule); err != nil {
+			return models.Task{}, err
+		}
+	}
 
-			task := models.Task{
-				Description: description,
-				DueDate:     dueDate,
-			}
+	task := models.Task{
+		GardenID:    gardenID,
+		Description: description,
+		Status:      status,
+		Priority:    priority,
+		Recurrence:  rule,
+	}
+	if bedID != "" {
+		task.BedID = &bedID
+	}
+	if dueDate != nil {
+		task.DueDate = *dueDate
+	}
+	return task, nil
+}
 
-			jsonData, err := json.Marshal(task)
-			if err != nil {
-				fmt.Println("Error marshalling task:")
-			}
+// taskPatchFromFlags builds a merge patch for the task flags the user set.
+// An empty --bed-id clears the task's bed.
+func taskPatchFromFlags(cmd *cobra.Command) (client.Patch, error) {
+	patch := patchFromFlags(cmd, map[string]string{
+		"garden-id":   "garden_id",
+		"description": "description",
+		"status":      "status",
+		"priority":    "priority",
+		"recurrence":  "recurrence",
+	})
+	if cmd.Flags().Changed("bed-id") {
+		if bedID, _ := cmd.Flags().GetString("bed-id"); bedID != "" {
+			patch["garden_bed_id"] = bedID
+		} else {
+			patch["garden_bed_id"] = nil
+		}
+	}
+	if cmd.Flags().Changed("due-date") {
+		dueDateStr, _ := cmd.Flags().GetString("due-date")
+		dueDate, err := parseOptionalDate(dueDateStr)
+		if err != nil || dueDate == nil {
+			return nil, fmt.Errorf("invalid due date, expected MM-DD-YYYY")
+		}
+		patch["due_date"] = *dueDate
+	}
+	if rule, ok := patch["recurrence"].(string); ok && rule != "" {
+		if _, err := recurrence.Parse(rule); err != nil {
+			return nil, err
+		}
+	}
+	return patch, nil
+}
 
-			response, err := http.Post(
-				fmt.Sprintf("%s/tasks", apiUrl),
-				"application/json",
-				bytes.NewBuffer(jsonData),
-			)
+func addTaskFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("garden-id", "g", "", "ID of the garden the task is for")
+	cmd.Flags().StringP("bed-id", "b", "", "ID of the bed the task is for")
+	cmd.Flags().StringP("description", "n", "", "Task description")
+	cmd.Flags().StringP("due-date", "l", "", "Date the task should be completed (MM-DD-YYYY)")
+	cmd.Flags().StringP("status", "s", "", "Task status")
+	cmd.Flags().StringP("priority", "p", "", "Task priority: Low, Medium or High")
+	cmd.Flags().StringP("recurrence", "r", "", `Repeat the task, e.g. "every 2 days", "weekly" or "FREQ=MONTHLY;COUNT=6"`)
+}
+
+func createTaskCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a new garden task",
+		Long: `Create a new garden task.
+
+With --recurrence the task repeats: completing an occurrence schedules the next
+one. Rules are a subset of RFC 5545 RRULE (FREQ=DAILY, WEEKLY or MONTHLY with
+INTERVAL and COUNT or UNTIL) or short forms such as "every 2 days".`,
+		Run: func(cmd *cobra.Command, args []string) {
+			task, err := taskFromFlags(cmd)
 			if err != nil {
-				fmt.Println("Error creating task:", err)
+				fmt.Println("Error:", err)
 				os.Exit(1)
 			}
 
-			defer response.Body.Close()
+			createdTask, err := apiClient().CreateTask(cmd.Context(), task)
+			exitOnError(err, "task", "Error creating task")
 
-			body, err := io.ReadAll(response.Body)
-			if err != nil {
-				fmt.Printf("Error reading response: %d: %s\n", response.StatusCode, string(body))
-				os.Exit(1)
+			fmt.Println("Task created successfully!")
+			if createdTask.IsRecurring() {
+				fmt.Printf("Repeats %s (series ID: %s)\n", describeRecurrence(createdTask.Recurrence), *createdTask.SeriesID)
 			}
 		},
 	}
-	cmd.Flag("description")
-	cmd.Flag("due-date")
+	addTaskFlags(cmd)
 
+	cmd.MarkFlagRequired("garden-id")
 	cmd.MarkFlagRequired("description")
 	cmd.MarkFlagRequired("due-date")
 	return cmd
 }
 
-func updateTaskCmd(apiUrl string) *cobra.Command {
-	updateGardenCmd := &cobra.Command{
-		Use:   "update",
+func updateTaskCmd() *cobra.Command {
+	updateTaskCmd := &cobra.Command{
+		Use:   "update <task-id>",
 		Short: "Update a task",
-		Args:  cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
-			id := args[0]
-			description, _ := cmd.Flags().GetString("description")
-			dueDateStr, _ := cmd.Flags().GetString("due-date")
-
-			dueDate, err := time.Parse("01-02-2006", dueDateStr)
-			if err != nil {
-				fmt.Println("Invalid date format")
-				os.Exit(1)
-			}
-
-			task := models.Task{
-				Description: description,
-				DueDate:     dueDate,
-			}
-
-			jsonData, err := json.Marshal(task)
-			if err != nil {
-				fmt.Println("Error marshalling task:", err)
-				os.Exit(1)
-			}
+		Long: `Update a task.
 
-			request, err := http.NewRequest(
-				"PUT",
-				fmt.Sprintf("%s/tasks/%s", apiUrl, id),
-				bytes.NewBuffer(jsonData),
-			)
-			if err != nil {
-				fmt.Println("Error updating task:", err)
-				os.Exit(1)
-			}
+For a recurring task, --scope occurrence (the default) changes only this
+occurrence, while --scope series changes the description, priority, location
+and recurrence of every open occurrence; changing the due date with
+--scope series moves the whole schedule. Clear --recurrence with
+--scope series to end the series.
 
-			request.Header.Set("Content-Type", "application/json")
+Only the flags you set are changed. Pass --bed-id "" to take the task out of
+its bed.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			id := args[0]
+			scope, _ := cmd.Flags().GetString("scope")
 
-			client := &http.Client{}
-			response, err := client.Do(request)
+			patch, err := taskPatchFromFlags(cmd)
 			if err != nil {
-				fmt.Println("Error updating task:", err)
+				fmt.Println("Error:", err)
 				os.Exit(1)
 			}
-			defer response.Body.Close()
-
-			body, err := io.ReadAll(response.Body)
-			if err != nil {
-				fmt.Println("Error reading response:", err)
+			if len(patch) == 0 {
+				fmt.Println("Nothing to update: set at least one flag")
 				os.Exit(1)
 			}
 
-			if response.StatusCode != http.StatusNoContent {
-				fmt.Printf(
-					"Error: Server returned status code %d: %s\n",
-					response.StatusCode,
-					string(body),
-				)
-				os.Exit(1)
-			}
+			_, err = apiClient().PatchTask(cmd.Context(), id, patch, scope, ifMatchVersion(cmd))
+			exitOnError(err, "task", "Error updating task")
 
 			fmt.Println("Task updated successfully!")
 		},
 	}
 
-	updateGardenCmd.Flags().StringP("description", "n", "", "Task description")
-	updateGardenCmd.Flags().StringP("due-date", "l", "", "Date the task should be completed")
+	addTaskFlags(updateTaskCmd)
+	updateTaskCmd.Flags().String("scope", "", "For recurring tasks: occurrence (default) or series")
+
+	addIfMatchFlag(updateTaskCmd, "task")
 
-	return updateGardenCmd
+	return updateTaskCmd
 }
 
-func deleteTaskCmd(apiUrl string) *cobra.Command {
+func completeTaskCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:   "delete",
-		Short: "Delete a garden task",
+		Use:   "complete <task-id>",
+		Short: "Mark a task as completed",
+		Long:  `Mark a task as completed. For a recurring task this schedules its next occurrence.`,
 		Args:  cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			id := args[0]
+			task, err := apiClient().CompleteTask(cmd.Context(), args[0], 0)
+			exitOnError(err, "task", "Error completing task")
 
-			request, err := http.NewRequest("DELETE", fmt.Sprintf("%s/tasks/%s", apiUrl, id), nil)
-			if err != nil {
-				fmt.Println("Error deleting task:", err)
-				os.Exit(1)
+			fmt.Println("Task completed!")
+			if task.IsRecurring() {
+				fmt.Printf("This task repeats %s; the next occurrence has been scheduled unless the series has ended.\n", describeRecurrence(task.Recurrence))
 			}
+		},
+	}
+}
 
-			request.Header.Set("Content-Type", "application/json")
+func taskHistoryCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "history <task-id>",
+		Short: "Show a task's status changes",
+		Long:  `Show a task's status changes, such as becoming Overdue once its due date passes.`,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			events, err := apiClient().TaskHistory(cmd.Context(), args[0])
+			exitOnError(err, "task", "Error getting task history")
 
-			client := &http.Client{}
-			response, err := client.Do(request)
-			if err != nil {
-				fmt.Println("Error deleting task:", err)
-				os.Exit(1)
+			if len(events) == 0 {
+				fmt.Println("No status changes recorded for this task.")
+				return
 			}
 
-			defer response.Body.Close()
-
-			body, err := io.ReadAll(response.Body)
-			if err != nil {
-				fmt.Println("Error reading response:", err)
-				os.Exit(1)
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetHeader([]string{"When", "From", "To", "Reason"})
+			for _, e := range events {
+				table.Append([]string{e.CreatedAt.Format(time.RFC822), e.FromStatus, e.ToStatus, e.Reason})
 			}
+			table.Render()
+		},
+	}
+}
 
-			if response.StatusCode != http.StatusNoContent {
-				fmt.Printf(
-					"ErrorL Server returned status code %d: %s\n",
-					response.StatusCode,
-					string(body),
-				)
-				os.Exit(1)
-			}
+func deleteTaskCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete",
+		Short: "Delete a garden task",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			err := apiClient().DeleteTask(cmd.Context(), args[0], ifMatchVersion(cmd))
+			exitOnError(err, "task", "Error deleting task")
 
-			fmt.Println("Task deleted successfully!")
+			fmt.Printf("Task moved to the trash. Restore it with: plantastic trash restore task %s\n", args[0])
 		},
 	}
+	addIfMatchFlag(cmd, "task")
+
+	return cmd
 }
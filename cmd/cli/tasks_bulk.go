@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"github.com/zjpiazza/plantastic/internal/client"
+)
+
+func bulkTasksCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bulk",
+		Short: "Apply an operation to many tasks at once",
+		Long: `Apply an operation to many tasks at once.
+
+By default a batch is all-or-nothing: if any task cannot be changed, none
+are. With --partial every task that can be changed is, and the failures are
+listed.`,
+	}
+	cmd.PersistentFlags().Bool("partial", false, "Apply the operations that succeed even if others fail")
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "complete <task-id>...",
+		Short: "Mark tasks as completed",
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			runBulk(cmd, opsForIDs("complete", args, nil))
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "delete <task-id>...",
+		Short: "Delete tasks",
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			runBulk(cmd, opsForIDs("delete", args, nil))
+		},
+	})
+
+	rescheduleCmd := &cobra.Command{
+		Use:   "reschedule <task-id>...",
+		Short: "Move tasks to a new due date, or by a number of days",
+		Long: `Move tasks to a new due date with --due-date, or push each one back by a
+number of days with --shift-days (negative to bring them forward).`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			dueDateStr, _ := cmd.Flags().GetString("due-date")
+			shiftDays, _ := cmd.Flags().GetInt("shift-days")
+			dueDate, err := parseOptionalDate(dueDateStr)
+			if err != nil {
+				fmt.Println("Error: invalid due date, expected MM-DD-YYYY:", err)
+				os.Exit(1)
+			}
+			if (dueDate == nil) == (shiftDays == 0) {
+				fmt.Println("Error: set exactly one of --due-date and --shift-days")
+				os.Exit(1)
+			}
+			runBulk(cmd, opsForIDs("reschedule", args, func(op *client.BulkTaskOp) {
+				op.DueDate = dueDate
+				op.ShiftDays = shiftDays
+			}))
+		},
+	}
+	rescheduleCmd.Flags().StringP("due-date", "l", "", "New due date (MM-DD-YYYY)")
+	rescheduleCmd.Flags().Int("shift-days", 0, "Days to move each due date by")
+	cmd.AddCommand(rescheduleCmd)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "apply <file>",
+		Short: "Apply the operations listed in a JSON file",
+		Long: `Apply the operations listed in a JSON file, or standard input if file is -.
+
+The file holds an array of operations, for example:
+
+  [
+    {"op": "create", "task": {"description": "Mulch", "garden_id": "<garden-id>"}},
+    {"op": "update", "id": "<task-id>", "task": {...}},
+    {"op": "complete", "id": "<task-id>"},
+    {"op": "reschedule", "id": "<task-id>", "shift_days": 2},
+    {"op": "delete", "id": "<task-id>", "version": 3}
+  ]`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var data []byte
+			var err error
+			if args[0] == "-" {
+				data, err = io.ReadAll(os.Stdin)
+			} else {
+				data, err = os.ReadFile(args[0])
+			}
+			if err != nil {
+				fmt.Println("Error reading operations:", err)
+				os.Exit(1)
+			}
+			var ops []json.RawMessage
+			if err := json.Unmarshal(data, &ops); err != nil {
+				fmt.Println("Error: operations must be a JSON array:", err)
+				os.Exit(1)
+			}
+			runBulkWith(cmd, func(ctx context.Context, mode string) (client.BulkTasksResult, error) {
+				return apiClient().BulkTasksRaw(ctx, mode, ops)
+			})
+		},
+	})
+
+	return cmd
+}
+
+// opsForIDs builds one op per task ID, applying set to each if it is non-nil.
+func opsForIDs(op string, ids []string, set func(*client.BulkTaskOp)) []client.BulkTaskOp {
+	ops := make([]client.BulkTaskOp, len(ids))
+	for i, id := range ids {
+		ops[i] = client.BulkTaskOp{Op: op, ID: id}
+		if set != nil {
+			set(&ops[i])
+		}
+	}
+	return ops
+}
+
+// runBulk sends ops to the bulk endpoint, prints each operation's outcome
+// and exits non-zero if any failed.
+func runBulk(cmd *cobra.Command, ops []client.BulkTaskOp) {
+	runBulkWith(cmd, func(ctx context.Context, mode string) (client.BulkTasksResult, error) {
+		return apiClient().BulkTasks(ctx, mode, ops)
+	})
+}
+
+// runBulkWith is runBulk for a batch sent by send, in the mode chosen with
+// --partial.
+func runBulkWith(cmd *cobra.Command, send func(ctx context.Context, mode string) (client.BulkTasksResult, error)) {
+	mode := client.BulkAtomic
+	if partial, _ := cmd.Flags().GetBool("partial"); partial {
+		mode = client.BulkPartial
+	}
+	result, err := send(cmd.Context(), mode)
+	if err != nil {
+		fmt.Println("Error applying operations:", err)
+		os.Exit(1)
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"#", "Op", "Task ID", "Result"})
+	failed := 0
+	for _, r := range result.Results {
+		outcome := http.StatusText(r.Status)
+		if err := r.Err(); err != nil {
+			outcome = err.Error()
+			failed++
+		}
+		table.Append([]string{strconv.Itoa(r.Index + 1), r.Op, r.ID, outcome})
+	}
+	table.Render()
+
+	switch {
+	case failed == 0:
+		fmt.Printf("Applied %d operations.\n", len(result.Results))
+	case result.Mode == client.BulkAtomic:
+		fmt.Println("Nothing was changed. Fix the failing operation, or rerun with --partial.")
+		os.Exit(1)
+	default:
+		fmt.Printf("%d of %d operations failed.\n", failed, len(result.Results))
+		os.Exit(1)
+	}
+}
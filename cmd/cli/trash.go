@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"github.com/zjpiazza/plantastic/internal/client"
+)
+
+func trashCmd() *cobra.Command {
+	trashCmd := &cobra.Command{
+		Use:   "trash",
+		Short: "Restore or purge deleted items",
+		Long: `Deleted gardens, beds and tasks go to the trash, where they can be
+restored until the server purges them. Restoring a garden also restores
+the beds and tasks deleted with it.`,
+	}
+
+	trashCmd.AddCommand(listTrashCmd())
+	trashCmd.AddCommand(restoreTrashCmd())
+	trashCmd.AddCommand(purgeTrashCmd())
+
+	return trashCmd
+}
+
+// trashTypes is what the type argument of the trash subcommands accepts.
+var trashTypes = []string{client.TrashGarden, client.TrashBed, client.TrashTask}
+
+func listTrashCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the items in the trash",
+		Run: func(cmd *cobra.Command, args []string) {
+			itemType, _ := cmd.Flags().GetString("type")
+			items, err := apiClient().ListTrash(cmd.Context(), itemType)
+			if err != nil {
+				fmt.Println("Error getting the trash:", err)
+				os.Exit(1)
+			}
+			if len(items) == 0 {
+				fmt.Println("The trash is empty.")
+				return
+			}
+
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetHeader([]string{"Type", "ID", "Name", "Garden ID", "Deleted At", "Purged After"})
+			for _, item := range items {
+				table.Append([]string{
+					item.Type,
+					item.ID,
+					item.Name,
+					item.GardenID,
+					item.DeletedAt.Local().Format(time.RFC822),
+					item.PurgeAfter.Local().Format(time.RFC822),
+				})
+			}
+			table.Render()
+		},
+	}
+	cmd.Flags().StringP("type", "t", "", "Only list items of this type: garden, bed or task")
+
+	return cmd
+}
+
+func restoreTrashCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:       "restore <garden|bed|task> <id>",
+		Short:     "Restore an item from the trash",
+		Args:      cobra.ExactArgs(2),
+		ValidArgs: trashTypes,
+		Run: func(cmd *cobra.Command, args []string) {
+			result, err := apiClient().RestoreTrash(cmd.Context(), args[0], args[1])
+			exitOnError(err, args[0], "Error restoring "+args[0])
+
+			fmt.Printf("Restored %s %s", result.Type, result.ID)
+			if result.Type == client.TrashGarden {
+				fmt.Printf(" with %d beds and %d tasks", result.RestoredBeds, result.RestoredTasks)
+			}
+			fmt.Println(".")
+		},
+	}
+}
+
+func purgeTrashCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "purge [<garden|bed|task> <id>]",
+		Short: "Delete items in the trash for good",
+		Long: `Delete an item in the trash for good, along with everything in it, or
+with --all empty the trash. Purged items cannot be restored.`,
+		ValidArgs: trashTypes,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if all, _ := cmd.Flags().GetBool("all"); all {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.ExactArgs(2)(cmd, args)
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if all, _ := cmd.Flags().GetBool("all"); all {
+				purged, err := apiClient().EmptyTrash(cmd.Context())
+				exitOnError(err, "trash", "Error emptying the trash")
+				fmt.Printf("Purged %d items from the trash.\n", purged)
+				return
+			}
+
+			err := apiClient().PurgeTrash(cmd.Context(), args[0], args[1])
+			exitOnError(err, args[0], "Error purging "+args[0])
+			fmt.Printf("Purged %s %s.\n", args[0], args[1])
+		},
+	}
+	cmd.Flags().Bool("all", false, "Empty the whole trash")
+
+	return cmd
+}
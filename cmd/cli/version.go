@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/zjpiazza/plantastic/internal/client"
+)
+
+// addIfMatchFlag adds --if-match to a command that changes or deletes a resource.
+func addIfMatchFlag(cmd *cobra.Command, what string) {
+	cmd.Flags().Int("if-match", 0, fmt.Sprintf("Only apply if the %s is still at this version (see the Version column of list)", what))
+}
+
+// ifMatchVersion returns the version set with --if-match, or 0 if it is unset.
+func ifMatchVersion(cmd *cobra.Command) int {
+	version, _ := cmd.Flags().GetInt("if-match")
+	return version
+}
+
+// exitOnError prints err after message and exits if err is non-nil. A
+// version conflict is explained instead; what names the resource (e.g.
+// "garden").
+func exitOnError(err error, what, message string) {
+	if err == nil {
+		return
+	}
+	if errors.Is(err, client.ErrVersionConflict) {
+		printVersionConflict(what)
+	} else {
+		fmt.Println(message+":", err)
+	}
+	os.Exit(1)
+}
+
+func printVersionConflict(what string) {
+	fmt.Printf("Error: the %s was changed by someone else since you fetched it. List it again to see the latest version, then retry.\n", what)
+}
@@ -0,0 +1,285 @@
+package components
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/zjpiazza/plantastic/internal/models"
+)
+
+// PlantForm represents a form for adding/editing plants in the catalog
+type PlantForm struct {
+	title        string
+	inputs       []textinput.Model
+	focusIndex   int
+	width        int
+	height       int
+	storage      PlantStorage
+	submitted    bool
+	cancelled    bool
+	errorMessage string
+	onSave       func(models.Plant)
+
+	// For editing existing plants
+	plant  models.Plant
+	isEdit bool
+}
+
+// PlantStorage interface for plant operations
+type PlantStorage interface {
+	GetPlant(id string) (models.Plant, bool)
+	AddPlant(plant models.Plant) error
+	UpdatePlant(plant models.Plant) error
+}
+
+// NewPlantForm creates a new form for adding/editing plants
+func NewPlantForm(storage PlantStorage, width, height int, onSave func(models.Plant)) PlantForm {
+	m := PlantForm{
+		title:   "Add Plant",
+		width:   width,
+		height:  height,
+		storage: storage,
+		inputs:  make([]textinput.Model, 7),
+		onSave:  onSave,
+	}
+
+	// Name input
+	m.inputs[0] = textinput.New()
+	m.inputs[0].Placeholder = "Name (e.g., Tomato)"
+	m.inputs[0].Focus()
+	m.inputs[0].Width = 30
+
+	// Variety input
+	m.inputs[1] = textinput.New()
+	m.inputs[1].Placeholder = "Variety (e.g., Cherokee Purple)"
+	m.inputs[1].Width = 30
+
+	// Species input
+	m.inputs[2] = textinput.New()
+	m.inputs[2].Placeholder = "Species"
+	m.inputs[2].Width = 30
+
+	// Days to maturity input
+	m.inputs[3] = textinput.New()
+	m.inputs[3].Placeholder = "Days to maturity"
+	m.inputs[3].Width = 30
+
+	// Spacing input
+	m.inputs[4] = textinput.New()
+	m.inputs[4].Placeholder = "Spacing"
+	m.inputs[4].Width = 30
+
+	// Sun input
+	m.inputs[5] = textinput.New()
+	m.inputs[5].Placeholder = "Sun (e.g., Full sun, Part shade)"
+	m.inputs[5].Width = 30
+
+	// Notes input
+	m.inputs[6] = textinput.New()
+	m.inputs[6].Placeholder = "Notes"
+	m.inputs[6].Width = 40
+
+	return m
+}
+
+// SetPlant sets the form to edit an existing plant
+func (m *PlantForm) SetPlant(plant models.Plant) {
+	m.title = "Edit Plant"
+	m.isEdit = true
+	m.plant = plant
+	m.inputs[0].SetValue(plant.Name)
+	m.inputs[1].SetValue(plant.Variety)
+	m.inputs[2].SetValue(plant.Species)
+	if plant.DaysToMaturity > 0 {
+		m.inputs[3].SetValue(strconv.Itoa(plant.DaysToMaturity))
+	}
+	m.inputs[4].SetValue(plant.Spacing)
+	m.inputs[5].SetValue(plant.Sun)
+	m.inputs[6].SetValue(plant.Notes)
+}
+
+// Init initializes the form
+func (m PlantForm) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// Update handles form events
+func (m PlantForm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmds []tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			m.cancelled = true
+			return m, nil
+
+		case "tab", "shift+tab", "up", "down":
+			// Cycle focus through inputs
+			s := msg.String()
+			if s == "up" || s == "shift+tab" {
+				m.focusIndex--
+			} else {
+				m.focusIndex++
+			}
+
+			if m.focusIndex < 0 {
+				m.focusIndex = len(m.inputs) - 1
+			} else if m.focusIndex >= len(m.inputs) {
+				m.focusIndex = 0
+			}
+
+			for i := 0; i < len(m.inputs); i++ {
+				if i == m.focusIndex {
+					cmds = append(cmds, m.inputs[i].Focus())
+				} else {
+					m.inputs[i].Blur()
+				}
+			}
+
+			return m, tea.Batch(cmds...)
+
+		case "enter":
+			// Submit the form
+			m.errorMessage = ""
+
+			if err := m.submitForm(); err != nil {
+				m.errorMessage = err.Error()
+				return m, nil
+			}
+
+			m.submitted = true
+			return m, nil
+		}
+	}
+
+	// Handle text input updates
+	cmd := m.updateInputs(msg)
+	return m, cmd
+}
+
+// updateInputs updates the text inputs
+func (m *PlantForm) updateInputs(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	var cmds []tea.Cmd
+
+	for i := range m.inputs {
+		m.inputs[i], cmd = m.inputs[i].Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
+	return tea.Batch(cmds...)
+}
+
+// submitForm validates and submits the plant form
+func (m *PlantForm) submitForm() error {
+	name := strings.TrimSpace(m.inputs[0].Value())
+	variety := strings.TrimSpace(m.inputs[1].Value())
+	species := strings.TrimSpace(m.inputs[2].Value())
+	daysStr := strings.TrimSpace(m.inputs[3].Value())
+	spacing := strings.TrimSpace(m.inputs[4].Value())
+	sun := strings.TrimSpace(m.inputs[5].Value())
+	notes := strings.TrimSpace(m.inputs[6].Value())
+
+	if name == "" {
+		return fmt.Errorf("plant name is required")
+	}
+
+	daysToMaturity := 0
+	if daysStr != "" {
+		days, err := strconv.Atoi(daysStr)
+		if err != nil || days < 0 {
+			return fmt.Errorf("days to maturity must be a whole number of days")
+		}
+		daysToMaturity = days
+	}
+
+	var plant models.Plant
+
+	if m.isEdit {
+		// Update existing plant
+		plant = m.plant
+		plant.Name = name
+		plant.Variety = variety
+		plant.Species = species
+		plant.DaysToMaturity = daysToMaturity
+		plant.Spacing = spacing
+		plant.Sun = sun
+		plant.Notes = notes
+		plant.UpdatedAt = time.Now()
+
+		if err := m.storage.UpdatePlant(plant); err != nil {
+			return fmt.Errorf("failed to update plant: %w", err)
+		}
+	} else {
+		// Create new plant
+		plant = models.NewPlant(name, species, variety, daysToMaturity, spacing, sun, notes)
+		if err := m.storage.AddPlant(plant); err != nil {
+			return fmt.Errorf("failed to add plant: %w", err)
+		}
+	}
+
+	// Call the onSave callback with the plant
+	if m.onSave != nil {
+		m.onSave(plant)
+	}
+
+	return nil
+}
+
+// View renders the form
+func (m PlantForm) View() string {
+	var b strings.Builder
+
+	// Form title
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#25A065")).
+		Padding(1, 0, 1, 2)
+
+	b.WriteString(titleStyle.Render(m.title))
+	b.WriteString("\n\n")
+
+	// Form inputs
+	for i, input := range m.inputs {
+		b.WriteString("  ")
+		b.WriteString(input.View())
+		if i < len(m.inputs)-1 {
+			b.WriteString("\n\n")
+		}
+	}
+
+	// Error message
+	if m.errorMessage != "" {
+		errorStyle := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FF3B30")).
+			Padding(1, 0)
+		b.WriteString("\n\n")
+		b.WriteString(errorStyle.Render("Error: " + m.errorMessage))
+	}
+
+	// Form controls
+	controlsStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#626262")).
+		Padding(2, 0)
+
+	b.WriteString("\n\n")
+	b.WriteString(controlsStyle.Render("TAB: Next field • SHIFT+TAB: Previous field • ENTER: Submit • ESC: Cancel"))
+
+	return b.String()
+}
+
+// Submitted returns true if the form was submitted
+func (m PlantForm) Submitted() bool {
+	return m.submitted
+}
+
+// Cancelled returns true if the form was cancelled
+func (m PlantForm) Cancelled() bool {
+	return m.cancelled
+}
@@ -9,6 +9,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/zjpiazza/plantastic/internal/models"
+	"github.com/zjpiazza/plantastic/internal/recurrence"
 )
 
 // TaskForm represents a form for adding/editing tasks
@@ -30,6 +31,9 @@ type TaskForm struct {
 	gardenID string
 	bedID    *string
 	dueDate  time.Time
+
+	// For recurring tasks, whether edits apply to every open occurrence
+	editSeries bool
 }
 
 // TaskStorage interface for task operations
@@ -37,6 +41,7 @@ type TaskStorage interface {
 	GetTask(id string) (models.Task, bool)
 	AddTask(task models.Task) error
 	UpdateTask(task models.Task) error
+	UpdateTaskSeries(task models.Task) error
 }
 
 // NewTaskForm creates a new form for adding/editing tasks
@@ -46,7 +51,7 @@ func NewTaskForm(storage TaskStorage, gardenID string, bedID *string, width, hei
 		width:    width,
 		height:   height,
 		storage:  storage,
-		inputs:   make([]textinput.Model, 3),
+		inputs:   make([]textinput.Model, 4),
 		onSave:   onSave,
 		gardenID: gardenID,
 		bedID:    bedID,
@@ -71,6 +76,11 @@ func NewTaskForm(storage TaskStorage, gardenID string, bedID *string, width, hei
 	m.inputs[2].Width = 30
 	m.inputs[2].SetValue(models.PriorityMedium)
 
+	// Recurrence input
+	m.inputs[3] = textinput.New()
+	m.inputs[3].Placeholder = "Repeat (e.g. every 2 days, weekly, FREQ=MONTHLY;COUNT=6)"
+	m.inputs[3].Width = 50
+
 	return m
 }
 
@@ -85,6 +95,7 @@ func (m *TaskForm) SetTask(task models.Task) {
 	m.inputs[0].SetValue(task.Description)
 	m.inputs[1].SetValue(task.Status)
 	m.inputs[2].SetValue(task.Priority)
+	m.inputs[3].SetValue(task.Recurrence)
 }
 
 // SetDueDate sets the due date for the task
@@ -108,6 +119,13 @@ func (m TaskForm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.cancelled = true
 			return m, nil
 
+		case "ctrl+a":
+			// Toggle between editing this occurrence and the whole series
+			if m.isEdit && m.task.IsRecurring() {
+				m.editSeries = !m.editSeries
+			}
+			return m, nil
+
 		case "tab", "shift+tab", "up", "down":
 			// Cycle focus through inputs
 			s := msg.String()
@@ -170,6 +188,7 @@ func (m *TaskForm) submitForm() error {
 	description := strings.TrimSpace(m.inputs[0].Value())
 	status := strings.TrimSpace(m.inputs[1].Value())
 	priority := strings.TrimSpace(m.inputs[2].Value())
+	rule := strings.TrimSpace(m.inputs[3].Value())
 
 	if description == "" {
 		return fmt.Errorf("task description is required")
@@ -203,9 +222,43 @@ func (m *TaskForm) submitForm() error {
 		return fmt.Errorf("invalid priority: use Low, Medium, or High")
 	}
 
+	if rule != "" {
+		parsed, err := recurrence.Parse(rule)
+		if err != nil {
+			return fmt.Errorf("invalid repeat rule: %w", err)
+		}
+		rule = parsed.String()
+	}
+
 	var task models.Task
 
-	if m.isEdit {
+	if m.isEdit && m.editSeries {
+		// Update every open occurrence of the series; status stays per occurrence
+		task = m.task
+		task.Description = description
+		task.Priority = priority
+		task.Recurrence = rule
+		task.DueDate = m.dueDate
+
+		if err := m.storage.UpdateTaskSeries(task); err != nil {
+			return fmt.Errorf("failed to update series: %w", err)
+		}
+		if status != m.task.Status {
+			if updated, ok := m.storage.GetTask(task.ID); ok {
+				updated.Status = status
+				if err := m.storage.UpdateTask(updated); err != nil {
+					return fmt.Errorf("failed to update task: %w", err)
+				}
+			}
+		}
+	} else if m.isEdit {
+		if rule != m.task.Recurrence {
+			if m.task.IsRecurring() {
+				return fmt.Errorf("press ctrl+a to edit the whole series before changing how it repeats")
+			}
+			return fmt.Errorf("repeat can only be set when creating a task")
+		}
+
 		// Update existing task
 		task = m.task
 		task.Description = description
@@ -220,6 +273,7 @@ func (m *TaskForm) submitForm() error {
 	} else {
 		// Create new task
 		task = models.NewTask(m.gardenID, m.bedID, description, m.dueDate, status, priority)
+		task.Recurrence = rule
 		if err := m.storage.AddTask(task); err != nil {
 			return fmt.Errorf("failed to add task: %w", err)
 		}
@@ -264,6 +318,17 @@ func (m TaskForm) View() string {
 	b.WriteString(dueDateStyle.Render(fmt.Sprintf("  Due Date: %s", m.dueDate.Format("2006-01-02"))))
 	b.WriteString("\n  (Press D to change due date)")
 
+	// Edit scope for recurring tasks
+	if m.isEdit && m.task.IsRecurring() {
+		scope := "this occurrence"
+		if m.editSeries {
+			scope = "all open occurrences in the series"
+		}
+		b.WriteString("\n\n")
+		b.WriteString(dueDateStyle.Render(fmt.Sprintf("  Editing: %s", scope)))
+		b.WriteString("\n  (Press CTRL+A to switch between this occurrence and the series)")
+	}
+
 	// Error message
 	if m.errorMessage != "" {
 		errorStyle := lipgloss.NewStyle().
@@ -18,6 +18,7 @@ const (
 	FormTypeGarden FormType = iota
 	FormTypeBed
 	FormTypeTask
+	FormTypePlant
 )
 
 // FormModel represents a form for adding/editing items
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
@@ -28,6 +29,32 @@ func (i BedItem) Title() string       { return i.bed.Name }
 func (i BedItem) Description() string { return fmt.Sprintf("%s - %s", i.bed.Type, i.bed.Notes) }
 func (i BedItem) FilterValue() string { return i.bed.Name }
 
+// PlantItem represents a plant in the list, with a summary of where it is planted
+type PlantItem struct {
+	plant   models.Plant
+	planted string // e.g. "Tomato Bed (6)"; empty if not planted anywhere
+}
+
+func (i PlantItem) Title() string       { return i.plant.DisplayName() }
+func (i PlantItem) Description() string { return i.summary() }
+func (i PlantItem) FilterValue() string { return i.plant.DisplayName() }
+
+func (i PlantItem) summary() string {
+	var parts []string
+	if i.plant.DaysToMaturity > 0 {
+		parts = append(parts, fmt.Sprintf("%d days", i.plant.DaysToMaturity))
+	}
+	if i.plant.Sun != "" {
+		parts = append(parts, i.plant.Sun)
+	}
+	if i.planted != "" {
+		parts = append(parts, "In: "+i.planted)
+	} else {
+		parts = append(parts, "Not planted")
+	}
+	return strings.Join(parts, " | ")
+}
+
 // GardenDelegate is a custom delegate for rendering gardens in the list
 type GardenDelegate struct {
 	styles list.DefaultItemStyles
@@ -127,6 +154,56 @@ func (d BedDelegate) Render(w io.Writer, m list.Model, index int, item list.Item
 	}
 }
 
+// PlantDelegate is a custom delegate for rendering plants in the list
+type PlantDelegate struct {
+	styles list.DefaultItemStyles
+}
+
+// NewPlantDelegate creates a new plant delegate with custom styles
+func NewPlantDelegate(styles list.DefaultItemStyles) list.ItemDelegate {
+	styles.NormalTitle = styles.NormalTitle.Copy().
+		Foreground(lipgloss.AdaptiveColor{Light: "#1a1a1a", Dark: "#dddddd"})
+
+	styles.NormalDesc = styles.NormalDesc.Copy().
+		Foreground(lipgloss.AdaptiveColor{Light: "#666666", Dark: "#999999"})
+
+	styles.SelectedTitle = styles.SelectedTitle.Copy().
+		Border(lipgloss.NormalBorder(), false, false, false, true).
+		BorderForeground(lipgloss.Color("#25A065")).
+		Foreground(lipgloss.Color("#25A065")).
+		Bold(true)
+
+	styles.SelectedDesc = styles.SelectedDesc.Copy().
+		Border(lipgloss.NormalBorder(), false, false, false, true).
+		BorderForeground(lipgloss.Color("#25A065")).
+		Foreground(lipgloss.Color("#25A065"))
+
+	return &PlantDelegate{styles: styles}
+}
+
+func (d PlantDelegate) Height() int { return 2 }
+
+func (d PlantDelegate) Spacing() int { return 1 }
+
+func (d PlantDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
+
+func (d PlantDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	plantItem, ok := item.(PlantItem)
+	if !ok {
+		return
+	}
+
+	title := plantItem.Title()
+	desc := plantItem.summary()
+	if index == m.Index() {
+		fmt.Fprint(w, d.styles.SelectedTitle.Render(title))
+		fmt.Fprintf(w, "\n%s", d.styles.SelectedDesc.Render(desc))
+	} else {
+		fmt.Fprint(w, d.styles.NormalTitle.Render(title))
+		fmt.Fprintf(w, "\n%s", d.styles.NormalDesc.Render(desc))
+	}
+}
+
 // TaskItem implements list.Item for Task
 type TaskItem struct {
 	task models.Task
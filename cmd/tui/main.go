@@ -4,16 +4,15 @@ package main
 // and continually print up to date terminal information.
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
-	"io"
 	"net"
-	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"sort"
 	"strings"
 	"syscall"
 	"time"
@@ -32,12 +31,12 @@ import (
 	"github.com/charmbracelet/wish/bubbletea"
 	"github.com/muesli/termenv"
 
-	clerkSDK "github.com/clerk/clerk-sdk-go/v2"
-
 	"github.com/google/uuid"
 	"github.com/joho/godotenv"
 	"github.com/zjpiazza/plantastic/cmd/tui/components"
+	"github.com/zjpiazza/plantastic/internal/client"
 	"github.com/zjpiazza/plantastic/internal/models"
+	"github.com/zjpiazza/plantastic/internal/recurrence"
 )
 
 const (
@@ -195,13 +194,24 @@ var keys = keyMap{
 	),
 }
 
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
 func main() {
 	err := godotenv.Load()
+	apiURL := flag.String("api-url", envOr("PLANTASTIC_API_URL", client.DefaultBaseURL), "Plantastic API URL, used to link this TUI as a device")
+	flag.Parse()
+	api := client.New(*apiURL, "")
+
 	s, err := wish.NewServer(
 		wish.WithAddress(net.JoinHostPort(host, port)),
 		wish.WithHostKeyPath(".ssh/id_ed25519"),
 		wish.WithMiddleware(
-			plantasticBubbleteaMiddleware(),
+			plantasticBubbleteaMiddleware(api),
 		),
 	)
 	if err != nil {
@@ -210,7 +220,7 @@ func main() {
 
 	done := make(chan os.Signal, 1)
 	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
-	log.Info("Starting SSH server", "host", host, "port", port)
+	log.Info("Starting SSH server", "host", host, "port", port, "api", api.BaseURL)
 	go func() {
 		if err = s.ListenAndServe(); err != nil && !errors.Is(err, ssh.ErrServerClosed) {
 			log.Error("Could not start server", "error", err)
@@ -227,15 +237,16 @@ func main() {
 	}
 }
 
-// plantasticBubbleteaMiddleware creates a custom bubbletea middleware
-func plantasticBubbleteaMiddleware() wish.Middleware {
+// plantasticBubbleteaMiddleware creates a custom bubbletea middleware whose
+// sessions link themselves through api
+func plantasticBubbleteaMiddleware(api *client.Client) wish.Middleware {
 	teaHandler := func(s ssh.Session) *tea.Program {
 		pty, _, active := s.Pty()
 		if !active {
 			wish.Fatalln(s, "no active terminal, skipping")
 			return nil
 		}
-		m := initialModel(pty.Term, pty.Window.Width, pty.Window.Height)
+		m := initialModel(pty.Term, pty.Window.Width, pty.Window.Height, api)
 		return tea.NewProgram(m, append(bubbletea.MakeOptions(s), tea.WithAltScreen())...)
 	}
 	return bubbletea.MiddlewareWithProgramHandler(teaHandler, termenv.ANSI256)
@@ -247,6 +258,7 @@ const (
 	dashboardTab tabContent = iota
 	gardensTab
 	bedsTab
+	plantsTab
 	tasksTab
 	settingsTab
 )
@@ -261,6 +273,7 @@ type model struct {
 	term       string
 	gardenList list.Model
 	bedList    list.Model
+	plantList  list.Model
 	taskTable  table.Model
 	loading    bool
 	err        error
@@ -276,38 +289,39 @@ type model struct {
 	gardenForm     components.GardenForm
 	bedForm        components.BedForm
 	taskForm       components.TaskForm
+	plantForm      components.PlantForm
 	activeFormType FormType
 
 	// Storage
 	storage *MemoryStorage
 
-	// Clerk Authentication - no client stored if using global SetKey
+	// Authentication (tokens come from the API's device flow)
 	isAuthenticated  bool
 	authTokenInput   textinput.Model
 	authErrorMessage string
 	// sessionClaims *clerkSession.Claims // Optionally store claims if needed later
 
 	// Auth state management
+	api              *client.Client // API the device flow runs against
 	authState        int
-	deviceCode       string // This will store the UserCode from the API
+	userCode         string // The code the user types into the web app
+	deviceCode       string // Secret device_code the TUI polls the token endpoint with
 	deviceID         string // Unique ID for this TUI instance
 	authError        string
 	pollTicker       *time.Ticker
 	verificationURI  string        // To store the URI provided by the API
 	authPollInterval time.Duration // To store the polling interval from API
+	accessToken      string        // Plantastic-issued device access token
+	refreshToken     string        // Exchanged at /device/token/refresh when the access token expires
 }
 
-func initialModel(term string, width, height int) model {
-	// Initialize Clerk Client by setting the global key
-	secretKey := os.Getenv("CLERK_SECRET_KEY")
-	if secretKey == "" {
-		log.Fatal("CLERK_SECRET_KEY environment variable not set.")
-	}
-	clerkSDK.SetKey(secretKey) // Set the key globally
+func initialModel(term string, width, height int, api *client.Client) model {
+	// No auth provider secrets are needed here: the TUI only obtains tokens
+	// through the API's device flow, and the API verifies them.
 
 	// Auth Token Input
 	tokenInput := textinput.New()
-	tokenInput.Placeholder = "Paste your Clerk Session Token here"
+	tokenInput.Placeholder = "Paste your session token here"
 	tokenInput.Focus()
 	tokenInput.CharLimit = 0 // No limit, tokens can be long
 	tokenInput.Width = 50
@@ -335,12 +349,19 @@ func initialModel(term string, width, height int) model {
 	bedList.SetShowHelp(false)
 	bedList.Styles.Title = titleStyle
 
+	plantDelegate := NewPlantDelegate(list.NewDefaultItemStyles())
+	plantList := list.New([]list.Item{}, plantDelegate, width/2, height-10)
+	plantList.Title = "Plants"
+	plantList.SetShowHelp(false)
+	plantList.Styles.Title = titleStyle
+
 	columns := []table.Column{
 		{Title: "ID", Width: 10},
 		{Title: "Description", Width: 30},
 		{Title: "Due Date", Width: 15},
 		{Title: "Status", Width: 15},
 		{Title: "Priority", Width: 10},
+		{Title: "Repeats", Width: 18},
 	}
 	rows := []table.Row{}
 	taskTable := table.New(
@@ -355,7 +376,7 @@ func initialModel(term string, width, height int) model {
 	s.Spinner = spinner.Dot
 	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
 
-	tabNames := []string{"Dashboard", "Gardens", "Beds", "Tasks", "Settings"}
+	tabNames := []string{"Dashboard", "Gardens", "Beds", "Plants", "Tasks", "Settings"}
 
 	// Generate a unique DeviceID for this TUI instance
 	instanceDeviceID := uuid.New().String()
@@ -369,6 +390,7 @@ func initialModel(term string, width, height int) model {
 		height:           height,
 		gardenList:       gardenList,
 		bedList:          bedList,
+		plantList:        plantList,
 		taskTable:        taskTable,
 		uiState:          stateAuth, // Start with authentication state
 		spinner:          s,
@@ -376,11 +398,13 @@ func initialModel(term string, width, height int) model {
 		loadMsg:          "Initializing Plantastic...",
 		storage:          storage,
 		authTokenInput:   tokenInput,
+		api:              api,
 		deviceID:         instanceDeviceID, // Set the generated DeviceID
 		authPollInterval: 5 * time.Second,  // Default poll interval
 	}
 
 	m.refreshGardenList() // Keep this, or move it after successful auth if data depends on user
+	m.refreshPlantList()
 
 	return m
 }
@@ -417,6 +441,7 @@ func loadSampleData(storage *MemoryStorage) {
 
 	// Tasks for Backyard Garden - Tomato Bed
 	task1 := models.NewTask(garden1.ID, &bed1.ID, "Water tomatoes", now.AddDate(0, 0, 1), models.TaskStatusPending, models.PriorityHigh)
+	task1.Recurrence = "FREQ=DAILY;INTERVAL=2"
 	task2 := models.NewTask(garden1.ID, &bed1.ID, "Add fertilizer", now.AddDate(0, 0, 7), models.TaskStatusPending, models.PriorityMedium)
 	task3 := models.NewTask(garden1.ID, &bed1.ID, "Check for pests", now.AddDate(0, 0, 3), models.TaskStatusPending, models.PriorityLow)
 
@@ -437,6 +462,24 @@ func loadSampleData(storage *MemoryStorage) {
 
 	storage.AddTask(task6)
 	storage.AddTask(task7)
+
+	// Create a sample plant catalog and place plants in the beds
+	tomato := models.NewPlant("Tomato", "Solanum lycopersicum", "Cherokee Purple", 80, "24in", "Full sun", "Indeterminate; needs staking")
+	basil := models.NewPlant("Basil", "Ocimum basilicum", "Genovese", 60, "12in", "Full sun", "")
+	lettuce := models.NewPlant("Lettuce", "Lactuca sativa", "Buttercrunch", 55, "8in", "Part shade", "Bolts in summer heat")
+	rose := models.NewPlant("Rose", "Rosa", "Double Delight", 0, "36in", "Full sun", "")
+
+	storage.AddPlant(tomato)
+	storage.AddPlant(basil)
+	storage.AddPlant(lettuce)
+	storage.AddPlant(rose)
+
+	sown := now.AddDate(0, 0, -60)
+	transplanted := now.AddDate(0, 0, -21)
+	storage.AddPlanting(models.NewPlanting(bed1.ID, tomato.ID, 6, &sown, &transplanted, models.PlantingStatusGrowing, ""))
+	storage.AddPlanting(models.NewPlanting(bed2.ID, basil.ID, 4, &sown, nil, models.PlantingStatusGrowing, ""))
+	storage.AddPlanting(models.NewPlanting(bed3.ID, lettuce.ID, 12, nil, nil, models.PlantingStatusPlanned, ""))
+	storage.AddPlanting(models.NewPlanting(bed4.ID, rose.ID, 3, nil, nil, models.PlantingStatusGrowing, ""))
 }
 
 type item struct {
@@ -497,7 +540,8 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, m.spinner.Tick
 
 		case deviceCodeMsg:
-			m.deviceCode = msg.code
+			m.userCode = msg.userCode
+			m.deviceCode = msg.deviceCode
 			if msg.verificationURI != "" {
 				m.verificationURI = msg.verificationURI
 			}
@@ -522,12 +566,27 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case authPendingMsg:
 			return m, m.checkAuthStatus()
 
+		case authWaitMsg:
+			// Schedule the next poll; a Cmd returned as a Msg would never run
+			return m, tea.Tick(m.authPollInterval, func(t time.Time) tea.Msg {
+				return authPendingMsg{}
+			})
+
+		case authSlowDownMsg:
+			// The server asked us to back off; RFC 8628 says to add 5 seconds to the interval
+			m.authPollInterval += 5 * time.Second
+			return m, tea.Tick(m.authPollInterval, func(t time.Time) tea.Msg {
+				return authPendingMsg{}
+			})
+
 		case authSuccessMsg:
-			log.Info("Authentication successful, received token", "length", len(msg.token))
+			log.Info("Authentication successful, received device tokens")
 			if m.pollTicker != nil {
 				m.pollTicker.Stop()
 			}
-			// Store the token and proceed
+			// Store the tokens and proceed
+			m.accessToken = msg.accessToken
+			m.refreshToken = msg.refreshToken
 			m.isAuthenticated = true
 			m.uiState = stateSplash
 			return m, tea.Batch(m.spinner.Tick, loadingTick())
@@ -570,6 +629,19 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, tea.Batch(cmds...)
 
+		case FormTypePlant:
+			formModel, cmd := m.plantForm.Update(msg)
+			m.plantForm = formModel.(components.PlantForm)
+			cmds = append(cmds, cmd)
+
+			if m.plantForm.Submitted() || m.plantForm.Cancelled() {
+				m.showingForm = false
+				if m.plantForm.Submitted() {
+					m.refreshPlantList()
+				}
+			}
+			return m, tea.Batch(cmds...)
+
 		case FormTypeTask:
 			formModel, cmd := m.taskForm.Update(msg)
 			m.taskForm = formModel.(components.TaskForm)
@@ -642,6 +714,13 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						m.showingForm = true
 					}
 
+				case plantsTab:
+					m.plantForm = components.NewPlantForm(m, m.width, m.height, func(plant models.Plant) {
+						m.refreshPlantList()
+					})
+					m.activeFormType = FormTypePlant
+					m.showingForm = true
+
 				case tasksTab:
 					if selectedGarden, ok := m.getSelectedGarden(); ok {
 						var bedID *string
@@ -678,6 +757,16 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						m.showingForm = true
 					}
 
+				case plantsTab:
+					if selectedPlant, ok := m.getSelectedPlant(); ok {
+						m.plantForm = components.NewPlantForm(m, m.width, m.height, func(plant models.Plant) {
+							m.refreshPlantList()
+						})
+						m.plantForm.SetPlant(selectedPlant)
+						m.activeFormType = FormTypePlant
+						m.showingForm = true
+					}
+
 				case tasksTab:
 					if selectedTask, ok := m.getSelectedTask(); ok {
 						m.taskForm = components.NewTaskForm(m, selectedTask.GardenID, selectedTask.BedID, m.width, m.height, func(task models.Task) {
@@ -698,6 +787,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Update list dimensions
 		m.gardenList.SetSize(m.width/2, m.height-10)
 		m.bedList.SetSize(m.width/2, m.height-10)
+		m.plantList.SetSize(m.width/2, m.height-10)
 		// Update table height
 		m.taskTable.SetHeight(m.height - 15)
 		// Update auth input width
@@ -760,6 +850,11 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+	case plantsTab:
+		var cmd tea.Cmd
+		m.plantList, cmd = m.plantList.Update(msg)
+		cmds = append(cmds, cmd)
+
 	case tasksTab:
 		var cmd tea.Cmd
 		m.taskTable, cmd = m.taskTable.Update(msg)
@@ -880,6 +975,8 @@ func (m model) View() string {
 			return m.bedForm.View()
 		case FormTypeTask:
 			return m.taskForm.View()
+		case FormTypePlant:
+			return m.plantForm.View()
 		}
 	}
 
@@ -907,6 +1004,8 @@ func (m model) View() string {
 		content = m.gardenList.View()
 	case bedsTab:
 		content = m.bedList.View()
+	case plantsTab:
+		content = m.plantList.View()
 	case tasksTab:
 		content = m.renderTasks()
 	case settingsTab:
@@ -953,17 +1052,13 @@ func (m model) renderAuthScreen() string {
 	case authStateWaitingForAuth:
 		// Show the code and URL
 		linkURL := m.verificationURI
-		if linkURL == "" {
-			// Fallback if verification URI wasn't received from API
-			linkURL = fmt.Sprintf("http://localhost:8080/link/%s", m.deviceCode)
-		}
 
 		codeStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#25A065"))
 		urlStyle := lipgloss.NewStyle().Underline(true).Foreground(lipgloss.Color("#4B9CD3"))
 
 		msg := fmt.Sprintf("Please visit:\n\n%s\n\nAnd enter this code: %s\n\nWaiting for authentication...",
 			urlStyle.Render(linkURL),
-			codeStyle.Render(m.deviceCode))
+			codeStyle.Render(m.userCode))
 		b.WriteString(lipgloss.NewStyle().Width(m.width).Align(lipgloss.Center).Render(msg))
 		b.WriteString("\n\n")
 		b.WriteString(lipgloss.NewStyle().Width(m.width).Align(lipgloss.Center).Render(m.spinner.View()))
@@ -1144,6 +1239,19 @@ func (m model) UpdateBed(bed models.Bed) error {
 	return m.storage.UpdateBed(bed)
 }
 
+// Implement the PlantStorage interface for PlantForm
+func (m model) GetPlant(id string) (models.Plant, bool) {
+	return m.storage.GetPlant(id)
+}
+
+func (m model) AddPlant(plant models.Plant) error {
+	return m.storage.AddPlant(plant)
+}
+
+func (m model) UpdatePlant(plant models.Plant) error {
+	return m.storage.UpdatePlant(plant)
+}
+
 // Helper methods to get selected items from the lists/tables
 func (m model) getSelectedGarden() (models.Garden, bool) {
 	// Check if we have any gardens in the list
@@ -1187,6 +1295,19 @@ func (m model) getSelectedBed() (models.Bed, bool) {
 	return models.Bed{}, false
 }
 
+func (m model) getSelectedPlant() (models.Plant, bool) {
+	index := m.plantList.Index()
+	if index < 0 || index >= len(m.plantList.Items()) {
+		return models.Plant{}, false
+	}
+
+	if plantItem, ok := m.plantList.Items()[index].(PlantItem); ok {
+		return plantItem.plant, true
+	}
+
+	return models.Plant{}, false
+}
+
 func (m model) getSelectedTask() (models.Task, bool) {
 	// Get the currently selected task from the table
 	if m.taskTable.Cursor() >= len(m.taskTable.Rows()) {
@@ -1218,6 +1339,10 @@ func (m model) UpdateTask(task models.Task) error {
 	return m.storage.UpdateTask(task)
 }
 
+func (m model) UpdateTaskSeries(task models.Task) error {
+	return m.storage.UpdateTaskSeries(task)
+}
+
 // Add refresh methods to update the UI lists and tables with data from storage
 func (m *model) refreshGardenList() {
 	gardens := m.storage.GetGardens()
@@ -1237,6 +1362,37 @@ func (m *model) refreshBedList(gardenID string) {
 	m.bedList.SetItems(items)
 }
 
+// refreshPlantList reloads the plant catalog, summarizing which beds each plant is in
+func (m *model) refreshPlantList() {
+	plants := m.storage.GetPlants()
+	sort.Slice(plants, func(i, j int) bool { return plants[i].DisplayName() < plants[j].DisplayName() })
+	items := make([]list.Item, len(plants))
+	for i, plant := range plants {
+		var beds []string
+		for _, planting := range m.storage.GetPlantingsByPlant(plant.ID) {
+			bedName := planting.BedID
+			if bed, ok := m.storage.GetBed(planting.BedID); ok {
+				bedName = bed.Name
+			}
+			beds = append(beds, fmt.Sprintf("%s (%d)", bedName, planting.Quantity))
+		}
+		items[i] = PlantItem{plant: plant, planted: strings.Join(beds, ", ")}
+	}
+	m.plantList.SetItems(items)
+}
+
+// describeRecurrence renders a task's recurrence rule for the task table.
+func describeRecurrence(rule string) string {
+	if rule == "" {
+		return ""
+	}
+	parsed, err := recurrence.Parse(rule)
+	if err != nil {
+		return rule
+	}
+	return parsed.Describe()
+}
+
 func (m *model) refreshTaskTable(gardenID string, bedID *string) {
 	tasks := m.storage.GetTasks(gardenID, bedID)
 	rows := make([]table.Row, len(tasks))
@@ -1248,11 +1404,15 @@ func (m *model) refreshTaskTable(gardenID string, bedID *string) {
 			dueDate,
 			task.Status,
 			task.Priority,
+			describeRecurrence(task.Recurrence),
 		}
 	}
 	m.taskTable.SetRows(rows)
 }
 
+// tuiClientID identifies the TUI to the device flow endpoints.
+const tuiClientID = "plantastic-tui"
+
 // Add these new functions for device auth
 func (m model) requestDeviceCode() tea.Cmd {
 	return func() tea.Msg {
@@ -1263,53 +1423,31 @@ func (m model) requestDeviceCode() tea.Cmd {
 
 		log.Info("Requesting device code", "deviceID", m.deviceID)
 
-		payload := map[string]string{"device_id": m.deviceID}
-		jsonPayload, err := json.Marshal(payload)
-		if err != nil {
-			return errMsg{fmt.Errorf("failed to marshal request payload: %w", err)}
-		}
-
-		req, err := http.NewRequest("POST", "http://localhost:8000/device/request-code", bytes.NewBuffer(jsonPayload))
-		if err != nil {
-			return errMsg{fmt.Errorf("failed to create request: %w", err)}
-		}
-		req.Header.Set("Content-Type", "application/json")
-
-		client := &http.Client{Timeout: 10 * time.Second}
-		resp, err := client.Do(req)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		result, err := m.api.RequestDeviceCode(ctx, tuiClientID, m.deviceID, "")
 		if err != nil {
 			log.Error("Failed to request device code", "error", err)
-			return errMsg{err}
+			return errMsg{fmt.Errorf("failed to request device code: %w", err)}
 		}
-		defer resp.Body.Close()
-
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		log.Info("Device code response", "status", resp.Status, "body", string(bodyBytes))
 
-		if resp.StatusCode != http.StatusOK {
-			return errMsg{fmt.Errorf("failed to request device code, status: %s, body: %s", resp.Status, string(bodyBytes))}
+		if result.UserCode == "" || result.DeviceCode == "" || result.VerificationURI == "" {
+			return errMsg{fmt.Errorf("received empty user_code, device_code or verification_uri from API")}
 		}
 
-		var result struct {
-			UserCode        string `json:"user_code"`
-			VerificationURI string `json:"verification_uri"`
-			ExpiresIn       int    `json:"expires_in"`
-			Interval        int    `json:"interval"`
-		}
-		if err := json.Unmarshal(bodyBytes, &result); err != nil {
-			return errMsg{fmt.Errorf("failed to decode device code response: %w", err)}
-		}
+		log.Info("Received device code", "code", result.UserCode)
 
-		if result.UserCode == "" {
-			return errMsg{fmt.Errorf("received empty user_code from API")}
+		// Prefer the complete URI so the user doesn't have to type the code
+		verificationURI := result.VerificationURIComplete
+		if verificationURI == "" {
+			verificationURI = result.VerificationURI
 		}
 
-		log.Info("Received device code", "code", result.UserCode)
-
 		// Return all details in the message
 		return deviceCodeMsg{
-			code:            result.UserCode,
-			verificationURI: result.VerificationURI,
+			deviceCode:      result.DeviceCode,
+			userCode:        result.UserCode,
+			verificationURI: verificationURI,
 			interval:        result.Interval,
 			expiresIn:       result.ExpiresIn,
 		}
@@ -1318,61 +1456,40 @@ func (m model) requestDeviceCode() tea.Cmd {
 
 func (m model) checkAuthStatus() tea.Cmd {
 	return func() tea.Msg {
-		if m.deviceCode == "" { // This is the user_code
+		if m.deviceCode == "" {
 			return errMsg{fmt.Errorf("no device code to check status for")}
 		}
 
-		log.Info("Checking auth status", "deviceCode", m.deviceCode)
-
-		req, err := http.NewRequest("GET", fmt.Sprintf("http://localhost:8000/device/check-status?code=%s", m.deviceCode), nil)
-		if err != nil {
-			return errMsg{fmt.Errorf("failed to create request: %w", err)}
-		}
-
-		client := &http.Client{Timeout: 10 * time.Second}
-		resp, err := client.Do(req)
-		if err != nil {
-			log.Error("Error checking auth status", "error", err)
-			// Continue polling even on connection errors
-			return tea.Tick(2*time.Second, func(t time.Time) tea.Msg {
-				return authPendingMsg{}
-			})
-		}
-		defer resp.Body.Close()
-
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		log.Info("Auth status response", "status", resp.Status, "body", string(bodyBytes))
+		log.Info("Checking auth status", "userCode", m.userCode)
 
-		if resp.StatusCode != http.StatusOK {
-			return errMsg{fmt.Errorf("failed to check auth status, status: %s, body: %s", resp.Status, string(bodyBytes))}
-		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		tokens, err := m.api.PollDeviceToken(ctx, tuiClientID, m.deviceCode)
 
-		// Re-use the buffer we already read
-		var result struct {
-			Status string `json:"status"`
-			Token  string `json:"token,omitempty"`
-			Error  string `json:"error,omitempty"`
-		}
-		if err := json.Unmarshal(bodyBytes, &result); err != nil {
-			return errMsg{fmt.Errorf("failed to decode auth status response: %w", err)}
-		}
-
-		switch result.Status {
-		case "activated":
+		// RFC 8628 section 3.5 error codes
+		var urlErr *url.Error
+		switch {
+		case err == nil:
 			log.Info("Device has been activated")
-			if result.Token == "" {
-				return errMsg{fmt.Errorf("status is activated but token is missing")}
+			if tokens.AccessToken == "" || tokens.RefreshToken == "" {
+				return errMsg{fmt.Errorf("status is activated but tokens are missing")}
 			}
-			return authSuccessMsg{token: result.Token}
-		case "pending_activation":
+			return authSuccessMsg{accessToken: tokens.AccessToken, refreshToken: tokens.RefreshToken}
+		case errors.Is(err, client.ErrAuthorizationPending):
 			log.Info("Still waiting for activation")
-			return tea.Tick(2*time.Second, func(t time.Time) tea.Msg {
-				return authPendingMsg{}
-			})
-		case "failed":
-			return errMsg{fmt.Errorf("authentication failed: %s", result.Error)}
+			return authWaitMsg{}
+		case errors.Is(err, client.ErrSlowDown):
+			return authSlowDownMsg{}
+		case errors.Is(err, client.ErrExpiredToken):
+			return errMsg{fmt.Errorf("the code expired before it was approved; restart to get a new one")}
+		case errors.Is(err, client.ErrAccessDenied):
+			return errMsg{fmt.Errorf("the link request was denied")}
+		case errors.As(err, &urlErr):
+			log.Error("Error checking auth status", "error", err)
+			// Continue polling even on connection errors
+			return authWaitMsg{}
 		default:
-			return errMsg{fmt.Errorf("unknown authentication status: %s", result.Status)}
+			return errMsg{fmt.Errorf("authentication failed: %w", err)}
 		}
 	}
 }
@@ -1380,12 +1497,15 @@ func (m model) checkAuthStatus() tea.Cmd {
 // Add message types
 type (
 	deviceCodeMsg struct {
-		code            string
+		deviceCode      string
+		userCode        string
 		verificationURI string
 		interval        int
 		expiresIn       int
 	}
-	authSuccessMsg struct{ token string }
-	authPendingMsg struct{}
-	errMsg         struct{ error error }
+	authSuccessMsg  struct{ accessToken, refreshToken string }
+	authPendingMsg  struct{} // Time to poll the token endpoint
+	authWaitMsg     struct{} // Poll again after the current interval
+	authSlowDownMsg struct{} // Poll again after widening the interval
+	errMsg          struct{ error error }
 )
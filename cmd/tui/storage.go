@@ -5,7 +5,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/zjpiazza/plantastic/internal/models"
+	"github.com/zjpiazza/plantastic/internal/recurrence"
 )
 
 // Storage defines the interface for interacting with data
@@ -29,23 +31,45 @@ type Storage interface {
 	GetTask(id string) (models.Task, bool)
 	AddTask(task models.Task) error
 	UpdateTask(task models.Task) error
+	UpdateTaskSeries(task models.Task) error
 	DeleteTask(id string) error
+
+	// Plant methods
+	GetPlants() []models.Plant
+	GetPlant(id string) (models.Plant, bool)
+	AddPlant(plant models.Plant) error
+	UpdatePlant(plant models.Plant) error
+	DeletePlant(id string) error
+
+	// Planting methods
+	GetPlantingsByPlant(plantID string) []models.Planting
+	AddPlanting(planting models.Planting) error
 }
 
-// MemoryStorage provides in-memory storage for gardens, beds, and tasks
+// MemoryStorage provides in-memory storage for gardens, beds, tasks, plants and plantings
 type MemoryStorage struct {
-	gardens map[string]models.Garden
-	beds    map[string]models.Bed
-	tasks   map[string]models.Task
-	mu      sync.RWMutex
+	gardens   map[string]models.Garden
+	beds      map[string]models.Bed
+	tasks     map[string]models.Task
+	plants    map[string]models.Plant
+	plantings map[string]models.Planting
+	mu        sync.RWMutex
+
+	// statusBeforeOverdue remembers what overdue tasks were before, so they
+	// reopen with it if their due date moves into the future
+	statusBeforeOverdue map[string]string
 }
 
 // NewMemoryStorage creates a new instance of MemoryStorage
 func NewMemoryStorage() *MemoryStorage {
 	return &MemoryStorage{
-		gardens: make(map[string]models.Garden),
-		beds:    make(map[string]models.Bed),
-		tasks:   make(map[string]models.Task),
+		gardens:   make(map[string]models.Garden),
+		beds:      make(map[string]models.Bed),
+		tasks:     make(map[string]models.Task),
+		plants:    make(map[string]models.Plant),
+		plantings: make(map[string]models.Planting),
+
+		statusBeforeOverdue: make(map[string]string),
 	}
 }
 
@@ -151,15 +175,17 @@ func (s *MemoryStorage) DeleteBed(id string) error {
 
 // Task operations
 func (s *MemoryStorage) GetTask(id string) (models.Task, bool) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refreshTaskStatuses()
 	task, ok := s.tasks[id]
 	return task, ok
 }
 
 func (s *MemoryStorage) GetTasks(gardenID string, bedID *string) []models.Task {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refreshTaskStatuses()
 	var tasks []models.Task
 	for _, task := range s.tasks {
 		if task.GardenID == gardenID {
@@ -171,27 +197,136 @@ func (s *MemoryStorage) GetTasks(gardenID string, bedID *string) []models.Task {
 	return tasks
 }
 
+// refreshTaskStatuses applies automatic status transitions, such as marking
+// tasks past their due date Overdue. Callers must hold s.mu for writing.
+func (s *MemoryStorage) refreshTaskStatuses() {
+	now := time.Now()
+	for id, task := range s.tasks {
+		if status := task.EvaluateStatus(now, s.statusBeforeOverdue[id]); status != task.Status {
+			if status == models.TaskStatusOverdue {
+				s.statusBeforeOverdue[id] = task.Status
+			} else {
+				delete(s.statusBeforeOverdue, id)
+			}
+			task.Status = status
+			task.UpdatedAt = now
+			s.tasks[id] = task
+		}
+	}
+}
+
+// AddTask stores a task. A task with a Recurrence rule becomes the first
+// occurrence of a new series.
 func (s *MemoryStorage) AddTask(task models.Task) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	if _, exists := s.tasks[task.ID]; exists {
 		return fmt.Errorf("task with ID %s already exists", task.ID)
 	}
+	if task.Recurrence != "" && !task.IsRecurring() {
+		rule, err := recurrence.Parse(task.Recurrence)
+		if err != nil {
+			return err
+		}
+		seriesID := uuid.New().String()
+		dueDate := task.DueDate
+		task.Recurrence = rule.String()
+		task.SeriesID = &seriesID
+		task.Occurrence = 1
+		task.RecurrenceID = &dueDate
+	}
 	s.tasks[task.ID] = task
 	return nil
 }
 
+// UpdateTask updates a single task. Closing an occurrence of a recurring task
+// adds the next occurrence of its series.
 func (s *MemoryStorage) UpdateTask(task models.Task) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	if _, exists := s.tasks[task.ID]; !exists {
+	existing, exists := s.tasks[task.ID]
+	if !exists {
 		return fmt.Errorf("task with ID %s not found", task.ID)
 	}
 	task.UpdatedAt = time.Now()
 	s.tasks[task.ID] = task
+
+	if existing.IsRecurring() && !existing.IsClosed() && task.IsClosed() {
+		s.addNextOccurrence(existing)
+	}
 	return nil
 }
 
+// UpdateTaskSeries applies the task's description, priority and recurrence
+// rule to every open occurrence of its series, shifting their due dates by
+// however much the task's due date moved. An empty rule ends the series.
+func (s *MemoryStorage) UpdateTaskSeries(task models.Task) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, exists := s.tasks[task.ID]
+	if !exists {
+		return fmt.Errorf("task with ID %s not found", task.ID)
+	}
+	if !existing.IsRecurring() {
+		return fmt.Errorf("task %s is not part of a recurring series", task.ID)
+	}
+	rule := ""
+	if task.Recurrence != "" {
+		parsed, err := recurrence.Parse(task.Recurrence)
+		if err != nil {
+			return err
+		}
+		rule = parsed.String()
+	}
+
+	shift := task.DueDate.Sub(existing.DueDate)
+	for id, occurrence := range s.tasks {
+		if !occurrence.IsRecurring() || *occurrence.SeriesID != *existing.SeriesID || occurrence.IsClosed() {
+			continue
+		}
+		occurrence.Description = task.Description
+		occurrence.Priority = task.Priority
+		occurrence.Recurrence = rule
+		occurrence.DueDate = occurrence.DueDate.Add(shift)
+		if occurrence.RecurrenceID != nil {
+			slot := occurrence.RecurrenceID.Add(shift)
+			occurrence.RecurrenceID = &slot
+		}
+		occurrence.UpdatedAt = time.Now()
+		s.tasks[id] = occurrence
+	}
+	return nil
+}
+
+// addNextOccurrence adds the occurrence after prev unless its series has ended
+// or that occurrence already exists. Callers must hold s.mu.
+func (s *MemoryStorage) addNextOccurrence(prev models.Task) {
+	rule, err := recurrence.Parse(prev.Recurrence)
+	if err != nil {
+		return
+	}
+	slot := prev.DueDate
+	if prev.RecurrenceID != nil {
+		slot = *prev.RecurrenceID
+	}
+	nextDue, ok := rule.Next(slot, prev.Occurrence)
+	if !ok {
+		return
+	}
+	for _, task := range s.tasks {
+		if task.IsRecurring() && *task.SeriesID == *prev.SeriesID && task.Occurrence == prev.Occurrence+1 {
+			return
+		}
+	}
+
+	next := models.NewTask(prev.GardenID, prev.BedID, prev.Description, nextDue, models.TaskStatusPending, prev.Priority)
+	next.Recurrence = prev.Recurrence
+	next.SeriesID = prev.SeriesID
+	next.Occurrence = prev.Occurrence + 1
+	next.RecurrenceID = &nextDue
+	s.tasks[next.ID] = next
+}
+
 func (s *MemoryStorage) DeleteTask(id string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -201,3 +336,83 @@ func (s *MemoryStorage) DeleteTask(id string) error {
 	delete(s.tasks, id)
 	return nil
 }
+
+// Plant operations
+func (s *MemoryStorage) GetPlant(id string) (models.Plant, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	plant, ok := s.plants[id]
+	return plant, ok
+}
+
+func (s *MemoryStorage) GetPlants() []models.Plant {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	plants := make([]models.Plant, 0, len(s.plants))
+	for _, plant := range s.plants {
+		plants = append(plants, plant)
+	}
+	return plants
+}
+
+func (s *MemoryStorage) AddPlant(plant models.Plant) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.plants[plant.ID]; exists {
+		return fmt.Errorf("plant with ID %s already exists", plant.ID)
+	}
+	s.plants[plant.ID] = plant
+	return nil
+}
+
+func (s *MemoryStorage) UpdatePlant(plant models.Plant) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.plants[plant.ID]; !exists {
+		return fmt.Errorf("plant with ID %s not found", plant.ID)
+	}
+	plant.UpdatedAt = time.Now()
+	s.plants[plant.ID] = plant
+	return nil
+}
+
+func (s *MemoryStorage) DeletePlant(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.plants[id]; !exists {
+		return fmt.Errorf("plant with ID %s not found", id)
+	}
+	for _, planting := range s.plantings {
+		if planting.PlantID == id {
+			return fmt.Errorf("plant with ID %s is still planted in a bed", id)
+		}
+	}
+	delete(s.plants, id)
+	return nil
+}
+
+// Planting operations
+func (s *MemoryStorage) GetPlantingsByPlant(plantID string) []models.Planting {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var plantings []models.Planting
+	for _, planting := range s.plantings {
+		if planting.PlantID == plantID {
+			plantings = append(plantings, planting)
+		}
+	}
+	return plantings
+}
+
+func (s *MemoryStorage) AddPlanting(planting models.Planting) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.plantings[planting.ID]; exists {
+		return fmt.Errorf("planting with ID %s already exists", planting.ID)
+	}
+	if bed, ok := s.beds[planting.BedID]; ok {
+		planting.GardenID = bed.GardenID
+	}
+	s.plantings[planting.ID] = planting
+	return nil
+}
@@ -5,26 +5,38 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strings"
 
 	"github.com/gorilla/mux"
+	"github.com/zjpiazza/plantastic/internal/auth"
 	"github.com/zjpiazza/plantastic/internal/device"
 )
 
 type DeviceHandler struct {
-	manager   *device.Manager
-	templates *template.Template
+	manager       *device.Manager
+	templates     *template.Template
+	authenticator auth.Authenticator
 }
 
 type PageData struct {
 	ClerkPublishableKey string
 	ClerkFrontendAPI    string
 	Code                string
+
+	// Local auth provider only. Pages read the token DevSignIn stored in the
+	// browser; the development sign-in page itself gets the users to sign in
+	// as, the token for the chosen one and the page to return to.
+	LocalAuth  bool
+	LocalUsers []auth.User
+	LocalToken string
+	Next       string
 }
 
-func NewDeviceHandler(manager *device.Manager, templates *template.Template) *DeviceHandler {
+func NewDeviceHandler(manager *device.Manager, templates *template.Template, authenticator auth.Authenticator) *DeviceHandler {
 	return &DeviceHandler{
-		manager:   manager,
-		templates: templates,
+		manager:       manager,
+		templates:     templates,
+		authenticator: authenticator,
 	}
 }
 
@@ -40,12 +52,40 @@ func (h *DeviceHandler) HandleDeviceLinkCode(w http.ResponseWriter, r *http.Requ
 	vars := mux.Vars(r)
 	code := vars["code"]
 
-	_, err := h.manager.ValidateUserCode(code)
+	_, err := h.manager.ValidateUserCode(r.Context(), code)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
+	data, ok := h.signInPageData(w, r)
+	if !ok {
+		return
+	}
+	data.Code = code
+	h.templates.ExecuteTemplate(w, "link_code.html", data)
+}
+
+// HandleDevices renders the linked-devices page. The page signs the user in
+// and calls the API's /devices endpoints from the browser.
+func (h *DeviceHandler) HandleDevices(w http.ResponseWriter, r *http.Request) {
+	data, ok := h.signInPageData(w, r)
+	if !ok {
+		return
+	}
+	h.templates.ExecuteTemplate(w, "devices.html", data)
+}
+
+// signInPageData fills in what a page needs to sign the user in with the
+// configured auth provider. It writes an error response and returns false
+// if the request can't be served.
+func (h *DeviceHandler) signInPageData(w http.ResponseWriter, r *http.Request) (PageData, bool) {
+	// With the local provider there is no hosted sign-in; the page uses the
+	// token from HandleDevSignIn, which is only served in development
+	if _, ok := h.authenticator.(*auth.LocalAuthenticator); ok {
+		return PageData{LocalAuth: true}, true
+	}
+
 	clerkPublishableKey := os.Getenv("CLERK_PUBLISHABLE_KEY")
 	if clerkPublishableKey == "" {
 		log.Println("Warning: CLERK_PUBLISHABLE_KEY environment variable not set for web handler.")
@@ -54,10 +94,36 @@ func (h *DeviceHandler) HandleDeviceLinkCode(w http.ResponseWriter, r *http.Requ
 
 	log.Println("Clerk Publishable Key:", clerkPublishableKey)
 
-	data := PageData{
+	return PageData{
 		ClerkPublishableKey: clerkPublishableKey,
 		ClerkFrontendAPI:    "relative-seasnail-33.clerk.accounts.dev",
-		Code:                code,
+	}, true
+}
+
+// HandleDevSignIn lets anyone sign in as any of the local auth provider's
+// users by picking one, and returns them to the page in ?next=. It is only
+// for development: main registers it when PLANTASTIC_DEV_SIGN_IN is set.
+func (h *DeviceHandler) HandleDevSignIn(w http.ResponseWriter, r *http.Request) {
+	local, ok := h.authenticator.(*auth.LocalAuthenticator)
+	if !ok {
+		http.NotFound(w, r)
+		return
 	}
-	h.templates.ExecuteTemplate(w, "link_code.html", data)
+
+	// Only return to a page on this site
+	next := r.URL.Query().Get("next")
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		next = "/"
+	}
+
+	data := PageData{LocalAuth: true, LocalUsers: local.Users(), Next: next}
+	if userID := r.URL.Query().Get("as"); userID != "" {
+		token, _, err := local.Issue(userID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		data.LocalToken = token
+	}
+	h.templates.ExecuteTemplate(w, "dev_sign_in.html", data)
 }
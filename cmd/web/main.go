@@ -5,14 +5,14 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 
-	"github.com/clerk/clerk-sdk-go/v2"
-	clerkhttp "github.com/clerk/clerk-sdk-go/v2/http"
 	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
 	"github.com/zjpiazza/plantastic/cmd/web/handlers"
+	"github.com/zjpiazza/plantastic/internal/auth"
+	"github.com/zjpiazza/plantastic/internal/database"
 	"github.com/zjpiazza/plantastic/internal/device"
-	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
@@ -22,19 +22,33 @@ func main() {
 		log.Fatal("Error loading .env file")
 	}
 
-	// Initialize Clerk client
-	clerkKey := os.Getenv("CLERK_SECRET_KEY")
-	if clerkKey == "" {
-		log.Fatal("CLERK_SECRET_KEY environment variable not set.")
+	// Initialize the auth provider selected by AUTH_PROVIDER (Clerk by default)
+	authenticator, err := auth.NewFromEnv()
+	if err != nil {
+		log.Fatal("Failed to initialize auth provider:", err)
 	}
-	clerk.SetKey(clerkKey)
 
-	// Initialize database connection
-	dsn := os.Getenv("DATABASE_URL")
-	if dsn == "" {
-		dsn = "host=localhost user=postgres password=postgres dbname=plantastic port=5432 sslmode=disable TimeZone=UTC"
+	// The local provider has no hosted sign-in. Its stand-in, /dev/sign-in,
+	// gives anyone who can reach this server a token for any configured user,
+	// so it has to be asked for by name and never runs unnoticed.
+	devSignIn := false
+	if v := os.Getenv("PLANTASTIC_DEV_SIGN_IN"); v != "" {
+		if devSignIn, err = strconv.ParseBool(v); err != nil {
+			log.Fatal("Invalid PLANTASTIC_DEV_SIGN_IN:", v)
+		}
+	}
+	if _, ok := authenticator.(*auth.LocalAuthenticator); ok {
+		if !devSignIn {
+			log.Fatal("AUTH_PROVIDER=local signs users in without a password; set PLANTASTIC_DEV_SIGN_IN=true to use it in development")
+		}
+		log.Println("Warning: development sign-in is enabled; anyone who can reach this server can sign in as any local user at /dev/sign-in")
+	} else {
+		devSignIn = false
 	}
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+
+	// Initialize database connection; share the API's DATABASE_DRIVER and
+	// DATABASE_URL (a SQLite file works, an in-memory database does not)
+	db, err := database.Open(database.ConfigFromEnv(), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Warn), // Or logger.Silent
 	})
 	if err != nil {
@@ -49,7 +63,7 @@ func main() {
 	templates := template.Must(template.ParseGlob("cmd/web/templates/*.html"))
 
 	// Initialize handlers
-	deviceHandler := handlers.NewDeviceHandler(deviceManager, templates)
+	deviceHandler := handlers.NewDeviceHandler(deviceManager, templates, authenticator)
 
 	// Set up router
 	r := mux.NewRouter()
@@ -59,14 +73,16 @@ func main() {
 
 	// Public routes
 	r.HandleFunc("/", deviceHandler.HandleHome)
+	if devSignIn {
+		r.HandleFunc("/dev/sign-in", deviceHandler.HandleDevSignIn)
+	}
 
-	// Protected routes (require Clerk authentication)
+	// Protected routes (attach the authenticated principal, if any)
 	protected := r.NewRoute().Subrouter()
-	protected.Use(func(next http.Handler) http.Handler {
-		return clerkhttp.WithHeaderAuthorization()(next)
-	})
+	protected.Use(auth.Middleware(authenticator))
 	protected.HandleFunc("/link", deviceHandler.HandleDeviceLink)
 	protected.HandleFunc("/link/{code}", deviceHandler.HandleDeviceLinkCode)
+	protected.HandleFunc("/devices", deviceHandler.HandleDevices)
 
 	// Start server
 	port := os.Getenv("PORT")
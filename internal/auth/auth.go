@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrMissingToken is returned when a request carries no bearer token.
+	ErrMissingToken = errors.New("missing bearer token")
+	// ErrInvalidToken is returned when a token fails verification or has expired.
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrUnknownUser is returned when a token is valid but its subject is not a known user.
+	ErrUnknownUser = errors.New("unknown user")
+)
+
+// Principal identifies the authenticated caller of a request.
+type Principal struct {
+	UserID    string // Stable user identifier (the token subject); used to scope data
+	SessionID string // Provider session identifier, if the provider has one
+	Email     string
+	Name      string
+	Provider  string // Name of the Authenticator that verified the caller
+}
+
+// Authenticator verifies bearer tokens and resolves them to a Principal.
+// Implementations must be safe for concurrent use.
+type Authenticator interface {
+	// Name returns the provider name, e.g. "clerk" or "local".
+	Name() string
+	// Authenticate verifies token and returns the caller it belongs to.
+	Authenticate(ctx context.Context, token string) (*Principal, error)
+}
+
+type principalContextKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying p.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, p)
+}
+
+// PrincipalFromContext returns the Principal stored in ctx, if any.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalContextKey{}).(*Principal)
+	return p, ok && p != nil
+}
+
+// BearerToken extracts the token from an "Authorization: Bearer <token>" header.
+// It returns an empty string if the header is missing or malformed.
+func BearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// Middleware attaches the Principal for a valid bearer token to the request
+// context. Like Clerk's WithHeaderAuthorization, it never rejects a request
+// on its own; handlers decide whether an anonymous caller is acceptable.
+func Middleware(authenticator Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if token := BearerToken(r); token != "" {
+				if p, err := authenticator.Authenticate(r.Context(), token); err == nil {
+					r = r.WithContext(WithPrincipal(r.Context(), p))
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Chain is an Authenticator that tries each of its members in order, so the
+// API can accept several kinds of token (e.g. device tokens and Clerk sessions).
+type Chain []Authenticator
+
+// NewChain returns a Chain of the given authenticators.
+func NewChain(authenticators ...Authenticator) Chain {
+	return Chain(authenticators)
+}
+
+// Name implements Authenticator.
+func (c Chain) Name() string {
+	names := make([]string, len(c))
+	for i, a := range c {
+		names[i] = a.Name()
+	}
+	return strings.Join(names, "+")
+}
+
+// Authenticate implements Authenticator. It returns the first successful
+// result, or the last error if every member rejects the token.
+func (c Chain) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	if token == "" {
+		return nil, ErrMissingToken
+	}
+	err := ErrInvalidToken
+	for _, a := range c {
+		var p *Principal
+		if p, err = a.Authenticate(ctx, token); err == nil {
+			return p, nil
+		}
+	}
+	return nil, err
+}
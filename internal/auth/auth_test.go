@@ -0,0 +1,36 @@
+package auth_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zjpiazza/plantastic/internal/auth"
+)
+
+func TestChain_FirstSuccessWins(t *testing.T) {
+	first := newHS256Authenticator(t, strings.Repeat("a", 32))
+	second := newHS256Authenticator(t, strings.Repeat("b", 32))
+	chain := auth.NewChain(first, second)
+
+	// Signed by the second member only; the first rejects it and the chain falls through.
+	token, _, err := second.Issue("user_bob")
+	require.NoError(t, err)
+
+	principal, err := chain.Authenticate(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, "user_bob", principal.UserID)
+	assert.Equal(t, "local+local", chain.Name())
+}
+
+func TestChain_AllReject(t *testing.T) {
+	chain := auth.NewChain(newHS256Authenticator(t, strings.Repeat("a", 32)))
+
+	_, err := chain.Authenticate(context.Background(), "not-a-token")
+	assert.ErrorIs(t, err, auth.ErrInvalidToken)
+
+	_, err = chain.Authenticate(context.Background(), "")
+	assert.ErrorIs(t, err, auth.ErrMissingToken)
+}
@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/clerk/clerk-sdk-go/v2"
+	"github.com/clerk/clerk-sdk-go/v2/jwt"
+)
+
+// ClerkAuthenticator verifies Clerk session JWTs.
+type ClerkAuthenticator struct{}
+
+// NewClerkAuthenticator sets the global Clerk secret key and returns an
+// Authenticator backed by Clerk.
+func NewClerkAuthenticator(secretKey string) (*ClerkAuthenticator, error) {
+	if secretKey == "" {
+		return nil, fmt.Errorf("clerk secret key cannot be empty")
+	}
+	clerk.SetKey(secretKey)
+	return &ClerkAuthenticator{}, nil
+}
+
+// Name implements Authenticator.
+func (a *ClerkAuthenticator) Name() string { return ProviderClerk }
+
+// Authenticate implements Authenticator by verifying token against Clerk's JWKS.
+func (a *ClerkAuthenticator) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	if token == "" {
+		return nil, ErrMissingToken
+	}
+	claims, err := jwt.Verify(ctx, &jwt.VerifyParams{Token: token})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: token has no subject", ErrInvalidToken)
+	}
+	return &Principal{
+		UserID:    claims.Subject,
+		SessionID: claims.SessionID,
+		Provider:  ProviderClerk,
+	}, nil
+}
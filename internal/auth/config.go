@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// ProviderClerk selects ClerkAuthenticator.
+	ProviderClerk = "clerk"
+	// ProviderLocal selects LocalAuthenticator.
+	ProviderLocal = "local"
+)
+
+// NewFromEnv builds the Authenticator selected by AUTH_PROVIDER (default "clerk").
+//
+// The clerk provider reads CLERK_SECRET_KEY. The local provider reads:
+//
+//	LOCAL_AUTH_ALG          HS256 (default) or EdDSA
+//	LOCAL_AUTH_SIGNING_KEY  HS256 secret, or base64 Ed25519 seed/private key for EdDSA
+//	LOCAL_AUTH_USERS        comma-separated id[:email[:name]] entries
+//	LOCAL_AUTH_TOKEN_TTL    token lifetime as a Go duration (default 24h)
+func NewFromEnv() (Authenticator, error) {
+	provider := strings.ToLower(os.Getenv("AUTH_PROVIDER"))
+	switch provider {
+	case "", ProviderClerk:
+		key := os.Getenv("CLERK_SECRET_KEY")
+		if key == "" {
+			return nil, fmt.Errorf("CLERK_SECRET_KEY environment variable not set (or set AUTH_PROVIDER=local)")
+		}
+		return NewClerkAuthenticator(key)
+	case ProviderLocal:
+		cfg, err := localConfigFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		return NewLocalAuthenticator(cfg)
+	default:
+		return nil, fmt.Errorf("unknown AUTH_PROVIDER %q", provider)
+	}
+}
+
+func localConfigFromEnv() (LocalConfig, error) {
+	cfg := LocalConfig{Algorithm: strings.ToUpper(os.Getenv("LOCAL_AUTH_ALG"))}
+	if cfg.Algorithm == strings.ToUpper(AlgEdDSA) {
+		cfg.Algorithm = AlgEdDSA
+	}
+
+	key := os.Getenv("LOCAL_AUTH_SIGNING_KEY")
+	if key == "" {
+		return cfg, fmt.Errorf("LOCAL_AUTH_SIGNING_KEY environment variable not set")
+	}
+	if cfg.Algorithm == AlgEdDSA {
+		raw, err := base64.StdEncoding.DecodeString(key)
+		if err != nil {
+			return cfg, fmt.Errorf("LOCAL_AUTH_SIGNING_KEY is not valid base64: %w", err)
+		}
+		switch len(raw) {
+		case ed25519.SeedSize:
+			cfg.PrivateKey = ed25519.NewKeyFromSeed(raw)
+		case ed25519.PrivateKeySize:
+			cfg.PrivateKey = ed25519.PrivateKey(raw)
+		default:
+			return cfg, fmt.Errorf("LOCAL_AUTH_SIGNING_KEY must decode to a %d-byte seed or %d-byte private key", ed25519.SeedSize, ed25519.PrivateKeySize)
+		}
+	} else {
+		cfg.Secret = []byte(key)
+	}
+
+	users, err := ParseUsers(os.Getenv("LOCAL_AUTH_USERS"))
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Users = users
+
+	if ttl := os.Getenv("LOCAL_AUTH_TOKEN_TTL"); ttl != "" {
+		d, err := time.ParseDuration(ttl)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid LOCAL_AUTH_TOKEN_TTL: %w", err)
+		}
+		cfg.TokenTTL = d
+	}
+	return cfg, nil
+}
+
+// ParseUsers parses a comma-separated list of id[:email[:name]] entries,
+// e.g. "user_alice:alice@example.com:Alice,user_bob".
+func ParseUsers(spec string) ([]User, error) {
+	var users []User
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		fields := strings.SplitN(entry, ":", 3)
+		u := User{ID: strings.TrimSpace(fields[0])}
+		if u.ID == "" {
+			return nil, fmt.Errorf("invalid user entry %q: empty id", entry)
+		}
+		if len(fields) > 1 {
+			u.Email = strings.TrimSpace(fields[1])
+		}
+		if len(fields) > 2 {
+			u.Name = strings.TrimSpace(fields[2])
+		}
+		users = append(users, u)
+	}
+	if len(users) == 0 {
+		return nil, fmt.Errorf("LOCAL_AUTH_USERS must list at least one user")
+	}
+	return users, nil
+}
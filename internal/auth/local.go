@@ -0,0 +1,241 @@
+package auth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// AlgHS256 signs local tokens with HMAC-SHA256 and a shared secret.
+	AlgHS256 = "HS256"
+	// AlgEdDSA signs local tokens with an Ed25519 private key.
+	AlgEdDSA = "EdDSA"
+
+	// DefaultLocalIssuer is the "iss" claim of tokens minted by LocalAuthenticator.
+	DefaultLocalIssuer = "plantastic-local"
+	// DefaultLocalTokenTTL is how long a locally issued token stays valid.
+	DefaultLocalTokenTTL = 24 * time.Hour
+)
+
+// User is an entry in the local provider's static user list.
+type User struct {
+	ID    string `json:"id"`
+	Email string `json:"email,omitempty"`
+	Name  string `json:"name,omitempty"`
+}
+
+// LocalConfig configures a LocalAuthenticator.
+type LocalConfig struct {
+	Algorithm  string             // AlgHS256 or AlgEdDSA
+	Secret     []byte             // HS256 shared secret
+	PrivateKey ed25519.PrivateKey // EdDSA signing key
+	Users      []User             // Users that may sign in; tokens for anyone else are rejected
+	Issuer     string             // Defaults to DefaultLocalIssuer
+	TokenTTL   time.Duration      // Defaults to DefaultLocalTokenTTL
+}
+
+// LocalAuthenticator issues and verifies self-signed JWTs for a static list
+// of users. It needs no network access and is intended for development and
+// integration tests.
+type LocalAuthenticator struct {
+	alg        string
+	secret     []byte
+	privateKey ed25519.PrivateKey
+	publicKey  ed25519.PublicKey
+	issuer     string
+	ttl        time.Duration
+	users      map[string]User
+	now        func() time.Time
+}
+
+type localHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+}
+
+type localClaims struct {
+	Subject   string `json:"sub"`
+	Issuer    string `json:"iss"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+	Email     string `json:"email,omitempty"`
+	Name      string `json:"name,omitempty"`
+}
+
+// NewLocalAuthenticator validates cfg and returns a LocalAuthenticator.
+func NewLocalAuthenticator(cfg LocalConfig) (*LocalAuthenticator, error) {
+	a := &LocalAuthenticator{
+		alg:    cfg.Algorithm,
+		issuer: cfg.Issuer,
+		ttl:    cfg.TokenTTL,
+		users:  make(map[string]User, len(cfg.Users)),
+		now:    time.Now,
+	}
+	if a.alg == "" {
+		a.alg = AlgHS256
+	}
+	if a.issuer == "" {
+		a.issuer = DefaultLocalIssuer
+	}
+	if a.ttl <= 0 {
+		a.ttl = DefaultLocalTokenTTL
+	}
+
+	switch a.alg {
+	case AlgHS256:
+		if len(cfg.Secret) < 32 {
+			return nil, fmt.Errorf("HS256 secret must be at least 32 bytes")
+		}
+		a.secret = cfg.Secret
+	case AlgEdDSA:
+		if len(cfg.PrivateKey) != ed25519.PrivateKeySize {
+			return nil, fmt.Errorf("EdDSA private key must be %d bytes", ed25519.PrivateKeySize)
+		}
+		a.privateKey = cfg.PrivateKey
+		a.publicKey = cfg.PrivateKey.Public().(ed25519.PublicKey)
+	default:
+		return nil, fmt.Errorf("unsupported local auth algorithm %q", a.alg)
+	}
+
+	if len(cfg.Users) == 0 {
+		return nil, fmt.Errorf("local auth requires at least one user")
+	}
+	for _, u := range cfg.Users {
+		if u.ID == "" {
+			return nil, fmt.Errorf("local auth user has an empty ID")
+		}
+		a.users[u.ID] = u
+	}
+	return a, nil
+}
+
+// Name implements Authenticator.
+func (a *LocalAuthenticator) Name() string { return ProviderLocal }
+
+// Users returns the static user list sorted by ID.
+func (a *LocalAuthenticator) Users() []User {
+	users := make([]User, 0, len(a.users))
+	for _, u := range a.users {
+		users = append(users, u)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users
+}
+
+// Issue mints a signed token for userID, which must be in the user list.
+func (a *LocalAuthenticator) Issue(userID string) (string, time.Time, error) {
+	user, ok := a.users[userID]
+	if !ok {
+		return "", time.Time{}, ErrUnknownUser
+	}
+	now := a.now()
+	expiresAt := now.Add(a.ttl)
+
+	header, err := json.Marshal(localHeader{Alg: a.alg, Typ: "JWT"})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	claims, err := json.Marshal(localClaims{
+		Subject:   user.ID,
+		Issuer:    a.issuer,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expiresAt.Unix(),
+		Email:     user.Email,
+		Name:      user.Name,
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	signingInput := b64(header) + "." + b64(claims)
+	return signingInput + "." + b64(a.sign([]byte(signingInput))), expiresAt, nil
+}
+
+// Authenticate implements Authenticator.
+func (a *LocalAuthenticator) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	if token == "" {
+		return nil, ErrMissingToken
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+
+	var header localHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+	// Only accept the configured algorithm so a token can't pick its own verifier.
+	if header.Alg != a.alg {
+		return nil, fmt.Errorf("%w: unexpected alg %q", ErrInvalidToken, header.Alg)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidToken)
+	}
+	if !a.verify([]byte(parts[0]+"."+parts[1]), signature) {
+		return nil, fmt.Errorf("%w: bad signature", ErrInvalidToken)
+	}
+
+	var claims localClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	if claims.Issuer != a.issuer {
+		return nil, fmt.Errorf("%w: unexpected issuer", ErrInvalidToken)
+	}
+	if a.now().Unix() >= claims.ExpiresAt {
+		return nil, fmt.Errorf("%w: token expired", ErrInvalidToken)
+	}
+	user, ok := a.users[claims.Subject]
+	if !ok {
+		return nil, ErrUnknownUser
+	}
+
+	return &Principal{
+		UserID:   user.ID,
+		Email:    user.Email,
+		Name:     user.Name,
+		Provider: ProviderLocal,
+	}, nil
+}
+
+func (a *LocalAuthenticator) sign(input []byte) []byte {
+	if a.alg == AlgEdDSA {
+		return ed25519.Sign(a.privateKey, input)
+	}
+	mac := hmac.New(sha256.New, a.secret)
+	mac.Write(input)
+	return mac.Sum(nil)
+}
+
+func (a *LocalAuthenticator) verify(input, signature []byte) bool {
+	if a.alg == AlgEdDSA {
+		return ed25519.Verify(a.publicKey, input, signature)
+	}
+	return hmac.Equal(a.sign(input), signature)
+}
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return fmt.Errorf("%w: malformed segment", ErrInvalidToken)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%w: malformed segment", ErrInvalidToken)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zjpiazza/plantastic/internal/auth"
+)
+
+var testUsers = []auth.User{
+	{ID: "user_alice", Email: "alice@example.com", Name: "Alice"},
+	{ID: "user_bob"},
+}
+
+func newHS256Authenticator(t *testing.T, secret string) *auth.LocalAuthenticator {
+	a, err := auth.NewLocalAuthenticator(auth.LocalConfig{
+		Algorithm: auth.AlgHS256,
+		Secret:    []byte(secret),
+		Users:     testUsers,
+	})
+	require.NoError(t, err)
+	return a
+}
+
+func TestLocalAuthenticator_HS256_RoundTrip(t *testing.T) {
+	a := newHS256Authenticator(t, strings.Repeat("s", 32))
+
+	token, _, err := a.Issue("user_alice")
+	require.NoError(t, err)
+
+	principal, err := a.Authenticate(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, "user_alice", principal.UserID)
+	assert.Equal(t, "alice@example.com", principal.Email)
+	assert.Equal(t, auth.ProviderLocal, principal.Provider)
+}
+
+func TestLocalAuthenticator_EdDSA_RoundTrip(t *testing.T) {
+	seed := make([]byte, ed25519.SeedSize)
+	a, err := auth.NewLocalAuthenticator(auth.LocalConfig{
+		Algorithm:  auth.AlgEdDSA,
+		PrivateKey: ed25519.NewKeyFromSeed(seed),
+		Users:      testUsers,
+	})
+	require.NoError(t, err)
+
+	token, _, err := a.Issue("user_bob")
+	require.NoError(t, err)
+
+	principal, err := a.Authenticate(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, "user_bob", principal.UserID)
+}
+
+func TestLocalAuthenticator_RejectsForeignSignature(t *testing.T) {
+	issuer := newHS256Authenticator(t, strings.Repeat("a", 32))
+	verifier := newHS256Authenticator(t, strings.Repeat("b", 32))
+
+	token, _, err := issuer.Issue("user_alice")
+	require.NoError(t, err)
+
+	_, err = verifier.Authenticate(context.Background(), token)
+	assert.ErrorIs(t, err, auth.ErrInvalidToken)
+}
+
+func TestLocalAuthenticator_RejectsTamperedClaims(t *testing.T) {
+	a := newHS256Authenticator(t, strings.Repeat("s", 32))
+	alice, _, err := a.Issue("user_alice")
+	require.NoError(t, err)
+	bob, _, err := a.Issue("user_bob")
+	require.NoError(t, err)
+
+	// Bob's claims with Alice's signature
+	aliceParts := strings.Split(alice, ".")
+	bobParts := strings.Split(bob, ".")
+	forged := aliceParts[0] + "." + bobParts[1] + "." + aliceParts[2]
+
+	_, err = a.Authenticate(context.Background(), forged)
+	assert.ErrorIs(t, err, auth.ErrInvalidToken)
+}
+
+func TestLocalAuthenticator_UnknownUser(t *testing.T) {
+	a := newHS256Authenticator(t, strings.Repeat("s", 32))
+
+	_, _, err := a.Issue("user_mallory")
+	assert.ErrorIs(t, err, auth.ErrUnknownUser)
+}
+
+func TestNewLocalAuthenticator_ShortSecret(t *testing.T) {
+	_, err := auth.NewLocalAuthenticator(auth.LocalConfig{
+		Algorithm: auth.AlgHS256,
+		Secret:    []byte("too-short"),
+		Users:     testUsers,
+	})
+	assert.Error(t, err)
+}
+
+func TestParseUsers(t *testing.T) {
+	users, err := auth.ParseUsers("user_alice:alice@example.com:Alice Smith, user_bob")
+	require.NoError(t, err)
+	assert.Equal(t, []auth.User{
+		{ID: "user_alice", Email: "alice@example.com", Name: "Alice Smith"},
+		{ID: "user_bob"},
+	}, users)
+
+	_, err = auth.ParseUsers(" , ")
+	assert.Error(t, err)
+}
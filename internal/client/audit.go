@@ -0,0 +1,24 @@
+package client
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/zjpiazza/plantastic/internal/models"
+)
+
+// ListAudit fetches a page of the audit log, newest first. Filters: entity,
+// entity_id, actor, actor_type, action, created_from, created_to.
+func (c *Client) ListAudit(ctx context.Context, opts ListOptions) (Page[models.AuditEntry], error) {
+	return list[models.AuditEntry](ctx, c, "/audit", opts)
+}
+
+// History fetches the latest limit changes to one entity, such as a garden,
+// newest first.
+func (c *Client) History(ctx context.Context, entity, id string, limit int) ([]models.AuditEntry, error) {
+	page, err := c.ListAudit(ctx, ListOptions{
+		Limit:   limit,
+		Filters: url.Values{"entity": {entity}, "entity_id": {id}},
+	})
+	return page.Items, err
+}
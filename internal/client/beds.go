@@ -0,0 +1,52 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/zjpiazza/plantastic/internal/models"
+)
+
+// ListBeds fetches a page of beds. Filters: garden_id, type, soil_type.
+func (c *Client) ListBeds(ctx context.Context, opts ListOptions) (Page[models.Bed], error) {
+	return list[models.Bed](ctx, c, "/beds", opts)
+}
+
+// ListGardenBeds fetches a page of the beds in a garden.
+func (c *Client) ListGardenBeds(ctx context.Context, gardenID string, opts ListOptions) (Page[models.Bed], error) {
+	return list[models.Bed](ctx, c, resourcePath("gardens", gardenID, "beds"), opts)
+}
+
+// GetBed fetches one bed.
+func (c *Client) GetBed(ctx context.Context, id string) (models.Bed, error) {
+	var bed models.Bed
+	_, err := c.do(ctx, call{method: http.MethodGet, path: resourcePath("beds", id)}, &bed)
+	return bed, err
+}
+
+// CreateBed creates a bed in bed.GardenID and returns it as stored.
+func (c *Client) CreateBed(ctx context.Context, bed models.Bed) (models.Bed, error) {
+	var created models.Bed
+	_, err := c.do(ctx, call{method: http.MethodPost, path: "/beds", body: bed}, &created)
+	return created, err
+}
+
+// UpdateBed replaces a bed. A non-zero ifMatch makes the update fail with
+// ErrVersionConflict if the bed is no longer at that version.
+func (c *Client) UpdateBed(ctx context.Context, bed models.Bed, ifMatch int) error {
+	_, err := c.do(ctx, call{method: http.MethodPut, path: resourcePath("beds", bed.ID), body: bed, ifMatch: ifMatch}, nil)
+	return err
+}
+
+// PatchBed changes the fields in patch and returns the updated bed.
+func (c *Client) PatchBed(ctx context.Context, id string, patch Patch, ifMatch int) (models.Bed, error) {
+	var bed models.Bed
+	_, err := c.do(ctx, call{method: http.MethodPatch, path: resourcePath("beds", id), body: patch, contentType: mergePatchContentType, ifMatch: ifMatch}, &bed)
+	return bed, err
+}
+
+// DeleteBed deletes a bed.
+func (c *Client) DeleteBed(ctx context.Context, id string, ifMatch int) error {
+	_, err := c.do(ctx, call{method: http.MethodDelete, path: resourcePath("beds", id), ifMatch: ifMatch}, nil)
+	return err
+}
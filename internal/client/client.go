@@ -0,0 +1,240 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zjpiazza/plantastic/internal/scheduler"
+)
+
+// DefaultBaseURL is where the API listens when run locally.
+const DefaultBaseURL = "http://localhost:8000"
+
+// apiVersion prefixes every request path: the client speaks version 1 of the API.
+const apiVersion = "/v1"
+
+const (
+	nextCursorHeader      = "X-Next-Cursor"
+	mergePatchContentType = "application/merge-patch+json"
+	formContentType       = "application/x-www-form-urlencoded"
+)
+
+// Client is a typed client for the Plantastic API. Every method takes a
+// context that bounds the whole call, retries included; failures the API
+// reports are returned as a *Problem (or *OAuthError from the device flow),
+// which can be matched with errors.Is against ErrNotFound and friends.
+type Client struct {
+	BaseURL    string
+	Token      string       // Sent as a bearer token if set
+	HTTPClient *http.Client // Retries through RetryTransport if made by New
+}
+
+// New returns a client for the API at baseURL that authenticates with token,
+// which may be empty for the public endpoints.
+func New(baseURL, token string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Token:   token,
+		HTTPClient: &http.Client{
+			Transport: &RetryTransport{},
+			Timeout:   30 * time.Second,
+		},
+	}
+}
+
+// Patch is a JSON merge patch (RFC 7396): members set to nil are cleared,
+// members left out keep their current value.
+type Patch map[string]interface{}
+
+// ListOptions selects a page of a list endpoint.
+type ListOptions struct {
+	Limit   int        // Server default if zero
+	Cursor  string     // From the previous page's NextCursor
+	Sort    string     // Field to sort by, prefixed with - for descending order
+	Filters url.Values // Filters named after fields, e.g. garden_id
+}
+
+func (o ListOptions) query() url.Values {
+	query := url.Values{}
+	for key, values := range o.Filters {
+		query[key] = values
+	}
+	if o.Limit > 0 {
+		query.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Cursor != "" {
+		query.Set("cursor", o.Cursor)
+	}
+	if o.Sort != "" {
+		query.Set("sort", o.Sort)
+	}
+	return query
+}
+
+// Page is one page of a list. NextCursor is empty on the last page.
+type Page[T any] struct {
+	Items      []T
+	NextCursor string
+}
+
+// All iterates over every item of a list, starting at opts and fetching
+// further pages as the loop reaches them. An error ends the iteration.
+//
+//	for garden, err := range client.All(ctx, c.ListGardens, client.ListOptions{}) {
+func All[T any](ctx context.Context, list func(context.Context, ListOptions) (Page[T], error), opts ListOptions) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for {
+			page, err := list(ctx, opts)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range page.Items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if page.NextCursor == "" {
+				return
+			}
+			opts.Cursor = page.NextCursor
+		}
+	}
+}
+
+// Collect gathers the items of seq, stopping at the first error.
+func Collect[T any](seq iter.Seq2[T, error]) ([]T, error) {
+	var items []T
+	for item, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// Jobs reports the API's background jobs.
+func (c *Client) Jobs(ctx context.Context) ([]scheduler.JobStatus, error) {
+	var jobs []scheduler.JobStatus
+	_, err := c.do(ctx, call{method: http.MethodGet, path: "/maintenance/jobs"}, &jobs)
+	return jobs, err
+}
+
+// call describes one request.
+type call struct {
+	method      string
+	path        string // Already escaped
+	query       url.Values
+	body        interface{} // Encoded as JSON
+	contentType string      // Of body; application/json if empty
+	form        url.Values  // Sent form-encoded instead of body
+	ifMatch     int         // Version the write is conditional on; 0 for none
+}
+
+// do sends r and decodes a successful response's body into out, if out is
+// non-nil. Any other response is returned as an error.
+func (c *Client) do(ctx context.Context, r call, out interface{}) (*http.Response, error) {
+	response, body, err := c.send(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response, ErrorFromResponse(response.StatusCode, body)
+	}
+	if out != nil && len(body) > 0 {
+		if err := json.Unmarshal(body, out); err != nil {
+			return response, fmt.Errorf("decoding %s %s response: %w", r.method, r.path, err)
+		}
+	}
+	return response, nil
+}
+
+// send sends r and reads the response, whatever its status.
+func (c *Client) send(ctx context.Context, r call) (*http.Response, []byte, error) {
+	target := c.BaseURL + apiVersion + r.path
+	if len(r.query) > 0 {
+		target += "?" + r.query.Encode()
+	}
+
+	var body io.Reader
+	contentType := r.contentType
+	switch {
+	case r.form != nil:
+		body = strings.NewReader(r.form.Encode())
+		contentType = formContentType
+	case r.body != nil:
+		data, err := json.Marshal(r.body)
+		if err != nil {
+			return nil, nil, fmt.Errorf("encoding %s %s request: %w", r.method, r.path, err)
+		}
+		body = bytes.NewReader(data)
+		if contentType == "" {
+			contentType = "application/json"
+		}
+	}
+
+	request, err := http.NewRequestWithContext(ctx, r.method, target, body)
+	if err != nil {
+		return nil, nil, err
+	}
+	request.Header.Set("Accept", "application/json, application/problem+json")
+	if contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+	if c.Token != "" {
+		request.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	if r.ifMatch > 0 {
+		request.Header.Set("If-Match", strconv.Quote(strconv.Itoa(r.ifMatch)))
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer response.Body.Close()
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading %s %s response: %w", r.method, r.path, err)
+	}
+	return response, data, nil
+}
+
+// list fetches one page of the list endpoint at path.
+func list[T any](ctx context.Context, c *Client, path string, opts ListOptions) (Page[T], error) {
+	var items []T
+	response, err := c.do(ctx, call{method: http.MethodGet, path: path, query: opts.query()}, &items)
+	if err != nil {
+		return Page[T]{}, err
+	}
+	return Page[T]{Items: items, NextCursor: response.Header.Get(nextCursorHeader)}, nil
+}
+
+// resourcePath joins escaped path segments, e.g. ("gardens", id) gives
+// "/gardens/<id>".
+func resourcePath(segments ...string) string {
+	var b strings.Builder
+	for _, segment := range segments {
+		b.WriteString("/")
+		b.WriteString(url.PathEscape(segment))
+	}
+	return b.String()
+}
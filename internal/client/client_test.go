@@ -0,0 +1,197 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zjpiazza/plantastic/internal/models"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	c := New(server.URL+"/", "secret")
+	c.HTTPClient.Transport = &RetryTransport{Backoff: time.Millisecond}
+	return c
+}
+
+func TestClient_PatchTask(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPatch, r.Method)
+		assert.Equal(t, "/v1/tasks/a%2Fb", r.URL.EscapedPath())
+		assert.Equal(t, "series", r.URL.Query().Get("scope"))
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		assert.Equal(t, mergePatchContentType, r.Header.Get("Content-Type"))
+		assert.Equal(t, `"3"`, r.Header.Get("If-Match"))
+		assert.NotEmpty(t, r.Header.Get(IdempotencyKeyHeader))
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `{"status": "Completed", "garden_bed_id": null}`, string(body))
+		w.Write([]byte(`{"id": "a/b", "status": "Completed", "version": 4}`))
+	})
+
+	task, err := c.PatchTask(context.Background(), "a/b", Patch{"status": models.TaskStatusCompleted, "garden_bed_id": nil}, ScopeSeries, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 4, task.Version)
+	assert.Equal(t, models.TaskStatusCompleted, task.Status)
+}
+
+func TestAll_FollowsCursors(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Backyard", r.URL.Query().Get("name"))
+		assert.Equal(t, "1", r.URL.Query().Get("limit"))
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			w.Header().Set(nextCursorHeader, "c1")
+			w.Write([]byte(`[{"id": "g1"}]`))
+		case "c1":
+			w.Header().Set(nextCursorHeader, "c2")
+			w.Write([]byte(`[{"id": "g2"}]`))
+		default:
+			w.Write([]byte(`[{"id": "g3"}]`))
+		}
+	})
+
+	opts := ListOptions{Limit: 1, Filters: map[string][]string{"name": {"Backyard"}}}
+	page, err := c.ListGardens(context.Background(), opts)
+	require.NoError(t, err)
+	assert.Equal(t, "c1", page.NextCursor)
+
+	gardens, err := Collect(All(context.Background(), c.ListGardens, opts))
+	require.NoError(t, err)
+	require.Len(t, gardens, 3)
+	assert.Equal(t, "g3", gardens[2].ID)
+
+	// Stopping early fetches no further pages
+	for garden, err := range All(context.Background(), c.ListGardens, opts) {
+		require.NoError(t, err)
+		assert.Equal(t, "g1", garden.ID)
+		break
+	}
+}
+
+func TestClient_Errors(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/gardens/stale":
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(http.StatusPreconditionFailed)
+			w.Write([]byte(`{"status": 412, "code": "version_conflict", "detail": "Garden was changed by someone else"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("404 page not found"))
+		}
+	})
+	ctx := context.Background()
+
+	err := c.DeleteGarden(ctx, "stale", 1)
+	assert.ErrorIs(t, err, ErrVersionConflict)
+	var problem *Problem
+	require.ErrorAs(t, err, &problem)
+	assert.Equal(t, "version_conflict", problem.Code)
+
+	_, err = c.GetGarden(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NotErrorIs(t, err, ErrVersionConflict)
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, "404 page not found", statusErr.Body)
+}
+
+func TestPollDeviceToken(t *testing.T) {
+	approved := false
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, DeviceCodeGrantType, r.PostForm.Get("grant_type"))
+		assert.Equal(t, "dc", r.PostForm.Get("device_code"))
+		if !approved {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": "authorization_pending", "error_description": "waiting"}`))
+			return
+		}
+		w.Write([]byte(`{"access_token": "at", "refresh_token": "rt", "token_type": "Bearer"}`))
+	})
+
+	_, err := c.PollDeviceToken(context.Background(), "cli", "dc")
+	assert.ErrorIs(t, err, ErrAuthorizationPending)
+	assert.NotErrorIs(t, err, ErrAccessDenied)
+
+	approved = true
+	pair, err := c.PollDeviceToken(context.Background(), "cli", "dc")
+	require.NoError(t, err)
+	assert.Equal(t, "at", pair.AccessToken)
+}
+
+func TestBulkTasks(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) == `{"mode":"atomic","operations":[]}` {
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status": 400, "code": "validation_failed", "detail": "The request has invalid fields"}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"mode": "atomic", "results": [
+			{"index": 0, "op": "complete", "id": "t1", "status": 424, "error": "not applied"},
+			{"index": 1, "op": "complete", "id": "t2", "status": 404, "error": "Task not found"}
+		]}`))
+	})
+	ctx := context.Background()
+
+	result, err := c.BulkTasks(ctx, BulkAtomic, []BulkTaskOp{{Op: "complete", ID: "t1"}, {Op: "complete", ID: "t2"}})
+	require.NoError(t, err)
+	failed := result.Failed()
+	require.Len(t, failed, 1)
+	assert.Equal(t, "t2", failed[0].ID)
+	assert.ErrorIs(t, failed[0].Err(), ErrNotFound)
+
+	_, err = c.BulkTasks(ctx, BulkAtomic, []BulkTaskOp{})
+	assert.ErrorIs(t, err, ErrInvalidRequest)
+}
+
+func TestClient_ContextCanceled(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := c.ListTasks(ctx, ListOptions{})
+	assert.True(t, errors.Is(err, context.Canceled), err)
+}
+
+func TestClient_RestoreTrash(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/v1/trash/garden/g1/restore", r.URL.Path)
+		w.Write([]byte(`{"type": "garden", "id": "g1", "restored_beds": 2, "restored_tasks": 5}`))
+	})
+
+	result, err := c.RestoreTrash(context.Background(), TrashGarden, "g1")
+	require.NoError(t, err)
+	assert.Equal(t, RestoreResult{Type: TrashGarden, ID: "g1", RestoredBeds: 2, RestoredTasks: 5}, result)
+}
+
+func TestClient_History(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/audit", r.URL.Path)
+		assert.Equal(t, "bed", r.URL.Query().Get("entity"))
+		assert.Equal(t, "b1", r.URL.Query().Get("entity_id"))
+		assert.Equal(t, "5", r.URL.Query().Get("limit"))
+		w.Write([]byte(`[{"id": "a1", "actor_type": "device", "actor_id": "d1", "entity": "bed", "entity_id": "b1",
+			"action": "update", "before": {"name": "Bed"}, "after": {"name": "Herb bed"}}]`))
+	})
+
+	entries, err := c.History(context.Background(), "bed", "b1", 5)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, models.ActorDevice, entries[0].ActorType)
+	assert.Equal(t, models.AuditValues{"name": "Herb bed"}, entries[0].After)
+}
@@ -0,0 +1,132 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DeviceCodeGrantType is the grant_type a device polls the token endpoint
+// with (RFC 8628 section 3.4).
+const DeviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+// DeviceAuthorization is the API's answer to a device asking to be linked:
+// the user approves UserCode at VerificationURI while the device polls with
+// DeviceCode every Interval seconds.
+type DeviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// TokenPair holds a linked device's tokens. Use AccessToken as the client's
+// Token, and RefreshToken to get a new pair before RefreshExpiresIn runs out.
+type TokenPair struct {
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int    `json:"expires_in"`
+	RefreshExpiresIn int    `json:"refresh_expires_in"`
+}
+
+// LinkedDevice is a device linked to the caller's account.
+type LinkedDevice struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	DeviceID   string     `json:"device_id"`
+	LinkedAt   *time.Time `json:"linked_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	IPAddress  string     `json:"ip_address"`
+	UserAgent  string     `json:"user_agent"`
+	Current    bool       `json:"current"` // The device making the request
+}
+
+// LocalToken is a token issued by the local auth provider.
+type LocalToken struct {
+	Token     string    `json:"token"`
+	TokenType string    `json:"token_type"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// RequestDeviceCode starts linking a device (RFC 8628 section 3.1). deviceID
+// identifies the device across links and deviceName is shown on the devices
+// page; both may be empty.
+func (c *Client) RequestDeviceCode(ctx context.Context, clientID, deviceID, deviceName string) (DeviceAuthorization, error) {
+	form := url.Values{"client_id": {clientID}}
+	if deviceID != "" {
+		form.Set("device_id", deviceID)
+	}
+	if deviceName != "" {
+		form.Set("device_name", deviceName)
+	}
+	var authorization DeviceAuthorization
+	_, err := c.do(ctx, call{method: http.MethodPost, path: "/device/code", form: form}, &authorization)
+	return authorization, err
+}
+
+// PollDeviceToken asks whether the user has approved deviceCode yet. Until
+// they do it fails with ErrAuthorizationPending or ErrSlowDown; ErrExpiredToken
+// and ErrAccessDenied end the flow.
+func (c *Client) PollDeviceToken(ctx context.Context, clientID, deviceCode string) (TokenPair, error) {
+	form := url.Values{
+		"grant_type":  {DeviceCodeGrantType},
+		"device_code": {deviceCode},
+		"client_id":   {clientID},
+	}
+	var pair TokenPair
+	_, err := c.do(ctx, call{method: http.MethodPost, path: "/device/token", form: form}, &pair)
+	return pair, err
+}
+
+// RefreshDeviceToken exchanges a device's refresh token for a new pair.
+func (c *Client) RefreshDeviceToken(ctx context.Context, refreshToken string) (TokenPair, error) {
+	var pair TokenPair
+	_, err := c.do(ctx, call{method: http.MethodPost, path: "/device/token/refresh", body: map[string]string{"refresh_token": refreshToken}}, &pair)
+	return pair, err
+}
+
+// LinkDevice approves a device's link request on behalf of the user that
+// sessionToken belongs to.
+func (c *Client) LinkDevice(ctx context.Context, userCode, sessionToken string) error {
+	body := map[string]string{"user_code": userCode, "clerk_session_token": sessionToken}
+	_, err := c.do(ctx, call{method: http.MethodPost, path: "/device/link", body: body}, nil)
+	return err
+}
+
+// DenyDevice rejects a device's link request.
+func (c *Client) DenyDevice(ctx context.Context, userCode string) error {
+	_, err := c.do(ctx, call{method: http.MethodPost, path: "/device/deny", body: map[string]string{"user_code": userCode}}, nil)
+	return err
+}
+
+// ListDevices fetches the devices linked to the caller's account.
+func (c *Client) ListDevices(ctx context.Context) ([]LinkedDevice, error) {
+	var devices []LinkedDevice
+	_, err := c.do(ctx, call{method: http.MethodGet, path: "/devices"}, &devices)
+	return devices, err
+}
+
+// RenameDevice changes the name a linked device is shown with.
+func (c *Client) RenameDevice(ctx context.Context, id, name string) (LinkedDevice, error) {
+	var device LinkedDevice
+	_, err := c.do(ctx, call{method: http.MethodPatch, path: resourcePath("devices", id), body: map[string]string{"name": name}}, &device)
+	return device, err
+}
+
+// RevokeDevice unlinks a device; its tokens stop working.
+func (c *Client) RevokeDevice(ctx context.Context, id string) error {
+	_, err := c.do(ctx, call{method: http.MethodDelete, path: resourcePath("devices", id)}, nil)
+	return err
+}
+
+// IssueLocalToken gets a token for one of the API's local users. It only
+// works when the API runs with the local auth provider.
+func (c *Client) IssueLocalToken(ctx context.Context, userID string) (LocalToken, error) {
+	var token LocalToken
+	_, err := c.do(ctx, call{method: http.MethodPost, path: "/auth/local/token", body: map[string]string{"user_id": userID}}, &token)
+	return token, err
+}
@@ -0,0 +1,48 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/zjpiazza/plantastic/internal/models"
+)
+
+// ListGardens fetches a page of gardens. Filters: name, created_from,
+// created_to.
+func (c *Client) ListGardens(ctx context.Context, opts ListOptions) (Page[models.Garden], error) {
+	return list[models.Garden](ctx, c, "/gardens", opts)
+}
+
+// GetGarden fetches one garden.
+func (c *Client) GetGarden(ctx context.Context, id string) (models.Garden, error) {
+	var garden models.Garden
+	_, err := c.do(ctx, call{method: http.MethodGet, path: resourcePath("gardens", id)}, &garden)
+	return garden, err
+}
+
+// CreateGarden creates a garden and returns it as stored.
+func (c *Client) CreateGarden(ctx context.Context, garden models.Garden) (models.Garden, error) {
+	var created models.Garden
+	_, err := c.do(ctx, call{method: http.MethodPost, path: "/gardens", body: garden}, &created)
+	return created, err
+}
+
+// UpdateGarden replaces a garden. A non-zero ifMatch makes the update fail
+// with ErrVersionConflict if the garden is no longer at that version.
+func (c *Client) UpdateGarden(ctx context.Context, garden models.Garden, ifMatch int) error {
+	_, err := c.do(ctx, call{method: http.MethodPut, path: resourcePath("gardens", garden.ID), body: garden, ifMatch: ifMatch}, nil)
+	return err
+}
+
+// PatchGarden changes the fields in patch and returns the updated garden.
+func (c *Client) PatchGarden(ctx context.Context, id string, patch Patch, ifMatch int) (models.Garden, error) {
+	var garden models.Garden
+	_, err := c.do(ctx, call{method: http.MethodPatch, path: resourcePath("gardens", id), body: patch, contentType: mergePatchContentType, ifMatch: ifMatch}, &garden)
+	return garden, err
+}
+
+// DeleteGarden deletes a garden.
+func (c *Client) DeleteGarden(ctx context.Context, id string, ifMatch int) error {
+	_, err := c.do(ctx, call{method: http.MethodDelete, path: resourcePath("gardens", id), ifMatch: ifMatch}, nil)
+	return err
+}
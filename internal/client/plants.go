@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/zjpiazza/plantastic/internal/models"
+)
+
+// ListPlants fetches a page of the plant catalog. Filters: name, sun.
+func (c *Client) ListPlants(ctx context.Context, opts ListOptions) (Page[models.Plant], error) {
+	return list[models.Plant](ctx, c, "/plants", opts)
+}
+
+// GetPlant fetches one plant.
+func (c *Client) GetPlant(ctx context.Context, id string) (models.Plant, error) {
+	var plant models.Plant
+	_, err := c.do(ctx, call{method: http.MethodGet, path: resourcePath("plants", id)}, &plant)
+	return plant, err
+}
+
+// CreatePlant adds a plant to the catalog and returns it as stored.
+func (c *Client) CreatePlant(ctx context.Context, plant models.Plant) (models.Plant, error) {
+	var created models.Plant
+	_, err := c.do(ctx, call{method: http.MethodPost, path: "/plants", body: plant}, &created)
+	return created, err
+}
+
+// UpdatePlant replaces a plant.
+func (c *Client) UpdatePlant(ctx context.Context, plant models.Plant) error {
+	_, err := c.do(ctx, call{method: http.MethodPut, path: resourcePath("plants", plant.ID), body: plant}, nil)
+	return err
+}
+
+// DeletePlant removes a plant from the catalog. It fails with ErrConflict
+// while the plant is still planted somewhere.
+func (c *Client) DeletePlant(ctx context.Context, id string) error {
+	_, err := c.do(ctx, call{method: http.MethodDelete, path: resourcePath("plants", id)}, nil)
+	return err
+}
+
+// ListPlantings fetches a page of plantings. Filters: garden_id, bed_id,
+// plant_id, status.
+func (c *Client) ListPlantings(ctx context.Context, opts ListOptions) (Page[models.Planting], error) {
+	return list[models.Planting](ctx, c, "/plantings", opts)
+}
+
+// GetPlanting fetches one planting.
+func (c *Client) GetPlanting(ctx context.Context, id string) (models.Planting, error) {
+	var planting models.Planting
+	_, err := c.do(ctx, call{method: http.MethodGet, path: resourcePath("plantings", id)}, &planting)
+	return planting, err
+}
+
+// CreatePlanting plants something in a bed and returns the planting as
+// stored.
+func (c *Client) CreatePlanting(ctx context.Context, planting models.Planting) (models.Planting, error) {
+	var created models.Planting
+	_, err := c.do(ctx, call{method: http.MethodPost, path: "/plantings", body: planting}, &created)
+	return created, err
+}
+
+// UpdatePlanting replaces a planting.
+func (c *Client) UpdatePlanting(ctx context.Context, planting models.Planting) error {
+	_, err := c.do(ctx, call{method: http.MethodPut, path: resourcePath("plantings", planting.ID), body: planting}, nil)
+	return err
+}
+
+// DeletePlanting deletes a planting.
+func (c *Client) DeletePlanting(ctx context.Context, id string) error {
+	_, err := c.do(ctx, call{method: http.MethodDelete, path: resourcePath("plantings", id)}, nil)
+	return err
+}
@@ -0,0 +1,146 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Errors to match API failures against with errors.Is. A *Problem or
+// *StatusError matches the one for its status.
+var (
+	ErrInvalidRequest  = errors.New("invalid request")  // 400
+	ErrUnauthorized    = errors.New("unauthorized")     // 401
+	ErrNotFound        = errors.New("not found")        // 404
+	ErrConflict        = errors.New("conflict")         // 409
+	ErrVersionConflict = errors.New("version conflict") // 412: the resource changed since the version given
+	ErrTimeout         = errors.New("timeout")          // 504: the API's database did not answer in time
+)
+
+var statusErrors = map[int]error{
+	http.StatusBadRequest:         ErrInvalidRequest,
+	http.StatusUnauthorized:       ErrUnauthorized,
+	http.StatusNotFound:           ErrNotFound,
+	http.StatusConflict:           ErrConflict,
+	http.StatusPreconditionFailed: ErrVersionConflict,
+	http.StatusGatewayTimeout:     ErrTimeout,
+}
+
+// Problem is an error response from the API, an RFC 7807 problem details
+// object. Code is stable and safe to branch on; Detail and Errors are meant
+// for people.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail"`
+	Instance  string       `json:"instance"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"request_id"`
+	Errors    []FieldError `json:"errors"`
+}
+
+// FieldError names an input field the API rejected and why.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error describes the problem on one line, e.g. "The request has invalid
+// fields: garden_id: garden does not exist (request 1b4e…)".
+func (p *Problem) Error() string {
+	message := p.Detail
+	if message == "" {
+		message = p.Title
+	}
+	if len(p.Errors) > 0 {
+		fields := make([]string, len(p.Errors))
+		for i, f := range p.Errors {
+			fields[i] = f.Field + ": " + f.Message
+		}
+		message += ": " + strings.Join(fields, "; ")
+	}
+	if p.RequestID != "" {
+		message += " (request " + p.RequestID + ")"
+	}
+	return message
+}
+
+// Is reports whether target is the Err variable for the problem's status.
+func (p *Problem) Is(target error) bool {
+	return statusErrors[p.Status] == target
+}
+
+// StatusError is an unsuccessful response whose body is not a problem, such
+// as one from a proxy in front of the API.
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("server returned status code %d: %s", e.StatusCode, e.Body)
+}
+
+// Is reports whether target is the Err variable for the response's status.
+func (e *StatusError) Is(target error) bool {
+	return statusErrors[e.StatusCode] == target
+}
+
+// OAuthError is an error from the device flow's token endpoints, which answer
+// in the OAuth format (RFC 6749 section 5.2) rather than with problems.
+type OAuthError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+// Device flow errors (RFC 8628 section 3.5) to match with errors.Is.
+var (
+	ErrAuthorizationPending = &OAuthError{Code: "authorization_pending"} // Keep polling
+	ErrSlowDown             = &OAuthError{Code: "slow_down"}             // Keep polling, less often
+	ErrExpiredToken         = &OAuthError{Code: "expired_token"}         // The code expired before tokens were issued
+	ErrAccessDenied         = &OAuthError{Code: "access_denied"}         // The user rejected the request
+)
+
+func (e *OAuthError) Error() string {
+	if e.Description == "" {
+		return e.Code
+	}
+	return e.Code + ": " + e.Description
+}
+
+// Is reports whether target is an *OAuthError with the same code.
+func (e *OAuthError) Is(target error) bool {
+	t, ok := target.(*OAuthError)
+	return ok && t.Code == e.Code
+}
+
+// ErrorFromResponse turns an unsuccessful response into an error: a *Problem
+// or *OAuthError if the body is one, otherwise a *StatusError.
+func ErrorFromResponse(status int, body []byte) error {
+	var problem Problem
+	if err := json.Unmarshal(body, &problem); err == nil && problem.Code != "" {
+		if problem.Status == 0 {
+			problem.Status = status
+		}
+		return &problem
+	}
+	var oauth OAuthError
+	if err := json.Unmarshal(body, &oauth); err == nil && oauth.Code != "" {
+		return &oauth
+	}
+	return &StatusError{StatusCode: status, Body: strings.TrimSpace(string(body))}
+}
+
+// ReadError reads an unsuccessful response's body and returns
+// ErrorFromResponse for it. It does not close the body.
+func ReadError(response *http.Response) error {
+	body, err := io.ReadAll(io.LimitReader(response.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("server returned status code %d", response.StatusCode)
+	}
+	return ErrorFromResponse(response.StatusCode, body)
+}
@@ -0,0 +1,40 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorFromResponse_Problem(t *testing.T) {
+	body := []byte(`{
+		"type": "about:blank",
+		"title": "Bad Request",
+		"status": 400,
+		"detail": "The request has invalid fields",
+		"code": "validation_failed",
+		"request_id": "req-1",
+		"errors": [
+			{"field": "garden_id", "message": "garden does not exist"},
+			{"field": "description", "message": "is required"}
+		]
+	}`)
+
+	err := ErrorFromResponse(http.StatusBadRequest, body)
+
+	var problem *Problem
+	require.True(t, errors.As(err, &problem))
+	assert.Equal(t, "validation_failed", problem.Code)
+	assert.Equal(t, "The request has invalid fields: garden_id: garden does not exist; description: is required (request req-1)", err.Error())
+}
+
+func TestErrorFromResponse_NotAProblem(t *testing.T) {
+	err := ErrorFromResponse(http.StatusBadGateway, []byte("upstream unavailable\n"))
+
+	var problem *Problem
+	assert.False(t, errors.As(err, &problem))
+	assert.Equal(t, "server returned status code 502: upstream unavailable", err.Error())
+}
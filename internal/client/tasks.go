@@ -0,0 +1,182 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/zjpiazza/plantastic/internal/models"
+)
+
+// Scopes of a change to a recurring task.
+const (
+	ScopeOccurrence = "occurrence" // Only this occurrence; the default
+	ScopeSeries     = "series"     // Every open occurrence
+)
+
+// Modes of a bulk request.
+const (
+	BulkAtomic  = "atomic"  // All operations are applied, or none
+	BulkPartial = "partial" // Each operation stands alone
+)
+
+// ListTasks fetches a page of tasks. Filters: garden_id, garden_bed_id,
+// status, priority, series_id, due_from, due_to.
+func (c *Client) ListTasks(ctx context.Context, opts ListOptions) (Page[models.Task], error) {
+	return list[models.Task](ctx, c, "/tasks", opts)
+}
+
+// ListGardenTasks fetches a page of the tasks in a garden.
+func (c *Client) ListGardenTasks(ctx context.Context, gardenID string, opts ListOptions) (Page[models.Task], error) {
+	return list[models.Task](ctx, c, resourcePath("gardens", gardenID, "tasks"), opts)
+}
+
+// ListBedTasks fetches a page of the tasks for a bed.
+func (c *Client) ListBedTasks(ctx context.Context, gardenID, bedID string, opts ListOptions) (Page[models.Task], error) {
+	return list[models.Task](ctx, c, resourcePath("gardens", gardenID, "beds", bedID, "tasks"), opts)
+}
+
+// GetTask fetches one task.
+func (c *Client) GetTask(ctx context.Context, id string) (models.Task, error) {
+	var task models.Task
+	_, err := c.do(ctx, call{method: http.MethodGet, path: resourcePath("tasks", id)}, &task)
+	return task, err
+}
+
+// TaskHistory fetches a task's status changes, oldest first.
+func (c *Client) TaskHistory(ctx context.Context, id string) ([]models.TaskEvent, error) {
+	var events []models.TaskEvent
+	_, err := c.do(ctx, call{method: http.MethodGet, path: resourcePath("tasks", id, "history")}, &events)
+	return events, err
+}
+
+// CreateTask creates a task and returns it as stored; for a recurring task,
+// that is its first occurrence.
+func (c *Client) CreateTask(ctx context.Context, task models.Task) (models.Task, error) {
+	var created models.Task
+	_, err := c.do(ctx, call{method: http.MethodPost, path: "/tasks", body: task}, &created)
+	return created, err
+}
+
+// UpdateTask replaces a task. scope is ScopeOccurrence or ScopeSeries, or
+// empty for the default. A non-zero ifMatch makes the update fail with
+// ErrVersionConflict if the task is no longer at that version.
+func (c *Client) UpdateTask(ctx context.Context, task models.Task, scope string, ifMatch int) error {
+	_, err := c.do(ctx, call{method: http.MethodPut, path: resourcePath("tasks", task.ID), query: scopeQuery(scope), body: task, ifMatch: ifMatch}, nil)
+	return err
+}
+
+// PatchTask changes the fields in patch and returns the updated task.
+// Setting garden_bed_id to nil takes the task out of its bed.
+func (c *Client) PatchTask(ctx context.Context, id string, patch Patch, scope string, ifMatch int) (models.Task, error) {
+	var task models.Task
+	_, err := c.do(ctx, call{method: http.MethodPatch, path: resourcePath("tasks", id), query: scopeQuery(scope), body: patch, contentType: mergePatchContentType, ifMatch: ifMatch}, &task)
+	return task, err
+}
+
+// CompleteTask marks a task as completed, which schedules the next
+// occurrence of a recurring task.
+func (c *Client) CompleteTask(ctx context.Context, id string, ifMatch int) (models.Task, error) {
+	return c.PatchTask(ctx, id, Patch{"status": models.TaskStatusCompleted}, "", ifMatch)
+}
+
+// DeleteTask deletes a task.
+func (c *Client) DeleteTask(ctx context.Context, id string, ifMatch int) error {
+	_, err := c.do(ctx, call{method: http.MethodDelete, path: resourcePath("tasks", id), ifMatch: ifMatch}, nil)
+	return err
+}
+
+func scopeQuery(scope string) url.Values {
+	if scope == "" {
+		return nil
+	}
+	return url.Values{"scope": {scope}}
+}
+
+// BulkTaskOp is one operation of a bulk request: create (with Task), update
+// (ID and Task), delete, complete, or reschedule (ID and one of DueDate and
+// ShiftDays). A non-zero Version makes the operation conditional.
+type BulkTaskOp struct {
+	Op        string       `json:"op"`
+	ID        string       `json:"id,omitempty"`
+	Task      *models.Task `json:"task,omitempty"`
+	DueDate   *time.Time   `json:"due_date,omitempty"`
+	ShiftDays int          `json:"shift_days,omitempty"`
+	Version   int          `json:"version,omitempty"`
+}
+
+// BulkTaskResult is the outcome of one operation. Status is the HTTP status
+// the operation would have had on its own; Error is set if it failed.
+type BulkTaskResult struct {
+	Index  int          `json:"index"`
+	Op     string       `json:"op"`
+	ID     string       `json:"id,omitempty"`
+	Status int          `json:"status"`
+	Error  string       `json:"error,omitempty"`
+	Errors []FieldError `json:"errors,omitempty"`
+	Task   *models.Task `json:"task,omitempty"`
+}
+
+// Err returns the operation's failure as a *Problem, or nil if it succeeded.
+func (r BulkTaskResult) Err() error {
+	if r.Error == "" {
+		return nil
+	}
+	return &Problem{Status: r.Status, Detail: r.Error, Errors: r.Errors}
+}
+
+// BulkTasksResult lists the outcome of each operation, in request order.
+type BulkTasksResult struct {
+	Mode    string           `json:"mode"`
+	Results []BulkTaskResult `json:"results"`
+}
+
+// Failed returns the results of the operations that failed. In atomic mode
+// the operations that were rolled back because another failed are left out.
+func (r BulkTasksResult) Failed() []BulkTaskResult {
+	var failed []BulkTaskResult
+	for _, result := range r.Results {
+		if result.Error != "" && result.Status != http.StatusFailedDependency {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// BulkTasks applies ops in mode BulkAtomic or BulkPartial. When the API
+// reports per-operation results the error is nil, even if operations failed:
+// check the result's Failed. An error means the batch as a whole was rejected.
+func (c *Client) BulkTasks(ctx context.Context, mode string, ops []BulkTaskOp) (BulkTasksResult, error) {
+	return c.bulkTasks(ctx, mode, ops)
+}
+
+// BulkTasksRaw is BulkTasks for operations already encoded as JSON, such as
+// ones read from a file.
+func (c *Client) BulkTasksRaw(ctx context.Context, mode string, ops []json.RawMessage) (BulkTasksResult, error) {
+	return c.bulkTasks(ctx, mode, ops)
+}
+
+func (c *Client) bulkTasks(ctx context.Context, mode string, ops interface{}) (BulkTasksResult, error) {
+	var result BulkTasksResult
+	response, body, err := c.send(ctx, call{
+		method: http.MethodPost,
+		path:   "/tasks/bulk",
+		body:   map[string]interface{}{"mode": mode, "operations": ops},
+	})
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(body, &result)
+	switch {
+	case err == nil && len(result.Results) > 0:
+		return result, nil
+	case response.StatusCode < 200 || response.StatusCode > 299:
+		return result, ErrorFromResponse(response.StatusCode, body)
+	case err != nil:
+		return result, fmt.Errorf("decoding bulk response: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,98 @@
+// Package client is the Go client for the Plantastic API, shared by the CLI
+// and TUI.
+package client
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IdempotencyKeyHeader is the request header the API uses to recognise a
+// retried write.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// RetryTransport is an http.RoundTripper that retries requests which fail
+// with a network error or a 502, 503 or 504 response. Writes (POST, PUT, PATCH
+// and DELETE) are given an Idempotency-Key unless they already have one, and
+// every attempt sends the same key, so the API applies a retried write once.
+type RetryTransport struct {
+	Base     http.RoundTripper // http.DefaultTransport if nil
+	Attempts int               // Tries per request, including the first; 3 if zero
+	Backoff  time.Duration     // Wait before the first retry, doubling each time; 200ms if zero
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	attempts := t.Attempts
+	if attempts <= 0 {
+		attempts = 3
+	}
+	backoff := t.Backoff
+	if backoff <= 0 {
+		backoff = 200 * time.Millisecond
+	}
+
+	if isWrite(req.Method) && req.Header.Get(IdempotencyKeyHeader) == "" {
+		req = req.Clone(req.Context()) // A RoundTripper must not modify the caller's request
+		req.Header.Set(IdempotencyKeyHeader, uuid.NewString())
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := base.RoundTrip(req)
+		if attempt == attempts || !shouldRetry(req, resp, err) {
+			return resp, err
+		}
+		if req.Body != nil && req.GetBody == nil {
+			return resp, err // The body cannot be sent again
+		}
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+func isWrite(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
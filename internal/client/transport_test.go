@@ -0,0 +1,79 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryTransport_RetriesWriteWithSameKey(t *testing.T) {
+	var keys, bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		keys = append(keys, r.Header.Get(IdempotencyKeyHeader))
+		bodies = append(bodies, string(body))
+		if len(keys) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &RetryTransport{Backoff: time.Millisecond}}
+	resp, err := client.Post(server.URL+"/gardens", "application/json", strings.NewReader(`{"name":"Backyard"}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	require.Len(t, keys, 3)
+	assert.NotEmpty(t, keys[0])
+	assert.Equal(t, keys[0], keys[1])
+	assert.Equal(t, keys[0], keys[2])
+	assert.Equal(t, []string{`{"name":"Backyard"}`, `{"name":"Backyard"}`, `{"name":"Backyard"}`}, bodies)
+}
+
+func TestRetryTransport_GivesUp(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &RetryTransport{Attempts: 2, Backoff: time.Millisecond}}
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.Equal(t, 2, calls)
+}
+
+func TestRetryTransport_LeavesOtherRequestsAlone(t *testing.T) {
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get(IdempotencyKeyHeader))
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+	client := &http.Client{Transport: &RetryTransport{Backoff: time.Millisecond}}
+
+	// Reads get no key, client errors are not retried, and a caller's key is kept
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	req, _ := http.NewRequest(http.MethodDelete, server.URL, nil)
+	req.Header.Set(IdempotencyKeyHeader, "mine")
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, []string{"", "mine"}, keys)
+}
@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Types of item in the trash.
+const (
+	TrashGarden = "garden"
+	TrashBed    = "bed"
+	TrashTask   = "task"
+)
+
+// TrashItem is a deleted garden, bed or task. It can be restored until
+// PurgeAfter, when the API purges it.
+type TrashItem struct {
+	Type       string    `json:"type"`
+	ID         string    `json:"id"`
+	Name       string    `json:"name"` // A task's description
+	GardenID   string    `json:"garden_id,omitempty"`
+	DeletedAt  time.Time `json:"deleted_at"`
+	PurgeAfter time.Time `json:"purge_after"`
+}
+
+// RestoreResult reports what a restore brought back. Restoring a garden
+// also restores the beds and tasks deleted with it.
+type RestoreResult struct {
+	Type          string `json:"type"`
+	ID            string `json:"id"`
+	RestoredBeds  int64  `json:"restored_beds"`
+	RestoredTasks int64  `json:"restored_tasks"`
+}
+
+// ListTrash fetches the deleted items of itemType, or of every type if it is
+// empty, most recently deleted first.
+func (c *Client) ListTrash(ctx context.Context, itemType string) ([]TrashItem, error) {
+	var query url.Values
+	if itemType != "" {
+		query = url.Values{"type": {itemType}}
+	}
+	var items []TrashItem
+	_, err := c.do(ctx, call{method: http.MethodGet, path: "/trash", query: query}, &items)
+	return items, err
+}
+
+// RestoreTrash takes an item out of the trash.
+func (c *Client) RestoreTrash(ctx context.Context, itemType, id string) (RestoreResult, error) {
+	var result RestoreResult
+	_, err := c.do(ctx, call{method: http.MethodPost, path: resourcePath("trash", itemType, id, "restore")}, &result)
+	return result, err
+}
+
+// PurgeTrash deletes an item in the trash for good.
+func (c *Client) PurgeTrash(ctx context.Context, itemType, id string) error {
+	_, err := c.do(ctx, call{method: http.MethodDelete, path: resourcePath("trash", itemType, id)}, nil)
+	return err
+}
+
+// EmptyTrash purges everything in the trash and returns how many items that
+// was.
+func (c *Client) EmptyTrash(ctx context.Context) (int64, error) {
+	var result struct {
+		Purged int64 `json:"purged"`
+	}
+	_, err := c.do(ctx, call{method: http.MethodDelete, path: "/trash"}, &result)
+	return result.Purged, err
+}
@@ -0,0 +1,64 @@
+// Package database opens the database the API and web servers share, on
+// Postgres or on a SQLite file or in-memory database.
+package database
+
+import (
+	"fmt"
+	"os"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// Supported values of Config.Driver.
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// Default DSNs for each driver, used when DATABASE_URL is unset.
+const (
+	DefaultPostgresDSN = "host=localhost user=postgres password=postgres dbname=plantastic port=5432 sslmode=disable TimeZone=UTC"
+	DefaultSQLiteDSN   = "plantastic.db"
+)
+
+// Memory is the SQLite DSN for an in-memory database. It lasts as long as the
+// process, so it suits tests and demos but cannot be shared with the web
+// server.
+const Memory = ":memory:"
+
+// Config selects a database.
+type Config struct {
+	Driver string // DriverPostgres or DriverSQLite
+	DSN    string // Connection string for Postgres; file path or Memory for SQLite
+}
+
+// ConfigFromEnv reads the database from DATABASE_DRIVER (Postgres unless
+// set) and DATABASE_URL, falling back to the driver's default DSN.
+func ConfigFromEnv() Config {
+	cfg := Config{Driver: os.Getenv("DATABASE_DRIVER"), DSN: os.Getenv("DATABASE_URL")}
+	if cfg.Driver == "" {
+		cfg.Driver = DriverPostgres
+	}
+	if cfg.DSN == "" {
+		switch cfg.Driver {
+		case DriverPostgres:
+			cfg.DSN = DefaultPostgresDSN
+		case DriverSQLite:
+			cfg.DSN = DefaultSQLiteDSN
+		}
+	}
+	return cfg
+}
+
+// Open connects to the database cfg selects.
+func Open(cfg Config, gormConfig *gorm.Config) (*gorm.DB, error) {
+	switch cfg.Driver {
+	case DriverPostgres:
+		return gorm.Open(postgres.Open(cfg.DSN), gormConfig)
+	case DriverSQLite:
+		return openSQLite(cfg.DSN, gormConfig)
+	default:
+		return nil, fmt.Errorf("unknown database driver %q (want %s or %s)", cfg.Driver, DriverPostgres, DriverSQLite)
+	}
+}
@@ -0,0 +1,318 @@
+package database
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// migrationFiles holds the schema's history as numbered SQL scripts, one
+// directory per driver: NNNN_name.up.sql applies a step and
+// NNNN_name.down.sql reverts it.
+//
+//go:embed migrations
+var migrationFiles embed.FS
+
+// Migration is one step of the schema's history.
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string // SHA-256 of Up, recorded when the migration is applied
+}
+
+// MigrationState says where a migration stands in a database.
+type MigrationState string
+
+const (
+	MigrationPending  MigrationState = "pending"
+	MigrationApplied  MigrationState = "applied"
+	MigrationModified MigrationState = "modified" // Applied, but the script has changed since
+	MigrationUnknown  MigrationState = "unknown"  // Applied by a build that had migrations this one lacks
+)
+
+// MigrationStatus reports one migration for MigrateStatus.
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	State     MigrationState
+	AppliedAt time.Time // Zero while pending
+}
+
+var (
+	// ErrMigrationModified is returned when a migration's script no longer
+	// matches the checksum recorded when it was applied. Applied migrations
+	// must not be edited; add a new one instead.
+	ErrMigrationModified = errors.New("applied migration has been modified")
+	// ErrUnknownMigration is returned when the database has a migration this
+	// build does not, because a newer build migrated it.
+	ErrUnknownMigration = errors.New("database has a migration this build does not know")
+	// ErrMigrationOrder is returned when a pending migration sorts before one
+	// that is already applied, which happens when branches adding migrations
+	// are merged out of order. Renumber the pending one.
+	ErrMigrationOrder = errors.New("pending migration is older than an applied one")
+)
+
+// schemaVersion is a row of the table recording applied migrations.
+type schemaVersion struct {
+	Version   int `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+func (schemaVersion) TableName() string { return "schema_version" }
+
+// schemaVersionDDL creates the schema_version table for each driver.
+var schemaVersionDDL = map[string]string{
+	DriverPostgres: "CREATE TABLE IF NOT EXISTS schema_version (version integer PRIMARY KEY, name text NOT NULL, checksum text NOT NULL, applied_at timestamptz NOT NULL)",
+	DriverSQLite:   "CREATE TABLE IF NOT EXISTS schema_version (version integer PRIMARY KEY, name text NOT NULL, checksum text NOT NULL, applied_at datetime NOT NULL)",
+}
+
+// migrationLockID is the Postgres advisory lock held while migrating, so
+// that servers starting together don't apply the same migration twice.
+const migrationLockID = 75268 // "plant" on a phone keypad
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migrations returns driver's migrations in order.
+func Migrations(driver string) ([]Migration, error) {
+	dir := path.Join("migrations", driver)
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for database driver %q", driver)
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		m := migrationFileName.FindStringSubmatch(entry.Name())
+		if m == nil {
+			return nil, fmt.Errorf("migration %s: name must look like 0001_name.up.sql", entry.Name())
+		}
+		version, _ := strconv.Atoi(m[1])
+		script, err := fs.ReadFile(migrationFiles, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration := byVersion[version]
+		if migration == nil {
+			migration = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = migration
+		} else if migration.Name != m[2] {
+			return nil, fmt.Errorf("migration %d is named both %s and %s", version, migration.Name, m[2])
+		}
+		if m[3] == "up" {
+			migration.Up = string(script)
+			sum := sha256.Sum256(script)
+			migration.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			migration.Down = string(script)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s needs both an up and a down script", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	slices.SortFunc(migrations, func(a, b Migration) int { return a.Version - b.Version })
+	return migrations, nil
+}
+
+// MigrateUp applies every pending migration in order, each in a transaction
+// of its own, and returns those it applied. It refuses to run if an applied
+// migration has been modified or is unknown to this build.
+func MigrateUp(db *gorm.DB) ([]Migration, error) {
+	var applied []Migration
+	err := withMigrations(db, func(conn *gorm.DB, migrations []Migration, done []schemaVersion) error {
+		for _, migration := range migrations[len(done):] {
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := execScript(tx, migration.Up); err != nil {
+					return err
+				}
+				return tx.Create(&schemaVersion{
+					Version:   migration.Version,
+					Name:      migration.Name,
+					Checksum:  migration.Checksum,
+					AppliedAt: time.Now().UTC(),
+				}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %04d_%s: %w", migration.Version, migration.Name, err)
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// MigrateDown reverts the last steps applied migrations, newest first, and
+// returns those it reverted.
+func MigrateDown(db *gorm.DB, steps int) ([]Migration, error) {
+	if steps < 1 {
+		return nil, fmt.Errorf("steps must be at least 1, got %d", steps)
+	}
+	var reverted []Migration
+	err := withMigrations(db, func(conn *gorm.DB, migrations []Migration, done []schemaVersion) error {
+		for i := len(done) - 1; i >= 0 && len(reverted) < steps; i-- {
+			migration := migrations[i]
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := execScript(tx, migration.Down); err != nil {
+					return err
+				}
+				return tx.Delete(&schemaVersion{}, migration.Version).Error
+			})
+			if err != nil {
+				return fmt.Errorf("reverting migration %04d_%s: %w", migration.Version, migration.Name, err)
+			}
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// MigrateStatus lists every migration this build has, and any the database
+// has that it doesn't, with where each stands. Unlike MigrateUp and
+// MigrateDown it reports modified and unknown migrations instead of failing.
+func MigrateStatus(db *gorm.DB) ([]MigrationStatus, error) {
+	migrations, err := Migrations(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+	done, err := appliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := map[int]schemaVersion{}
+	for _, row := range done {
+		applied[row.Version] = row
+	}
+	var statuses []MigrationStatus
+	for _, migration := range migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name, State: MigrationPending}
+		if row, ok := applied[migration.Version]; ok {
+			status.State, status.AppliedAt = MigrationApplied, row.AppliedAt
+			if row.Checksum != migration.Checksum {
+				status.State = MigrationModified
+			}
+			delete(applied, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, row := range done {
+		if _, ok := applied[row.Version]; ok {
+			statuses = append(statuses, MigrationStatus{Version: row.Version, Name: row.Name, State: MigrationUnknown, AppliedAt: row.AppliedAt})
+		}
+	}
+	slices.SortFunc(statuses, func(a, b MigrationStatus) int { return a.Version - b.Version })
+	return statuses, nil
+}
+
+// withMigrations runs fn on a single connection holding the migration lock,
+// with the driver's migrations and the applied ones, which it has checked
+// are an unmodified prefix of them.
+func withMigrations(db *gorm.DB, fn func(conn *gorm.DB, migrations []Migration, done []schemaVersion) error) error {
+	driver := db.Dialector.Name()
+	migrations, err := Migrations(driver)
+	if err != nil {
+		return err
+	}
+
+	return db.Connection(func(conn *gorm.DB) error {
+		if driver == DriverPostgres {
+			if err := conn.Exec("SELECT pg_advisory_lock(?)", migrationLockID).Error; err != nil {
+				return fmt.Errorf("taking the migration lock: %w", err)
+			}
+			defer conn.Exec("SELECT pg_advisory_unlock(?)", migrationLockID)
+		}
+
+		done, err := appliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+		for i, row := range done {
+			if !slices.ContainsFunc(migrations, func(m Migration) bool { return m.Version == row.Version }) {
+				return fmt.Errorf("%w: %04d_%s", ErrUnknownMigration, row.Version, row.Name)
+			}
+			if migrations[i].Version != row.Version {
+				return fmt.Errorf("%w: %04d_%s is applied but %04d_%s is not", ErrMigrationOrder, row.Version, row.Name, migrations[i].Version, migrations[i].Name)
+			}
+			if migrations[i].Checksum != row.Checksum {
+				return fmt.Errorf("%w: %04d_%s", ErrMigrationModified, row.Version, row.Name)
+			}
+		}
+		return fn(conn, migrations, done)
+	})
+}
+
+// appliedMigrations returns the recorded migrations in order, creating the
+// table that records them if need be.
+func appliedMigrations(db *gorm.DB) ([]schemaVersion, error) {
+	ddl, ok := schemaVersionDDL[db.Dialector.Name()]
+	if !ok {
+		return nil, fmt.Errorf("no migrations for database driver %q", db.Dialector.Name())
+	}
+	if err := db.Exec(ddl).Error; err != nil {
+		return nil, fmt.Errorf("creating schema_version table: %w", err)
+	}
+	var rows []schemaVersion
+	if err := db.Order("version").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("reading schema_version table: %w", err)
+	}
+	return rows, nil
+}
+
+// execScript runs each statement of a migration script in turn, since not
+// every driver accepts several in one call.
+func execScript(tx *gorm.DB, script string) error {
+	for _, statement := range splitStatements(script) {
+		if err := tx.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitStatements splits a script into statements at semicolons ending a
+// line, dropping lines that are only comments. Scripts keep to that layout;
+// semicolons inside a line are left alone.
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	scanner := bufio.NewScanner(strings.NewReader(script))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "--") {
+			continue
+		}
+		current.WriteString(scanner.Text())
+		current.WriteString("\n")
+		if strings.HasSuffix(line, ";") {
+			statements = append(statements, strings.TrimSpace(current.String()))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
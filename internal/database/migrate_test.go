@@ -0,0 +1,289 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newMemoryDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := Open(Config{Driver: DriverSQLite, DSN: Memory}, &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+func TestMigrations_EveryDriverHasTheSameSteps(t *testing.T) {
+	postgres, err := Migrations(DriverPostgres)
+	require.NoError(t, err)
+	sqlite, err := Migrations(DriverSQLite)
+	require.NoError(t, err)
+
+	require.NotEmpty(t, postgres)
+	require.Len(t, sqlite, len(postgres))
+	for i := range postgres {
+		assert.Equal(t, i+1, postgres[i].Version, "versions are numbered from 1 without gaps")
+		assert.Equal(t, postgres[i].Version, sqlite[i].Version)
+		assert.Equal(t, postgres[i].Name, sqlite[i].Name)
+	}
+}
+
+func TestMigrateUp_AppliesPendingOnce(t *testing.T) {
+	db := newMemoryDB(t)
+	all, err := Migrations(DriverSQLite)
+	require.NoError(t, err)
+
+	applied, err := MigrateUp(db)
+	require.NoError(t, err)
+	assert.Len(t, applied, len(all))
+	assert.True(t, db.Migrator().HasTable("gardens"))
+
+	applied, err = MigrateUp(db)
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+
+	statuses, err := MigrateStatus(db)
+	require.NoError(t, err)
+	require.Len(t, statuses, len(all))
+	for _, s := range statuses {
+		assert.Equal(t, MigrationApplied, s.State, "migration %d", s.Version)
+		assert.False(t, s.AppliedAt.IsZero())
+	}
+}
+
+func TestMigrateDown_RevertsNewestFirst(t *testing.T) {
+	db := newMemoryDB(t)
+	all, err := Migrations(DriverSQLite)
+	require.NoError(t, err)
+	_, err = MigrateUp(db)
+	require.NoError(t, err)
+
+	reverted, err := MigrateDown(db, 1)
+	require.NoError(t, err)
+	require.Len(t, reverted, 1)
+	assert.Equal(t, all[len(all)-1].Version, reverted[0].Version)
+
+	statuses, err := MigrateStatus(db)
+	require.NoError(t, err)
+	assert.Equal(t, MigrationPending, statuses[len(statuses)-1].State)
+	assert.True(t, statuses[len(statuses)-1].AppliedAt.IsZero())
+
+	// Reverting more steps than are applied stops at the first migration
+	reverted, err = MigrateDown(db, 99)
+	require.NoError(t, err)
+	assert.Len(t, reverted, len(all)-1)
+	assert.False(t, db.Migrator().HasTable("gardens"))
+
+	// And everything can be applied again
+	applied, err := MigrateUp(db)
+	require.NoError(t, err)
+	assert.Len(t, applied, len(all))
+
+	_, err = MigrateDown(db, 0)
+	assert.Error(t, err)
+}
+
+func TestMigrateUp_RefusesModifiedMigration(t *testing.T) {
+	db := newMemoryDB(t)
+	_, err := MigrateUp(db)
+	require.NoError(t, err)
+	require.NoError(t, db.Exec("UPDATE schema_version SET checksum = 'edited' WHERE version = 1").Error)
+
+	_, err = MigrateUp(db)
+	assert.ErrorIs(t, err, ErrMigrationModified)
+	_, err = MigrateDown(db, 1)
+	assert.ErrorIs(t, err, ErrMigrationModified)
+
+	statuses, err := MigrateStatus(db)
+	require.NoError(t, err)
+	assert.Equal(t, MigrationModified, statuses[0].State)
+}
+
+func TestMigrateUp_RefusesUnknownMigration(t *testing.T) {
+	db := newMemoryDB(t)
+	_, err := MigrateUp(db)
+	require.NoError(t, err)
+	require.NoError(t, db.Exec("INSERT INTO schema_version (version, name, checksum, applied_at) VALUES (9999, 'from_the_future', 'x', CURRENT_TIMESTAMP)").Error)
+
+	_, err = MigrateUp(db)
+	assert.ErrorIs(t, err, ErrUnknownMigration)
+
+	statuses, err := MigrateStatus(db)
+	require.NoError(t, err)
+	last := statuses[len(statuses)-1]
+	assert.Equal(t, 9999, last.Version)
+	assert.Equal(t, MigrationUnknown, last.State)
+}
+
+func TestMigrateUp_AdoptsAutoMigratedSchema(t *testing.T) {
+	db := newMemoryDB(t)
+	// A database created before versioned migrations, with an orphaned bed
+	// left by a garden deleted without cascading
+	require.NoError(t, db.Exec("CREATE TABLE `gardens` (`id` text,`user_id` text,`name` text,`location` text,`description` text,`version` integer NOT NULL DEFAULT 1,`created_at` datetime,`updated_at` datetime,PRIMARY KEY (`id`))").Error)
+	require.NoError(t, db.Exec("CREATE TABLE `beds` (`id` text,`user_id` text,`garden_id` text,`name` text,`type` text,`size` text,`soil_type` text,`notes` text,`version` integer NOT NULL DEFAULT 1,`created_at` datetime,`updated_at` datetime,PRIMARY KEY (`id`))").Error)
+	require.NoError(t, db.Exec("INSERT INTO gardens (id, name) VALUES ('g1', 'Backyard')").Error)
+	require.NoError(t, db.Exec("INSERT INTO beds (id, garden_id, name) VALUES ('b1', 'g1', 'Kept'), ('b2', 'gone', 'Orphan')").Error)
+
+	_, err := MigrateUp(db)
+	require.NoError(t, err)
+
+	var names []string
+	require.NoError(t, db.Raw("SELECT name FROM beds ORDER BY id").Scan(&names).Error)
+	assert.Equal(t, []string{"Kept"}, names)
+}
+
+// TestMigrateUp_UpgradesBaselinePostgresSchema migrates a database as the
+// first release's AutoMigrate left it. It needs a Postgres server, named by
+// PLANTASTIC_TEST_POSTGRES_URL, and works in a schema of its own there.
+func TestMigrateUp_UpgradesBaselinePostgresSchema(t *testing.T) {
+	dsn := os.Getenv("PLANTASTIC_TEST_POSTGRES_URL")
+	if dsn == "" {
+		t.Skip("PLANTASTIC_TEST_POSTGRES_URL is not set")
+	}
+	db, err := Open(Config{Driver: DriverPostgres, DSN: dsn}, &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1) // Keeps the search_path below on every query
+	schema := fmt.Sprintf("plantastic_test_%d", time.Now().UnixNano())
+	require.NoError(t, db.Exec("CREATE SCHEMA "+schema).Error)
+	t.Cleanup(func() {
+		db.Exec("DROP SCHEMA " + schema + " CASCADE")
+		sqlDB.Close()
+	})
+	require.NoError(t, db.Exec("SET search_path TO "+schema).Error)
+
+	for _, statement := range []string{
+		"CREATE TABLE gardens (id text, name text, location text, description text, created_at timestamptz, updated_at timestamptz, PRIMARY KEY (id))",
+		"CREATE TABLE beds (id text, garden_id text, name text, type text, size text, soil_type text, notes text, created_at timestamptz, updated_at timestamptz, PRIMARY KEY (id))",
+		"CREATE TABLE tasks (id text, garden_id text, bed_id text, description text, due_date timestamptz, status text, priority text, created_at timestamptz, updated_at timestamptz, PRIMARY KEY (id))",
+		"CREATE TABLE devices (id uuid, user_code text NOT NULL, device_id text NOT NULL, token text, created_at timestamptz, updated_at timestamptz, expires_at timestamptz, last_used_at timestamptz, PRIMARY KEY (id))",
+		"CREATE UNIQUE INDEX idx_devices_user_code ON devices (user_code)",
+		"CREATE UNIQUE INDEX idx_devices_device_id ON devices (device_id)",
+		"INSERT INTO gardens (id, name) VALUES ('g1', 'Backyard')",
+		"INSERT INTO beds (id, garden_id, name) VALUES ('b1', 'g1', 'North')",
+		"INSERT INTO tasks (id, garden_id, bed_id, description) VALUES ('t1', 'g1', 'b1', 'Water')",
+	} {
+		require.NoError(t, db.Exec(statement).Error, statement)
+	}
+
+	_, err = MigrateUp(db)
+	require.NoError(t, err)
+
+	for table, columns := range map[string][]string{
+		"gardens": {"user_id", "version", "deleted_at"},
+		"beds":    {"user_id", "version", "deleted_at"},
+		"tasks":   {"user_id", "recurrence", "series_id", "occurrence", "recurrence_id", "version", "deleted_at"},
+		"devices": {"device_code_hash", "user_id", "access_token_hash", "refresh_token_hash", "tokens_issued_at", "revoked_at", "poll_interval", "last_polled_at"},
+	} {
+		for _, column := range columns {
+			assert.True(t, db.Migrator().HasColumn(table, column), "%s.%s", table, column)
+		}
+	}
+	assert.False(t, db.Migrator().HasColumn("devices", "token"), "raw tokens are dropped")
+
+	var version int
+	require.NoError(t, db.Raw("SELECT version FROM gardens WHERE id = 'g1'").Scan(&version).Error)
+	assert.Equal(t, 1, version)
+
+	unowned, err := UnownedRows(db)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"gardens": 1, "beds": 1, "tasks": 1}, unowned)
+}
+
+func TestAssignOwner_GivesUnownedRowsToUser(t *testing.T) {
+	db := newMemoryDB(t)
+	_, err := MigrateUp(db)
+	require.NoError(t, err)
+	for _, statement := range []string{
+		"INSERT INTO gardens (id, user_id, name) VALUES ('g1', NULL, 'Backyard'), ('g2', 'someone', 'Allotment')",
+		"INSERT INTO beds (id, user_id, garden_id, name) VALUES ('b1', '', 'g1', 'North')",
+	} {
+		require.NoError(t, db.Exec(statement).Error, statement)
+	}
+
+	unowned, err := UnownedRows(db)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"gardens": 1, "beds": 1}, unowned)
+
+	assigned, err := AssignOwner(db, "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, unowned, assigned)
+
+	var owners []string
+	require.NoError(t, db.Raw("SELECT user_id FROM gardens ORDER BY id").Scan(&owners).Error)
+	assert.Equal(t, []string{"user-1", "someone"}, owners)
+	unowned, err = UnownedRows(db)
+	require.NoError(t, err)
+	assert.Empty(t, unowned)
+}
+
+func TestForeignKeys_CascadeFromGardens(t *testing.T) {
+	db := newMemoryDB(t)
+	_, err := MigrateUp(db)
+	require.NoError(t, err)
+
+	for _, statement := range []string{
+		"INSERT INTO gardens (id, name) VALUES ('g1', 'Backyard')",
+		"INSERT INTO beds (id, garden_id, name) VALUES ('b1', 'g1', 'North')",
+		"INSERT INTO plants (id, name) VALUES ('p1', 'Tomato')",
+		"INSERT INTO tasks (id, garden_id, bed_id, description) VALUES ('t1', 'g1', 'b1', 'Water'), ('t2', 'g1', NULL, 'Mulch')",
+		"INSERT INTO task_events (id, task_id, to_status) VALUES ('e1', 't1', 'pending')",
+		"INSERT INTO plantings (id, garden_id, bed_id, plant_id) VALUES ('pl1', 'g1', 'b1', 'p1')",
+	} {
+		require.NoError(t, db.Exec(statement).Error, statement)
+	}
+
+	count := func(table string) int64 {
+		var n int64
+		require.NoError(t, db.Table(table).Count(&n).Error)
+		return n
+	}
+
+	// A bed can't point at a garden that doesn't exist, nor a plant in use be deleted
+	assert.Error(t, db.Exec("INSERT INTO beds (id, garden_id) VALUES ('b2', 'missing')").Error)
+	assert.Error(t, db.Exec("DELETE FROM plants WHERE id = 'p1'").Error)
+
+	// Deleting a bed keeps its tasks on the garden and removes its plantings
+	require.NoError(t, db.Exec("DELETE FROM beds WHERE id = 'b1'").Error)
+	var bedID *string
+	require.NoError(t, db.Raw("SELECT bed_id FROM tasks WHERE id = 't1'").Scan(&bedID).Error)
+	assert.Nil(t, bedID)
+	assert.Equal(t, int64(0), count("plantings"))
+
+	// Deleting the garden removes the rest
+	require.NoError(t, db.Exec("DELETE FROM gardens WHERE id = 'g1'").Error)
+	assert.Equal(t, int64(0), count("tasks"))
+	assert.Equal(t, int64(0), count("task_events"))
+	assert.Equal(t, int64(1), count("plants"), "plants are not part of a garden")
+}
+
+func TestSplitStatements(t *testing.T) {
+	script := `-- A comment; with a semicolon
+CREATE TABLE a (
+    id text -- trailing comments are kept
+);
+
+INSERT INTO a (id) VALUES ('x;y');
+DROP TABLE b`
+	assert.Equal(t, []string{
+		"CREATE TABLE a (\n    id text -- trailing comments are kept\n);",
+		"INSERT INTO a (id) VALUES ('x;y');",
+		"DROP TABLE b",
+	}, splitStatements(script))
+}
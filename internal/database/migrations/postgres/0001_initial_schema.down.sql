@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS idempotency_records;
+DROP TABLE IF EXISTS devices;
+DROP TABLE IF EXISTS plantings;
+DROP TABLE IF EXISTS plants;
+DROP TABLE IF EXISTS task_events;
+DROP TABLE IF EXISTS tasks;
+DROP TABLE IF EXISTS task_series;
+DROP TABLE IF EXISTS beds;
+DROP TABLE IF EXISTS gardens;
@@ -0,0 +1,174 @@
+-- The schema as AutoMigrate left it, so databases created before versioned
+-- migrations are adopted. Tables that already exist are left alone by CREATE
+-- TABLE IF NOT EXISTS, so the columns added since the first release are added
+-- to them here; rows from before users had accounts are left without a
+-- user_id, which "migrate status" reports.
+
+CREATE TABLE IF NOT EXISTS gardens (
+    id text PRIMARY KEY,
+    user_id text,
+    name text,
+    location text,
+    description text,
+    version bigint NOT NULL DEFAULT 1,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+ALTER TABLE gardens ADD COLUMN IF NOT EXISTS user_id text;
+ALTER TABLE gardens ADD COLUMN IF NOT EXISTS version bigint NOT NULL DEFAULT 1;
+
+CREATE TABLE IF NOT EXISTS beds (
+    id text PRIMARY KEY,
+    user_id text,
+    garden_id text,
+    name text,
+    type text,
+    size text,
+    soil_type text,
+    notes text,
+    version bigint NOT NULL DEFAULT 1,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+ALTER TABLE beds ADD COLUMN IF NOT EXISTS user_id text;
+ALTER TABLE beds ADD COLUMN IF NOT EXISTS version bigint NOT NULL DEFAULT 1;
+
+CREATE TABLE IF NOT EXISTS task_series (
+    id text PRIMARY KEY,
+    user_id text,
+    garden_id text,
+    bed_id text,
+    description text,
+    priority text,
+    recurrence text,
+    start timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS tasks (
+    id text PRIMARY KEY,
+    user_id text,
+    garden_id text,
+    bed_id text,
+    description text,
+    due_date timestamptz,
+    status text,
+    priority text,
+    recurrence text,
+    series_id text,
+    occurrence bigint,
+    recurrence_id timestamptz,
+    version bigint NOT NULL DEFAULT 1,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS user_id text;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS recurrence text;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS series_id text;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS occurrence bigint;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS recurrence_id timestamptz;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS version bigint NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_tasks_series_id ON tasks (series_id);
+
+CREATE TABLE IF NOT EXISTS task_events (
+    id text PRIMARY KEY,
+    user_id text,
+    task_id text,
+    from_status text,
+    to_status text,
+    reason text,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_task_events_task_id ON task_events (task_id);
+
+CREATE TABLE IF NOT EXISTS plants (
+    id text PRIMARY KEY,
+    user_id text,
+    name text,
+    species text,
+    variety text,
+    days_to_maturity bigint,
+    spacing text,
+    sun text,
+    notes text,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS plantings (
+    id text PRIMARY KEY,
+    user_id text,
+    garden_id text,
+    bed_id text,
+    plant_id text,
+    quantity bigint,
+    sow_date timestamptz,
+    transplant_date timestamptz,
+    status text,
+    notes text,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS devices (
+    id uuid PRIMARY KEY,
+    user_code text NOT NULL,
+    device_code_hash text,
+    device_id text NOT NULL,
+    user_id text,
+    name text,
+    ip_address text,
+    user_agent text,
+    created_at timestamptz,
+    updated_at timestamptz,
+    expires_at timestamptz,
+    last_used_at timestamptz,
+    access_token_hash text,
+    refresh_token_hash text,
+    access_token_expires_at timestamptz,
+    refresh_token_expires_at timestamptz,
+    activated_at timestamptz,
+    tokens_issued_at timestamptz,
+    denied_at timestamptz,
+    revoked_at timestamptz,
+    poll_interval bigint NOT NULL DEFAULT 5,
+    last_polled_at timestamptz
+);
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS device_code_hash text;
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS user_id text;
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS name text;
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS ip_address text;
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS user_agent text;
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS access_token_hash text;
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS refresh_token_hash text;
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS access_token_expires_at timestamptz;
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS refresh_token_expires_at timestamptz;
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS activated_at timestamptz;
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS tokens_issued_at timestamptz;
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS denied_at timestamptz;
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS revoked_at timestamptz;
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS poll_interval bigint NOT NULL DEFAULT 5;
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS last_polled_at timestamptz;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_devices_user_code ON devices (user_code);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_devices_device_id ON devices (device_id);
+CREATE INDEX IF NOT EXISTS idx_devices_device_code_hash ON devices (device_code_hash);
+CREATE INDEX IF NOT EXISTS idx_devices_user_id ON devices (user_id);
+CREATE INDEX IF NOT EXISTS idx_devices_access_token_hash ON devices (access_token_hash);
+CREATE INDEX IF NOT EXISTS idx_devices_refresh_token_hash ON devices (refresh_token_hash);
+-- Devices used to store raw Clerk session JWTs; only token hashes are kept now
+ALTER TABLE devices DROP COLUMN IF EXISTS token;
+
+CREATE TABLE IF NOT EXISTS idempotency_records (
+    user_id text,
+    idempotency_key text,
+    request_hash text NOT NULL,
+    status_code bigint NOT NULL,
+    content_type text,
+    etag text,
+    body bytea,
+    created_at timestamptz,
+    expires_at timestamptz,
+    PRIMARY KEY (user_id, idempotency_key)
+);
+CREATE INDEX IF NOT EXISTS idx_idempotency_records_expires_at ON idempotency_records (expires_at);
@@ -0,0 +1,22 @@
+DROP INDEX IF EXISTS idx_plantings_plant_id;
+DROP INDEX IF EXISTS idx_plantings_bed_id;
+DROP INDEX IF EXISTS idx_plantings_garden_id;
+DROP INDEX IF EXISTS idx_plants_user_id;
+DROP INDEX IF EXISTS idx_tasks_due_date;
+DROP INDEX IF EXISTS idx_tasks_bed_id;
+DROP INDEX IF EXISTS idx_tasks_garden_id;
+DROP INDEX IF EXISTS idx_task_series_bed_id;
+DROP INDEX IF EXISTS idx_task_series_garden_id;
+DROP INDEX IF EXISTS idx_beds_garden_id;
+DROP INDEX IF EXISTS idx_gardens_user_id;
+
+ALTER TABLE plantings DROP CONSTRAINT IF EXISTS fk_plantings_plant;
+ALTER TABLE plantings DROP CONSTRAINT IF EXISTS fk_plantings_bed;
+ALTER TABLE plantings DROP CONSTRAINT IF EXISTS fk_plantings_garden;
+ALTER TABLE task_events DROP CONSTRAINT IF EXISTS fk_task_events_task;
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS fk_tasks_series;
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS fk_tasks_bed;
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS fk_tasks_garden;
+ALTER TABLE task_series DROP CONSTRAINT IF EXISTS fk_task_series_bed;
+ALTER TABLE task_series DROP CONSTRAINT IF EXISTS fk_task_series_garden;
+ALTER TABLE beds DROP CONSTRAINT IF EXISTS fk_beds_garden;
@@ -0,0 +1,49 @@
+-- Rows left behind by deletes made before these constraints existed are
+-- removed or detached the way the new ON DELETE actions would have.
+DELETE FROM beds WHERE garden_id NOT IN (SELECT id FROM gardens);
+DELETE FROM task_series WHERE garden_id NOT IN (SELECT id FROM gardens);
+UPDATE task_series SET bed_id = NULL WHERE bed_id NOT IN (SELECT id FROM beds);
+DELETE FROM tasks WHERE garden_id NOT IN (SELECT id FROM gardens);
+UPDATE tasks SET bed_id = NULL WHERE bed_id NOT IN (SELECT id FROM beds);
+UPDATE tasks SET series_id = NULL WHERE series_id NOT IN (SELECT id FROM task_series);
+DELETE FROM task_events WHERE task_id NOT IN (SELECT id FROM tasks);
+DELETE FROM plantings WHERE garden_id NOT IN (SELECT id FROM gardens)
+    OR bed_id NOT IN (SELECT id FROM beds)
+    OR plant_id NOT IN (SELECT id FROM plants);
+
+-- Deleting a garden takes everything in it along; deleting a bed keeps its
+-- tasks on the garden but removes what was planted in it. Plants in use
+-- cannot be deleted.
+ALTER TABLE beds ADD CONSTRAINT fk_beds_garden
+    FOREIGN KEY (garden_id) REFERENCES gardens (id) ON DELETE CASCADE;
+ALTER TABLE task_series ADD CONSTRAINT fk_task_series_garden
+    FOREIGN KEY (garden_id) REFERENCES gardens (id) ON DELETE CASCADE;
+ALTER TABLE task_series ADD CONSTRAINT fk_task_series_bed
+    FOREIGN KEY (bed_id) REFERENCES beds (id) ON DELETE SET NULL;
+ALTER TABLE tasks ADD CONSTRAINT fk_tasks_garden
+    FOREIGN KEY (garden_id) REFERENCES gardens (id) ON DELETE CASCADE;
+ALTER TABLE tasks ADD CONSTRAINT fk_tasks_bed
+    FOREIGN KEY (bed_id) REFERENCES beds (id) ON DELETE SET NULL;
+ALTER TABLE tasks ADD CONSTRAINT fk_tasks_series
+    FOREIGN KEY (series_id) REFERENCES task_series (id) ON DELETE SET NULL;
+ALTER TABLE task_events ADD CONSTRAINT fk_task_events_task
+    FOREIGN KEY (task_id) REFERENCES tasks (id) ON DELETE CASCADE;
+ALTER TABLE plantings ADD CONSTRAINT fk_plantings_garden
+    FOREIGN KEY (garden_id) REFERENCES gardens (id) ON DELETE CASCADE;
+ALTER TABLE plantings ADD CONSTRAINT fk_plantings_bed
+    FOREIGN KEY (bed_id) REFERENCES beds (id) ON DELETE CASCADE;
+ALTER TABLE plantings ADD CONSTRAINT fk_plantings_plant
+    FOREIGN KEY (plant_id) REFERENCES plants (id) ON DELETE RESTRICT;
+
+-- Postgres does not index foreign keys by itself
+CREATE INDEX idx_gardens_user_id ON gardens (user_id);
+CREATE INDEX idx_beds_garden_id ON beds (garden_id);
+CREATE INDEX idx_task_series_garden_id ON task_series (garden_id);
+CREATE INDEX idx_task_series_bed_id ON task_series (bed_id);
+CREATE INDEX idx_tasks_garden_id ON tasks (garden_id);
+CREATE INDEX idx_tasks_bed_id ON tasks (bed_id);
+CREATE INDEX idx_tasks_due_date ON tasks (due_date);
+CREATE INDEX idx_plants_user_id ON plants (user_id);
+CREATE INDEX idx_plantings_garden_id ON plantings (garden_id);
+CREATE INDEX idx_plantings_bed_id ON plantings (bed_id);
+CREATE INDEX idx_plantings_plant_id ON plantings (plant_id);
@@ -0,0 +1,11 @@
+-- Without the column, whatever is in the trash would reappear, so it is
+-- purged first
+DELETE FROM tasks WHERE deleted_at IS NOT NULL;
+DELETE FROM beds WHERE deleted_at IS NOT NULL;
+DELETE FROM gardens WHERE deleted_at IS NOT NULL;
+DROP INDEX IF EXISTS idx_tasks_deleted_at;
+DROP INDEX IF EXISTS idx_beds_deleted_at;
+DROP INDEX IF EXISTS idx_gardens_deleted_at;
+ALTER TABLE tasks DROP COLUMN deleted_at;
+ALTER TABLE beds DROP COLUMN deleted_at;
+ALTER TABLE gardens DROP COLUMN deleted_at;
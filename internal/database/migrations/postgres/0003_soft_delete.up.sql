@@ -0,0 +1,8 @@
+-- Deleted gardens, beds and tasks are kept in the trash, marked with the time
+-- they were deleted, until they are restored or purged.
+ALTER TABLE gardens ADD COLUMN deleted_at timestamptz;
+ALTER TABLE beds ADD COLUMN deleted_at timestamptz;
+ALTER TABLE tasks ADD COLUMN deleted_at timestamptz;
+CREATE INDEX idx_gardens_deleted_at ON gardens (deleted_at);
+CREATE INDEX idx_beds_deleted_at ON beds (deleted_at);
+CREATE INDEX idx_tasks_deleted_at ON tasks (deleted_at);
@@ -0,0 +1,144 @@
+-- The schema as AutoMigrate left it, so databases created before versioned
+-- migrations are adopted as they are.
+
+CREATE TABLE IF NOT EXISTS gardens (
+    id text PRIMARY KEY,
+    user_id text,
+    name text,
+    location text,
+    description text,
+    version integer NOT NULL DEFAULT 1,
+    created_at datetime,
+    updated_at datetime
+);
+
+CREATE TABLE IF NOT EXISTS beds (
+    id text PRIMARY KEY,
+    user_id text,
+    garden_id text,
+    name text,
+    type text,
+    size text,
+    soil_type text,
+    notes text,
+    version integer NOT NULL DEFAULT 1,
+    created_at datetime,
+    updated_at datetime
+);
+
+CREATE TABLE IF NOT EXISTS task_series (
+    id text PRIMARY KEY,
+    user_id text,
+    garden_id text,
+    bed_id text,
+    description text,
+    priority text,
+    recurrence text,
+    start datetime,
+    created_at datetime,
+    updated_at datetime
+);
+
+CREATE TABLE IF NOT EXISTS tasks (
+    id text PRIMARY KEY,
+    user_id text,
+    garden_id text,
+    bed_id text,
+    description text,
+    due_date datetime,
+    status text,
+    priority text,
+    recurrence text,
+    series_id text,
+    occurrence integer,
+    recurrence_id datetime,
+    version integer NOT NULL DEFAULT 1,
+    created_at datetime,
+    updated_at datetime
+);
+CREATE INDEX IF NOT EXISTS idx_tasks_series_id ON tasks (series_id);
+
+CREATE TABLE IF NOT EXISTS task_events (
+    id text PRIMARY KEY,
+    user_id text,
+    task_id text,
+    from_status text,
+    to_status text,
+    reason text,
+    created_at datetime
+);
+CREATE INDEX IF NOT EXISTS idx_task_events_task_id ON task_events (task_id);
+
+CREATE TABLE IF NOT EXISTS plants (
+    id text PRIMARY KEY,
+    user_id text,
+    name text,
+    species text,
+    variety text,
+    days_to_maturity integer,
+    spacing text,
+    sun text,
+    notes text,
+    created_at datetime,
+    updated_at datetime
+);
+
+CREATE TABLE IF NOT EXISTS plantings (
+    id text PRIMARY KEY,
+    user_id text,
+    garden_id text,
+    bed_id text,
+    plant_id text,
+    quantity integer,
+    sow_date datetime,
+    transplant_date datetime,
+    status text,
+    notes text,
+    created_at datetime,
+    updated_at datetime
+);
+
+CREATE TABLE IF NOT EXISTS devices (
+    id uuid PRIMARY KEY,
+    user_code text NOT NULL,
+    device_code_hash text,
+    device_id text NOT NULL,
+    user_id text,
+    name text,
+    ip_address text,
+    user_agent text,
+    created_at datetime,
+    updated_at datetime,
+    expires_at datetime,
+    last_used_at datetime,
+    access_token_hash text,
+    refresh_token_hash text,
+    access_token_expires_at datetime,
+    refresh_token_expires_at datetime,
+    activated_at datetime,
+    tokens_issued_at datetime,
+    denied_at datetime,
+    revoked_at datetime,
+    poll_interval integer NOT NULL DEFAULT 5,
+    last_polled_at datetime
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_devices_user_code ON devices (user_code);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_devices_device_id ON devices (device_id);
+CREATE INDEX IF NOT EXISTS idx_devices_device_code_hash ON devices (device_code_hash);
+CREATE INDEX IF NOT EXISTS idx_devices_user_id ON devices (user_id);
+CREATE INDEX IF NOT EXISTS idx_devices_access_token_hash ON devices (access_token_hash);
+CREATE INDEX IF NOT EXISTS idx_devices_refresh_token_hash ON devices (refresh_token_hash);
+
+CREATE TABLE IF NOT EXISTS idempotency_records (
+    user_id text,
+    idempotency_key text,
+    request_hash text NOT NULL,
+    status_code integer NOT NULL,
+    content_type text,
+    etag text,
+    body blob,
+    created_at datetime,
+    expires_at datetime,
+    PRIMARY KEY (user_id, idempotency_key)
+);
+CREATE INDEX IF NOT EXISTS idx_idempotency_records_expires_at ON idempotency_records (expires_at);
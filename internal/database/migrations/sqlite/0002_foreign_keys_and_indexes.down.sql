@@ -0,0 +1,97 @@
+DROP INDEX IF EXISTS idx_plants_user_id;
+DROP INDEX IF EXISTS idx_gardens_user_id;
+
+-- Children are rebuilt before their parents, for the same reason as on the
+-- way up. Only the indexes that predate the foreign keys are recreated.
+
+CREATE TABLE plantings_new (
+    id text PRIMARY KEY,
+    user_id text,
+    garden_id text,
+    bed_id text,
+    plant_id text,
+    quantity integer,
+    sow_date datetime,
+    transplant_date datetime,
+    status text,
+    notes text,
+    created_at datetime,
+    updated_at datetime
+);
+INSERT INTO plantings_new (id, user_id, garden_id, bed_id, plant_id, quantity, sow_date, transplant_date, status, notes, created_at, updated_at)
+    SELECT id, user_id, garden_id, bed_id, plant_id, quantity, sow_date, transplant_date, status, notes, created_at, updated_at FROM plantings;
+DROP TABLE plantings;
+ALTER TABLE plantings_new RENAME TO plantings;
+
+CREATE TABLE task_events_new (
+    id text PRIMARY KEY,
+    user_id text,
+    task_id text,
+    from_status text,
+    to_status text,
+    reason text,
+    created_at datetime
+);
+INSERT INTO task_events_new (id, user_id, task_id, from_status, to_status, reason, created_at)
+    SELECT id, user_id, task_id, from_status, to_status, reason, created_at FROM task_events;
+DROP TABLE task_events;
+ALTER TABLE task_events_new RENAME TO task_events;
+CREATE INDEX idx_task_events_task_id ON task_events (task_id);
+
+CREATE TABLE tasks_new (
+    id text PRIMARY KEY,
+    user_id text,
+    garden_id text,
+    bed_id text,
+    description text,
+    due_date datetime,
+    status text,
+    priority text,
+    recurrence text,
+    series_id text,
+    occurrence integer,
+    recurrence_id datetime,
+    version integer NOT NULL DEFAULT 1,
+    created_at datetime,
+    updated_at datetime
+);
+INSERT INTO tasks_new (id, user_id, garden_id, bed_id, description, due_date, status, priority, recurrence, series_id, occurrence, recurrence_id, version, created_at, updated_at)
+    SELECT id, user_id, garden_id, bed_id, description, due_date, status, priority, recurrence, series_id, occurrence, recurrence_id, version, created_at, updated_at FROM tasks;
+DROP TABLE tasks;
+ALTER TABLE tasks_new RENAME TO tasks;
+CREATE INDEX idx_tasks_series_id ON tasks (series_id);
+
+CREATE TABLE task_series_new (
+    id text PRIMARY KEY,
+    user_id text,
+    garden_id text,
+    bed_id text,
+    description text,
+    priority text,
+    recurrence text,
+    start datetime,
+    created_at datetime,
+    updated_at datetime
+);
+INSERT INTO task_series_new (id, user_id, garden_id, bed_id, description, priority, recurrence, start, created_at, updated_at)
+    SELECT id, user_id, garden_id, bed_id, description, priority, recurrence, start, created_at, updated_at FROM task_series;
+DROP TABLE task_series;
+ALTER TABLE task_series_new RENAME TO task_series;
+
+CREATE TABLE beds_new (
+    id text PRIMARY KEY,
+    user_id text,
+    garden_id text,
+    name text,
+    type text,
+    size text,
+    soil_type text,
+    notes text,
+    version integer NOT NULL DEFAULT 1,
+    created_at datetime,
+    updated_at datetime
+);
+INSERT INTO beds_new (id, user_id, garden_id, name, type, size, soil_type, notes, version, created_at, updated_at)
+    SELECT id, user_id, garden_id, name, type, size, soil_type, notes, version, created_at, updated_at FROM beds;
+DROP TABLE beds;
+ALTER TABLE beds_new RENAME TO beds;
@@ -0,0 +1,125 @@
+-- Rows left behind by deletes made before these constraints existed are
+-- removed or detached the way the new ON DELETE actions would have.
+DELETE FROM beds WHERE garden_id NOT IN (SELECT id FROM gardens);
+DELETE FROM task_series WHERE garden_id NOT IN (SELECT id FROM gardens);
+UPDATE task_series SET bed_id = NULL WHERE bed_id NOT IN (SELECT id FROM beds);
+DELETE FROM tasks WHERE garden_id NOT IN (SELECT id FROM gardens);
+UPDATE tasks SET bed_id = NULL WHERE bed_id NOT IN (SELECT id FROM beds);
+UPDATE tasks SET series_id = NULL WHERE series_id NOT IN (SELECT id FROM task_series);
+DELETE FROM task_events WHERE task_id NOT IN (SELECT id FROM tasks);
+DELETE FROM plantings WHERE garden_id NOT IN (SELECT id FROM gardens)
+    OR bed_id NOT IN (SELECT id FROM beds)
+    OR plant_id NOT IN (SELECT id FROM plants);
+
+-- Deleting a garden takes everything in it along; deleting a bed keeps its
+-- tasks on the garden but removes what was planted in it. Plants in use
+-- cannot be deleted.
+--
+-- SQLite cannot add constraints to an existing table, so each table that
+-- gains foreign keys is rebuilt with them, parents before children so that
+-- no table is dropped while another still references it. The rebuilt
+-- tables get their old indexes back along with the new ones.
+
+CREATE TABLE beds_new (
+    id text PRIMARY KEY,
+    user_id text,
+    garden_id text REFERENCES gardens (id) ON DELETE CASCADE,
+    name text,
+    type text,
+    size text,
+    soil_type text,
+    notes text,
+    version integer NOT NULL DEFAULT 1,
+    created_at datetime,
+    updated_at datetime
+);
+INSERT INTO beds_new (id, user_id, garden_id, name, type, size, soil_type, notes, version, created_at, updated_at)
+    SELECT id, user_id, garden_id, name, type, size, soil_type, notes, version, created_at, updated_at FROM beds;
+DROP TABLE beds;
+ALTER TABLE beds_new RENAME TO beds;
+CREATE INDEX idx_beds_garden_id ON beds (garden_id);
+
+CREATE TABLE task_series_new (
+    id text PRIMARY KEY,
+    user_id text,
+    garden_id text REFERENCES gardens (id) ON DELETE CASCADE,
+    bed_id text REFERENCES beds (id) ON DELETE SET NULL,
+    description text,
+    priority text,
+    recurrence text,
+    start datetime,
+    created_at datetime,
+    updated_at datetime
+);
+INSERT INTO task_series_new (id, user_id, garden_id, bed_id, description, priority, recurrence, start, created_at, updated_at)
+    SELECT id, user_id, garden_id, bed_id, description, priority, recurrence, start, created_at, updated_at FROM task_series;
+DROP TABLE task_series;
+ALTER TABLE task_series_new RENAME TO task_series;
+CREATE INDEX idx_task_series_garden_id ON task_series (garden_id);
+CREATE INDEX idx_task_series_bed_id ON task_series (bed_id);
+
+CREATE TABLE tasks_new (
+    id text PRIMARY KEY,
+    user_id text,
+    garden_id text REFERENCES gardens (id) ON DELETE CASCADE,
+    bed_id text REFERENCES beds (id) ON DELETE SET NULL,
+    description text,
+    due_date datetime,
+    status text,
+    priority text,
+    recurrence text,
+    series_id text REFERENCES task_series (id) ON DELETE SET NULL,
+    occurrence integer,
+    recurrence_id datetime,
+    version integer NOT NULL DEFAULT 1,
+    created_at datetime,
+    updated_at datetime
+);
+INSERT INTO tasks_new (id, user_id, garden_id, bed_id, description, due_date, status, priority, recurrence, series_id, occurrence, recurrence_id, version, created_at, updated_at)
+    SELECT id, user_id, garden_id, bed_id, description, due_date, status, priority, recurrence, series_id, occurrence, recurrence_id, version, created_at, updated_at FROM tasks;
+DROP TABLE tasks;
+ALTER TABLE tasks_new RENAME TO tasks;
+CREATE INDEX idx_tasks_series_id ON tasks (series_id);
+CREATE INDEX idx_tasks_garden_id ON tasks (garden_id);
+CREATE INDEX idx_tasks_bed_id ON tasks (bed_id);
+CREATE INDEX idx_tasks_due_date ON tasks (due_date);
+
+CREATE TABLE task_events_new (
+    id text PRIMARY KEY,
+    user_id text,
+    task_id text REFERENCES tasks (id) ON DELETE CASCADE,
+    from_status text,
+    to_status text,
+    reason text,
+    created_at datetime
+);
+INSERT INTO task_events_new (id, user_id, task_id, from_status, to_status, reason, created_at)
+    SELECT id, user_id, task_id, from_status, to_status, reason, created_at FROM task_events;
+DROP TABLE task_events;
+ALTER TABLE task_events_new RENAME TO task_events;
+CREATE INDEX idx_task_events_task_id ON task_events (task_id);
+
+CREATE TABLE plantings_new (
+    id text PRIMARY KEY,
+    user_id text,
+    garden_id text REFERENCES gardens (id) ON DELETE CASCADE,
+    bed_id text REFERENCES beds (id) ON DELETE CASCADE,
+    plant_id text REFERENCES plants (id) ON DELETE RESTRICT,
+    quantity integer,
+    sow_date datetime,
+    transplant_date datetime,
+    status text,
+    notes text,
+    created_at datetime,
+    updated_at datetime
+);
+INSERT INTO plantings_new (id, user_id, garden_id, bed_id, plant_id, quantity, sow_date, transplant_date, status, notes, created_at, updated_at)
+    SELECT id, user_id, garden_id, bed_id, plant_id, quantity, sow_date, transplant_date, status, notes, created_at, updated_at FROM plantings;
+DROP TABLE plantings;
+ALTER TABLE plantings_new RENAME TO plantings;
+CREATE INDEX idx_plantings_garden_id ON plantings (garden_id);
+CREATE INDEX idx_plantings_bed_id ON plantings (bed_id);
+CREATE INDEX idx_plantings_plant_id ON plantings (plant_id);
+
+CREATE INDEX idx_gardens_user_id ON gardens (user_id);
+CREATE INDEX idx_plants_user_id ON plants (user_id);
@@ -0,0 +1,17 @@
+-- An append-only record of every change made through the API. Entries are
+-- kept after the entity itself is purged, so there are no foreign keys.
+CREATE TABLE audit_entries (
+    id text PRIMARY KEY,
+    user_id text NOT NULL,
+    actor_type text NOT NULL,
+    actor_id text,
+    entity text NOT NULL,
+    entity_id text NOT NULL,
+    action text NOT NULL,
+    before_values text,
+    after_values text,
+    created_at datetime NOT NULL
+);
+CREATE INDEX idx_audit_entries_entity ON audit_entries (user_id, entity, entity_id);
+CREATE INDEX idx_audit_entries_created_at ON audit_entries (user_id, created_at);
+CREATE INDEX idx_audit_entries_actor ON audit_entries (user_id, actor_id);
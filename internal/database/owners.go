@@ -0,0 +1,52 @@
+package database
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// ownedTables are the tables whose rows belong to a user through user_id.
+var ownedTables = []string{"gardens", "beds", "task_series", "tasks", "task_events", "plants", "plantings"}
+
+// UnownedRows counts the rows without a user_id in each table that has any.
+// Databases from before users had accounts hold such rows; every query is
+// scoped to a user, so nobody sees them until AssignOwner gives them one.
+func UnownedRows(db *gorm.DB) (map[string]int64, error) {
+	counts := map[string]int64{}
+	for _, table := range ownedTables {
+		var n int64
+		if err := db.Table(table).Where("user_id IS NULL OR user_id = ''").Count(&n).Error; err != nil {
+			return nil, fmt.Errorf("counting unowned rows in %s: %w", table, err)
+		}
+		if n > 0 {
+			counts[table] = n
+		}
+	}
+	return counts, nil
+}
+
+// AssignOwner gives every row without a user_id to userID, in one
+// transaction, and returns how many rows it changed in each table.
+func AssignOwner(db *gorm.DB, userID string) (map[string]int64, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("a user ID is required")
+	}
+	counts := map[string]int64{}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for _, table := range ownedTables {
+			result := tx.Table(table).Where("user_id IS NULL OR user_id = ''").Update("user_id", userID)
+			if result.Error != nil {
+				return fmt.Errorf("assigning rows in %s: %w", table, result.Error)
+			}
+			if result.RowsAffected > 0 {
+				counts[table] = result.RowsAffected
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
@@ -0,0 +1,93 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"net/url"
+	"strings"
+	"time"
+
+	gosqlite "github.com/glebarez/go-sqlite"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// openSQLite opens a SQLite database with the pure-Go driver, so no C
+// toolchain is needed.
+func openSQLite(dsn string, gormConfig *gorm.Config) (*gorm.DB, error) {
+	memory := dsn == Memory
+	conn := sql.OpenDB(utcConnector{dsn: sqliteDSN(dsn, memory)})
+	if memory {
+		// Each connection to :memory: gets a database of its own, so keep to one
+		conn.SetMaxOpenConns(1)
+	}
+	db, err := gorm.Open(sqlite.Dialector{Conn: conn}, gormConfig)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// sqliteDSN adds the connection settings the stores rely on to dsn: foreign
+// keys enforced as they are on Postgres, waiting for a busy database instead
+// of failing, and transactions that take the write lock when they begin, so
+// two of them cannot deadlock upgrading a read lock.
+func sqliteDSN(dsn string, memory bool) string {
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "busy_timeout(5000)")
+	if !memory {
+		params.Add("_pragma", "journal_mode(WAL)")
+	}
+	params.Set("_txlock", "immediate")
+	params.Set("_time_format", "sqlite")
+
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
+	}
+	return dsn + separator + params.Encode()
+}
+
+// utcConnector opens SQLite connections that write every time in UTC. SQLite
+// keeps times as text, which only compares and sorts correctly when every
+// value has the same offset.
+type utcConnector struct{ dsn string }
+
+func (c utcConnector) Connect(context.Context) (driver.Conn, error) {
+	conn, err := c.Driver().Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	return utcConn{conn.(sqliteConn)}, nil
+}
+
+func (utcConnector) Driver() driver.Driver { return &gosqlite.Driver{} }
+
+// sqliteConn is the set of interfaces the SQLite driver's connections implement.
+type sqliteConn interface {
+	driver.Conn
+	driver.ConnBeginTx
+	driver.ConnPrepareContext
+	driver.ExecerContext
+	driver.QueryerContext
+	driver.Pinger
+}
+
+type utcConn struct{ sqliteConn }
+
+// CheckNamedValue converts a value as database/sql would by default, then
+// moves times (including those behind pointers and Valuers) to UTC.
+func (utcConn) CheckNamedValue(nv *driver.NamedValue) error {
+	value, err := driver.DefaultParameterConverter.ConvertValue(nv.Value)
+	if err != nil {
+		return err
+	}
+	if t, ok := value.(time.Time); ok {
+		value = t.UTC()
+	}
+	nv.Value = value
+	return nil
+}
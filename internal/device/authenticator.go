@@ -0,0 +1,46 @@
+package device
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/zjpiazza/plantastic/internal/auth"
+)
+
+// ProviderName is the Principal.Provider of callers using a device access token.
+const ProviderName = "device"
+
+// Authenticator adapts Manager to auth.Authenticator so API middleware can
+// accept device access tokens.
+type Authenticator struct {
+	manager *Manager
+}
+
+// NewAuthenticator returns an auth.Authenticator backed by m.
+func NewAuthenticator(m *Manager) *Authenticator {
+	return &Authenticator{manager: m}
+}
+
+// Name implements auth.Authenticator.
+func (a *Authenticator) Name() string { return ProviderName }
+
+// Authenticate implements auth.Authenticator. Tokens without the device
+// prefix are rejected without touching the database.
+func (a *Authenticator) Authenticate(ctx context.Context, token string) (*auth.Principal, error) {
+	if !IsDeviceToken(token) {
+		return nil, auth.ErrInvalidToken
+	}
+	d, err := a.manager.AuthenticateAccessToken(ctx, token)
+	if err != nil {
+		if errors.Is(err, ErrInvalidToken) {
+			return nil, fmt.Errorf("%w: %v", auth.ErrInvalidToken, err)
+		}
+		return nil, err
+	}
+	return &auth.Principal{
+		UserID:    d.UserID,
+		SessionID: d.ID,
+		Provider:  ProviderName,
+	}, nil
+}
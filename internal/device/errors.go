@@ -0,0 +1,34 @@
+package device
+
+import "errors"
+
+var (
+	// ErrUserCodeExhausted is returned when every generated user code collided with an existing one.
+	ErrUserCodeExhausted = errors.New("could not allocate a unique user code")
+	// ErrDeviceIDInUse is returned when starting a new authorization for a device_id that already has a live or linked record.
+	ErrDeviceIDInUse = errors.New("device_id already in use")
+	// ErrInvalidUserCode is returned when a user code does not exist.
+	ErrInvalidUserCode = errors.New("invalid user code")
+	// ErrUserCodeExpired is returned when a user code is past its expiry.
+	ErrUserCodeExpired = errors.New("user code expired")
+	// ErrExpiredNotActivated is returned when polling a code that expired before its tokens were issued.
+	ErrExpiredNotActivated = errors.New("user code expired and was not activated")
+	// ErrAlreadyActivated is returned when linking a code that is already bound to a user.
+	ErrAlreadyActivated = errors.New("user code already activated")
+	// ErrAuthorizationPending is returned while a code is waiting to be linked.
+	ErrAuthorizationPending = errors.New("token not yet available for this user code")
+	// ErrTokensAlreadyIssued is returned when polling a code whose tokens were already handed out.
+	ErrTokensAlreadyIssued = errors.New("tokens already issued for this user code")
+	// ErrInvalidDeviceCode is returned when polling with an unknown device_code.
+	ErrInvalidDeviceCode = errors.New("invalid device code")
+	// ErrSlowDown is returned when a device polls faster than its interval allows.
+	ErrSlowDown = errors.New("polling too frequently")
+	// ErrAccessDenied is returned once the user has rejected the authorization request.
+	ErrAccessDenied = errors.New("user denied the authorization request")
+	// ErrDeviceNotFound is returned when a linked device does not exist or belongs to another user.
+	ErrDeviceNotFound = errors.New("device not found")
+	// ErrInvalidDeviceName is returned when renaming a device to an empty or overlong name.
+	ErrInvalidDeviceName = errors.New("invalid device name")
+	// ErrInvalidToken is returned for unknown, expired or revoked device tokens.
+	ErrInvalidToken = errors.New("invalid device token")
+)
@@ -0,0 +1,6 @@
+package device
+
+// SetUserCodeGenerator replaces the user code generator so tests can force collisions.
+func SetUserCodeGenerator(m *Manager, f func() (string, error)) {
+	m.newUserCode = f
+}
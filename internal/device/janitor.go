@@ -0,0 +1,63 @@
+package device
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zjpiazza/plantastic/internal/models"
+)
+
+const (
+	// ExpiredCodeRetention is how long an unused user code is kept after it expires.
+	ExpiredCodeRetention = 24 * time.Hour
+	// IdleDeviceTimeout revokes linked devices that have not used their tokens for this long.
+	IdleDeviceTimeout = 90 * 24 * time.Hour
+	// RevokedDeviceRetention is how long revoked or dead devices stay visible before deletion.
+	RevokedDeviceRetention = 30 * 24 * time.Hour
+)
+
+// Janitor stats keys reported by RunJanitor.
+const (
+	StatExpiredCodesPurged = "expired_codes_purged"
+	StatIdleDevicesRevoked = "idle_devices_revoked"
+	StatDeadDevicesPurged  = "dead_devices_purged"
+)
+
+// RunJanitor performs one maintenance pass over the devices table:
+//   - deletes codes that expired without ever issuing tokens,
+//   - revokes linked devices idle for longer than IdleDeviceTimeout,
+//   - deletes devices revoked, or whose refresh token died, more than
+//     RevokedDeviceRetention ago.
+//
+// Its signature matches scheduler.JobFunc.
+func (m *Manager) RunJanitor(ctx context.Context) (map[string]int64, error) {
+	now := time.Now()
+	db := m.db.WithContext(ctx)
+	stats := map[string]int64{}
+
+	result := db.Where("tokens_issued_at IS NULL AND expires_at < ?", now.Add(-ExpiredCodeRetention)).
+		Delete(&models.Device{})
+	if result.Error != nil {
+		return stats, fmt.Errorf("failed to purge expired codes: %w", result.Error)
+	}
+	stats[StatExpiredCodesPurged] = result.RowsAffected
+
+	result = db.Model(&models.Device{}).
+		Where("tokens_issued_at IS NOT NULL AND revoked_at IS NULL AND COALESCE(last_used_at, tokens_issued_at) < ?", now.Add(-IdleDeviceTimeout)).
+		Updates(revokedColumns(now))
+	if result.Error != nil {
+		return stats, fmt.Errorf("failed to revoke idle devices: %w", result.Error)
+	}
+	stats[StatIdleDevicesRevoked] = result.RowsAffected
+
+	cutoff := now.Add(-RevokedDeviceRetention)
+	result = db.Where("revoked_at < ? OR refresh_token_expires_at < ?", cutoff, cutoff).
+		Delete(&models.Device{})
+	if result.Error != nil {
+		return stats, fmt.Errorf("failed to purge dead devices: %w", result.Error)
+	}
+	stats[StatDeadDevicesPurged] = result.RowsAffected
+
+	return stats, nil
+}
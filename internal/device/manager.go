@@ -1,11 +1,12 @@
 package device
 
 import (
+	"context"
+	"crypto/rand"
 	"fmt"
 	"strings"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/zjpiazza/plantastic/internal/models"
 	"gorm.io/gorm"
 )
@@ -15,114 +16,448 @@ const (
 	UserCodeDuration = 15 * time.Minute
 	// UserCodeFormat is the format string for generating user codes.
 	UserCodeFormat = "PLANT-%s"
+	// UserCodeAlphabet omits characters that are easy to misread (0/O, 1/I).
+	// Its 32 symbols divide 256 evenly, so mapping random bytes onto it is unbiased.
+	UserCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	// UserCodeLength is the number of random characters in a user code.
+	UserCodeLength = 6
+	// MaxUserCodeAttempts bounds retries when a generated user code is already taken.
+	MaxUserCodeAttempts = 5
+	// DefaultPollInterval is the minimum time a device must wait between token polls.
+	DefaultPollInterval = 5 * time.Second
+	// SlowDownIncrement is added to a device's interval each time it polls too fast (RFC 8628 section 3.5).
+	SlowDownIncrement = 5 * time.Second
+	// LastUsedResolution limits how often TouchDevice writes LastUsedAt for a busy device.
+	LastUsedResolution = time.Minute
+	// MaxDeviceNameLength bounds user-supplied device names.
+	MaxDeviceNameLength = 100
 )
 
+// ClientInfo describes the client a device request came from.
+type ClientInfo struct {
+	Name      string // Suggested device name; the user can rename it later
+	IPAddress string
+	UserAgent string
+}
+
+// Authorization is a newly started device authorization request
+// (RFC 8628 section 3.2). DeviceCode is secret to the device; UserCode is
+// what the user types into the web app.
+type Authorization struct {
+	DeviceCode string
+	UserCode   string
+	ExpiresAt  time.Time
+	Interval   time.Duration
+}
+
 // Manager handles the device linking flow using a database.
 type Manager struct {
-	db *gorm.DB
+	db          *gorm.DB
+	newUserCode func() (string, error)
 }
 
 // NewManager creates a new device manager with a database connection.
 func NewManager(db *gorm.DB) *Manager {
-	return &Manager{db: db}
+	return &Manager{db: db, newUserCode: GenerateUserCode}
+}
+
+// GenerateUserCode returns a random user code such as PLANT-K7XQ2M.
+func GenerateUserCode() (string, error) {
+	b := make([]byte, UserCodeLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate user code: %w", err)
+	}
+	for i := range b {
+		b[i] = UserCodeAlphabet[int(b[i])%len(UserCodeAlphabet)]
+	}
+	return fmt.Sprintf(UserCodeFormat, b), nil
+}
+
+// NormalizeUserCode canonicalizes a code typed by a user: surrounding
+// whitespace is dropped and letters are upper-cased.
+func NormalizeUserCode(userCode string) string {
+	return strings.ToUpper(strings.TrimSpace(userCode))
 }
 
-// NewCode generates a new user code for a given deviceID, stores it in the database,
-// and returns the user code.
-func (m *Manager) NewCode(deviceID string) (string, error) {
+// NewCode starts a device authorization for deviceID: it generates a device
+// code and user code, stores the record in the database, and returns both.
+// Only a hash of the device code is persisted. An earlier code for deviceID
+// that expired without being linked is replaced; any other existing record
+// yields ErrDeviceIDInUse.
+func (m *Manager) NewCode(ctx context.Context, deviceID string, client ClientInfo) (*Authorization, error) {
+	db := m.db.WithContext(ctx)
 	if deviceID == "" {
-		return "", fmt.Errorf("deviceID cannot be empty")
+		return nil, fmt.Errorf("deviceID cannot be empty")
 	}
 
-	userCode := fmt.Sprintf(UserCodeFormat, strings.ToUpper(uuid.New().String()[:6]))
+	deviceCode, err := randomToken("")
+	if err != nil {
+		return nil, err
+	}
 	expiresAt := time.Now().Add(UserCodeDuration)
 
-	deviceAuth := models.Device{
-		// ID will be set by BeforeCreate hook
-		DeviceID:  deviceID,
-		UserCode:  userCode,
-		ExpiresAt: expiresAt,
+	// User codes are short, so collisions with live codes are possible; the
+	// unique index on user_code detects them and we retry with a fresh code.
+	replaced := false
+	for attempt := 1; attempt <= MaxUserCodeAttempts; attempt++ {
+		userCode, err := m.newUserCode()
+		if err != nil {
+			return nil, err
+		}
+
+		deviceAuth := models.Device{
+			// ID will be set by BeforeCreate hook
+			DeviceID:       deviceID,
+			Name:           client.Name,
+			IPAddress:      client.IPAddress,
+			UserAgent:      client.UserAgent,
+			UserCode:       userCode,
+			DeviceCodeHash: HashToken(deviceCode),
+			ExpiresAt:      expiresAt,
+			PollInterval:   int(DefaultPollInterval.Seconds()),
+		}
+
+		err = db.Create(&deviceAuth).Error
+		if err == nil {
+			return &Authorization{
+				DeviceCode: deviceCode,
+				UserCode:   userCode,
+				ExpiresAt:  expiresAt,
+				Interval:   DefaultPollInterval,
+			}, nil
+		}
+		switch {
+		case isUniqueViolation(err, "user_code"):
+			continue
+		case isUniqueViolation(err, "device_id"):
+			if replaced {
+				return nil, ErrDeviceIDInUse
+			}
+			// A device retrying after its code expired unused takes its old row over.
+			ok, err := m.deleteExpiredCode(ctx, deviceID)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return nil, ErrDeviceIDInUse
+			}
+			replaced = true
+		default:
+			return nil, fmt.Errorf("failed to create device auth record: %w", err)
+		}
 	}
+	return nil, ErrUserCodeExhausted
+}
 
-	if err := m.db.Create(&deviceAuth).Error; err != nil {
-		// TODO: Handle potential UserCode collision more gracefully if relying on DB uniqueness,
-		// though UUID[:6] makes it rare. Could retry with a new code.
-		return "", fmt.Errorf("failed to create device auth record: %w", err)
+// deleteExpiredCode deletes deviceID's record if it is a code that expired
+// without ever being linked, and reports whether it did.
+func (m *Manager) deleteExpiredCode(ctx context.Context, deviceID string) (bool, error) {
+	result := m.db.WithContext(ctx).
+		Where("device_id = ? AND user_id = ? AND tokens_issued_at IS NULL AND expires_at < ?", deviceID, "", time.Now()).
+		Delete(&models.Device{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to replace expired code for device %s: %w", deviceID, result.Error)
 	}
-	return userCode, nil
+	return result.RowsAffected > 0, nil
+}
+
+// isUniqueViolation reports whether err is a unique constraint violation on
+// column. It matches driver messages, as storage.ParseDatabaseError does:
+// PostgreSQL says `duplicate key ... "idx_devices_user_code"`, SQLite says
+// `UNIQUE constraint failed: devices.user_code`.
+func isUniqueViolation(err error, column string) bool {
+	msg := strings.ToLower(err.Error())
+	return (strings.Contains(msg, "duplicate key") || strings.Contains(msg, "unique constraint failed")) &&
+		strings.Contains(msg, column)
 }
 
 // ValidateUserCode checks if a user code is valid (exists and not expired).
 // It returns the device record if valid.
-func (m *Manager) ValidateUserCode(userCode string) (*models.Device, error) {
+func (m *Manager) ValidateUserCode(ctx context.Context, userCode string) (*models.Device, error) {
+	userCode = NormalizeUserCode(userCode)
 	var deviceAuth models.Device
-	err := m.db.Where("user_code = ?", userCode).First(&deviceAuth).Error
+	err := m.db.WithContext(ctx).Where("user_code = ?", userCode).First(&deviceAuth).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("invalid user code")
+			return nil, ErrInvalidUserCode
 		}
 		return nil, fmt.Errorf("database error validating user code: %w", err)
 	}
 
 	if time.Now().After(deviceAuth.ExpiresAt) {
-		// Optionally, clean up expired codes here or via a background job.
-		return nil, fmt.Errorf("user code expired")
+		// Expired codes are purged in the background by RunJanitor.
+		return nil, ErrUserCodeExpired
 	}
 
-	if deviceAuth.Token != "" {
-		// Code has already been used to successfully link and get a token.
-		// Depending on desired behavior, this could be an error or still considered valid.
-		// For now, let's treat it as an error for subsequent validation attempts once a token is set.
-		return nil, fmt.Errorf("user code already activated")
+	if deviceAuth.UserID != "" || deviceAuth.DeniedAt != nil {
+		// Code has already been approved or denied; it cannot be claimed twice.
+		return nil, ErrAlreadyActivated
 	}
 
 	return &deviceAuth, nil
 }
 
-// SetTokenForUserCode associates a session token with a user code if the code is valid.
-func (m *Manager) SetTokenForUserCode(userCode string, token string) error {
-	if userCode == "" || token == "" {
-		return fmt.Errorf("userCode and token cannot be empty")
+// LinkUserCode binds a valid, unactivated user code to userID. The device
+// collects its tokens on its next poll.
+func (m *Manager) LinkUserCode(ctx context.Context, userCode string, userID string) error {
+	userCode = NormalizeUserCode(userCode)
+	if userCode == "" || userID == "" {
+		return fmt.Errorf("userCode and userID cannot be empty")
 	}
 
-	// Validate the code first (exists, not expired, not already activated with a token)
-	// ValidateUserCode now returns an error if already activated, so we don't need to check Token != "" here
-	deviceAuth, err := m.ValidateUserCode(userCode)
-	if err != nil {
-		return fmt.Errorf("cannot set token: %w", err)
+	// Validate the code first (exists, not expired, not already activated)
+	if _, err := m.ValidateUserCode(ctx, userCode); err != nil {
+		return fmt.Errorf("cannot link device: %w", err)
+	}
+
+	now := time.Now()
+	// The user_id/denied_at guard makes concurrent link attempts race safely: only one wins.
+	result := m.db.WithContext(ctx).Model(&models.Device{}).
+		Where("user_code = ? AND user_id = ? AND denied_at IS NULL", userCode, "").
+		Updates(map[string]interface{}{"user_id": userID, "activated_at": now})
+	if result.Error != nil {
+		return fmt.Errorf("failed to link user code %s: %w", userCode, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("cannot link device: %w", ErrAlreadyActivated)
 	}
-	// If ValidateUserCode allowed already activated codes, we would check deviceAuth.Token == "" here.
+	return nil
+}
 
-	result := m.db.Model(&deviceAuth).Where("user_code = ?", userCode).Update("token", token)
+// DenyUserCode records that the user rejected the authorization request, so
+// the device's next poll fails with ErrAccessDenied.
+func (m *Manager) DenyUserCode(ctx context.Context, userCode string) error {
+	userCode = NormalizeUserCode(userCode)
+	if _, err := m.ValidateUserCode(ctx, userCode); err != nil {
+		return fmt.Errorf("cannot deny device: %w", err)
+	}
+	result := m.db.WithContext(ctx).Model(&models.Device{}).
+		Where("user_code = ? AND user_id = ? AND denied_at IS NULL", userCode, "").
+		Update("denied_at", time.Now())
 	if result.Error != nil {
-		return fmt.Errorf("failed to update token for user code %s: %w", userCode, result.Error)
+		return fmt.Errorf("failed to deny user code %s: %w", userCode, result.Error)
 	}
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("no device record found for user code %s to update token (should not happen after ValidateUserCode)", userCode)
+		return fmt.Errorf("cannot deny device: %w", ErrAlreadyActivated)
 	}
 	return nil
 }
 
-// GetTokenForUserCode retrieves the session token for a user code if it has been set and the code is valid.
-func (m *Manager) GetTokenForUserCode(userCode string) (string, error) {
+// PollDeviceCode is the device side of the grant (RFC 8628 section 3.4). Once
+// the user code has been linked it mints the device's first access/refresh
+// token pair; the raw tokens are returned exactly once. Until then it returns
+// ErrAuthorizationPending, and ErrSlowDown if the device polls faster than
+// its interval, which also widens the interval.
+func (m *Manager) PollDeviceCode(ctx context.Context, deviceCode string) (*TokenPair, error) {
+	db := m.db.WithContext(ctx)
+	if deviceCode == "" {
+		return nil, ErrInvalidDeviceCode
+	}
+
 	var deviceAuth models.Device
-	err := m.db.Where("user_code = ?", userCode).First(&deviceAuth).Error
+	err := db.Where("device_code_hash = ?", HashToken(deviceCode)).First(&deviceAuth).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return "", fmt.Errorf("invalid user code")
+			return nil, ErrInvalidDeviceCode
 		}
-		return "", fmt.Errorf("database error retrieving token: %w", err)
+		return nil, fmt.Errorf("database error retrieving device: %w", err)
+	}
+	if deviceAuth.TokensIssuedAt != nil {
+		return nil, ErrTokensAlreadyIssued
 	}
 
-	// It's okay if it's "expired" for polling, as long as a token was set before expiry.
-	// However, if a token was never set, and it's now past ExpiresAt, the user can't activate it anymore.
-	if deviceAuth.Token == "" && time.Now().After(deviceAuth.ExpiresAt) {
-		return "", fmt.Errorf("user code expired and was not activated")
+	now := time.Now()
+	// The device code dies at expires_in whether or not anyone linked it.
+	if now.After(deviceAuth.ExpiresAt) {
+		return nil, ErrExpiredNotActivated
+	}
+	interval := time.Duration(deviceAuth.PollInterval) * time.Second
+	if deviceAuth.LastPolledAt != nil && now.Sub(*deviceAuth.LastPolledAt) < interval {
+		newInterval := interval + SlowDownIncrement
+		if err := db.Model(&models.Device{}).Where("id = ?", deviceAuth.ID).
+			Updates(map[string]interface{}{"poll_interval": int(newInterval.Seconds()), "last_polled_at": now}).Error; err != nil {
+			return nil, fmt.Errorf("failed to record poll: %w", err)
+		}
+		return nil, ErrSlowDown
+	}
+	if err := db.Model(&models.Device{}).Where("id = ?", deviceAuth.ID).Update("last_polled_at", now).Error; err != nil {
+		return nil, fmt.Errorf("failed to record poll: %w", err)
 	}
 
-	if deviceAuth.Token == "" {
-		return "", fmt.Errorf("token not yet available for this user code") // Or a specific error/nil to indicate pending
+	if deviceAuth.DeniedAt != nil {
+		return nil, ErrAccessDenied
+	}
+	if deviceAuth.UserID == "" {
+		return nil, ErrAuthorizationPending
 	}
 
-	return deviceAuth.Token, nil
+	pair, err := newTokenPair(now)
+	if err != nil {
+		return nil, err
+	}
+	result := db.Model(&models.Device{}).
+		Where("id = ? AND tokens_issued_at IS NULL", deviceAuth.ID).
+		Updates(tokenColumns(pair, map[string]interface{}{"tokens_issued_at": now}))
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to store tokens for device %s: %w", deviceAuth.DeviceID, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		// A concurrent poll issued the tokens first.
+		return nil, ErrTokensAlreadyIssued
+	}
+	return pair, nil
+}
+
+// RefreshTokens exchanges a valid refresh token for a new token pair. Both
+// tokens are rotated, so the old refresh token stops working immediately.
+func (m *Manager) RefreshTokens(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	if !strings.HasPrefix(refreshToken, RefreshTokenPrefix) {
+		return nil, ErrInvalidToken
+	}
+
+	var deviceAuth models.Device
+	err := m.db.WithContext(ctx).Where("refresh_token_hash = ?", HashToken(refreshToken)).First(&deviceAuth).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrInvalidToken
+		}
+		return nil, fmt.Errorf("database error refreshing token: %w", err)
+	}
+	now := time.Now()
+	if deviceAuth.RevokedAt != nil || deviceAuth.RefreshTokenExpiresAt == nil || now.After(*deviceAuth.RefreshTokenExpiresAt) {
+		return nil, ErrInvalidToken
+	}
+
+	pair, err := newTokenPair(now)
+	if err != nil {
+		return nil, err
+	}
+	// Matching on the old hash makes rotation single-use under concurrent refreshes.
+	result := m.db.WithContext(ctx).Model(&models.Device{}).
+		Where("id = ? AND refresh_token_hash = ?", deviceAuth.ID, deviceAuth.RefreshTokenHash).
+		Updates(tokenColumns(pair, nil))
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to rotate tokens: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrInvalidToken
+	}
+	return pair, nil
+}
+
+// AuthenticateAccessToken returns the linked device an access token belongs to.
+func (m *Manager) AuthenticateAccessToken(ctx context.Context, accessToken string) (*models.Device, error) {
+	if !strings.HasPrefix(accessToken, AccessTokenPrefix) {
+		return nil, ErrInvalidToken
+	}
+
+	var deviceAuth models.Device
+	err := m.db.WithContext(ctx).Where("access_token_hash = ?", HashToken(accessToken)).First(&deviceAuth).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrInvalidToken
+		}
+		return nil, fmt.Errorf("database error authenticating device: %w", err)
+	}
+	if deviceAuth.UserID == "" || deviceAuth.RevokedAt != nil || deviceAuth.AccessTokenExpiresAt == nil || time.Now().After(*deviceAuth.AccessTokenExpiresAt) {
+		return nil, ErrInvalidToken
+	}
+	return &deviceAuth, nil
+}
+
+// ListDevices returns the devices linked to userID that have not been revoked,
+// most recently linked first.
+func (m *Manager) ListDevices(ctx context.Context, userID string) ([]models.Device, error) {
+	var devices []models.Device
+	err := m.db.WithContext(ctx).Where("user_id = ? AND tokens_issued_at IS NOT NULL AND revoked_at IS NULL", userID).
+		Order("activated_at DESC").
+		Find(&devices).Error
+	if err != nil {
+		return nil, fmt.Errorf("database error listing devices: %w", err)
+	}
+	return devices, nil
+}
+
+// RenameDevice sets the display name of one of userID's linked devices.
+func (m *Manager) RenameDevice(ctx context.Context, userID, id, name string) (*models.Device, error) {
+	db := m.db.WithContext(ctx)
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > MaxDeviceNameLength {
+		return nil, fmt.Errorf("%w: name must be 1-%d characters", ErrInvalidDeviceName, MaxDeviceNameLength)
+	}
+
+	result := db.Model(&models.Device{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("name", name)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to rename device: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrDeviceNotFound
+	}
+
+	var deviceAuth models.Device
+	if err := db.Where("id = ?", id).First(&deviceAuth).Error; err != nil {
+		return nil, fmt.Errorf("database error reloading device: %w", err)
+	}
+	return &deviceAuth, nil
+}
+
+// RevokeDevice unlinks one of userID's devices. Its token hashes are cleared,
+// so both its access and refresh tokens stop working immediately.
+func (m *Manager) RevokeDevice(ctx context.Context, userID, id string) error {
+	result := m.db.WithContext(ctx).Model(&models.Device{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Updates(revokedColumns(time.Now()))
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke device: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrDeviceNotFound
+	}
+	return nil
+}
+
+// TouchDevice records that device id was just used from client. Writes are
+// skipped if LastUsedAt is newer than LastUsedResolution, so busy devices
+// don't cost a write per request.
+func (m *Manager) TouchDevice(ctx context.Context, id string, client ClientInfo) error {
+	now := time.Now()
+	err := m.db.WithContext(ctx).Model(&models.Device{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, now.Add(-LastUsedResolution)).
+		Updates(map[string]interface{}{
+			"last_used_at": now,
+			"ip_address":   client.IPAddress,
+			"user_agent":   client.UserAgent,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to record device use: %w", err)
+	}
+	return nil
+}
+
+// revokedColumns returns the column updates that revoke a device at now and
+// forget its token hashes.
+func revokedColumns(now time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"revoked_at":               now,
+		"access_token_hash":        "",
+		"refresh_token_hash":       "",
+		"access_token_expires_at":  nil,
+		"refresh_token_expires_at": nil,
+	}
+}
+
+// tokenColumns returns the column updates that store pair's hashes and expiries.
+func tokenColumns(pair *TokenPair, extra map[string]interface{}) map[string]interface{} {
+	columns := map[string]interface{}{
+		"access_token_hash":        HashToken(pair.AccessToken),
+		"refresh_token_hash":       HashToken(pair.RefreshToken),
+		"access_token_expires_at":  pair.AccessTokenExpiresAt,
+		"refresh_token_expires_at": pair.RefreshTokenExpiresAt,
+	}
+	for k, v := range extra {
+		columns[k] = v
+	}
+	return columns
 }
@@ -0,0 +1,131 @@
+package device_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zjpiazza/plantastic/internal/device"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var insertDevice = regexp.QuoteMeta(`INSERT INTO "devices"`)
+
+func newMockManager(t *testing.T) (*device.Manager, sqlmock.Sqlmock) {
+	sqlDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	require.NoError(t, err, "Failed to create sqlmock")
+	t.Cleanup(func() { sqlDB.Close() })
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{
+		Conn:                 sqlDB,
+		PreferSimpleProtocol: true,
+	}), &gorm.Config{})
+	require.NoError(t, err, "Failed to open gorm with mock connection")
+
+	return device.NewManager(gormDB), mock
+}
+
+// sequence returns a generator yielding codes in order.
+func sequence(codes ...string) func() (string, error) {
+	i := 0
+	return func() (string, error) {
+		code := codes[i]
+		i++
+		return code, nil
+	}
+}
+
+func expectInsert(mock sqlmock.Sqlmock, err error) {
+	mock.ExpectBegin()
+	if err != nil {
+		mock.ExpectExec(insertDevice).WillReturnError(err)
+		mock.ExpectRollback()
+		return
+	}
+	mock.ExpectExec(insertDevice).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+}
+
+func TestNewCode_RetriesOnUserCodeCollision(t *testing.T) {
+	m, mock := newMockManager(t)
+	device.SetUserCodeGenerator(m, sequence("PLANT-AAAAAA", "PLANT-BBBBBB"))
+
+	expectInsert(mock, errors.New(`ERROR: duplicate key value violates unique constraint "idx_devices_user_code" (SQLSTATE 23505)`))
+	expectInsert(mock, nil)
+
+	authorization, err := m.NewCode(context.Background(), "device-1", device.ClientInfo{Name: "laptop"})
+	require.NoError(t, err)
+	assert.Equal(t, "PLANT-BBBBBB", authorization.UserCode)
+	assert.NotEmpty(t, authorization.DeviceCode)
+	assert.Equal(t, device.DefaultPollInterval, authorization.Interval)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNewCode_GivesUpAfterMaxAttempts(t *testing.T) {
+	m, mock := newMockManager(t)
+	device.SetUserCodeGenerator(m, func() (string, error) { return "PLANT-AAAAAA", nil })
+
+	for i := 0; i < device.MaxUserCodeAttempts; i++ {
+		expectInsert(mock, errors.New(`UNIQUE constraint failed: devices.user_code`))
+	}
+
+	_, err := m.NewCode(context.Background(), "device-1", device.ClientInfo{})
+	assert.ErrorIs(t, err, device.ErrUserCodeExhausted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNewCode_LiveDeviceIDConflictIsNotRetried(t *testing.T) {
+	m, mock := newMockManager(t)
+	device.SetUserCodeGenerator(m, sequence("PLANT-AAAAAA"))
+
+	expectInsert(mock, errors.New(`ERROR: duplicate key value violates unique constraint "idx_devices_device_id" (SQLSTATE 23505)`))
+	// The existing record is still live or linked, so there is nothing to replace.
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "devices" WHERE device_id = $1 AND user_id = $2 AND tokens_issued_at IS NULL AND expires_at < $3`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	_, err := m.NewCode(context.Background(), "device-1", device.ClientInfo{})
+	assert.ErrorIs(t, err, device.ErrDeviceIDInUse)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNewCode_OtherErrorsAreReturned(t *testing.T) {
+	m, mock := newMockManager(t)
+	device.SetUserCodeGenerator(m, sequence("PLANT-AAAAAA"))
+
+	expectInsert(mock, errors.New("connection reset"))
+
+	_, err := m.NewCode(context.Background(), "device-1", device.ClientInfo{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "connection reset")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGenerateUserCode_UsesUnambiguousAlphabet(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		code, err := device.GenerateUserCode()
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(code, "PLANT-"), code)
+
+		suffix := strings.TrimPrefix(code, "PLANT-")
+		require.Len(t, suffix, device.UserCodeLength)
+		for _, r := range suffix {
+			assert.True(t, strings.ContainsRune(device.UserCodeAlphabet, r), "unexpected %q in %s", r, code)
+		}
+		assert.NotContains(t, suffix, "0")
+		assert.NotContains(t, suffix, "O")
+		assert.NotContains(t, suffix, "1")
+		assert.NotContains(t, suffix, "I")
+	}
+}
+
+func TestNormalizeUserCode(t *testing.T) {
+	assert.Equal(t, "PLANT-K7XQ2M", device.NormalizeUserCode("  plant-k7xq2m\n"))
+}
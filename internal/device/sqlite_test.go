@@ -0,0 +1,182 @@
+package device_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zjpiazza/plantastic/internal/database"
+	"github.com/zjpiazza/plantastic/internal/device"
+	"github.com/zjpiazza/plantastic/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newSQLiteManager returns a Manager backed by a migrated in-memory SQLite
+// database, along with the database for arranging and inspecting rows.
+func newSQLiteManager(t *testing.T) (*device.Manager, *gorm.DB) {
+	t.Helper()
+	db, err := database.Open(database.Config{Driver: database.DriverSQLite, DSN: database.Memory}, &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+	_, err = database.MigrateUp(db)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return device.NewManager(db), db
+}
+
+// expireCode moves the expiry of the code for deviceID into the past.
+func expireCode(t *testing.T, db *gorm.DB, deviceID string) {
+	t.Helper()
+	require.NoError(t, db.Model(&models.Device{}).Where("device_id = ?", deviceID).
+		Update("expires_at", time.Now().Add(-time.Minute)).Error)
+}
+
+func TestSQLite_PollDeviceCode_LinkedCodeExpires(t *testing.T) {
+	m, db := newSQLiteManager(t)
+	ctx := context.Background()
+
+	auth, err := m.NewCode(ctx, "device-1", device.ClientInfo{})
+	require.NoError(t, err)
+	require.NoError(t, m.LinkUserCode(ctx, auth.UserCode, "user-1"))
+	expireCode(t, db, "device-1")
+
+	pair, err := m.PollDeviceCode(ctx, auth.DeviceCode)
+	assert.ErrorIs(t, err, device.ErrExpiredNotActivated)
+	assert.Nil(t, pair)
+}
+
+func TestSQLite_NewCode_ReplacesExpiredUnlinkedCode(t *testing.T) {
+	m, db := newSQLiteManager(t)
+	ctx := context.Background()
+
+	first, err := m.NewCode(ctx, "device-1", device.ClientInfo{})
+	require.NoError(t, err)
+	expireCode(t, db, "device-1")
+
+	second, err := m.NewCode(ctx, "device-1", device.ClientInfo{})
+	require.NoError(t, err)
+	assert.NotEqual(t, first.UserCode, second.UserCode)
+
+	var count int64
+	require.NoError(t, db.Model(&models.Device{}).Where("device_id = ?", "device-1").Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+	_, err = m.PollDeviceCode(ctx, first.DeviceCode)
+	assert.ErrorIs(t, err, device.ErrInvalidDeviceCode, "the replaced device code must stop working")
+	_, err = m.ValidateUserCode(ctx, second.UserCode)
+	assert.NoError(t, err)
+}
+
+func TestSQLite_NewCode_KeepsLiveOrLinkedRecords(t *testing.T) {
+	m, db := newSQLiteManager(t)
+	ctx := context.Background()
+
+	_, err := m.NewCode(ctx, "live", device.ClientInfo{})
+	require.NoError(t, err)
+	_, err = m.NewCode(ctx, "live", device.ClientInfo{})
+	assert.ErrorIs(t, err, device.ErrDeviceIDInUse)
+
+	linked, err := m.NewCode(ctx, "linked", device.ClientInfo{})
+	require.NoError(t, err)
+	require.NoError(t, m.LinkUserCode(ctx, linked.UserCode, "user-1"))
+	expireCode(t, db, "linked")
+	_, err = m.NewCode(ctx, "linked", device.ClientInfo{})
+	assert.ErrorIs(t, err, device.ErrDeviceIDInUse)
+}
+
+func TestSQLite_TouchDevice_ThrottlesWrites(t *testing.T) {
+	m, db := newSQLiteManager(t)
+	ctx := context.Background()
+
+	_, err := m.NewCode(ctx, "device-1", device.ClientInfo{IPAddress: "10.0.0.1"})
+	require.NoError(t, err)
+	var d models.Device
+	require.NoError(t, db.Where("device_id = ?", "device-1").First(&d).Error)
+
+	require.NoError(t, m.TouchDevice(ctx, d.ID, device.ClientInfo{IPAddress: "10.0.0.2", UserAgent: "cli/1"}))
+	require.NoError(t, db.First(&d, "id = ?", d.ID).Error)
+	require.NotNil(t, d.LastUsedAt)
+	assert.Equal(t, "10.0.0.2", d.IPAddress)
+	assert.Equal(t, "cli/1", d.UserAgent)
+	firstUse := *d.LastUsedAt
+
+	// Within LastUsedResolution the row is left alone.
+	require.NoError(t, m.TouchDevice(ctx, d.ID, device.ClientInfo{IPAddress: "10.0.0.3"}))
+	require.NoError(t, db.First(&d, "id = ?", d.ID).Error)
+	assert.True(t, firstUse.Equal(*d.LastUsedAt))
+	assert.Equal(t, "10.0.0.2", d.IPAddress)
+
+	// Once it is older than that, the next use is recorded.
+	require.NoError(t, db.Model(&d).Update("last_used_at", time.Now().Add(-2*device.LastUsedResolution)).Error)
+	require.NoError(t, m.TouchDevice(ctx, d.ID, device.ClientInfo{IPAddress: "10.0.0.3"}))
+	require.NoError(t, db.First(&d, "id = ?", d.ID).Error)
+	assert.Equal(t, "10.0.0.3", d.IPAddress)
+}
+
+func TestSQLite_RunJanitor(t *testing.T) {
+	m, db := newSQLiteManager(t)
+	now := time.Now()
+	ago := func(d time.Duration) *time.Time {
+		at := now.Add(-d)
+		return &at
+	}
+	live := now.Add(time.Hour)
+	rows := []models.Device{
+		// Codes that never issued tokens: purged once past ExpiredCodeRetention
+		{DeviceID: "stale-code", ExpiresAt: *ago(device.ExpiredCodeRetention + time.Hour)},
+		{DeviceID: "stale-linked-code", UserID: "user-1", ExpiresAt: *ago(device.ExpiredCodeRetention + time.Hour)},
+		{DeviceID: "recent-code", ExpiresAt: *ago(time.Hour)},
+		{DeviceID: "pending-code", ExpiresAt: live},
+		// Linked devices: revoked once idle for IdleDeviceTimeout
+		{DeviceID: "idle", UserID: "user-1", TokensIssuedAt: ago(device.IdleDeviceTimeout + time.Hour), RefreshTokenExpiresAt: &live},
+		{DeviceID: "idle-used-once", UserID: "user-1", TokensIssuedAt: ago(2 * device.IdleDeviceTimeout), LastUsedAt: ago(device.IdleDeviceTimeout + time.Hour), RefreshTokenExpiresAt: &live},
+		{DeviceID: "active", UserID: "user-1", TokensIssuedAt: ago(2 * device.IdleDeviceTimeout), LastUsedAt: ago(time.Hour), RefreshTokenExpiresAt: &live},
+		// Dead devices: purged once past RevokedDeviceRetention
+		{DeviceID: "long-revoked", UserID: "user-1", TokensIssuedAt: ago(time.Hour), RevokedAt: ago(device.RevokedDeviceRetention + time.Hour)},
+		{DeviceID: "long-dead-refresh", UserID: "user-1", TokensIssuedAt: ago(time.Hour), LastUsedAt: ago(time.Hour), RefreshTokenExpiresAt: ago(device.RevokedDeviceRetention + time.Hour)},
+		{DeviceID: "recently-revoked", UserID: "user-1", TokensIssuedAt: ago(time.Hour), RevokedAt: ago(time.Hour)},
+	}
+	for i := range rows {
+		rows[i].UserCode = rows[i].DeviceID
+		require.NoError(t, db.Create(&rows[i]).Error)
+	}
+
+	stats, err := m.RunJanitor(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{
+		device.StatExpiredCodesPurged: 2,
+		device.StatIdleDevicesRevoked: 2,
+		device.StatDeadDevicesPurged:  2,
+	}, stats)
+
+	var remaining []models.Device
+	require.NoError(t, db.Order("device_id").Find(&remaining).Error)
+	revoked := map[string]bool{}
+	for _, d := range remaining {
+		revoked[d.DeviceID] = d.RevokedAt != nil
+	}
+	assert.Equal(t, map[string]bool{
+		"recent-code":      false,
+		"pending-code":     false,
+		"idle":             true,
+		"idle-used-once":   true,
+		"active":           false,
+		"recently-revoked": true,
+	}, revoked)
+
+	// A second pass has nothing left to do.
+	stats, err = m.RunJanitor(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{
+		device.StatExpiredCodesPurged: 0,
+		device.StatIdleDevicesRevoked: 0,
+		device.StatDeadDevicesPurged:  0,
+	}, stats)
+}
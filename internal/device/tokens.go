@@ -0,0 +1,75 @@
+package device
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// TokenPrefix marks every token minted by the device flow, so middleware can
+	// route it to the device authenticator without asking other providers first.
+	TokenPrefix = "plt_"
+	// AccessTokenPrefix prefixes device access tokens.
+	AccessTokenPrefix = TokenPrefix + "at_"
+	// RefreshTokenPrefix prefixes device refresh tokens.
+	RefreshTokenPrefix = TokenPrefix + "rt_"
+
+	// AccessTokenDuration defines how long a device access token is valid.
+	AccessTokenDuration = time.Hour
+	// RefreshTokenDuration defines how long a device refresh token is valid.
+	RefreshTokenDuration = 30 * 24 * time.Hour
+
+	tokenBytes = 32
+)
+
+// TokenPair is an access + refresh token pair handed to a linked device.
+// The raw values are only available at issue time; the database stores hashes.
+type TokenPair struct {
+	AccessToken           string
+	RefreshToken          string
+	AccessTokenExpiresAt  time.Time
+	RefreshTokenExpiresAt time.Time
+}
+
+// IsDeviceToken reports whether token looks like a device-flow token.
+func IsDeviceToken(token string) bool {
+	return strings.HasPrefix(token, TokenPrefix)
+}
+
+// HashToken returns the hex SHA-256 of token, which is what gets stored.
+// Tokens carry 256 bits of randomness, so a fast unsalted hash is sufficient.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// newTokenPair generates a fresh random token pair valid from now.
+func newTokenPair(now time.Time) (*TokenPair, error) {
+	access, err := randomToken(AccessTokenPrefix)
+	if err != nil {
+		return nil, err
+	}
+	refresh, err := randomToken(RefreshTokenPrefix)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenPair{
+		AccessToken:           access,
+		RefreshToken:          refresh,
+		AccessTokenExpiresAt:  now.Add(AccessTokenDuration),
+		RefreshTokenExpiresAt: now.Add(RefreshTokenDuration),
+	}, nil
+}
+
+func randomToken(prefix string) (string, error) {
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return prefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Kinds of actor recorded in the audit log
+const (
+	ActorUser   = "user"   // A signed-in user; ActorID is their auth subject
+	ActorDevice = "device" // A linked device; ActorID is the device's ID
+	ActorSystem = "system" // A background job, such as the trash purge
+)
+
+// Actions recorded in the audit log
+const (
+	AuditCreate  = "create"
+	AuditUpdate  = "update"
+	AuditDelete  = "delete"  // Moved to the trash, or deleted outright for entities without one
+	AuditRestore = "restore" // Taken out of the trash
+	AuditPurge   = "purge"   // Deleted for good from the trash
+)
+
+// AuditEntry records one change to an entity: who made it, and the fields
+// it changed. Entries are never updated or deleted.
+type AuditEntry struct {
+	ID        string      `json:"id"`
+	UserID    string      `json:"user_id"` // Owner of the entity
+	ActorType string      `json:"actor_type"`
+	ActorID   string      `json:"actor_id,omitempty"`
+	Entity    string      `json:"entity"` // "garden", "bed", "task", "plant" or "planting"
+	EntityID  string      `json:"entity_id"`
+	Action    string      `json:"action"`
+	Before    AuditValues `json:"before,omitempty" gorm:"column:before_values;type:text"` // The changed fields before; the whole entity for a delete
+	After     AuditValues `json:"after,omitempty" gorm:"column:after_values;type:text"`   // The changed fields after; the whole entity for a create
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// AuditValues maps an entity's JSON field names to their values. It is
+// stored as a JSON document.
+type AuditValues map[string]any
+
+// Value implements driver.Valuer.
+func (v AuditValues) Value() (driver.Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner.
+func (v *AuditValues) Scan(src any) error {
+	switch src := src.(type) {
+	case nil:
+		*v = nil
+		return nil
+	case string:
+		return json.Unmarshal([]byte(src), v)
+	case []byte:
+		return json.Unmarshal(src, v)
+	}
+	return fmt.Errorf("cannot scan %T into AuditValues", src)
+}
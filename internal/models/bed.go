@@ -4,19 +4,23 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // Bed represents a garden bed
 type Bed struct {
-	ID        string    `json:"id"`
-	GardenID  string    `json:"garden_id"` // Foreign key to Garden
-	Name      string    `json:"name"`
-	Type      string    `json:"type"`
-	Size      string    `json:"size"`
-	SoilType  string    `json:"soil_type"`
-	Notes     string    `json:"notes"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        string         `json:"id"`
+	UserID    string         `json:"user_id"`   // Owner, inherited from the parent Garden
+	GardenID  string         `json:"garden_id"` // Foreign key to Garden
+	Name      string         `json:"name"`
+	Type      string         `json:"type"`
+	Size      string         `json:"size"`
+	SoilType  string         `json:"soil_type"`
+	Notes     string         `json:"notes"`
+	Version   int            `json:"version" gorm:"not null;default:1"` // See Garden.Version
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-"` // See Garden.DeletedAt
 }
 
 // NewBed creates a new Bed with default values
@@ -30,6 +34,7 @@ func NewBed(gardenID, name, bedType, size, soilType, notes string) Bed {
 		Size:      size,
 		SoilType:  soilType,
 		Notes:     notes,
+		Version:   1,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
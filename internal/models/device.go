@@ -9,15 +9,32 @@ import (
 
 // Device represents a device authentication record in the database.
 type Device struct {
-	ID         string     `gorm:"type:uuid;primary_key;" json:"id"`      // Unique identifier for the device record
-	UserCode   string     `gorm:"uniqueIndex;not null" json:"user_code"` // The code shown to the user (e.g., PLANT-XXXXXX)
-	DeviceID   string     `gorm:"uniqueIndex;not null" json:"device_id"` // A more permanent ID for the device being linked (could be user-generated or auto) - Placeholder for now
-	Token      string     `json:"token"`                                 // The session token once authenticated
-	CreatedAt  time.Time  `json:"created_at"`
-	UpdatedAt  time.Time  `json:"updated_at"`
-	ExpiresAt  time.Time  `json:"expires_at"`             // When the user_code or linking process expires
-	LastUsedAt *time.Time `json:"last_used_at,omitempty"` // Optional: track when the token was last used
-	// UserID    string    `json:"user_id"` // Optional: to link to a user if you have a users table
+	ID             string     `gorm:"type:uuid;primary_key;" json:"id"`      // Unique identifier for the device record
+	UserCode       string     `gorm:"uniqueIndex;not null" json:"user_code"` // The code shown to the user (e.g., PLANT-XXXXXX)
+	DeviceCodeHash string     `gorm:"index" json:"-"`                        // SHA-256 of the secret device_code the device polls with
+	DeviceID       string     `gorm:"uniqueIndex;not null" json:"device_id"` // A more permanent ID for the device being linked (could be user-generated or auto) - Placeholder for now
+	UserID         string     `gorm:"index" json:"user_id"`                  // Auth subject the device is linked to, set when the user code is activated
+	Name           string     `json:"name"`                                  // User-editable label, e.g. "Work laptop"
+	IPAddress      string     `json:"ip_address"`                            // Address the device last connected from
+	UserAgent      string     `json:"user_agent"`                            // User-Agent the device last connected with
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	ExpiresAt      time.Time  `json:"expires_at"`             // When the user_code or linking process expires
+	LastUsedAt     *time.Time `json:"last_used_at,omitempty"` // Optional: track when the token was last used
+
+	// Plantastic-issued tokens, stored as SHA-256 hashes and never serialized
+	AccessTokenHash       string     `gorm:"index" json:"-"`
+	RefreshTokenHash      string     `gorm:"index" json:"-"`
+	AccessTokenExpiresAt  *time.Time `json:"access_token_expires_at,omitempty"`
+	RefreshTokenExpiresAt *time.Time `json:"refresh_token_expires_at,omitempty"`
+	ActivatedAt           *time.Time `json:"activated_at,omitempty"`     // When a user linked the code
+	TokensIssuedAt        *time.Time `json:"tokens_issued_at,omitempty"` // When the device collected its first token pair
+	DeniedAt              *time.Time `json:"denied_at,omitempty"`        // When the user rejected the request (access_denied)
+	RevokedAt             *time.Time `json:"revoked_at,omitempty"`       // When the user unlinked the device; its tokens stop working
+
+	// Polling state for the device grant (RFC 8628 section 3.5)
+	PollInterval int        `gorm:"not null;default:5" json:"-"` // Minimum seconds between polls; raised on slow_down
+	LastPolledAt *time.Time `json:"-"`
 }
 
 // BeforeCreate will set a UUID for the ID if it's not set.
@@ -4,16 +4,20 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // Garden represents a garden in the system
 type Garden struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Location    string    `json:"location"`
-	Description string    `json:"description"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          string         `json:"id"`
+	UserID      string         `json:"user_id"` // Owner (auth subject) of the garden
+	Name        string         `json:"name"`
+	Location    string         `json:"location"`
+	Description string         `json:"description"`
+	Version     int            `json:"version" gorm:"not null;default:1"` // Starts at 1 and increases on every update; the API serves it as the ETag
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-"` // Set while the garden is in the trash; GORM leaves such rows out of queries
 }
 
 // NewGarden creates a new Garden with default values
@@ -24,6 +28,7 @@ func NewGarden(name, location, description string) Garden {
 		Name:        name,
 		Location:    location,
 		Description: description,
+		Version:     1,
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// IdempotencyRecord holds the response to a mutating API request sent with an
+// Idempotency-Key header, so that a retry of the request is answered with the
+// same response instead of being applied again. Keys are scoped to the user.
+type IdempotencyRecord struct {
+	UserID      string `gorm:"primaryKey"`
+	Key         string `gorm:"primaryKey;column:idempotency_key"`
+	RequestHash string `gorm:"not null"` // SHA-256 of the method, URL and body of the first request
+	StatusCode  int    `gorm:"not null"` // 0 while the first request is still being handled
+
+	// The stored response
+	ContentType string
+	ETag        string `gorm:"column:etag"`
+	Body        []byte
+
+	CreatedAt time.Time
+	ExpiresAt time.Time `gorm:"index"` // After this the key may be reused for a new request
+}
+
+// Completed reports whether the response to the request has been stored.
+func (r IdempotencyRecord) Completed() bool {
+	return r.StatusCode != 0
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Plant is an entry in a user's plant catalog: a species or variety that can
+// be planted in beds. Placing it in a bed is recorded as a Planting.
+type Plant struct {
+	ID             string    `json:"id"`
+	UserID         string    `json:"user_id"`          // Owner (auth subject) of the catalog entry
+	Name           string    `json:"name"`             // Common name, e.g. "Tomato"
+	Species        string    `json:"species"`          // Botanical name, e.g. "Solanum lycopersicum"
+	Variety        string    `json:"variety"`          // Cultivar, e.g. "Cherokee Purple"
+	DaysToMaturity int       `json:"days_to_maturity"` // From sowing/transplant to first harvest; 0 if unknown
+	Spacing        string    `json:"spacing"`          // Recommended spacing, e.g. "24in"
+	Sun            string    `json:"sun"`              // Light requirement, e.g. "Full sun"
+	Notes          string    `json:"notes"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// NewPlant creates a new Plant with default values
+func NewPlant(name, species, variety string, daysToMaturity int, spacing, sun, notes string) Plant {
+	now := time.Now()
+	return Plant{
+		ID:             uuid.New().String(),
+		Name:           name,
+		Species:        species,
+		Variety:        variety,
+		DaysToMaturity: daysToMaturity,
+		Spacing:        spacing,
+		Sun:            sun,
+		Notes:          notes,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+// DisplayName returns the plant's name with its variety, e.g. "Tomato (Cherokee Purple)".
+func (p Plant) DisplayName() string {
+	if p.Variety == "" {
+		return p.Name
+	}
+	return p.Name + " (" + p.Variety + ")"
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Planting records a Plant placed in a Bed: how many, when it was sown or
+// transplanted, and where it is in its life cycle.
+type Planting struct {
+	ID             string     `json:"id"`
+	UserID         string     `json:"user_id"`   // Owner, inherited from the parent Bed
+	GardenID       string     `json:"garden_id"` // Garden of the bed, kept for filtering
+	BedID          string     `json:"bed_id"`    // Foreign key to Bed
+	PlantID        string     `json:"plant_id"`  // Foreign key to Plant
+	Quantity       int        `json:"quantity"`
+	SowDate        *time.Time `json:"sow_date,omitempty"`
+	TransplantDate *time.Time `json:"transplant_date,omitempty"`
+	Status         string     `json:"status"`
+	Notes          string     `json:"notes"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// Planting status constants
+const (
+	PlantingStatusPlanned      = "Planned"
+	PlantingStatusSown         = "Sown"
+	PlantingStatusTransplanted = "Transplanted"
+	PlantingStatusGrowing      = "Growing"
+	PlantingStatusHarvested    = "Harvested"
+	PlantingStatusRemoved      = "Removed"
+)
+
+// PlantingStatuses lists every valid planting status in life-cycle order.
+var PlantingStatuses = []string{
+	PlantingStatusPlanned,
+	PlantingStatusSown,
+	PlantingStatusTransplanted,
+	PlantingStatusGrowing,
+	PlantingStatusHarvested,
+	PlantingStatusRemoved,
+}
+
+// IsValidPlantingStatus reports whether status is one of PlantingStatuses.
+func IsValidPlantingStatus(status string) bool {
+	for _, s := range PlantingStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// NewPlanting creates a new Planting with default values
+func NewPlanting(bedID, plantID string, quantity int, sowDate, transplantDate *time.Time, status, notes string) Planting {
+	now := time.Now()
+
+	// Set default status if empty
+	if status == "" {
+		status = PlantingStatusPlanned
+	}
+
+	// A planting is at least one plant
+	if quantity <= 0 {
+		quantity = 1
+	}
+
+	return Planting{
+		ID:             uuid.New().String(),
+		BedID:          bedID,
+		PlantID:        plantID,
+		Quantity:       quantity,
+		SowDate:        sowDate,
+		TransplantDate: transplantDate,
+		Status:         status,
+		Notes:          notes,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
@@ -4,17 +4,46 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // Task represents a task in the system
 type Task struct {
 	ID          string    `json:"id"`
+	UserID      string    `json:"user_id"`       // Owner, inherited from the parent Garden
 	GardenID    string    `json:"garden_id"`     // Foreign key to Garden
 	BedID       *string   `json:"garden_bed_id"` // Foreign key to Bed (nullable)
 	Description string    `json:"description"`
 	DueDate     time.Time `json:"due_date"`
 	Status      string    `json:"status"`
 	Priority    string    `json:"priority"`
+
+	// Recurring tasks: Recurrence holds the series' RRULE (see package
+	// recurrence) and each occurrence is its own Task row. RecurrenceID is the
+	// slot the occurrence was originally scheduled for, which stays fixed when
+	// a single occurrence is rescheduled.
+	Recurrence   string     `json:"recurrence,omitempty"`
+	SeriesID     *string    `json:"series_id,omitempty" gorm:"index"`
+	Occurrence   int        `json:"occurrence,omitempty"`
+	RecurrenceID *time.Time `json:"recurrence_id,omitempty"`
+
+	Version   int            `json:"version" gorm:"not null;default:1"` // See Garden.Version
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-"` // See Garden.DeletedAt
+}
+
+// TaskSeries holds the shared fields and schedule of a recurring task. Only
+// the next open occurrence exists as a Task; closing it creates the one after.
+type TaskSeries struct {
+	ID          string    `json:"id"`
+	UserID      string    `json:"user_id"`
+	GardenID    string    `json:"garden_id"`
+	BedID       *string   `json:"garden_bed_id"`
+	Description string    `json:"description"`
+	Priority    string    `json:"priority"`
+	Recurrence  string    `json:"recurrence"` // Empty once the series has been ended
+	Start       time.Time `json:"start"`      // Due date of the first occurrence
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
@@ -35,6 +64,43 @@ const (
 	PriorityHigh   = "High"
 )
 
+// IsRecurring reports whether the task is an occurrence of a TaskSeries.
+func (t Task) IsRecurring() bool {
+	return t.SeriesID != nil && *t.SeriesID != ""
+}
+
+// IsClosed reports whether the task has been completed or cancelled.
+func (t Task) IsClosed() bool {
+	return t.Status == TaskStatusCompleted || t.Status == TaskStatusCancelled
+}
+
+// IsOpen reports whether work on the task is still outstanding and it can
+// become overdue.
+func (t Task) IsOpen() bool {
+	return t.Status == TaskStatusPending || t.Status == TaskStatusInProgress
+}
+
+// EvaluateStatus returns the status the task should have at now: open tasks
+// past their due date become Overdue, and Overdue tasks whose due date has
+// moved into the future reopen with the status they had before, previous,
+// as recorded in their history (Pending if it is unknown). Tasks without a
+// due date and closed tasks keep their status.
+func (t Task) EvaluateStatus(now time.Time, previous string) string {
+	if t.DueDate.IsZero() {
+		return t.Status
+	}
+	if t.IsOpen() && t.DueDate.Before(now) {
+		return TaskStatusOverdue
+	}
+	if t.Status == TaskStatusOverdue && !t.DueDate.Before(now) {
+		if previous == TaskStatusInProgress {
+			return previous
+		}
+		return TaskStatusPending
+	}
+	return t.Status
+}
+
 // NewTask creates a new Task with default values
 func NewTask(gardenID string, bedID *string, description string, dueDate time.Time, status string, priority string) Task {
 	now := time.Now()
@@ -57,6 +123,7 @@ func NewTask(gardenID string, bedID *string, description string, dueDate time.Ti
 		DueDate:     dueDate,
 		Status:      status,
 		Priority:    priority,
+		Version:     1,
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaskEvent records a status transition in a task's history
+type TaskEvent struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"user_id"`
+	TaskID     string    `json:"task_id" gorm:"index"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Reason     string    `json:"reason"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Reasons recorded for automatic status transitions
+const (
+	TaskEventReasonDuePassed = "due date passed"
+	TaskEventReasonDueMoved  = "due date moved into the future"
+)
+
+// NewTaskEvent creates a history entry for task moving to status toStatus
+func NewTaskEvent(task Task, toStatus, reason string, at time.Time) TaskEvent {
+	return TaskEvent{
+		ID:         uuid.New().String(),
+		UserID:     task.UserID,
+		TaskID:     task.ID,
+		FromStatus: task.Status,
+		ToStatus:   toStatus,
+		Reason:     reason,
+		CreatedAt:  at,
+	}
+}
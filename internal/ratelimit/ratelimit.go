@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limiter is an in-memory token-bucket rate limiter keyed by an arbitrary
+// string (an IP address, a device ID, ...). Each key may make up to limit
+// requests per period, refilled continuously. It is safe for concurrent use.
+type Limiter struct {
+	mu      sync.Mutex
+	rate    float64 // tokens per second
+	burst   float64
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// New returns a Limiter allowing limit requests per period for each key.
+func New(limit int, period time.Duration) *Limiter {
+	return &Limiter{
+		rate:    float64(limit) / period.Seconds(),
+		burst:   float64(limit),
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Allow consumes one request for key. If the key is over its limit it returns
+// false and how long the caller should wait before retrying.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	return false, wait
+}
+
+// Sweep forgets keys whose buckets have refilled completely, since they are
+// indistinguishable from new keys. Its signature matches scheduler.JobFunc so
+// it can run as a periodic job.
+func (l *Limiter) Sweep(ctx context.Context) (map[string]int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	var removed int64
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+			removed++
+		}
+	}
+	return map[string]int64{"keys_removed": removed, "keys_tracked": int64(len(l.buckets))}, nil
+}
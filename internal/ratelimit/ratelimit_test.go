@@ -0,0 +1,62 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestLimiter returns a Limiter driven by a fake clock the test can advance.
+func newTestLimiter(limit int, period time.Duration) (*Limiter, *time.Time) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := New(limit, period)
+	l.now = func() time.Time { return now }
+	return l, &now
+}
+
+func TestLimiter_BurstThenThrottle(t *testing.T) {
+	l, _ := newTestLimiter(3, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		ok, _ := l.Allow("1.2.3.4")
+		require.True(t, ok, "request %d should be allowed", i+1)
+	}
+
+	ok, wait := l.Allow("1.2.3.4")
+	assert.False(t, ok)
+	assert.Equal(t, 20*time.Second, wait)
+
+	// Other keys have their own budget.
+	ok, _ = l.Allow("5.6.7.8")
+	assert.True(t, ok)
+}
+
+func TestLimiter_Refills(t *testing.T) {
+	l, now := newTestLimiter(2, time.Minute)
+
+	l.Allow("device")
+	l.Allow("device")
+	ok, _ := l.Allow("device")
+	require.False(t, ok)
+
+	*now = now.Add(30 * time.Second)
+	ok, _ = l.Allow("device")
+	assert.True(t, ok)
+	ok, _ = l.Allow("device")
+	assert.False(t, ok)
+}
+
+func TestLimiter_SweepForgetsIdleKeys(t *testing.T) {
+	l, now := newTestLimiter(2, time.Minute)
+	l.Allow("idle")
+	*now = now.Add(time.Minute)
+	l.Allow("busy")
+
+	stats, err := l.Sweep(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats["keys_removed"])
+	assert.Equal(t, int64(1), stats["keys_tracked"])
+}
@@ -0,0 +1,217 @@
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported frequencies.
+const (
+	Daily   = "DAILY"
+	Weekly  = "WEEKLY"
+	Monthly = "MONTHLY"
+)
+
+// ErrInvalidRule is returned by Parse for rules outside the supported subset.
+var ErrInvalidRule = errors.New("invalid recurrence rule")
+
+const (
+	untilDateFormat     = "20060102"
+	untilDateTimeFormat = "20060102T150405Z"
+)
+
+// Rule is a parsed recurrence rule. Only a subset of RFC 5545 RRULE is
+// supported: FREQ=DAILY, WEEKLY or MONTHLY with optional INTERVAL and either
+// COUNT or UNTIL, e.g. "FREQ=DAILY;INTERVAL=2" for every other day.
+type Rule struct {
+	Freq     string
+	Interval int        // Number of Freq units between occurrences; at least 1
+	Count    int        // Total number of occurrences, including the first; 0 means unbounded
+	Until    *time.Time // Last instant an occurrence may fall on; nil means unbounded
+
+	untilIsDate bool // UNTIL was a DATE value, so it includes the whole day
+}
+
+// Parse parses an RRULE such as "FREQ=WEEKLY;INTERVAL=2;UNTIL=20250901" (an
+// optional "RRULE:" prefix is ignored) or one of the short forms "daily",
+// "weekly", "monthly" and "every N days|weeks|months".
+func Parse(s string) (Rule, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Rule{}, fmt.Errorf("%w: empty rule", ErrInvalidRule)
+	}
+	if rule, ok := parseShortForm(s); ok {
+		return rule, nil
+	}
+
+	s = strings.TrimPrefix(strings.ToUpper(s), "RRULE:")
+	rule := Rule{Interval: 1}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(s, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return Rule{}, fmt.Errorf("%w: malformed part %q", ErrInvalidRule, part)
+		}
+		if seen[key] {
+			return Rule{}, fmt.Errorf("%w: %s given twice", ErrInvalidRule, key)
+		}
+		seen[key] = true
+
+		switch key {
+		case "FREQ":
+			switch value {
+			case Daily, Weekly, Monthly:
+				rule.Freq = value
+			default:
+				return Rule{}, fmt.Errorf("%w: unsupported FREQ %s", ErrInvalidRule, value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return Rule{}, fmt.Errorf("%w: INTERVAL must be a positive integer", ErrInvalidRule)
+			}
+			rule.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return Rule{}, fmt.Errorf("%w: COUNT must be a positive integer", ErrInvalidRule)
+			}
+			rule.Count = n
+		case "UNTIL":
+			if t, err := time.Parse(untilDateTimeFormat, value); err == nil {
+				rule.Until = &t
+			} else if t, err := time.Parse(untilDateFormat, value); err == nil {
+				rule.Until = &t
+				rule.untilIsDate = true
+			} else {
+				return Rule{}, fmt.Errorf("%w: UNTIL must be YYYYMMDD or YYYYMMDDTHHMMSSZ", ErrInvalidRule)
+			}
+		default:
+			return Rule{}, fmt.Errorf("%w: unsupported part %s", ErrInvalidRule, key)
+		}
+	}
+
+	if rule.Freq == "" {
+		return Rule{}, fmt.Errorf("%w: FREQ is required", ErrInvalidRule)
+	}
+	if rule.Count > 0 && rule.Until != nil {
+		// RFC 5545 section 3.3.10: COUNT and UNTIL MUST NOT occur in the same rule
+		return Rule{}, fmt.Errorf("%w: COUNT and UNTIL cannot both be set", ErrInvalidRule)
+	}
+	return rule, nil
+}
+
+// parseShortForm handles "daily", "weekly", "monthly" and "every N <unit>".
+func parseShortForm(s string) (Rule, bool) {
+	fields := strings.Fields(strings.ToLower(s))
+	units := map[string]string{
+		"day": Daily, "days": Daily,
+		"week": Weekly, "weeks": Weekly,
+		"month": Monthly, "months": Monthly,
+	}
+	switch {
+	case len(fields) == 1:
+		switch fields[0] {
+		case "daily":
+			return Rule{Freq: Daily, Interval: 1}, true
+		case "weekly":
+			return Rule{Freq: Weekly, Interval: 1}, true
+		case "monthly":
+			return Rule{Freq: Monthly, Interval: 1}, true
+		}
+	case len(fields) == 2 && fields[0] == "every":
+		if freq, ok := units[fields[1]]; ok {
+			return Rule{Freq: freq, Interval: 1}, true
+		}
+	case len(fields) == 3 && fields[0] == "every":
+		n, err := strconv.Atoi(fields[1])
+		if freq, ok := units[fields[2]]; ok && err == nil && n >= 1 {
+			return Rule{Freq: freq, Interval: n}, true
+		}
+	}
+	return Rule{}, false
+}
+
+// String formats the rule as a canonical RRULE value (without the "RRULE:" prefix).
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		if r.untilIsDate {
+			parts = append(parts, "UNTIL="+r.Until.Format(untilDateFormat))
+		} else {
+			parts = append(parts, "UNTIL="+r.Until.UTC().Format(untilDateTimeFormat))
+		}
+	}
+	return strings.Join(parts, ";")
+}
+
+// Describe returns a human-readable summary such as "every 2 days, 5 times".
+func (r Rule) Describe() string {
+	unit := map[string]string{Daily: "day", Weekly: "week", Monthly: "month"}[r.Freq]
+	s := "every " + unit
+	if r.Interval > 1 {
+		s = fmt.Sprintf("every %d %ss", r.Interval, unit)
+	}
+	if r.Count > 0 {
+		s += fmt.Sprintf(", %d times", r.Count)
+	}
+	if r.Until != nil {
+		s += ", until " + r.Until.Format("2006-01-02")
+	}
+	return s
+}
+
+// Next returns the occurrence following prev, which was occurrence number n
+// (1-based) of the series. ok is false once the series is over because of
+// COUNT or UNTIL. Monthly rules skip months that lack prev's day, as RFC 5545
+// requires, so a series on the 31st falls on the 31st of each long month.
+func (r Rule) Next(prev time.Time, n int) (next time.Time, ok bool) {
+	if r.Count > 0 && n >= r.Count {
+		return time.Time{}, false
+	}
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	switch r.Freq {
+	case Daily:
+		next = prev.AddDate(0, 0, interval)
+	case Weekly:
+		next = prev.AddDate(0, 0, 7*interval)
+	case Monthly:
+		y, m, d := prev.Date()
+		for k := 1; ; k++ {
+			next = time.Date(y, m+time.Month(interval*k), d, prev.Hour(), prev.Minute(), prev.Second(), prev.Nanosecond(), prev.Location())
+			if next.Day() == d {
+				break
+			}
+		}
+	default:
+		return time.Time{}, false
+	}
+
+	if r.Until != nil && r.after(next) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+// after reports whether t falls after the rule's UNTIL bound.
+func (r Rule) after(t time.Time) bool {
+	if r.untilIsDate {
+		y, m, d := t.Date()
+		day := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+		return day.After(*r.Until)
+	}
+	return t.After(*r.Until)
+}
@@ -0,0 +1,96 @@
+package recurrence_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zjpiazza/plantastic/internal/recurrence"
+)
+
+func TestParse_RRULE(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"FREQ=DAILY", "FREQ=DAILY"},
+		{"RRULE:FREQ=DAILY;INTERVAL=2", "FREQ=DAILY;INTERVAL=2"},
+		{"freq=weekly;count=6", "FREQ=WEEKLY;COUNT=6"},
+		{"FREQ=MONTHLY;UNTIL=20251231", "FREQ=MONTHLY;UNTIL=20251231"},
+		{"FREQ=WEEKLY;INTERVAL=1;UNTIL=20250901T120000Z", "FREQ=WEEKLY;UNTIL=20250901T120000Z"},
+		{"every 2 days", "FREQ=DAILY;INTERVAL=2"},
+		{"Weekly", "FREQ=WEEKLY"},
+		{"every month", "FREQ=MONTHLY"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			rule, err := recurrence.Parse(tt.in)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, rule.String())
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"FREQ=YEARLY",
+		"INTERVAL=2",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;COUNT=3;UNTIL=20250101",
+		"FREQ=WEEKLY;BYDAY=MO",
+		"FREQ=DAILY;FREQ=WEEKLY",
+		"every 0 days",
+		"fortnightly",
+	} {
+		_, err := recurrence.Parse(in)
+		assert.ErrorIs(t, err, recurrence.ErrInvalidRule, in)
+	}
+}
+
+func TestRule_NextEveryNDays(t *testing.T) {
+	rule, err := recurrence.Parse("FREQ=DAILY;INTERVAL=2;COUNT=3")
+	require.NoError(t, err)
+
+	start := time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC)
+	second, ok := rule.Next(start, 1)
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2025, 6, 3, 8, 0, 0, 0, time.UTC), second)
+
+	third, ok := rule.Next(second, 2)
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2025, 6, 5, 8, 0, 0, 0, time.UTC), third)
+
+	_, ok = rule.Next(third, 3)
+	assert.False(t, ok, "COUNT=3 ends the series after the third occurrence")
+}
+
+func TestRule_NextMonthlySkipsShortMonths(t *testing.T) {
+	rule, err := recurrence.Parse("FREQ=MONTHLY")
+	require.NoError(t, err)
+
+	next, ok := rule.Next(time.Date(2025, 1, 31, 9, 0, 0, 0, time.UTC), 1)
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2025, 3, 31, 9, 0, 0, 0, time.UTC), next)
+}
+
+func TestRule_NextStopsAtUntil(t *testing.T) {
+	rule, err := recurrence.Parse("FREQ=WEEKLY;UNTIL=20250615")
+	require.NoError(t, err)
+
+	// A DATE-valued UNTIL includes the whole day, whatever the time zone.
+	loc := time.FixedZone("EDT", -4*60*60)
+	next, ok := rule.Next(time.Date(2025, 6, 8, 20, 0, 0, 0, loc), 1)
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2025, 6, 15, 20, 0, 0, 0, loc), next)
+
+	_, ok = rule.Next(next, 2)
+	assert.False(t, ok)
+}
+
+func TestRule_Describe(t *testing.T) {
+	rule, err := recurrence.Parse("FREQ=DAILY;INTERVAL=2;COUNT=5")
+	require.NoError(t, err)
+	assert.Equal(t, "every 2 days, 5 times", rule.Describe())
+}
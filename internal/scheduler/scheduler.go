@@ -0,0 +1,212 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrJobNotFound is returned by RunNow for an unregistered job name.
+	ErrJobNotFound = errors.New("job not found")
+	// ErrJobExists is returned when registering a name twice.
+	ErrJobExists = errors.New("job already registered")
+	// ErrJobRunning is returned by RunNow when the job is already in progress.
+	ErrJobRunning = errors.New("job is already running")
+)
+
+// JobFunc performs one run of a periodic job and returns counters describing
+// what it did (e.g. {"purged": 12}), which are kept as the job's last-run stats.
+type JobFunc func(ctx context.Context) (map[string]int64, error)
+
+// JobStatus is a snapshot of a job's schedule and most recent run.
+type JobStatus struct {
+	Name         string           `json:"name"`
+	Interval     string           `json:"interval"`
+	Running      bool             `json:"running"`
+	Runs         int64            `json:"runs"`
+	Failures     int64            `json:"failures"`
+	LastRunAt    *time.Time       `json:"last_run_at,omitempty"`
+	LastDuration string           `json:"last_duration,omitempty"`
+	LastError    string           `json:"last_error,omitempty"`
+	LastStats    map[string]int64 `json:"last_stats,omitempty"`
+	NextRunAt    *time.Time       `json:"next_run_at,omitempty"`
+}
+
+type job struct {
+	name     string
+	interval time.Duration
+	run      JobFunc
+	running  bool
+	status   JobStatus
+}
+
+// Scheduler runs registered jobs on fixed intervals in the background. Each
+// job runs once at start-up and then every interval; a job never overlaps
+// with itself. Jobs may be registered before or after Start.
+type Scheduler struct {
+	mu      sync.Mutex
+	jobs    map[string]*job
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	started bool
+}
+
+// New creates an empty, stopped Scheduler.
+func New() *Scheduler {
+	return &Scheduler{jobs: make(map[string]*job)}
+}
+
+// Register adds a job that runs fn every interval.
+func (s *Scheduler) Register(name string, interval time.Duration, fn JobFunc) error {
+	if name == "" {
+		return fmt.Errorf("job name cannot be empty")
+	}
+	if interval <= 0 {
+		return fmt.Errorf("job %s: interval must be positive", name)
+	}
+	if fn == nil {
+		return fmt.Errorf("job %s: func cannot be nil", name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.jobs[name]; exists {
+		return fmt.Errorf("%w: %s", ErrJobExists, name)
+	}
+	j := &job{
+		name:     name,
+		interval: interval,
+		run:      fn,
+		status:   JobStatus{Name: name, Interval: interval.String()},
+	}
+	s.jobs[name] = j
+	if s.started {
+		s.launch(j)
+	}
+	return nil
+}
+
+// Start launches every registered job. It returns immediately; jobs stop when
+// ctx is cancelled or Stop is called.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return
+	}
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	s.started = true
+	for _, j := range s.jobs {
+		s.launch(j)
+	}
+}
+
+// Stop cancels all jobs and waits for in-flight runs to return.
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	if !s.started {
+		s.mu.Unlock()
+		return
+	}
+	s.cancel()
+	s.started = false
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+// RunNow runs the named job synchronously, outside its schedule.
+func (s *Scheduler) RunNow(ctx context.Context, name string) error {
+	s.mu.Lock()
+	j, ok := s.jobs[name]
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrJobNotFound, name)
+	}
+	return s.runJob(ctx, j)
+}
+
+// Status returns a snapshot of every job, sorted by name.
+func (s *Scheduler) Status() []JobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	statuses := make([]JobStatus, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		st := j.status
+		st.Running = j.running
+		if j.status.LastStats != nil {
+			st.LastStats = make(map[string]int64, len(j.status.LastStats))
+			for k, v := range j.status.LastStats {
+				st.LastStats[k] = v
+			}
+		}
+		statuses = append(statuses, st)
+	}
+	sort.Slice(statuses, func(a, b int) bool { return statuses[a].Name < statuses[b].Name })
+	return statuses
+}
+
+// launch starts j's loop. Callers must hold s.mu.
+func (s *Scheduler) launch(j *job) {
+	ctx := s.ctx
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+		for {
+			if err := s.runJob(ctx, j); err != nil && !errors.Is(err, ErrJobRunning) {
+				log.Printf("Scheduler: job %s failed: %v\n", j.name, err)
+			}
+			s.mu.Lock()
+			next := time.Now().Add(j.interval)
+			j.status.NextRunAt = &next
+			s.mu.Unlock()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// runJob runs j once, recording its outcome. Panics are reported as errors.
+func (s *Scheduler) runJob(ctx context.Context, j *job) (err error) {
+	s.mu.Lock()
+	if j.running {
+		s.mu.Unlock()
+		return ErrJobRunning
+	}
+	j.running = true
+	s.mu.Unlock()
+
+	start := time.Now()
+	var stats map[string]int64
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job %s panicked: %v", j.name, r)
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		j.running = false
+		j.status.Runs++
+		j.status.LastRunAt = &start
+		j.status.LastDuration = time.Since(start).String()
+		j.status.LastStats = stats
+		j.status.LastError = ""
+		if err != nil {
+			j.status.Failures++
+			j.status.LastError = err.Error()
+		}
+	}()
+
+	stats, err = j.run(ctx)
+	return err
+}
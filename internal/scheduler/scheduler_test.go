@@ -0,0 +1,82 @@
+package scheduler_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zjpiazza/plantastic/internal/scheduler"
+)
+
+func TestScheduler_RunNowRecordsStats(t *testing.T) {
+	s := scheduler.New()
+	require.NoError(t, s.Register("janitor", time.Hour, func(ctx context.Context) (map[string]int64, error) {
+		return map[string]int64{"purged": 3}, nil
+	}))
+
+	require.NoError(t, s.RunNow(context.Background(), "janitor"))
+
+	statuses := s.Status()
+	require.Len(t, statuses, 1)
+	assert.Equal(t, "janitor", statuses[0].Name)
+	assert.Equal(t, int64(1), statuses[0].Runs)
+	assert.Equal(t, int64(0), statuses[0].Failures)
+	assert.Equal(t, map[string]int64{"purged": 3}, statuses[0].LastStats)
+	assert.NotNil(t, statuses[0].LastRunAt)
+}
+
+func TestScheduler_RecordsFailuresAndPanics(t *testing.T) {
+	s := scheduler.New()
+	require.NoError(t, s.Register("failing", time.Hour, func(ctx context.Context) (map[string]int64, error) {
+		return nil, errors.New("boom")
+	}))
+	require.NoError(t, s.Register("panicking", time.Hour, func(ctx context.Context) (map[string]int64, error) {
+		panic("oops")
+	}))
+
+	assert.EqualError(t, s.RunNow(context.Background(), "failing"), "boom")
+	assert.Error(t, s.RunNow(context.Background(), "panicking"))
+
+	for _, st := range s.Status() {
+		assert.Equal(t, int64(1), st.Failures, st.Name)
+		assert.NotEmpty(t, st.LastError, st.Name)
+	}
+}
+
+func TestScheduler_RegisterValidation(t *testing.T) {
+	s := scheduler.New()
+	noop := func(ctx context.Context) (map[string]int64, error) { return nil, nil }
+
+	require.NoError(t, s.Register("job", time.Minute, noop))
+	assert.ErrorIs(t, s.Register("job", time.Minute, noop), scheduler.ErrJobExists)
+	assert.Error(t, s.Register("", time.Minute, noop))
+	assert.Error(t, s.Register("zero", 0, noop))
+	assert.ErrorIs(t, s.RunNow(context.Background(), "missing"), scheduler.ErrJobNotFound)
+}
+
+func TestScheduler_StartRunsJobsPeriodically(t *testing.T) {
+	s := scheduler.New()
+	var runs atomic.Int64
+	require.NoError(t, s.Register("tick", 10*time.Millisecond, func(ctx context.Context) (map[string]int64, error) {
+		runs.Add(1)
+		return nil, nil
+	}))
+
+	s.Start(context.Background())
+	assert.Eventually(t, func() bool { return runs.Load() >= 3 }, time.Second, 5*time.Millisecond)
+	s.Stop()
+
+	// A job registered after Start still gets scheduled.
+	s.Start(context.Background())
+	defer s.Stop()
+	var late atomic.Int64
+	require.NoError(t, s.Register("late", time.Hour, func(ctx context.Context) (map[string]int64, error) {
+		late.Add(1)
+		return nil, nil
+	}))
+	assert.Eventually(t, func() bool { return late.Load() == 1 }, time.Second, 5*time.Millisecond)
+}
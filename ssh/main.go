@@ -2,13 +2,14 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
-	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"sort"
 	"strings"
 	"syscall"
 	"time"
@@ -24,6 +25,7 @@ import (
 	bm "github.com/charmbracelet/wish/bubbletea"
 	lm "github.com/charmbracelet/wish/logging"
 
+	"github.com/zjpiazza/plantastic/internal/client"
 	"github.com/zjpiazza/plantastic/internal/models"
 )
 
@@ -84,98 +86,58 @@ func (t tabsModel) View() string {
 	return lipgloss.JoinHorizontal(lipgloss.Top, tabViews...)
 }
 
-// PlantasticClient is the API client for Plantastic.
-type PlantasticClient struct {
-	// Replace with actual API configuration.
-	BaseURL string
-	APIKey  string
+// getGardens fetches every garden.
+func getGardens(ctx context.Context, api *client.Client) ([]models.Garden, error) {
+	return client.Collect(client.All(ctx, api.ListGardens, client.ListOptions{}))
 }
 
-// GetGardens fetches gardens from the API.
-func (c *PlantasticClient) GetGardens() ([]models.Garden, error) {
-	request, err := http.NewRequest("GET", fmt.Sprintf("%s/gardens", c.BaseURL), nil)
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %v", err)
-	}
-
-	response, err := http.DefaultClient.Do(request)
-	if err != nil {
-		return nil, fmt.Errorf("API connection error: %v", err)
-	}
-	defer response.Body.Close()
-
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API returned error: %s", response.Status)
-	}
-
-	var gardens []models.Garden
-	if err := json.NewDecoder(response.Body).Decode(&gardens); err != nil {
-		return nil, fmt.Errorf("error parsing response: %v", err)
+// getBeds fetches the beds in a garden; an empty gardenID fetches every bed.
+func getBeds(ctx context.Context, api *client.Client, gardenID string) ([]models.Bed, error) {
+	list := api.ListBeds
+	if gardenID != "" {
+		list = func(ctx context.Context, opts client.ListOptions) (client.Page[models.Bed], error) {
+			return api.ListGardenBeds(ctx, gardenID, opts)
+		}
 	}
-
-	return gardens, nil
+	return client.Collect(client.All(ctx, list, client.ListOptions{}))
 }
 
-// GetBeds fetches beds from the API.
-func (c *PlantasticClient) GetBeds(gardenID string) ([]models.Bed, error) {
-	request, err := http.NewRequest("GET", fmt.Sprintf("%s/beds", c.BaseURL), nil)
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %v", err)
-	}
-
-	response, err := http.DefaultClient.Do(request)
-	if err != nil {
-		return nil, fmt.Errorf("API connection error: %v", err)
-	}
-	defer response.Body.Close()
-
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API returned error: %s", response.Status)
-	}
-
-	var beds []models.Bed
-	if err := json.NewDecoder(response.Body).Decode(&beds); err != nil {
-		return nil, fmt.Errorf("error parsing response: %v", err)
+// getTasks fetches tasks, narrowed to a garden and bed when given.
+func getTasks(ctx context.Context, api *client.Client, gardenID, bedID string) ([]models.Task, error) {
+	list := api.ListTasks
+	opts := client.ListOptions{}
+	switch {
+	case gardenID != "" && bedID != "":
+		list = func(ctx context.Context, opts client.ListOptions) (client.Page[models.Task], error) {
+			return api.ListBedTasks(ctx, gardenID, bedID, opts)
+		}
+	case gardenID != "":
+		list = func(ctx context.Context, opts client.ListOptions) (client.Page[models.Task], error) {
+			return api.ListGardenTasks(ctx, gardenID, opts)
+		}
+	case bedID != "":
+		opts.Filters = url.Values{"garden_bed_id": {bedID}}
 	}
-
-	return beds, nil
+	return client.Collect(client.All(ctx, list, opts))
 }
 
-// GetTasks fetches tasks from the API.
-func (c *PlantasticClient) GetTasks(gardenID, bedID string) ([]models.Task, error) {
-	request, err := http.NewRequest("GET", fmt.Sprintf("%s/tasks", c.BaseURL), nil)
+// bulkTasks applies ops all-or-nothing: if any of them fails, none are
+// applied and the error describes the one that failed.
+func bulkTasks(ctx context.Context, api *client.Client, ops []client.BulkTaskOp) error {
+	result, err := api.BulkTasks(ctx, client.BulkAtomic, ops)
 	if err != nil {
-		return nil, fmt.Errorf("error creating request: %v", err)
+		return err
 	}
-
-	response, err := http.DefaultClient.Do(request)
-	if err != nil {
-		return nil, fmt.Errorf("API connection error: %v", err)
-	}
-	defer response.Body.Close()
-
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API returned error: %s", response.Status)
+	if failed := result.Failed(); len(failed) > 0 {
+		return fmt.Errorf("task %s: %v; nothing was changed", failed[0].ID, failed[0].Err())
 	}
-
-	var tasks []models.Task
-	if err := json.NewDecoder(response.Body).Decode(&tasks); err != nil {
-		return nil, fmt.Errorf("error parsing response: %v", err)
-	}
-
-	return tasks, nil
-}
-
-// CompleteTask marks a task as completed.
-func (c *PlantasticClient) CompleteTask(taskID string) error {
-	// Placeholder - replace with actual API call.
-	fmt.Println("Completing task:", taskID)
 	return nil
 }
 
 // Model represents the application state.
 type Model struct {
-	client    PlantasticClient
+	ctx       context.Context // Ends with the session, cancelling its requests
+	client    *client.Client
 	tabs      tabsModel
 	activeTab int
 
@@ -189,6 +151,14 @@ type Model struct {
 	selectedBed    *models.Bed
 	selectedTask   *models.Task
 
+	// Tasks marked for a bulk action, by ID
+	marked map[string]bool
+
+	// Recent changes to the selected item, shown with its details
+	showHistory bool
+	historyFor  string // "entity/id" the history was fetched for
+	history     []models.AuditEntry
+
 	// Tables
 	gardenTable table.Model
 	bedTable    table.Model
@@ -220,6 +190,9 @@ type keyMap struct {
 	Tab      key.Binding
 	Select   key.Binding
 	Complete key.Binding
+	Mark     key.Binding
+	Postpone key.Binding
+	History  key.Binding
 	Refresh  key.Binding
 	Quit     key.Binding
 	Help     key.Binding
@@ -257,6 +230,18 @@ var keys = keyMap{
 		key.WithKeys("c"),
 		key.WithHelp("c", "complete task"),
 	),
+	Mark: key.NewBinding(
+		key.WithKeys(" "),
+		key.WithHelp("space", "mark task"),
+	),
+	Postpone: key.NewBinding(
+		key.WithKeys("p"),
+		key.WithHelp("p", "postpone a day"),
+	),
+	History: key.NewBinding(
+		key.WithKeys("v"),
+		key.WithHelp("v", "toggle history"),
+	),
 	Refresh: key.NewBinding(
 		key.WithKeys("r"),
 		key.WithHelp("r", "refresh"),
@@ -277,11 +262,11 @@ var keys = keyMap{
 // FullHelp implements help.KeyMap.
 func (k keyMap) FullHelp() [][]key.Binding {
 	navigation := []key.Binding{k.Up, k.Down, k.Left, k.Right}
-	actions := []key.Binding{k.Select, k.Refresh}
+	actions := []key.Binding{k.Select, k.History, k.Refresh}
 
-	// Only add the Complete binding when on the Tasks tab
+	// Only add the task actions when on the Tasks tab
 	if k.activeTab == TaskTab {
-		actions = append(actions, k.Complete)
+		actions = append(actions, k.Complete, k.Mark, k.Postpone)
 	}
 
 	system := []key.Binding{k.Help, k.Quit}
@@ -293,16 +278,16 @@ func (k keyMap) FullHelp() [][]key.Binding {
 func (k keyMap) ShortHelp() []key.Binding {
 	result := []key.Binding{k.Tab, k.Select, k.Refresh, k.Quit}
 
-	// Only add the Complete binding when on the Tasks tab
+	// Only add the task actions when on the Tasks tab
 	if k.activeTab == TaskTab {
-		result = append(result, k.Complete)
+		result = append(result, k.Complete, k.Mark)
 	}
 
 	return result
 }
 
 // NewModel initializes the model.
-func NewModel() Model {
+func NewModel(ctx context.Context, api *client.Client) Model {
 	// Gardens table
 	gardenCols := []table.Column{
 		{Title: "ID", Width: 5},
@@ -322,6 +307,7 @@ func NewModel() Model {
 
 	// Tasks table
 	taskCols := []table.Column{
+		{Title: "✓", Width: 2},
 		{Title: "ID", Width: 5},
 		{Title: "Description", Width: 30},
 		{Title: "Due Date", Width: 12},
@@ -374,15 +360,14 @@ func NewModel() Model {
 	localKeys.activeTab = GardenTab
 
 	return Model{
-		client: PlantasticClient{
-			BaseURL: "http://localhost:8080",
-			APIKey:  "your-api-key",
-		},
+		ctx:         ctx,
+		client:      api,
 		tabs:        tabsModel,
 		activeTab:   GardenTab,
 		gardenTable: gardenTable,
 		bedTable:    bedTable,
 		taskTable:   taskTable,
+		marked:      map[string]bool{},
 		spinner:     s,
 		loading:     true,
 		help:        help.New(),
@@ -412,6 +397,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Quit
 		case key.Matches(msg, m.keys.Help):
 			m.help.ShowAll = !m.help.ShowAll
+		case key.Matches(msg, m.keys.History):
+			m.showHistory = !m.showHistory
+			m.historyFor, m.history = "", nil
 		case key.Matches(msg, m.keys.Refresh):
 			m.loading = true
 			switch m.activeTab {
@@ -457,9 +445,36 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			keys.activeTab = m.activeTab
 			m.keys = keys
 
-		case key.Matches(msg, m.keys.Complete):
+		case key.Matches(msg, m.keys.Mark):
 			if m.activeTab == TaskTab && m.selectedTask != nil {
-				return m, m.completeTask(m.selectedTask.ID)
+				if m.marked[m.selectedTask.ID] {
+					delete(m.marked, m.selectedTask.ID)
+				} else {
+					m.marked[m.selectedTask.ID] = true
+				}
+				m.updateTaskTable()
+				return m, nil // Space also pages the table; don't pass it on
+			}
+
+		case key.Matches(msg, m.keys.Complete):
+			if m.activeTab == TaskTab {
+				if marked := m.markedTasks(); len(marked) > 0 {
+					return m, m.bulkTasks(marked, "complete", 0)
+				}
+				if m.selectedTask != nil {
+					return m, m.completeTask(*m.selectedTask)
+				}
+			}
+
+		case key.Matches(msg, m.keys.Postpone):
+			if m.activeTab == TaskTab {
+				targets := m.markedTasks()
+				if len(targets) == 0 && m.selectedTask != nil {
+					targets = []models.Task{*m.selectedTask}
+				}
+				if len(targets) > 0 {
+					return m, m.bulkTasks(targets, "reschedule", 1)
+				}
 			}
 		}
 
@@ -548,11 +563,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.tasks = msg
 		m.updateTaskTable()
 
+	case fetchHistoryMsg:
+		if msg.subject == m.historyFor {
+			m.history = msg.entries
+		}
+
 	case fetchErrMsg:
 		m.loading = false
 		m.err = msg
 
-	case completeTaskMsg:
+	case completeTaskMsg, bulkTasksMsg:
+		m.marked = map[string]bool{}
+		m.historyFor = "" // Fetched again once the tasks are
 		gardenID := ""
 		bedID := ""
 		if m.selectedGarden != nil {
@@ -618,6 +640,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	// Keep the history in step with the selection
+	if m.showHistory {
+		if entity, id := m.historySubject(); id != "" && entity+"/"+id != m.historyFor {
+			m.historyFor, m.history = entity+"/"+id, nil
+			cmds = append(cmds, m.fetchHistory(entity, id))
+		}
+	}
+
 	return m, tea.Batch(cmds...)
 }
 
@@ -774,7 +804,12 @@ func (m Model) renderDetails() string {
 			)
 
 			// Add a hint to complete the task if it's not already completed
-			if strings.ToLower(m.selectedTask.Status) != "completed" {
+			if marked := len(m.markedTasks()); marked > 0 {
+				details += "\n" + lipgloss.NewStyle().
+					Italic(true).
+					Foreground(lipgloss.Color("246")).
+					Render(fmt.Sprintf("%d marked: 'c' to complete, 'p' to postpone a day", marked))
+			} else if strings.ToLower(m.selectedTask.Status) != "completed" {
 				details += "\n" + lipgloss.NewStyle().
 					Italic(true).
 					Foreground(lipgloss.Color("246")).
@@ -783,6 +818,10 @@ func (m Model) renderDetails() string {
 		}
 	}
 
+	if details != "" && m.showHistory {
+		details += "\n\n" + m.renderHistory()
+	}
+
 	if details != "" {
 		// Add a subtle box around the details
 		return lipgloss.NewStyle().
@@ -795,6 +834,64 @@ func (m Model) renderDetails() string {
 	return ""
 }
 
+// historySubject returns the audit log entity and ID of the selected item on
+// the active tab; the ID is empty if nothing is selected.
+func (m Model) historySubject() (entity, id string) {
+	switch m.activeTab {
+	case GardenTab:
+		if m.selectedGarden != nil {
+			return "garden", m.selectedGarden.ID
+		}
+	case BedTab:
+		if m.selectedBed != nil {
+			return "bed", m.selectedBed.ID
+		}
+	case TaskTab:
+		if m.selectedTask != nil {
+			return "task", m.selectedTask.ID
+		}
+	}
+	return "", ""
+}
+
+// renderHistory renders the recent changes to the selected item, one line
+// each: when, what and by whom, and for an update the fields it changed.
+func (m Model) renderHistory() string {
+	heading := lipgloss.NewStyle().Bold(true).Render("History")
+	if len(m.history) == 0 {
+		return heading + "\n" + lipgloss.NewStyle().Italic(true).Render("No changes recorded")
+	}
+
+	lines := []string{heading}
+	for _, e := range m.history {
+		actor := e.ActorType
+		if e.ActorID != "" {
+			actor += " " + e.ActorID
+		}
+		line := fmt.Sprintf("%s  %s by %s", e.CreatedAt.Local().Format("Jan 02 15:04"), e.Action, actor)
+		if e.Action == models.AuditUpdate {
+			line += ": " + strings.Join(changedFields(e), ", ")
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// changedFields returns the sorted names of the fields an update changed.
+func changedFields(e models.AuditEntry) []string {
+	var fields []string
+	for field := range e.After {
+		fields = append(fields, field)
+	}
+	for field := range e.Before {
+		if _, ok := e.After[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
 // Custom messages
 type (
 	fetchGardensMsg []models.Garden
@@ -802,12 +899,17 @@ type (
 	fetchTasksMsg   []models.Task
 	fetchErrMsg     error
 	completeTaskMsg struct{}
+	bulkTasksMsg    struct{}
+	fetchHistoryMsg struct {
+		subject string // "entity/id"
+		entries []models.AuditEntry
+	}
 )
 
 // Commands
 func (m Model) fetchGardens() tea.Cmd {
 	return func() tea.Msg {
-		gardens, err := m.client.GetGardens()
+		gardens, err := getGardens(m.ctx, m.client)
 		if err != nil {
 			return fetchErrMsg(err)
 		}
@@ -817,7 +919,7 @@ func (m Model) fetchGardens() tea.Cmd {
 
 func (m Model) fetchBeds(gardenID string) tea.Cmd {
 	return func() tea.Msg {
-		beds, err := m.client.GetBeds(gardenID)
+		beds, err := getBeds(m.ctx, m.client, gardenID)
 		if err != nil {
 			return fetchErrMsg(err)
 		}
@@ -827,7 +929,7 @@ func (m Model) fetchBeds(gardenID string) tea.Cmd {
 
 func (m Model) fetchTasks(gardenID, bedID string) tea.Cmd {
 	return func() tea.Msg {
-		tasks, err := m.client.GetTasks(gardenID, bedID)
+		tasks, err := getTasks(m.ctx, m.client, gardenID, bedID)
 		if err != nil {
 			return fetchErrMsg(err)
 		}
@@ -835,9 +937,27 @@ func (m Model) fetchTasks(gardenID, bedID string) tea.Cmd {
 	}
 }
 
-func (m Model) completeTask(taskID string) tea.Cmd {
+// historyLength is how many recent changes the history shows.
+const historyLength = 5
+
+func (m Model) fetchHistory(entity, id string) tea.Cmd {
+	return func() tea.Msg {
+		entries, err := m.client.History(m.ctx, entity, id, historyLength)
+		if err != nil {
+			return fetchErrMsg(err)
+		}
+		return fetchHistoryMsg{subject: entity + "/" + id, entries: entries}
+	}
+}
+
+func (m Model) completeTask(task models.Task) tea.Cmd {
 	return func() tea.Msg {
-		err := m.client.CompleteTask(taskID)
+		// Conditional on the version shown, so a change made elsewhere in the
+		// meantime is not overwritten
+		_, err := m.client.CompleteTask(m.ctx, task.ID, task.Version)
+		if errors.Is(err, client.ErrVersionConflict) {
+			return fetchErrMsg(fmt.Errorf("task was changed elsewhere; refresh and try again"))
+		}
 		if err != nil {
 			return fetchErrMsg(err)
 		}
@@ -845,6 +965,32 @@ func (m Model) completeTask(taskID string) tea.Cmd {
 	}
 }
 
+// bulkTasks applies op to tasks in one all-or-nothing batch, each conditional
+// on the version shown in the table.
+func (m Model) bulkTasks(tasks []models.Task, op string, shiftDays int) tea.Cmd {
+	ops := make([]client.BulkTaskOp, len(tasks))
+	for i, t := range tasks {
+		ops[i] = client.BulkTaskOp{Op: op, ID: t.ID, ShiftDays: shiftDays, Version: t.Version}
+	}
+	return func() tea.Msg {
+		if err := bulkTasks(m.ctx, m.client, ops); err != nil {
+			return fetchErrMsg(err)
+		}
+		return bulkTasksMsg{}
+	}
+}
+
+// markedTasks returns the listed tasks that are marked for a bulk action.
+func (m Model) markedTasks() []models.Task {
+	var marked []models.Task
+	for _, t := range m.tasks {
+		if m.marked[t.ID] {
+			marked = append(marked, t)
+		}
+	}
+	return marked
+}
+
 // updateGardenTable updates the garden table data.
 func (m *Model) updateGardenTable() {
 	rows := []table.Row{}
@@ -878,7 +1024,12 @@ func (m *Model) updateBedTable() {
 func (m *Model) updateTaskTable() {
 	rows := []table.Row{}
 	for _, t := range m.tasks {
+		mark := ""
+		if m.marked[t.ID] {
+			mark = "✓"
+		}
 		rows = append(rows, table.Row{
+			mark,
 			t.ID,
 			t.Description,
 			t.DueDate.String(),
@@ -889,8 +1040,8 @@ func (m *Model) updateTaskTable() {
 	m.taskTable.SetRows(rows)
 }
 
-func teaProgram() tea.Model {
-	m := NewModel()
+func teaProgram(api *client.Client) tea.Model {
+	m := NewModel(context.Background(), api)
 
 	// Set reasonable initial window dimensions for local mode
 	// These will be updated when the terminal sends a WindowSizeMsg
@@ -900,7 +1051,14 @@ func teaProgram() tea.Model {
 	return m
 }
 
-func teaHandler(s ssh.Session) (tea.Model, []tea.ProgramOption) {
+// newTeaHandler returns the handler that starts a TUI for each SSH session.
+func newTeaHandler(api *client.Client) bm.Handler {
+	return func(s ssh.Session) (tea.Model, []tea.ProgramOption) {
+		return teaHandler(s, api)
+	}
+}
+
+func teaHandler(s ssh.Session, api *client.Client) (tea.Model, []tea.ProgramOption) {
 	pty, _, active := s.Pty()
 	if !active {
 		// Handle non-pty sessions if necessary.
@@ -914,7 +1072,7 @@ func teaHandler(s ssh.Session) (tea.Model, []tea.ProgramOption) {
 		height = pty.Window.Height
 	}
 
-	m := NewModel()
+	m := NewModel(s.Context(), api)
 	m.width = width
 	m.height = height
 
@@ -930,20 +1088,32 @@ func teaHandler(s ssh.Session) (tea.Model, []tea.ProgramOption) {
 	return m, opts
 }
 
+// envOr returns the environment variable key, or fallback if it is unset.
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
 func main() {
 	// Set up SSH server
 	// Enable flag for local development
 	local := flag.Bool("local", false, "Run in local mode")
+	apiURL := flag.String("api-url", envOr("PLANTASTIC_API_URL", client.DefaultBaseURL), "Plantastic API URL")
+	token := flag.String("token", os.Getenv("PLANTASTIC_TOKEN"), "Bearer token to authenticate with")
 	flag.Parse()
 
+	api := client.New(*apiURL, *token)
+
 	if *local {
 		fmt.Println("Running Plantastic in local mode")
 		fmt.Println("Press 'q' to quit, '?' for help")
-		fmt.Println("Connecting to API at http://localhost:8080...")
+		fmt.Printf("Connecting to API at %s...\n", api.BaseURL)
 
 		// Create a new program with the BubbleTea model
 		p := tea.NewProgram(
-			teaProgram(),
+			teaProgram(api),
 			tea.WithAltScreen(),
 			tea.WithMouseCellMotion(),
 		)
@@ -960,7 +1130,7 @@ func main() {
 				return "Welcome to Plantastic"
 			}),
 			wish.WithMiddleware(
-				bm.Middleware(teaHandler),
+				bm.Middleware(newTeaHandler(api)),
 				lm.Middleware(),
 			),
 		)